@@ -0,0 +1,7 @@
+package application
+
+const DEFAULT_APPLICATION = "default"
+
+type ApplicationGetter interface {
+	GetApplication(id string) *ApplicationEntity
+}
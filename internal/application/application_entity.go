@@ -0,0 +1,162 @@
+package application
+
+import (
+	"strings"
+	"time"
+)
+
+type ApplicationEntity struct {
+	Id       string         `json:"-"`
+	Quota    QuotaEntity    `json:"quota"`
+	Policies []PolicyEntity `json:"policies"`
+	Presets  []PresetEntity `json:"presets"`
+	// StripMetadata removes exif, xmp and gps blocks of uploaded images
+	StripMetadata bool `json:"strip_metadata"`
+	// MaxVersions is the number of content versions kept per file, zero keeps every version
+	MaxVersions int `json:"max_versions"`
+	// Lifecycle rules expire uploaded files which do not set their own expiry
+	// and transition stored files between providers
+	Lifecycle []LifecycleRuleEntity `json:"lifecycle"`
+}
+
+// FindPolicy returns the upload policy of the given provider,
+// policy without provider applies to every provider which has no specific policy
+func (e *ApplicationEntity) FindPolicy(provider string) *PolicyEntity {
+	var fallback *PolicyEntity
+	for i, policy := range e.Policies {
+		if policy.Provider == provider {
+			return &e.Policies[i]
+		}
+		if policy.Provider == "" && fallback == nil {
+			fallback = &e.Policies[i]
+		}
+	}
+	return fallback
+}
+
+// FindExpiryRule returns the first lifecycle rule expiring the uploaded file
+func (e *ApplicationEntity) FindExpiryRule(provider, mimetype string, size int64, tags []string) *LifecycleRuleEntity {
+	for i, rule := range e.Lifecycle {
+		if rule.ExpireAfter > 0 && rule.Matches(provider, mimetype, size, tags) {
+			return &e.Lifecycle[i]
+		}
+	}
+	return nil
+}
+
+// FindTransitionRule returns the first lifecycle rule moving the file away from its current provider
+func (e *ApplicationEntity) FindTransitionRule(provider, mimetype string, size int64, tags []string) *LifecycleRuleEntity {
+	for i, rule := range e.Lifecycle {
+		if rule.TransitionTo != "" && rule.TransitionTo != provider && rule.Matches(provider, mimetype, size, tags) {
+			return &e.Lifecycle[i]
+		}
+	}
+	return nil
+}
+
+type QuotaEntity struct {
+	MaxTotalSize int64 `json:"max_total_size"`
+	MaxFileCount int64 `json:"max_file_count"`
+	MaxFileSize  int64 `json:"max_file_size"`
+}
+
+type PolicyEntity struct {
+	Provider          string   `json:"provider"`
+	AllowedExtensions []string `json:"allowed_extensions"`
+	AllowedMimetypes  []string `json:"allowed_mimetypes"`
+	MinSize           int64    `json:"min_size"`
+	MaxSize           int64    `json:"max_size"`
+	MinWidth          int      `json:"min_width"`
+	MaxWidth          int      `json:"max_width"`
+	MinHeight         int      `json:"min_height"`
+	MaxHeight         int      `json:"max_height"`
+	MaxFiles          int      `json:"max_files"`
+}
+
+// PresetEntity is a named image variant generated right after upload,
+// the values follow the resource transformation query parameters
+type PresetEntity struct {
+	Name    string `json:"name"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	Fit     string `json:"fit"`
+	Crop    string `json:"crop"`
+	Quality int    `json:"quality"`
+	Format  string `json:"format"`
+}
+
+type applicationConfigEntity struct {
+	Default      ApplicationEntity            `json:"default"`
+	Applications map[string]ApplicationEntity `json:"applications"`
+}
+
+// LifecycleRuleEntity matches files having the provider, one of the mimetypes, at least the size and every tag,
+// empty condition matches every file and mimetype may end with a wildcard, e.g: `image/*`
+type LifecycleRuleEntity struct {
+	Provider  string   `json:"provider"`
+	Mimetypes []string `json:"mimetypes"`
+	Tags      []string `json:"tags"`
+	MinSize   int64    `json:"min_size"`
+	// ExpireAfter is the `second` a matching file lives after its upload, zero never expires
+	ExpireAfter int64 `json:"expire_after"`
+	// TransitionTo is the provider a matching file is moved to, empty never moves the file
+	TransitionTo string `json:"transition_to"`
+	// TransitionAfter is the `second` after upload before the file is moved
+	TransitionAfter int64 `json:"transition_after"`
+	// TransitionAfterAccess is the `second` without access before the file is moved
+	TransitionAfterAccess int64 `json:"transition_after_access"`
+}
+
+func (e *LifecycleRuleEntity) Matches(provider, mimetype string, size int64, tags []string) bool {
+	if e.Provider != "" && e.Provider != provider {
+		return false
+	}
+
+	if size < e.MinSize {
+		return false
+	}
+
+	isMimetypeMatched := len(e.Mimetypes) == 0
+	for _, pattern := range e.Mimetypes {
+		if pattern == mimetype || (strings.HasSuffix(pattern, "/*") && strings.HasPrefix(mimetype, strings.TrimSuffix(pattern, "*"))) {
+			isMimetypeMatched = true
+			break
+		}
+	}
+	if !isMimetypeMatched {
+		return false
+	}
+
+	for _, required := range e.Tags {
+		isTagged := false
+		for _, tag := range tags {
+			if tag == required {
+				isTagged = true
+				break
+			}
+		}
+		if !isTagged {
+			return false
+		}
+	}
+	return true
+}
+
+// IsTransitionDue tells whether the file has been stored and left unaccessed long enough,
+// a file which was never accessed counts from its upload
+func (e *LifecycleRuleEntity) IsTransitionDue(createdAt, accessedAt *time.Time, now time.Time) bool {
+	if createdAt == nil {
+		return false
+	}
+	if e.TransitionAfter > 0 && now.Before(createdAt.Add(time.Duration(e.TransitionAfter)*time.Second)) {
+		return false
+	}
+	lastAccess := createdAt
+	if accessedAt != nil && accessedAt.After(*createdAt) {
+		lastAccess = accessedAt
+	}
+	if e.TransitionAfterAccess > 0 && now.Before(lastAccess.Add(time.Duration(e.TransitionAfterAccess)*time.Second)) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,165 @@
+package application_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/application"
+)
+
+var _ = Describe("Application Entity", func() {
+	Context("FindPolicy method", func() {
+		var (
+			app *application.ApplicationEntity
+		)
+
+		BeforeEach(func() {
+			app = &application.ApplicationEntity{
+				Policies: []application.PolicyEntity{
+					{MaxSize: 100},
+					{Provider: "backup", MaxSize: 200},
+				},
+			}
+		})
+
+		When("provider has specific policy", func() {
+			It("should return the provider policy", func() {
+				policy := app.FindPolicy("backup")
+
+				Expect(policy.MaxSize).To(Equal(int64(200)))
+			})
+		})
+
+		When("provider has no specific policy", func() {
+			It("should return the generic policy", func() {
+				policy := app.FindPolicy("local")
+
+				Expect(policy.MaxSize).To(Equal(int64(100)))
+			})
+		})
+
+		When("application has no policy", func() {
+			It("should return nil", func() {
+				app.Policies = nil
+				policy := app.FindPolicy("local")
+
+				Expect(policy).To(BeNil())
+			})
+		})
+	})
+
+	Context("FindExpiryRule method", func() {
+		var (
+			app *application.ApplicationEntity
+		)
+
+		BeforeEach(func() {
+			app = &application.ApplicationEntity{
+				Lifecycle: []application.LifecycleRuleEntity{
+					{Tags: []string{"export"}, TransitionTo: "archive"},
+					{Tags: []string{"export", "temporary"}, ExpireAfter: 86400},
+					{Provider: "local", Mimetypes: []string{"image/*"}, ExpireAfter: 3600},
+					{MinSize: 1024, ExpireAfter: 60},
+				},
+			}
+		})
+
+		When("file has every tag of the rule", func() {
+			It("should return the expiring rule", func() {
+				rule := app.FindExpiryRule("local", "text/csv", 10, []string{"temporary", "export", "daily"})
+
+				Expect(rule.ExpireAfter).To(Equal(int64(86400)))
+			})
+		})
+
+		When("file mimetype matches the wildcard", func() {
+			It("should return the rule", func() {
+				rule := app.FindExpiryRule("local", "image/png", 10, []string{"export"})
+
+				Expect(rule.ExpireAfter).To(Equal(int64(3600)))
+			})
+		})
+
+		When("file reaches the minimum size", func() {
+			It("should return the rule", func() {
+				rule := app.FindExpiryRule("backup", "text/csv", 2048, nil)
+
+				Expect(rule.ExpireAfter).To(Equal(int64(60)))
+			})
+		})
+
+		When("no rule matches the file", func() {
+			It("should return nil", func() {
+				rule := app.FindExpiryRule("backup", "image/png", 10, nil)
+
+				Expect(rule).To(BeNil())
+			})
+		})
+	})
+
+	Context("FindTransitionRule method", func() {
+		var (
+			app *application.ApplicationEntity
+		)
+
+		BeforeEach(func() {
+			app = &application.ApplicationEntity{
+				Lifecycle: []application.LifecycleRuleEntity{
+					{Tags: []string{"export"}, ExpireAfter: 86400},
+					{Mimetypes: []string{"video/*"}, TransitionTo: "archive"},
+				},
+			}
+		})
+
+		When("file matches a transition rule", func() {
+			It("should return the rule", func() {
+				rule := app.FindTransitionRule("local", "video/mp4", 10, []string{"export"})
+
+				Expect(rule.TransitionTo).To(Equal("archive"))
+			})
+		})
+
+		When("file is already held by the target provider", func() {
+			It("should return nil", func() {
+				rule := app.FindTransitionRule("archive", "video/mp4", 10, nil)
+
+				Expect(rule).To(BeNil())
+			})
+		})
+	})
+
+	Context("IsTransitionDue method", func() {
+		var (
+			rule      *application.LifecycleRuleEntity
+			createdAt time.Time
+			now       time.Time
+		)
+
+		BeforeEach(func() {
+			rule = &application.LifecycleRuleEntity{TransitionTo: "archive", TransitionAfter: 3600, TransitionAfterAccess: 600}
+			createdAt = time.Unix(1640858210, 0)
+			now = createdAt.Add(2 * time.Hour)
+		})
+
+		When("file is younger than the rule age", func() {
+			It("should return false", func() {
+				Expect(rule.IsTransitionDue(&createdAt, nil, createdAt.Add(time.Minute))).To(BeFalse())
+			})
+		})
+
+		When("file was accessed recently", func() {
+			It("should return false", func() {
+				accessedAt := now.Add(-time.Minute)
+
+				Expect(rule.IsTransitionDue(&createdAt, &accessedAt, now)).To(BeFalse())
+			})
+		})
+
+		When("file was never accessed", func() {
+			It("should count from its upload", func() {
+				Expect(rule.IsTransitionDue(&createdAt, nil, now)).To(BeTrue())
+			})
+		})
+	})
+})
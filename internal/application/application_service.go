@@ -0,0 +1,49 @@
+package application
+
+import (
+	"io/ioutil"
+
+	"idaman.id/storage/internal/serialization"
+)
+
+type applicationService struct {
+	defaultApp   ApplicationEntity
+	applications map[string]ApplicationEntity
+}
+
+// GetApplication returns settings of the given application,
+// unknown or empty id falls back to the default application settings
+func (s *applicationService) GetApplication(id string) *ApplicationEntity {
+	app, isAvailable := s.applications[id]
+	if !isAvailable {
+		app = s.defaultApp
+		if id == "" {
+			id = DEFAULT_APPLICATION
+		}
+	}
+	app.Id = id
+	return &app
+}
+
+func NewApplicationService(configFile string, d serialization.Decoder) (ApplicationGetter, error) {
+	cfg := applicationConfigEntity{}
+	if configFile != "" {
+		data, err := ioutil.ReadFile(configFile)
+		if err != nil {
+			return nil, err
+		}
+		err = d.Decode(data, &cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Applications == nil {
+		cfg.Applications = map[string]ApplicationEntity{}
+	}
+	s := &applicationService{
+		defaultApp:   cfg.Default,
+		applications: cfg.Applications,
+	}
+	return s, nil
+}
@@ -0,0 +1,98 @@
+package application_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/application"
+	"idaman.id/storage/internal/serialization"
+)
+
+var _ = Describe("Application Service", func() {
+	var (
+		configFile string
+	)
+
+	BeforeEach(func() {
+		configFile = filepath.Join(GinkgoT().TempDir(), "application.json")
+		content := `{
+			"default": {"quota": {"max_total_size": 1000, "max_file_count": 10, "max_file_size": 100}},
+			"applications": {
+				"billing-app": {
+					"quota": {"max_total_size": 5000},
+					"presets": [{"name": "thumb", "width": 150, "height": 150, "fit": "cover"}],
+					"strip_metadata": true,
+					"max_versions": 5
+				}
+			}
+		}`
+		err := os.WriteFile(configFile, []byte(content), 0644)
+		Expect(err).To(BeNil())
+	})
+
+	Context("NewApplicationService function", func() {
+		When("config file is not found", func() {
+			It("should return error", func() {
+				s, err := application.NewApplicationService("not-found.json", serialization.NewJsonSerialization())
+
+				Expect(s).To(BeNil())
+				Expect(err).ToNot(BeNil())
+			})
+		})
+
+		When("config file is not specified", func() {
+			It("should return unlimited default application", func() {
+				s, err := application.NewApplicationService("", serialization.NewJsonSerialization())
+				Expect(err).To(BeNil())
+
+				app := s.GetApplication("")
+				Expect(app).To(Equal(&application.ApplicationEntity{Id: application.DEFAULT_APPLICATION}))
+			})
+		})
+	})
+
+	Context("GetApplication method", func() {
+		var (
+			appGetter application.ApplicationGetter
+		)
+
+		BeforeEach(func() {
+			var err error
+			appGetter, err = application.NewApplicationService(configFile, serialization.NewJsonSerialization())
+			Expect(err).To(BeNil())
+		})
+
+		When("application is configured", func() {
+			It("should return application settings", func() {
+				app := appGetter.GetApplication("billing-app")
+
+				Expect(app.Id).To(Equal("billing-app"))
+				Expect(app.Quota).To(Equal(application.QuotaEntity{MaxTotalSize: 5000}))
+				Expect(app.Presets).To(Equal([]application.PresetEntity{
+					{Name: "thumb", Width: 150, Height: 150, Fit: "cover"},
+				}))
+				Expect(app.StripMetadata).To(BeTrue())
+				Expect(app.MaxVersions).To(Equal(5))
+			})
+		})
+
+		When("application is not configured", func() {
+			It("should return default settings", func() {
+				app := appGetter.GetApplication("other-app")
+
+				Expect(app.Id).To(Equal("other-app"))
+				Expect(app.Quota).To(Equal(application.QuotaEntity{MaxTotalSize: 1000, MaxFileCount: 10, MaxFileSize: 100}))
+			})
+		})
+
+		When("application id is empty", func() {
+			It("should return default application", func() {
+				app := appGetter.GetApplication("")
+
+				Expect(app.Id).To(Equal(application.DEFAULT_APPLICATION))
+			})
+		})
+	})
+})
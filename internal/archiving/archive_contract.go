@@ -0,0 +1,51 @@
+package archiving
+
+import (
+	"io"
+
+	"idaman.id/storage/internal/auth"
+)
+
+const (
+	SKIP_REASON_NOT_FOUND   = "not_found"
+	SKIP_REASON_FORBIDDEN   = "forbidden"
+	SKIP_REASON_UNAVAILABLE = "unavailable"
+	SKIP_REASON_EXPIRED     = "expired"
+	SKIP_REASON_UNREADABLE  = "unreadable"
+
+	// SKIPPED_MANIFEST_NAME lists the skipped files at the end of the archive
+	SKIPPED_MANIFEST_NAME = "skipped.txt"
+)
+
+type ArchivePreparer interface {
+	// PrepareArchive resolves and authorizes every requested file before anything is streamed,
+	// files which can not be archived are skipped instead of failing the whole archive
+	PrepareArchive(p PrepareArchiveParam) (*ArchiveEntity, error)
+}
+
+type ArchiveWriter interface {
+	// WriteArchive streams the prepared files as zip into Writer one by one,
+	// the archive is never staged on disk
+	WriteArchive(p WriteArchiveParam) error
+}
+
+type ArchiveService interface {
+	ArchivePreparer
+	ArchiveWriter
+}
+
+type PrepareArchiveParam struct {
+	Claims *auth.Claims
+	Files  []ArchiveFileParam
+}
+
+// ArchiveFileParam places the file inside Folder, empty Folder places it at the archive root
+type ArchiveFileParam struct {
+	Identifier string
+	Folder     string
+}
+
+type WriteArchiveParam struct {
+	Archive *ArchiveEntity
+	Writer  io.Writer
+}
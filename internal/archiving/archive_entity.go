@@ -0,0 +1,24 @@
+package archiving
+
+import "time"
+
+type ArchiveEntity struct {
+	Entries   []EntryEntity
+	Skipped   []SkippedEntity
+	TotalSize int64
+}
+
+type EntryEntity struct {
+	Identifier string
+	// Name is the unique slash separated path inside the archive
+	Name         string
+	FileLocation string
+	FileName     string
+	Size         int64
+	ModifiedAt   *time.Time
+}
+
+type SkippedEntity struct {
+	Identifier string
+	Reason     string
+}
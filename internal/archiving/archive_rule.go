@@ -0,0 +1,23 @@
+package archiving
+
+type archiveRule struct {
+	Files []archiveFileRule `json:"files" validate:"required,min=1,dive"`
+}
+
+type archiveFileRule struct {
+	Identifier string `json:"identifier" validate:"required,max=250"`
+	Folder     string `json:"folder" validate:"max=255"`
+}
+
+func NewArchiveRule(p PrepareArchiveParam) *archiveRule {
+	ar := archiveRule{
+		Files: []archiveFileRule{},
+	}
+	for _, f := range p.Files {
+		ar.Files = append(ar.Files, archiveFileRule{
+			Identifier: f.Identifier,
+			Folder:     f.Folder,
+		})
+	}
+	return &ar
+}
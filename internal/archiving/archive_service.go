@@ -0,0 +1,213 @@
+package archiving
+
+import (
+	"archive/zip"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"idaman.id/storage/internal/config"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/file"
+	"idaman.id/storage/internal/repository"
+	"idaman.id/storage/internal/scanning"
+	"idaman.id/storage/internal/storage"
+	"idaman.id/storage/internal/validation"
+)
+
+type archiveService struct {
+	validator        validation.Validator
+	configGetter     config.Getter
+	fileRepo         repository.FileRepository
+	storageRetriever storage.Retriever
+}
+
+func (s *archiveService) PrepareArchive(p PrepareArchiveParam) (*ArchiveEntity, error) {
+	err := s.validator.Validate(*NewArchiveRule(p))
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Files) > s.configGetter.GetInt("ARCHIVE_MAX_FILES") {
+		return nil, newInvalidFilesError("max")
+	}
+
+	archive := &ArchiveEntity{
+		Entries: []EntryEntity{},
+		Skipped: []SkippedEntity{},
+	}
+	maxSize := int64(s.configGetter.GetInt("ARCHIVE_MAX_SIZE"))
+	names := map[string]bool{}
+	for _, f := range p.Files {
+		fileRecord, err := s.fileRepo.FindByIdentifier(f.Identifier)
+		if _, isNotFound := err.(*app_error.NotfoundError); isNotFound {
+			archive.skip(f.Identifier, SKIP_REASON_NOT_FOUND)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// private files are only archived for an authenticated caller of the same tenant,
+		// a tenant-less private file belongs to no caller
+		isPrivate := fileRecord.Visibility == file.VISIBILITY_PRIVATE
+		isOwnTenant := p.Claims != nil && p.Claims.Tenant == fileRecord.TenantId
+		if !p.Claims.CanAccessTenant(fileRecord.TenantId) || (isPrivate && !isOwnTenant) {
+			archive.skip(f.Identifier, SKIP_REASON_FORBIDDEN)
+			continue
+		}
+		if !scanning.IsServable(fileRecord.ScanStatus) {
+			archive.skip(f.Identifier, SKIP_REASON_UNAVAILABLE)
+			continue
+		}
+		if fileRecord.IsExpired(time.Now()) {
+			archive.skip(f.Identifier, SKIP_REASON_EXPIRED)
+			continue
+		}
+
+		archive.TotalSize += fileRecord.Size
+		if maxSize > 0 && archive.TotalSize > maxSize {
+			return nil, newInvalidFilesError("max_size")
+		}
+
+		modifiedAt := fileRecord.UpdatedAt
+		if modifiedAt == nil {
+			modifiedAt = fileRecord.CreatedAt
+		}
+		archive.Entries = append(archive.Entries, EntryEntity{
+			Identifier:   f.Identifier,
+			Name:         uniqueName(names, path.Join(cleanFolder(f.Folder), entryName(fileRecord))),
+			FileLocation: fileRecord.FileLocation,
+			FileName:     fileRecord.FileName,
+			Size:         fileRecord.Size,
+			ModifiedAt:   modifiedAt,
+		})
+	}
+
+	if len(archive.Entries) == 0 {
+		return nil, app_error.NewNotfoundError("File")
+	}
+	return archive, nil
+}
+
+// WriteArchive reads one file at a time from storage, a file which can not be read anymore
+// is reported in the skipped manifest since the response has already started
+func (s *archiveService) WriteArchive(p WriteArchiveParam) error {
+	zw := zip.NewWriter(p.Writer)
+	skipped := append([]SkippedEntity{}, p.Archive.Skipped...)
+	names := map[string]bool{}
+	for _, e := range p.Archive.Entries {
+		names[e.Name] = true
+
+		localPath := fmt.Sprintf("%s/%s", e.FileLocation, e.FileName)
+		fileData, err := s.storageRetriever.RetrieveFile(localPath)
+		if err != nil {
+			skipped = append(skipped, SkippedEntity{Identifier: e.Identifier, Reason: SKIP_REASON_UNREADABLE})
+			continue
+		}
+
+		header := &zip.FileHeader{
+			Name:   e.Name,
+			Method: zip.Deflate,
+		}
+		if e.ModifiedAt != nil {
+			header.Modified = *e.ModifiedAt
+		}
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(fileData)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(skipped) > 0 {
+		w, err := zw.Create(uniqueName(names, SKIPPED_MANIFEST_NAME))
+		if err != nil {
+			return err
+		}
+		for _, sk := range skipped {
+			_, err = fmt.Fprintf(w, "%s\t%s\n", sk.Identifier, sk.Reason)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return zw.Close()
+}
+
+func (a *ArchiveEntity) skip(identifier, reason string) {
+	a.Skipped = append(a.Skipped, SkippedEntity{
+		Identifier: identifier,
+		Reason:     reason,
+	})
+}
+
+func newInvalidFilesError(tag string) error {
+	return app_error.NewValidationError([]app_error.ValidationItem{
+		{
+			Field:   "files",
+			Message: fmt.Sprintf("Key: 'files' Error:Field validation for 'files' failed on the '%s' tag", tag),
+		},
+	})
+}
+
+// entryName is the original file name carrying the current extension,
+// falling back to the slug when the original name is not usable
+func entryName(fileRecord *repository.FileModel) string {
+	name := cleanName(strings.TrimSuffix(fileRecord.OriginalName, filepath.Ext(fileRecord.OriginalName)))
+	if name == "" {
+		name = fileRecord.Slug
+	}
+	if fileRecord.Extension == "" {
+		return name
+	}
+	return fmt.Sprintf("%s.%s", name, fileRecord.Extension)
+}
+
+// cleanFolder keeps the folder inside the archive root, dropping empty, `.` and `..` segments
+func cleanFolder(folder string) string {
+	segments := []string{}
+	for _, segment := range strings.FieldsFunc(folder, func(r rune) bool { return r == '/' || r == '\\' }) {
+		segment = cleanName(segment)
+		if segment == "" || segment == "." || segment == ".." {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// cleanName replaces path separators and control characters of a single path segment
+func cleanName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '/' || r == '\\' || r == ':' {
+			return '_'
+		}
+		return r
+	}, name)
+	return strings.TrimSpace(name)
+}
+
+// uniqueName suffixes the name before its extension when it is already used, e.g: `report (2).pdf`
+func uniqueName(names map[string]bool, name string) string {
+	res := name
+	ext := path.Ext(name)
+	for i := 2; names[res]; i++ {
+		res = fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), i, ext)
+	}
+	names[res] = true
+	return res
+}
+
+func NewArchiveService(v validation.Validator, cg config.Getter, fr repository.FileRepository, sr storage.Retriever) ArchiveService {
+	return &archiveService{
+		validator:        v,
+		configGetter:     cg,
+		fileRepo:         fr,
+		storageRetriever: sr,
+	}
+}
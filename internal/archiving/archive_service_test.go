@@ -0,0 +1,195 @@
+package archiving_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/archiving"
+	"idaman.id/storage/internal/auth"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/file"
+	"idaman.id/storage/internal/repository"
+	"idaman.id/storage/internal/scanning"
+	validation_go "idaman.id/storage/internal/validation-go"
+)
+
+var _ = Describe("Archive Service", func() {
+	var (
+		service  archiving.ArchiveService
+		config   *FakeConfig
+		fileRepo *FakeFileRepository
+		store    *FakeStorage
+		claims   *auth.Claims
+	)
+
+	BeforeEach(func() {
+		config = &FakeConfig{values: map[string]interface{}{
+			"ARCHIVE_MAX_FILES": 10,
+			"ARCHIVE_MAX_SIZE":  100,
+		}}
+		validator, err := validation_go.NewGoValidator(config)
+		Expect(err).To(BeNil())
+
+		expiredAt := time.Now().Add(-time.Minute)
+
+		fileRepo = &FakeFileRepository{files: []repository.FileModel{
+			{UniqueId: "a", Slug: "report", OriginalName: "report.pdf", Extension: "pdf", Size: 10, FileLocation: "storage", FileName: "a.pdf", Visibility: file.VISIBILITY_PUBLIC},
+			{UniqueId: "b", Slug: "report-b", OriginalName: "report.pdf", Extension: "pdf", Size: 20, FileLocation: "storage", FileName: "b.pdf", Visibility: file.VISIBILITY_PUBLIC},
+			{UniqueId: "c", Slug: "other", OriginalName: "other.png", Extension: "png", Size: 30, FileLocation: "storage", FileName: "c.png", TenantId: "other-tenant"},
+			{UniqueId: "d", Slug: "infected", OriginalName: "infected.exe", Extension: "exe", Size: 5, FileLocation: "storage", FileName: "d.exe", ScanStatus: scanning.SCAN_STATUS_INFECTED},
+			{UniqueId: "e", Slug: "secret", OriginalName: "secret.txt", Extension: "txt", Size: 5, FileLocation: "storage", FileName: "e.txt", TenantId: "tenant", Visibility: file.VISIBILITY_PRIVATE},
+			{UniqueId: "f", Slug: "lost", OriginalName: "lost.txt", Extension: "txt", Size: 5, FileLocation: "storage", FileName: "f.txt"},
+			{UniqueId: "g", Slug: "big", OriginalName: "big.bin", Extension: "bin", Size: 90, FileLocation: "storage", FileName: "g.bin"},
+			{UniqueId: "h", Slug: "export", OriginalName: "export.csv", Extension: "csv", Size: 5, FileLocation: "storage", FileName: "h.csv", ExpiresAt: &expiredAt},
+			{UniqueId: "i", Slug: "orphan", OriginalName: "orphan.txt", Extension: "txt", Size: 5, FileLocation: "storage", FileName: "i.txt", Visibility: file.VISIBILITY_PRIVATE},
+		}}
+		store = &FakeStorage{files: map[string]string{
+			"storage/a.pdf": "first report",
+			"storage/b.pdf": "second report",
+			"storage/e.txt": "secret",
+		}}
+		claims = &auth.Claims{Subject: "user", Tenant: "tenant"}
+		service = archiving.NewArchiveService(validator, config, fileRepo, store)
+	})
+
+	Describe("PrepareArchive function", func() {
+		When("no file requested", func() {
+			It("should return validation error", func() {
+				res, err := service.PrepareArchive(archiving.PrepareArchiveParam{Claims: claims, Files: []archiving.ArchiveFileParam{}})
+
+				Expect(res).To(BeNil())
+				Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+			})
+		})
+
+		When("too many files requested", func() {
+			It("should return validation error", func() {
+				config.values["ARCHIVE_MAX_FILES"] = 1
+				res, err := service.PrepareArchive(archiving.PrepareArchiveParam{Claims: claims, Files: []archiving.ArchiveFileParam{
+					{Identifier: "a"}, {Identifier: "b"},
+				}})
+
+				Expect(res).To(BeNil())
+				Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+			})
+		})
+
+		When("total size exceeds the maximum", func() {
+			It("should return validation error", func() {
+				res, err := service.PrepareArchive(archiving.PrepareArchiveParam{Claims: claims, Files: []archiving.ArchiveFileParam{
+					{Identifier: "a"}, {Identifier: "b"}, {Identifier: "g"},
+				}})
+
+				Expect(res).To(BeNil())
+				Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+			})
+		})
+
+		When("no file can be archived", func() {
+			It("should return not found error", func() {
+				res, err := service.PrepareArchive(archiving.PrepareArchiveParam{Claims: claims, Files: []archiving.ArchiveFileParam{
+					{Identifier: "missing"}, {Identifier: "c"},
+				}})
+
+				Expect(res).To(BeNil())
+				Expect(err).To(Equal(app_error.NewNotfoundError("File")))
+			})
+		})
+
+		When("some files can not be archived", func() {
+			It("should skip them with the reason", func() {
+				res, err := service.PrepareArchive(archiving.PrepareArchiveParam{Claims: claims, Files: []archiving.ArchiveFileParam{
+					{Identifier: "a"}, {Identifier: "missing"}, {Identifier: "c"}, {Identifier: "d"}, {Identifier: "h"},
+				}})
+
+				Expect(err).To(BeNil())
+				Expect(res.Entries).To(HaveLen(1))
+				Expect(res.Skipped).To(Equal([]archiving.SkippedEntity{
+					{Identifier: "missing", Reason: archiving.SKIP_REASON_NOT_FOUND},
+					{Identifier: "c", Reason: archiving.SKIP_REASON_FORBIDDEN},
+					{Identifier: "d", Reason: archiving.SKIP_REASON_UNAVAILABLE},
+					{Identifier: "h", Reason: archiving.SKIP_REASON_EXPIRED},
+				}))
+				Expect(res.TotalSize).To(Equal(int64(10)))
+			})
+		})
+
+		When("private file requested without claims", func() {
+			It("should skip the file", func() {
+				res, err := service.PrepareArchive(archiving.PrepareArchiveParam{Files: []archiving.ArchiveFileParam{
+					{Identifier: "a"}, {Identifier: "e"},
+				}})
+
+				Expect(err).To(BeNil())
+				Expect(res.Entries).To(HaveLen(1))
+				Expect(res.Skipped).To(Equal([]archiving.SkippedEntity{
+					{Identifier: "e", Reason: archiving.SKIP_REASON_FORBIDDEN},
+				}))
+			})
+		})
+
+		When("private file has no tenant", func() {
+			It("should skip the file", func() {
+				res, err := service.PrepareArchive(archiving.PrepareArchiveParam{Claims: claims, Files: []archiving.ArchiveFileParam{
+					{Identifier: "a"}, {Identifier: "i"},
+				}})
+
+				Expect(err).To(BeNil())
+				Expect(res.Entries).To(HaveLen(1))
+				Expect(res.Skipped).To(Equal([]archiving.SkippedEntity{
+					{Identifier: "i", Reason: archiving.SKIP_REASON_FORBIDDEN},
+				}))
+			})
+		})
+
+		When("folders are given", func() {
+			It("should keep entries inside the archive and deduplicate names", func() {
+				res, err := service.PrepareArchive(archiving.PrepareArchiveParam{Claims: claims, Files: []archiving.ArchiveFileParam{
+					{Identifier: "a", Folder: "../../docs/./2022"},
+					{Identifier: "b", Folder: "docs\\2022"},
+					{Identifier: "e", Folder: "/"},
+				}})
+
+				Expect(err).To(BeNil())
+				Expect(res.Entries).To(HaveLen(3))
+				Expect(res.Entries[0].Name).To(Equal("docs/2022/report.pdf"))
+				Expect(res.Entries[1].Name).To(Equal("docs/2022/report (2).pdf"))
+				Expect(res.Entries[2].Name).To(Equal("secret.txt"))
+			})
+		})
+	})
+
+	Describe("WriteArchive function", func() {
+		It("should stream the files and the skipped manifest", func() {
+			archive, err := service.PrepareArchive(archiving.PrepareArchiveParam{Claims: claims, Files: []archiving.ArchiveFileParam{
+				{Identifier: "a"}, {Identifier: "missing"}, {Identifier: "f"},
+			}})
+			Expect(err).To(BeNil())
+
+			out := &bytes.Buffer{}
+			err = service.WriteArchive(archiving.WriteArchiveParam{Archive: archive, Writer: out})
+			Expect(err).To(BeNil())
+
+			zr, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+			Expect(err).To(BeNil())
+			Expect(zr.File).To(HaveLen(2))
+			Expect(zr.File[0].Name).To(Equal("report.pdf"))
+			Expect(readEntry(zr.File[0])).To(Equal("first report"))
+			Expect(zr.File[1].Name).To(Equal(archiving.SKIPPED_MANIFEST_NAME))
+			Expect(readEntry(zr.File[1])).To(Equal("missing\tnot_found\nf\tunreadable\n"))
+		})
+	})
+})
+
+func readEntry(f *zip.File) string {
+	r, err := f.Open()
+	Expect(err).To(BeNil())
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	Expect(err).To(BeNil())
+	return string(data)
+}
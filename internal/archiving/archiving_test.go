@@ -0,0 +1,65 @@
+package archiving_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/repository"
+	"idaman.id/storage/internal/storage"
+)
+
+func TestArchiving(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Archiving Package")
+}
+
+type FakeConfig struct {
+	values map[string]interface{}
+}
+
+func (c *FakeConfig) GetString(key string) string {
+	v, _ := c.values[key].(string)
+	return v
+}
+
+func (c *FakeConfig) GetInt(key string) int {
+	v, _ := c.values[key].(int)
+	return v
+}
+
+func (c *FakeConfig) GetBool(key string) bool {
+	v, _ := c.values[key].(bool)
+	return v
+}
+
+func (c *FakeConfig) Get(key string) interface{} {
+	return c.values[key]
+}
+
+type FakeFileRepository struct {
+	repository.FileRepository
+	files []repository.FileModel
+}
+
+func (r *FakeFileRepository) FindByIdentifier(identifier string) (*repository.FileModel, error) {
+	for i, f := range r.files {
+		if f.UniqueId == identifier || f.Slug == identifier {
+			return &r.files[i], nil
+		}
+	}
+	return nil, app_error.NewNotfoundError("File")
+}
+
+type FakeStorage struct {
+	files map[string]string
+}
+
+func (s *FakeStorage) RetrieveFile(localPath string) (storage.BinaryFile, error) {
+	data, ok := s.files[localPath]
+	if !ok {
+		return nil, app_error.NewNotfoundError("File")
+	}
+	return []byte(data), nil
+}
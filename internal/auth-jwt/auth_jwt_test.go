@@ -0,0 +1,62 @@
+package auth_jwt_test
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestAuthJwt(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "AuthJwt Package")
+}
+
+type FakeConfig struct {
+	values map[string]interface{}
+}
+
+func (c *FakeConfig) GetString(key string) string {
+	v, _ := c.values[key].(string)
+	return v
+}
+
+func (c *FakeConfig) GetInt(key string) int {
+	v, _ := c.values[key].(int)
+	return v
+}
+
+func (c *FakeConfig) GetBool(key string) bool {
+	v, _ := c.values[key].(bool)
+	return v
+}
+
+func (c *FakeConfig) Get(key string) interface{} {
+	return c.values[key]
+}
+
+func encodeSegment(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func SignHS256(secret string, claims map[string]interface{}) string {
+	signed := encodeSegment(map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encodeSegment(claims)
+	mac := hmac.New(crypto.SHA256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func SignRS256(key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	signed := encodeSegment(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid}) + "." + encodeSegment(claims)
+	hasher := crypto.SHA256.New()
+	hasher.Write([]byte(signed))
+	signature, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hasher.Sum(nil))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
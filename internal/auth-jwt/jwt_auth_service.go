@@ -0,0 +1,288 @@
+package auth_jwt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"idaman.id/storage/internal/auth"
+	"idaman.id/storage/internal/config"
+	app_error "idaman.id/storage/internal/error"
+)
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyId     string `json:"kid"`
+}
+
+type jwtAuthService struct {
+	keys        []jwtKey
+	issuer      string
+	audience    string
+	tenantClaim string
+	leeway      time.Duration
+	now         func() time.Time
+}
+
+func (s *jwtAuthService) Authenticate(token string) (*auth.Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, app_error.NewUnauthenticatedError("Token format")
+	}
+
+	headerData, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, app_error.NewUnauthenticatedError("Token header")
+	}
+	var header jwtHeader
+	err = json.Unmarshal(headerData, &header)
+	if err != nil {
+		return nil, app_error.NewUnauthenticatedError("Token header")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, app_error.NewUnauthenticatedError("Token signature")
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	if !s.verifySignature(header, signed, signature) {
+		return nil, app_error.NewUnauthenticatedError("Token signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, app_error.NewUnauthenticatedError("Token payload")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var rawClaims map[string]interface{}
+	err = decoder.Decode(&rawClaims)
+	if err != nil {
+		return nil, app_error.NewUnauthenticatedError("Token payload")
+	}
+
+	return s.parseClaims(rawClaims)
+}
+
+func (s *jwtAuthService) verifySignature(h jwtHeader, signed, signature []byte) bool {
+	for _, key := range s.keys {
+		if h.KeyId != "" && key.Id != "" && h.KeyId != key.Id {
+			continue
+		}
+		if key.Algorithm != "" && key.Algorithm != h.Algorithm {
+			continue
+		}
+		err := verify(h.Algorithm, key.Key, signed, signature)
+		if err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func verify(alg string, key interface{}, signed, signature []byte) error {
+	if len(alg) < 2 {
+		return errors.New("unsupported algorithm")
+	}
+
+	if alg == "EdDSA" {
+		pub, isValid := key.(ed25519.PublicKey)
+		if !isValid || !ed25519.Verify(pub, signed, signature) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+
+	var hash crypto.Hash
+	switch alg[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return errors.New("unsupported algorithm")
+	}
+
+	switch alg[:2] {
+	case "HS":
+		secret, isValid := key.([]byte)
+		if !isValid {
+			return errors.New("invalid key type")
+		}
+		mac := hmac.New(hash.New, secret)
+		mac.Write(signed)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	case "RS", "PS":
+		pub, isValid := key.(*rsa.PublicKey)
+		if !isValid {
+			return errors.New("invalid key type")
+		}
+		hasher := hash.New()
+		hasher.Write(signed)
+		if alg[:2] == "PS" {
+			return rsa.VerifyPSS(pub, hash, hasher.Sum(nil), signature, nil)
+		}
+		return rsa.VerifyPKCS1v15(pub, hash, hasher.Sum(nil), signature)
+	case "ES":
+		pub, isValid := key.(*ecdsa.PublicKey)
+		if !isValid {
+			return errors.New("invalid key type")
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("invalid signature")
+		}
+		hasher := hash.New()
+		hasher.Write(signed)
+		r := new(big.Int).SetBytes(signature[:size])
+		sig := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, hasher.Sum(nil), r, sig) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+	return errors.New("unsupported algorithm")
+}
+
+func (s *jwtAuthService) parseClaims(raw map[string]interface{}) (*auth.Claims, error) {
+	now := s.now()
+
+	exp, isAvailable := parseNumericDate(raw["exp"])
+	if !isAvailable {
+		return nil, app_error.NewUnauthenticatedError("Token expiration")
+	}
+	if now.After(exp.Add(s.leeway)) {
+		return nil, app_error.NewUnauthenticatedError("Token expiration")
+	}
+
+	nbf, isAvailable := parseNumericDate(raw["nbf"])
+	if isAvailable && now.Add(s.leeway).Before(nbf) {
+		return nil, app_error.NewUnauthenticatedError("Token activation")
+	}
+
+	issuer, _ := raw["iss"].(string)
+	if s.issuer != "" && issuer != s.issuer {
+		return nil, app_error.NewUnauthenticatedError("Token issuer")
+	}
+
+	audience := parseStringList(raw["aud"], false)
+	if s.audience != "" && !containString(audience, s.audience) {
+		return nil, app_error.NewUnauthenticatedError("Token audience")
+	}
+
+	scopes := parseStringList(raw["scope"], true)
+	if len(scopes) == 0 {
+		scopes = parseStringList(raw["scp"], true)
+	}
+
+	subject, _ := raw["sub"].(string)
+	tenant, _ := raw[s.tenantClaim].(string)
+
+	claims := &auth.Claims{
+		Subject:   subject,
+		Tenant:    tenant,
+		Issuer:    issuer,
+		Audience:  audience,
+		Scopes:    scopes,
+		ExpiresAt: &exp,
+	}
+	return claims, nil
+}
+
+func parseNumericDate(v interface{}) (time.Time, bool) {
+	n, isValid := v.(json.Number)
+	if !isValid {
+		return time.Time{}, false
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(f), 0), true
+}
+
+func parseStringList(v interface{}, splitSpace bool) []string {
+	list := []string{}
+	switch v := v.(type) {
+	case string:
+		if splitSpace {
+			return strings.Fields(v)
+		}
+		list = append(list, v)
+	case []interface{}:
+		for _, i := range v {
+			s, isString := i.(string)
+			if isString {
+				list = append(list, s)
+			}
+		}
+	}
+	return list
+}
+
+func containString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+func NewJwtAuthService(cg config.Getter) (auth.Authenticator, error) {
+	keys := []jwtKey{}
+
+	jwksFile := cg.GetString("AUTH_JWT_JWKS_FILE")
+	if jwksFile != "" {
+		jwks, err := loadJwksFile(jwksFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, jwks...)
+	}
+
+	publicKeyFiles := cg.GetString("AUTH_JWT_PUBLIC_KEY_FILE")
+	if publicKeyFiles != "" {
+		publicKeys, err := loadPublicKeyFiles(publicKeyFiles)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, publicKeys...)
+	}
+
+	secret := cg.GetString("AUTH_JWT_SECRET")
+	if secret != "" {
+		keys = append(keys, jwtKey{Key: []byte(secret)})
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no jwt verification key configured")
+	}
+
+	s := &jwtAuthService{
+		keys:        keys,
+		issuer:      cg.GetString("AUTH_JWT_ISSUER"),
+		audience:    cg.GetString("AUTH_JWT_AUDIENCE"),
+		tenantClaim: cg.GetString("AUTH_JWT_TENANT_CLAIM"),
+		leeway:      time.Duration(cg.GetInt("AUTH_JWT_LEEWAY")) * time.Second,
+		now:         time.Now,
+	}
+	return s, nil
+}
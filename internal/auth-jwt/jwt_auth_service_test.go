@@ -0,0 +1,226 @@
+package auth_jwt_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/auth"
+	auth_jwt "idaman.id/storage/internal/auth-jwt"
+	app_error "idaman.id/storage/internal/error"
+)
+
+var _ = Describe("Jwt Auth Service", func() {
+	var (
+		cfg    *FakeConfig
+		claims map[string]interface{}
+	)
+
+	BeforeEach(func() {
+		cfg = &FakeConfig{values: map[string]interface{}{
+			"AUTH_JWT_SECRET":       "secret",
+			"AUTH_JWT_ISSUER":       "https://sso.domain.tld",
+			"AUTH_JWT_AUDIENCE":     "goseidon",
+			"AUTH_JWT_TENANT_CLAIM": "tenant",
+			"AUTH_JWT_LEEWAY":       0,
+		}}
+		claims = map[string]interface{}{
+			"iss":    "https://sso.domain.tld",
+			"aud":    []string{"goseidon", "other"},
+			"sub":    "user-1",
+			"tenant": "app-1",
+			"scope":  "file:read file:write",
+			"exp":    time.Now().Add(time.Hour).Unix(),
+		}
+	})
+
+	Context("NewJwtAuthService function", func() {
+		When("no key configured", func() {
+			It("should return error", func() {
+				cfg.values["AUTH_JWT_SECRET"] = ""
+				s, err := auth_jwt.NewJwtAuthService(cfg)
+
+				Expect(s).To(BeNil())
+				Expect(err).ToNot(BeNil())
+			})
+		})
+
+		When("jwks file is not found", func() {
+			It("should return error", func() {
+				cfg.values["AUTH_JWT_JWKS_FILE"] = "not-found.json"
+				s, err := auth_jwt.NewJwtAuthService(cfg)
+
+				Expect(s).To(BeNil())
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+
+	Context("Authenticate method", func() {
+		var (
+			authenticator auth.Authenticator
+		)
+
+		BeforeEach(func() {
+			var err error
+			authenticator, err = auth_jwt.NewJwtAuthService(cfg)
+			Expect(err).To(BeNil())
+		})
+
+		When("token is valid", func() {
+			It("should return claims", func() {
+				token := SignHS256("secret", claims)
+				res, err := authenticator.Authenticate(token)
+
+				Expect(err).To(BeNil())
+				Expect(res.Subject).To(Equal("user-1"))
+				Expect(res.Tenant).To(Equal("app-1"))
+				Expect(res.Issuer).To(Equal("https://sso.domain.tld"))
+				Expect(res.Audience).To(Equal([]string{"goseidon", "other"}))
+				Expect(res.Scopes).To(Equal([]string{"file:read", "file:write"}))
+				Expect(res.HasScope(auth.SCOPE_FILE_WRITE)).To(BeTrue())
+				Expect(res.HasScope(auth.SCOPE_FILE_DELETE)).To(BeFalse())
+			})
+		})
+
+		When("token is malformed", func() {
+			It("should return unauthenticated error", func() {
+				res, err := authenticator.Authenticate("not-a-token")
+
+				Expect(res).To(BeNil())
+				Expect(err).To(BeAssignableToTypeOf(&app_error.UnauthenticatedError{}))
+			})
+		})
+
+		When("token signature is invalid", func() {
+			It("should return unauthenticated error", func() {
+				token := SignHS256("another-secret", claims)
+				res, err := authenticator.Authenticate(token)
+
+				Expect(res).To(BeNil())
+				Expect(err).To(MatchError(app_error.NewUnauthenticatedError("Token signature")))
+			})
+		})
+
+		When("token is expired", func() {
+			It("should return unauthenticated error", func() {
+				claims["exp"] = time.Now().Add(-time.Minute).Unix()
+				token := SignHS256("secret", claims)
+				res, err := authenticator.Authenticate(token)
+
+				Expect(res).To(BeNil())
+				Expect(err).To(MatchError(app_error.NewUnauthenticatedError("Token expiration")))
+			})
+		})
+
+		When("token has no expiration", func() {
+			It("should return unauthenticated error", func() {
+				delete(claims, "exp")
+				token := SignHS256("secret", claims)
+				res, err := authenticator.Authenticate(token)
+
+				Expect(res).To(BeNil())
+				Expect(err).To(MatchError(app_error.NewUnauthenticatedError("Token expiration")))
+			})
+		})
+
+		When("token issuer is different", func() {
+			It("should return unauthenticated error", func() {
+				claims["iss"] = "https://evil.tld"
+				token := SignHS256("secret", claims)
+				res, err := authenticator.Authenticate(token)
+
+				Expect(res).To(BeNil())
+				Expect(err).To(MatchError(app_error.NewUnauthenticatedError("Token issuer")))
+			})
+		})
+
+		When("token audience is different", func() {
+			It("should return unauthenticated error", func() {
+				claims["aud"] = "other"
+				token := SignHS256("secret", claims)
+				res, err := authenticator.Authenticate(token)
+
+				Expect(res).To(BeNil())
+				Expect(err).To(MatchError(app_error.NewUnauthenticatedError("Token audience")))
+			})
+		})
+
+		When("token algorithm is none", func() {
+			It("should return unauthenticated error", func() {
+				parts := strings.Split(SignHS256("secret", claims), ".")
+				header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+				token := header + "." + parts[1] + "."
+				res, err := authenticator.Authenticate(token)
+
+				Expect(res).To(BeNil())
+				Expect(err).To(BeAssignableToTypeOf(&app_error.UnauthenticatedError{}))
+			})
+		})
+	})
+
+	Context("Authenticate method using jwks file", func() {
+		var (
+			authenticator auth.Authenticator
+			privateKey    *rsa.PrivateKey
+		)
+
+		BeforeEach(func() {
+			var err error
+			privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).To(BeNil())
+
+			n := base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes())
+			e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes())
+			jwks := fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":"key-1","alg":"RS256","use":"sig","n":"%s","e":"%s"}]}`, n, e)
+
+			jwksFile := filepath.Join(GinkgoT().TempDir(), "jwks.json")
+			err = os.WriteFile(jwksFile, []byte(jwks), 0644)
+			Expect(err).To(BeNil())
+
+			cfg.values["AUTH_JWT_SECRET"] = ""
+			cfg.values["AUTH_JWT_JWKS_FILE"] = jwksFile
+			authenticator, err = auth_jwt.NewJwtAuthService(cfg)
+			Expect(err).To(BeNil())
+		})
+
+		When("token is signed by known key", func() {
+			It("should return claims", func() {
+				token := SignRS256(privateKey, "key-1", claims)
+				res, err := authenticator.Authenticate(token)
+
+				Expect(err).To(BeNil())
+				Expect(res.Subject).To(Equal("user-1"))
+			})
+		})
+
+		When("token key id is unknown", func() {
+			It("should return unauthenticated error", func() {
+				token := SignRS256(privateKey, "key-2", claims)
+				res, err := authenticator.Authenticate(token)
+
+				Expect(res).To(BeNil())
+				Expect(err).To(MatchError(app_error.NewUnauthenticatedError("Token signature")))
+			})
+		})
+
+		When("token is signed by unknown key", func() {
+			It("should return unauthenticated error", func() {
+				otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+				token := SignRS256(otherKey, "key-1", claims)
+				res, err := authenticator.Authenticate(token)
+
+				Expect(res).To(BeNil())
+				Expect(err).To(MatchError(app_error.NewUnauthenticatedError("Token signature")))
+			})
+		})
+	})
+})
@@ -0,0 +1,11 @@
+package auth
+
+const (
+	SCOPE_FILE_READ   = "file:read"
+	SCOPE_FILE_WRITE  = "file:write"
+	SCOPE_FILE_DELETE = "file:delete"
+)
+
+type Authenticator interface {
+	Authenticate(token string) (*Claims, error)
+}
@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"time"
+)
+
+type Claims struct {
+	Subject   string
+	Tenant    string
+	Issuer    string
+	Audience  []string
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+func (c *Claims) HasScope(scope string) bool {
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAccessTenant tells whether the claims owner may touch a record owned by tenantId,
+// records stored without tenant are shared by everyone
+func (c *Claims) CanAccessTenant(tenantId string) bool {
+	if c == nil || tenantId == "" {
+		return true
+	}
+	return c.Tenant == tenantId
+}
@@ -1,16 +1,48 @@
 package builtin_app
 
 import (
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"idaman.id/storage/internal/config"
+	"idaman.id/storage/internal/expiring"
+	"idaman.id/storage/internal/replicating"
+	"idaman.id/storage/internal/tiering"
 )
 
 type FiberApp struct {
 	fiber        *fiber.App
 	configGetter config.Getter
+	reaper       expiring.Reaper
+	tierer       tiering.Tierer
+	replicator   replicating.Replicator
+	repairer     replicating.Repairer
 }
 
 func (app *FiberApp) Run() error {
+	if app.reaper != nil {
+		go app.runEvery("EXPIRY_REAPER_INTERVAL", func() { app.reaper.Reap() })
+	}
+	if app.tierer != nil {
+		go app.runEvery("TIERING_INTERVAL", func() { app.tierer.Transition() })
+	}
+	if app.replicator != nil {
+		go app.replicator.ProcessQueue()
+	}
+	if app.repairer != nil {
+		go app.runEvery("REPLICATION_REPAIR_INTERVAL", func() { app.repairer.Repair() })
+	}
 	addr := app.configGetter.GetString("APP_HOST") + ":" + app.configGetter.GetString("APP_PORT")
 	return app.fiber.Listen(addr)
 }
+
+// runEvery runs the background job every interval `second` read from the config key,
+// a failed run is reflected by the job metrics and retried on the next tick
+func (app *FiberApp) runEvery(intervalKey string, run func()) {
+	interval := time.Duration(app.configGetter.GetInt(intervalKey)) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		run()
+	}
+}
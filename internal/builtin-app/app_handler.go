@@ -1,8 +1,12 @@
 package builtin_app
 
 import (
+	"bytes"
+	"io"
+
 	"github.com/gofiber/fiber/v2"
 	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/metrics"
 	response "idaman.id/storage/internal/response"
 )
 
@@ -22,6 +26,59 @@ func NewNotFoundHandler() Handler {
 	}
 }
 
+func NewMetricsHandler(registry metrics.Registry) Handler {
+	return func(ctx *Context) error {
+		body := &bytes.Buffer{}
+		err := registry.WriteText(body)
+		if err != nil {
+			return err
+		}
+		ctx.Set(fiber.HeaderContentType, "text/plain; version=0.0.4")
+		return ctx.Send(body.Bytes())
+	}
+}
+
+// NewStreamedBodyHandler closes the connection of a request whose body is not read ahead of its handler,
+// since the body is left unread when the request is refused the connection can not be reused
+func NewStreamedBodyHandler(limit int) Handler {
+	return func(ctx *Context) error {
+		contentLength := ctx.Request().Header.ContentLength()
+		if contentLength < 0 || contentLength > limit {
+			ctx.Context().SetConnectionClose()
+		}
+		return ctx.Next()
+	}
+}
+
+// NewBodyLimitHandler reads the streamed request body within the limit so the next handlers get it as a whole,
+// a larger body is refused before it is read and its connection is closed
+func NewBodyLimitHandler(limit int) Handler {
+	return func(ctx *Context) error {
+		contentLength := ctx.Request().Header.ContentLength()
+		if contentLength > limit {
+			ctx.Context().SetConnectionClose()
+			return fiber.ErrRequestEntityTooLarge
+		}
+		stream := ctx.Context().RequestBodyStream()
+		if contentLength >= 0 || stream == nil {
+			return ctx.Next()
+		}
+
+		// the length of a chunked body is only known once it is read
+		body, err := io.ReadAll(io.LimitReader(stream, int64(limit)+1))
+		if err != nil {
+			return fiber.ErrBadRequest
+		}
+		if len(body) > limit {
+			ctx.Context().SetConnectionClose()
+			return fiber.ErrRequestEntityTooLarge
+		}
+		ctx.Request().SetBody(body)
+		ctx.Request().Header.SetContentLength(len(body))
+		return ctx.Next()
+	}
+}
+
 func NewErrorHandler() ErrorHandler {
 	return func(ctx *Context, err error) error {
 
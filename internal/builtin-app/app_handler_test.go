@@ -2,14 +2,17 @@ package builtin_app_test
 
 import (
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	builtin_app "idaman.id/storage/internal/builtin-app"
 	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/metrics"
 	response "idaman.id/storage/internal/response"
 )
 
@@ -64,6 +67,113 @@ var _ = Describe("App Handler", func() {
 		})
 	})
 
+	Context("Metrics Handler", func() {
+		BeforeEach(func() {
+			registry := metrics.NewRegistry()
+			registry.Counter("storage_expiry_reap_runs_total", "Number of expired file reaper runs").Add(2)
+			fiberApp.Get("/metrics", builtin_app.NewMetricsHandler(registry))
+		})
+		When("metrics endpoint accessed", func() {
+			It("should return the metrics as text", func() {
+				req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+				res, _ := fiberApp.Test(req)
+
+				body, _ := io.ReadAll(res.Body)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(res.Header.Get("Content-Type")).To(Equal("text/plain; version=0.0.4"))
+				Expect(string(body)).To(ContainSubstring("storage_expiry_reap_runs_total 2\n"))
+			})
+		})
+	})
+
+	Context("StreamedBody Handler", func() {
+		BeforeEach(func() {
+			fiberApp = fiber.New(fiber.Config{
+				StreamRequestBody: true,
+				BodyLimit:         4,
+			})
+			fiberApp.Use(builtin_app.NewStreamedBodyHandler(4))
+			fiberApp.Put("/", func(ctx *fiber.Ctx) error {
+				return ctx.SendStatus(fiber.StatusForbidden)
+			})
+		})
+		When("body is within the body limit", func() {
+			It("should keep the connection", func() {
+				req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader("body"))
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusForbidden))
+				Expect(res.Close).To(BeFalse())
+			})
+		})
+		When("body is over the body limit", func() {
+			It("should close the connection", func() {
+				req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader("large body"))
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusForbidden))
+				Expect(res.Close).To(BeTrue())
+			})
+		})
+	})
+
+	Context("BodyLimit Handler", func() {
+		BeforeEach(func() {
+			fiberApp = fiber.New(fiber.Config{
+				ErrorHandler:      builtin_app.NewErrorHandler(),
+				StreamRequestBody: true,
+				BodyLimit:         4,
+			})
+			fiberApp.Use(builtin_app.NewBodyLimitHandler(4))
+			fiberApp.Put("/", func(ctx *fiber.Ctx) error {
+				return ctx.Send(ctx.Body())
+			})
+		})
+		When("body is within the body limit", func() {
+			It("should pass the body", func() {
+				req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader("body"))
+				res, _ := fiberApp.Test(req)
+
+				body, _ := io.ReadAll(res.Body)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(string(body)).To(Equal("body"))
+			})
+		})
+		When("body is over the body limit", func() {
+			It("should return request entity too large response", func() {
+				req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader("large body"))
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusRequestEntityTooLarge))
+			})
+		})
+		When("chunked body is within the body limit", func() {
+			It("should pass the body", func() {
+				req := httptest.NewRequest(http.MethodPut, "/", io.MultiReader(strings.NewReader("bo"), strings.NewReader("dy")))
+				req.ContentLength = -1
+				req.TransferEncoding = []string{"chunked"}
+				res, _ := fiberApp.Test(req)
+
+				body, _ := io.ReadAll(res.Body)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(string(body)).To(Equal("body"))
+			})
+		})
+		When("chunked body is over the body limit", func() {
+			It("should return request entity too large response", func() {
+				req := httptest.NewRequest(http.MethodPut, "/", io.MultiReader(strings.NewReader("large"), strings.NewReader(" body")))
+				req.ContentLength = -1
+				req.TransferEncoding = []string{"chunked"}
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusRequestEntityTooLarge))
+			})
+		})
+	})
+
 	Context("Error Handler", func() {
 		When("error is not fiber error", func() {
 			BeforeEach(func() {
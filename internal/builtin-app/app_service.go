@@ -1,6 +1,8 @@
 package builtin_app
 
 import (
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/etag"
@@ -8,15 +10,45 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"idaman.id/storage/internal/app"
+	"idaman.id/storage/internal/application"
+	"idaman.id/storage/internal/archiving"
+	"idaman.id/storage/internal/auth"
+	auth_jwt "idaman.id/storage/internal/auth-jwt"
 	"idaman.id/storage/internal/config"
 	"idaman.id/storage/internal/database"
+	"idaman.id/storage/internal/deleting"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/expiring"
+	"idaman.id/storage/internal/extracting"
+	"idaman.id/storage/internal/fetching"
 	"idaman.id/storage/internal/file"
+	"idaman.id/storage/internal/idempotency"
+	idempotency_memory "idaman.id/storage/internal/idempotency-memory"
+	idempotency_redis "idaman.id/storage/internal/idempotency-redis"
+	"idaman.id/storage/internal/imaging"
+	"idaman.id/storage/internal/metrics"
+	"idaman.id/storage/internal/quota"
+	"idaman.id/storage/internal/ratelimit"
+	ratelimit_memory "idaman.id/storage/internal/ratelimit-memory"
+	ratelimit_redis "idaman.id/storage/internal/ratelimit-redis"
+	"idaman.id/storage/internal/replicating"
 	repository_mysql "idaman.id/storage/internal/repository-mysql"
 	"idaman.id/storage/internal/retrieving"
+	"idaman.id/storage/internal/sanitizing"
+	"idaman.id/storage/internal/scanning"
+	scanning_clamav "idaman.id/storage/internal/scanning-clamav"
+	"idaman.id/storage/internal/serialization"
+	"idaman.id/storage/internal/signing"
+	"idaman.id/storage/internal/storage"
 	storage_local "idaman.id/storage/internal/storage-local"
 	"idaman.id/storage/internal/text"
+	"idaman.id/storage/internal/tiering"
+	"idaman.id/storage/internal/unpacking"
+	"idaman.id/storage/internal/updating"
 	"idaman.id/storage/internal/uploading"
 	"idaman.id/storage/internal/validation"
+	"idaman.id/storage/internal/variant"
+	"idaman.id/storage/internal/versioning"
 )
 
 func NewApp() (app.App, error) {
@@ -37,30 +69,246 @@ func NewApp() (app.App, error) {
 		return nil, err
 	}
 	fileRepo := repository_mysql.NewFileRepository(mysqlClient, fileService)
+	usageRepo := repository_mysql.NewUsageRepository(mysqlClient)
+	variantRepo := repository_mysql.NewVariantRepository(mysqlClient)
+	versionRepo := repository_mysql.NewVersionRepository(mysqlClient)
+	transformRepo := repository_mysql.NewTransformRepository(mysqlClient)
+
+	jsonSerializer := serialization.NewJsonSerialization()
+	appService, err := application.NewApplicationService(configService.GetString("APPLICATION_CONFIG_FILE"), jsonSerializer)
+	if err != nil {
+		return nil, err
+	}
+	quotaService := quota.NewQuotaService(appService, usageRepo)
 
-	localStorage := storage_local.NewStorageLocal("storage/file")
+	storageRegistry, err := newStorageRegistry(configService)
+	if err != nil {
+		return nil, err
+	}
 
-	retrieveService := retrieving.NewRetrieveService(fileRepo, configService, fileService, localStorage)
-	uploadService := uploading.NewUploadService(validatorService, configService, localStorage, textService, fileRepo)
+	// the contents are written and read through the replicator when replication is enabled,
+	// variants are derived from the contents and only kept by the primary provider
+	metricsRegistry := metrics.NewRegistry()
+	var contentStorage storage.StreamStorage = storageRegistry
+	var replicator replicating.Replicator
+	var repairer replicating.Repairer
+	if configService.GetBool("REPLICATION_ENABLED") {
+		for _, provider := range replicating.ParseProviders(configService.GetString("REPLICATION_PROVIDERS")) {
+			_, err = storageRegistry.Provider(provider)
+			if err != nil {
+				return nil, err
+			}
+		}
+		replicaRepo := repository_mysql.NewReplicaRepository(mysqlClient)
+		replicator = replicating.NewReplicateService(configService, storageRegistry, replicaRepo, metricsRegistry)
+		contentStorage = replicator
+		if configService.GetInt("REPLICATION_REPAIR_INTERVAL") > 0 {
+			repairer = replicating.NewRepairService(configService, replicaRepo, storageRegistry, replicator, metricsRegistry)
+		}
+	}
+
+	var scanService scanning.ScanService
+	if configService.GetBool("SCAN_ENABLED") {
+		scanner, err := scanning_clamav.NewClamavScanner(configService)
+		if err != nil {
+			return nil, err
+		}
+		quarantineStorage := storage_local.NewStorageLocal(configService.GetString("SCAN_QUARANTINE_DIR"))
+		scanService = scanning.NewScanService(scanner, configService.GetString("SCAN_MODE"), configService.GetInt("SCAN_WORKERS"), quarantineStorage, contentStorage, fileRepo)
+	}
+
+	extractingService := extracting.NewExtractingService()
+	sanitizingService := sanitizing.NewSanitizingService(extractingService, configService)
+	imagingService := imaging.NewImagingService(validatorService, configService)
+	variantService := variant.NewVariantService(appService, imagingService, storageRegistry, storageRegistry, variantRepo)
+
+	versionService := versioning.NewVersionService(configService, fileRepo, versionRepo, contentStorage, quotaService, appService, variantService)
+
+	signingService := signing.NewSigningService(configService)
+	retrieveService := retrieving.NewRetrieveService(fileRepo, variantRepo, configService, fileService, contentStorage, storageRegistry, imagingService, jsonSerializer, versionRepo, signingService, transformRepo, validatorService)
+	unpackService := unpacking.NewUnpackService(configService)
+	fetchService := fetching.NewFetchService(configService)
+	uploadService := uploading.NewUploadService(validatorService, configService, contentStorage, textService, fileRepo, quotaService, appService, jsonSerializer, fileService, uploading.UploadServiceDeps{
+		ScanService:    scanService,
+		VariantService: variantService,
+		Extractor:      extractingService,
+		Sanitizer:      sanitizingService,
+		VersionPruner:  versionService,
+		Unpacker:       unpackService,
+		Fetcher:        fetchService,
+	})
+	updateService := updating.NewUpdateService(validatorService, fileRepo, jsonSerializer, fileService)
+	deleteService := deleting.NewDeleteService(fileRepo, contentStorage, quotaService, variantService, versionRepo, transformRepo)
+	archiveService := archiving.NewArchiveService(validatorService, configService, fileRepo, contentStorage)
 
+	var reaper expiring.Reaper
+	if configService.GetBool("EXPIRY_REAPER_ENABLED") {
+		reaper = expiring.NewReapService(configService, fileRepo, deleteService, metricsRegistry)
+	}
+	var tierer tiering.Tierer
+	if configService.GetBool("TIERING_ENABLED") {
+		tierer = tiering.NewTierService(configService, fileRepo, appService, storageRegistry, metricsRegistry)
+	}
+
+	var authenticator auth.Authenticator
+	if configService.GetBool("AUTH_ENABLED") {
+		authenticator, err = auth_jwt.NewJwtAuthService(configService)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var limiter ratelimit.Limiter
+	if configService.GetBool("RATE_LIMIT_ENABLED") {
+		var store ratelimit.Store
+		switch configService.GetString("RATE_LIMIT_STORE") {
+		case "redis":
+			redisClient, err := database.NewRedisClient(configService)
+			if err != nil {
+				return nil, err
+			}
+			store = ratelimit_redis.NewRedisStore(redisClient)
+		default:
+			store = ratelimit_memory.NewMemoryStore()
+		}
+		limiter = ratelimit.NewRateLimitService(store, configService)
+	}
+
+	var keeper idempotency.Keeper
+	if configService.GetBool("IDEMPOTENCY_ENABLED") {
+		var store idempotency.Store
+		switch configService.GetString("IDEMPOTENCY_STORE") {
+		case "redis":
+			redisClient, err := database.NewRedisClient(configService)
+			if err != nil {
+				return nil, err
+			}
+			store = idempotency_redis.NewRedisStore(redisClient, jsonSerializer)
+		default:
+			store = idempotency_memory.NewMemoryStore()
+		}
+		keeper = idempotency.NewIdempotencyService(store, configService)
+	}
+
+	// the request bodies are streamed so the raw upload is stored while it is read,
+	// the multipart forms are parsed by their handlers within the body limit
 	app := fiber.New(fiber.Config{
-		ErrorHandler: NewErrorHandler(),
+		ErrorHandler:                 NewErrorHandler(),
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
 	})
 	app.Use(recover.New())
 	app.Use(requestid.New())
 	app.Use(etag.New())
 	app.Use(cors.New())
 	app.Use(logger.New())
+	app.Use(NewStreamedBodyHandler(app.Config().BodyLimit))
+	app.Use(NewIpRateLimitHandler(limiter))
+
+	// the raw upload is registered ahead of the body limit, its body is streamed into the storage whatever its size
+	app.Put("/v1/file/:name", NewScopedHandlers(authenticator, auth.SCOPE_FILE_WRITE,
+		NewRateLimitHandler(limiter, "upload-raw-file", true),
+		NewIdempotencyHandler(keeper),
+		NewUploadRawFileHandler(uploadService, fileService),
+	)...)
+	app.Use(NewBodyLimitHandler(app.Config().BodyLimit))
 
 	app.Get("/", NewHomeHandler())
-	app.Get("/file/:identifier", NewGetResourceHandler(retrieveService))
-	app.Post("/v1/file", NewUploadFileHandler(uploadService, fileService))
-	app.Get("/v1/file/:identifier", NewFileGetDetailHandler(retrieveService))
+	app.Get("/file/:identifier",
+		NewRateLimitHandler(limiter, "get-resource", false),
+		NewGetResourceHandler(retrieveService),
+	)
+	app.Post("/v1/file", NewScopedHandlers(authenticator, auth.SCOPE_FILE_WRITE,
+		NewRateLimitHandler(limiter, "upload-file", true),
+		NewIdempotencyHandler(keeper),
+		NewUploadFileHandler(uploadService, fileService),
+	)...)
+	app.Post("/v1/file/remote", NewScopedHandlers(authenticator, auth.SCOPE_FILE_WRITE,
+		NewRateLimitHandler(limiter, "upload-remote-file", true),
+		NewIdempotencyHandler(keeper),
+		NewUploadRemoteFileHandler(uploadService),
+	)...)
+	app.Get("/v1/file", NewScopedHandlers(authenticator, auth.SCOPE_FILE_READ,
+		NewRateLimitHandler(limiter, "list-file", false),
+		NewFileListHandler(retrieveService),
+	)...)
+	app.Get("/v1/file/:identifier", NewScopedHandlers(authenticator, auth.SCOPE_FILE_READ,
+		NewRateLimitHandler(limiter, "get-file-detail", false),
+		NewFileGetDetailHandler(retrieveService),
+	)...)
+	app.Patch("/v1/file/:identifier", NewScopedHandlers(authenticator, auth.SCOPE_FILE_WRITE,
+		NewRateLimitHandler(limiter, "update-file", false),
+		NewIdempotencyHandler(keeper),
+		NewUpdateFileHandler(updateService, retrieveService),
+	)...)
+	app.Post("/v1/file/:identifier/signed-url", NewScopedHandlers(authenticator, auth.SCOPE_FILE_READ,
+		NewRateLimitHandler(limiter, "sign-file-url", false),
+		NewSignFileUrlHandler(retrieveService),
+	)...)
+	app.Put("/v1/file/:identifier/content", NewScopedHandlers(authenticator, auth.SCOPE_FILE_WRITE,
+		NewRateLimitHandler(limiter, "replace-file", true),
+		NewIdempotencyHandler(keeper),
+		NewReplaceFileContentHandler(uploadService, fileService),
+	)...)
+	app.Get("/v1/file/:identifier/versions", NewScopedHandlers(authenticator, auth.SCOPE_FILE_READ,
+		NewRateLimitHandler(limiter, "list-file-version", false),
+		NewFileVersionListHandler(versionService),
+	)...)
+	app.Post("/v1/file/:identifier/versions/:version/restore", NewScopedHandlers(authenticator, auth.SCOPE_FILE_WRITE,
+		NewRateLimitHandler(limiter, "restore-file-version", false),
+		NewIdempotencyHandler(keeper),
+		NewRestoreVersionHandler(versionService, retrieveService),
+	)...)
+	app.Delete("/v1/file/:identifier", NewScopedHandlers(authenticator, auth.SCOPE_FILE_DELETE,
+		NewRateLimitHandler(limiter, "delete-file", false),
+		NewIdempotencyHandler(keeper),
+		NewDeleteFileHandler(deleteService),
+	)...)
+	app.Post("/v1/archive", NewScopedHandlers(authenticator, auth.SCOPE_FILE_READ,
+		NewRateLimitHandler(limiter, "download-archive", false),
+		NewArchiveHandler(archiveService),
+	)...)
+	app.Get("/v1/quota", NewScopedHandlers(authenticator, auth.SCOPE_FILE_READ,
+		NewRateLimitHandler(limiter, "get-quota", false),
+		NewGetQuotaHandler(quotaService),
+	)...)
+	if configService.GetBool("METRICS_ENABLED") {
+		app.Get("/metrics", NewMetricsHandler(metricsRegistry))
+	}
 	app.Get("*", NewNotFoundHandler())
 
 	fiberApp := &FiberApp{
 		fiber:        app,
 		configGetter: configService,
+		reaper:       reaper,
+		tierer:       tierer,
+		replicator:   replicator,
+		repairer:     repairer,
 	}
 	return fiberApp, nil
 }
+
+// newStorageRegistry configures every provider of STORAGE_PROVIDERS as a local directory read from
+// STORAGE_<PROVIDER>_DIR, e.g: a mounted bucket, `local` keeps `storage/file` when it is not set
+func newStorageRegistry(configGetter config.Getter) (storage.Registry, error) {
+	providers := []storage.ProviderEntity{}
+	for _, name := range strings.Split(configGetter.GetString("STORAGE_PROVIDERS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		dir := configGetter.GetString("STORAGE_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_DIR")
+		if dir == "" && name == "local" {
+			dir = "storage/file"
+		}
+		if dir == "" {
+			return nil, app_error.NewUnsupportedError("Provider")
+		}
+		providers = append(providers, storage.ProviderEntity{
+			Name:     name,
+			Location: dir,
+			Storage:  storage_local.NewStorageLocal(dir),
+		})
+	}
+	return storage.NewProviderRegistry(configGetter.GetString("DEFAULT_PROVIDER"), providers)
+}
@@ -5,14 +5,24 @@ import (
 	"errors"
 	"io"
 	"io/ioutil"
+	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"idaman.id/storage/internal/archiving"
+	"idaman.id/storage/internal/auth"
+	"idaman.id/storage/internal/deleting"
 	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/quota"
+	"idaman.id/storage/internal/ratelimit"
 	response "idaman.id/storage/internal/response"
 	"idaman.id/storage/internal/retrieving"
+	"idaman.id/storage/internal/updating"
+	"idaman.id/storage/internal/uploading"
+	"idaman.id/storage/internal/versioning"
 )
 
 func TestBuiltinApp(t *testing.T) {
@@ -39,10 +49,12 @@ func UnmarshallResponseBody(r io.Reader) *response.ResponseEntity {
 type FakeDeleteService struct {
 }
 
-func (s *FakeDeleteService) DeleteFile(identifier string) error {
-	if identifier == "not-found" {
+func (s *FakeDeleteService) DeleteFile(p deleting.DeleteFileParam) error {
+	if p.Identifier == "not-found" {
 		return app_error.NewNotfoundError("File")
-	} else if identifier == "error" {
+	} else if p.Identifier == "forbidden" {
+		return app_error.NewForbiddenError("File")
+	} else if p.Identifier == "error" {
 		return errors.New(response.STATUS_ERROR)
 	}
 	return nil
@@ -51,30 +63,331 @@ func (s *FakeDeleteService) DeleteFile(identifier string) error {
 type FakeFileGetterService struct {
 }
 
-func (stub *FakeFileGetterService) GetFile(identifier string) (*retrieving.FileEntity, error) {
-	if identifier == "not-found" {
+func (stub *FakeFileGetterService) GetFile(p retrieving.GetFileParam) (*retrieving.FileEntity, error) {
+	if p.Identifier == "not-found" {
 		return nil, app_error.NewNotfoundError("File")
-	} else if identifier == "error" {
+	} else if p.Identifier == "forbidden" {
+		return nil, app_error.NewForbiddenError("File")
+	} else if p.Identifier == "error" {
 		return nil, errors.New(response.STATUS_ERROR)
 	}
-	file := &retrieving.FileEntity{}
+	file := &retrieving.FileEntity{Slug: p.Identifier, Revision: 3}
 	return file, nil
 }
 
 type FakeFileRetrieverService struct {
 }
 
-func (stub *FakeFileRetrieverService) RetrieveFile(identifier string) (*retrieving.RetrieveFileResult, error) {
+func (stub *FakeFileRetrieverService) RetrieveFile(p retrieving.RetrieveFileParam) (*retrieving.RetrieveFileResult, error) {
+	identifier := p.Identifier
+	if p.Transform.Width < 0 {
+		return nil, app_error.NewValidationError([]app_error.ValidationItem{
+			{Field: "width", Message: "Key: 'width' Error:Field validation for 'width' failed on the 'min' tag"},
+		})
+	}
 	if identifier == "not-found" {
 		return nil, app_error.NewNotfoundError("File")
+	} else if identifier == "pending" {
+		return nil, app_error.NewUnavailableError("File")
+	} else if identifier == "private" {
+		return nil, app_error.NewForbiddenError("File")
+	} else if identifier == "expired" {
+		return nil, app_error.NewExpiredError("File")
+	} else if identifier == "unsupported" {
+		return nil, app_error.NewUnsupportedError("Image")
+	} else if p.Version > 2 {
+		return nil, app_error.NewNotfoundError("Version")
 	} else if identifier == "error" {
 		return nil, errors.New(response.STATUS_ERROR)
+	} else if identifier == "moved" {
+		result := &retrieving.RetrieveFileResult{
+			File:     &retrieving.FileEntity{Url: "http://localhost/file/annual-report.pdf"},
+			Redirect: true,
+		}
+		return result, nil
+	}
+	file := retrieving.FileEntity{OriginalName: "annual report.pdf", Extension: "pdf"}
+	if identifier == "unicode" {
+		file.OriginalName = "laporan ñ.pdf"
+	}
+	if p.Transform.Format == "png" {
+		file.Mimetype = "image/png"
+		file.Extension = "png"
 	}
-	file := retrieving.FileEntity{}
 	fileData := make([]byte, 0)
 	result := &retrieving.RetrieveFileResult{
-		File:     &file,
-		FileData: fileData,
+		File:         &file,
+		FileData:     fileData,
+		IsAttachment: p.Download,
+	}
+	return result, nil
+}
+
+type FakeFileSignerService struct {
+	param retrieving.SignFileUrlParam
+}
+
+func (stub *FakeFileSignerService) SignFileUrl(p retrieving.SignFileUrlParam) (*retrieving.SignedUrlEntity, error) {
+	stub.param = p
+	if p.Identifier == "not-found" {
+		return nil, app_error.NewNotfoundError("File")
+	} else if p.Identifier == "unsupported" {
+		return nil, app_error.NewUnsupportedError("Signed url")
+	} else if p.ExpiresIn < 0 {
+		return nil, app_error.NewValidationError([]app_error.ValidationItem{
+			{Field: "expires_in", Message: "Key: 'expires_in' Error:Field validation for 'expires_in' failed on the 'min' tag"},
+		})
+	}
+	expiresAt := time.Unix(1640858210, 0)
+	result := &retrieving.SignedUrlEntity{
+		Url:       "http://localhost/file/annual-report.pdf?expires=1640858210&signature=abc",
+		ExpiresAt: &expiresAt,
+	}
+	return result, nil
+}
+
+type FakeArchiveService struct {
+	param archiving.PrepareArchiveParam
+}
+
+func (stub *FakeArchiveService) PrepareArchive(p archiving.PrepareArchiveParam) (*archiving.ArchiveEntity, error) {
+	stub.param = p
+	if len(p.Files) == 0 {
+		return nil, app_error.NewValidationError([]app_error.ValidationItem{
+			{Field: "files", Message: "Key: 'files' Error:Field validation for 'files' failed on the 'min' tag"},
+		})
+	} else if p.Files[0].Identifier == "not-found" {
+		return nil, app_error.NewNotfoundError("File")
+	}
+	result := &archiving.ArchiveEntity{
+		Entries: []archiving.EntryEntity{{Identifier: p.Files[0].Identifier, Name: "report.pdf"}},
+		Skipped: []archiving.SkippedEntity{{Identifier: "missing", Reason: archiving.SKIP_REASON_NOT_FOUND}},
+	}
+	return result, nil
+}
+
+func (stub *FakeArchiveService) WriteArchive(p archiving.WriteArchiveParam) error {
+	_, err := p.Writer.Write([]byte("zip content"))
+	return err
+}
+
+type FakeFileListerService struct {
+	param retrieving.ListFilesParam
+}
+
+func (stub *FakeFileListerService) ListFiles(p retrieving.ListFilesParam) (*retrieving.ListFilesResult, error) {
+	stub.param = p
+	if p.Metadata["category"] == "error" {
+		return nil, errors.New(response.STATUS_ERROR)
+	}
+	if _, ok := p.Metadata["1category"]; ok {
+		return nil, app_error.NewValidationError([]app_error.ValidationItem{
+			{Field: "metadata", Message: "metadata must be valid metadata"},
+		})
+	}
+	result := &retrieving.ListFilesResult{
+		Files:  []retrieving.FileEntity{{UniqueId: "file-1", Tags: p.Tags}},
+		Total:  1,
+		Limit:  20,
+		Offset: 0,
+	}
+	return result, nil
+}
+
+type FakeUpdateService struct {
+	param updating.UpdateFileParam
+}
+
+func (stub *FakeUpdateService) UpdateFile(p updating.UpdateFileParam) error {
+	stub.param = p
+	if p.Identifier == "not-found" {
+		return app_error.NewNotfoundError("File")
+	} else if p.Identifier == "forbidden" {
+		return app_error.NewForbiddenError("File")
+	} else if p.Identifier == "stale" {
+		return app_error.NewPreconditionFailedError("File")
+	} else if p.Slug == "taken" {
+		return app_error.NewAlreadyExistsError("Slug")
+	} else if p.Identifier == "invalid" {
+		return app_error.NewValidationError([]app_error.ValidationItem{
+			{Field: "tags", Message: "Key: 'updateRule.tags' Error:Field validation for 'tags' failed on the 'valid_tags' tag"},
+		})
+	}
+	return nil
+}
+
+type FakeUploadService struct {
+	uploadParam  uploading.UploadFileParam
+	streamed     []byte
+	replaceParam uploading.ReplaceFileParam
+	archiveParam uploading.UploadArchiveParam
+	remoteParam  uploading.UploadRemoteFileParam
+}
+
+func (stub *FakeUploadService) UploadFile(p uploading.UploadFileParam) (*uploading.FileEntity, error) {
+	stub.uploadParam = p
+	if p.File.Stream != nil {
+		streamed, err := io.ReadAll(p.File.Stream)
+		if err != nil {
+			return nil, err
+		}
+		stub.streamed = streamed
+	}
+	return &uploading.FileEntity{Version: 1, Revision: 1}, nil
+}
+
+func (stub *FakeUploadService) ReplaceFile(p uploading.ReplaceFileParam) (*uploading.FileEntity, error) {
+	stub.replaceParam = p
+	if p.Identifier == "not-found" {
+		return nil, app_error.NewNotfoundError("File")
+	} else if p.Identifier == "stale" {
+		return nil, app_error.NewPreconditionFailedError("File")
+	}
+	return &uploading.FileEntity{Version: 2, Revision: 4}, nil
+}
+
+func (stub *FakeUploadService) UploadArchive(p uploading.UploadArchiveParam) (*uploading.UploadArchiveResult, error) {
+	stub.archiveParam = p
+	if p.File.OriginalName == "bomb.zip" {
+		return nil, app_error.NewValidationError([]app_error.ValidationItem{
+			{Field: "file", Message: "Key: 'file' Error:Field validation for 'file' failed on the 'max_ratio' tag"},
+		})
+	}
+	result := &uploading.UploadArchiveResult{
+		Entries: []uploading.ArchiveEntryEntity{
+			{Name: "docs/report.pdf", Status: uploading.ENTRY_STATUS_UPLOADED, File: &uploading.FileEntity{UniqueId: "file-1"}},
+			{Name: "docs/tool.exe", Status: uploading.ENTRY_STATUS_FAILED, Error: app_error.NewValidationError([]app_error.ValidationItem{
+				{Field: "detected_mimetype", Message: "Key: 'fileRule.detected_mimetype' Error:Field validation for 'detected_mimetype' failed on the 'valid_content_type' tag"},
+			})},
+			{Name: "../passwd", Status: uploading.ENTRY_STATUS_SKIPPED, Reason: "unsafe_path"},
+		},
+	}
+	return result, nil
+}
+
+func (stub *FakeUploadService) UploadRemoteFile(p uploading.UploadRemoteFileParam) (*uploading.FileEntity, error) {
+	stub.remoteParam = p
+	if strings.Contains(p.Url, "127.0.0.1") {
+		return nil, app_error.NewValidationError([]app_error.ValidationItem{
+			{Field: "url", Message: "Key: 'url' Error:Field validation for 'url' failed on the 'public_address' tag"},
+		})
+	} else if strings.HasSuffix(p.Url, "/missing") {
+		return nil, app_error.NewUnavailableError("Remote file")
+	}
+	return &uploading.FileEntity{UniqueId: "file-1", Version: 1, Revision: 1}, nil
+}
+
+type FakeVersionService struct {
+	restoreParam versioning.RestoreVersionParam
+}
+
+func (stub *FakeVersionService) ListVersions(p versioning.ListVersionsParam) ([]versioning.VersionEntity, error) {
+	if p.Identifier == "not-found" {
+		return nil, app_error.NewNotfoundError("File")
+	} else if p.Identifier == "forbidden" {
+		return nil, app_error.NewForbiddenError("File")
+	}
+	return []versioning.VersionEntity{
+		{Version: 1, Size: 10, Extension: "png", Url: "http://localhost/file/fake-identifier.png?version=1"},
+		{Version: 2, Size: 20, Extension: "png", Url: "http://localhost/file/fake-identifier.png?version=2", IsCurrent: true},
+	}, nil
+}
+
+func (stub *FakeVersionService) RestoreVersion(p versioning.RestoreVersionParam) error {
+	stub.restoreParam = p
+	if p.Identifier == "not-found" {
+		return app_error.NewNotfoundError("Version")
+	} else if p.Identifier == "pending" {
+		return app_error.NewUnavailableError("Version")
+	} else if p.Identifier == "stale" {
+		return app_error.NewPreconditionFailedError("File")
+	}
+	return nil
+}
+
+func (stub *FakeVersionService) PruneVersions(p versioning.PruneVersionsParam) error {
+	return nil
+}
+
+type FakeAuthenticator struct {
+}
+
+func (stub *FakeAuthenticator) Authenticate(token string) (*auth.Claims, error) {
+	switch token {
+	case "reader":
+		return &auth.Claims{Subject: token, Scopes: []string{auth.SCOPE_FILE_READ}}, nil
+	case "writer":
+		return &auth.Claims{Subject: token, Scopes: []string{auth.SCOPE_FILE_WRITE}}, nil
+	}
+	return nil, app_error.NewUnauthenticatedError("Token")
+}
+
+type FakeLimiter struct {
+	deniedIp        string
+	deniedPrincipal string
+}
+
+func (stub *FakeLimiter) LimitIp(ip string) (*ratelimit.LimitResult, error) {
+	result := &ratelimit.LimitResult{
+		Limit:      10,
+		Remaining:  3,
+		ResetAfter: 6 * time.Second,
+	}
+	if ip == stub.deniedIp {
+		result.Remaining = 0
+		return result, app_error.NewTooManyRequestError("ip", 1500*time.Millisecond)
+	}
+	return result, nil
+}
+
+func (stub *FakeLimiter) Limit(p ratelimit.LimitParam) (*ratelimit.LimitResult, error) {
+	result := &ratelimit.LimitResult{
+		Limit:      10,
+		Remaining:  9,
+		ResetAfter: 6 * time.Second,
+	}
+	if p.Principal != "" && p.Principal == stub.deniedPrincipal {
+		result.Remaining = 0
+		return result, app_error.NewTooManyRequestError("principal", 1500*time.Millisecond)
+	}
+	return result, nil
+}
+
+type FakeConfig struct {
+	values map[string]interface{}
+}
+
+func (c *FakeConfig) GetString(key string) string {
+	value, _ := c.values[key].(string)
+	return value
+}
+
+func (c *FakeConfig) GetInt(key string) int {
+	value, _ := c.values[key].(int)
+	return value
+}
+
+func (c *FakeConfig) GetBool(key string) bool {
+	value, _ := c.values[key].(bool)
+	return value
+}
+
+func (c *FakeConfig) Get(key string) interface{} {
+	return c.values[key]
+}
+
+type FakeQuotaGetterService struct {
+}
+
+func (stub *FakeQuotaGetterService) GetQuota(applicationId string) (*quota.QuotaEntity, error) {
+	if applicationId == "error" {
+		return nil, errors.New(response.STATUS_ERROR)
+	}
+	result := &quota.QuotaEntity{
+		ApplicationId: applicationId,
+		MaxTotalSize:  100,
+		TotalSize:     40,
+		FileCount:     2,
 	}
 	return result, nil
 }
@@ -0,0 +1,78 @@
+package builtin_app
+
+import (
+	"bufio"
+	"encoding/json"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"idaman.id/storage/internal/archiving"
+	app_error "idaman.id/storage/internal/error"
+	response "idaman.id/storage/internal/response"
+)
+
+func NewArchiveHandler(aService archiving.ArchiveService) Handler {
+	return func(ctx *Context) error {
+		body := struct {
+			Files []struct {
+				Identifier string `json:"identifier"`
+				Folder     string `json:"folder"`
+			} `json:"files"`
+		}{}
+		err := json.Unmarshal(ctx.Body(), &body)
+		if err != nil {
+			return newInvalidJsonResponse(ctx, "body")
+		}
+
+		files := []archiving.ArchiveFileParam{}
+		for _, f := range body.Files {
+			files = append(files, archiving.ArchiveFileParam{
+				Identifier: f.Identifier,
+				Folder:     f.Folder,
+			})
+		}
+		archive, err := aService.PrepareArchive(archiving.PrepareArchiveParam{
+			Claims: GetClaims(ctx),
+			Files:  files,
+		})
+		if err != nil {
+			var statusCode int
+			var resBody *response.ResponseEntity
+
+			switch err.(type) {
+			case *app_error.ValidationError:
+				validationError := err.(*app_error.ValidationError)
+				statusCode = fiber.StatusUnprocessableEntity
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: validationError.Error(),
+					Error:   validationError.Items,
+				})
+			case *app_error.NotfoundError:
+				statusCode = fiber.StatusNotFound
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			default:
+				statusCode = fiber.StatusBadRequest
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			}
+			return ctx.Status(statusCode).JSON(resBody)
+		}
+
+		ctx.Set(fiber.HeaderContentType, "application/zip")
+		ctx.Set(fiber.HeaderContentDisposition, contentDisposition("attachment", "archive.zip"))
+		ctx.Set("X-Archive-Skipped", strconv.Itoa(len(archive.Skipped)))
+		// the status and headers are already sent once streaming starts,
+		// a failing write leaves the client with a truncated archive
+		ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			aService.WriteArchive(archiving.WriteArchiveParam{
+				Archive: archive,
+				Writer:  w,
+			})
+			w.Flush()
+		})
+		return nil
+	}
+}
@@ -0,0 +1,78 @@
+package builtin_app_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/auth"
+	builtin_app "idaman.id/storage/internal/builtin-app"
+)
+
+var _ = Describe("Archive Handler", func() {
+	var (
+		fiberApp       *fiber.App
+		archiveService *FakeArchiveService
+	)
+
+	BeforeEach(func() {
+		archiveService = &FakeArchiveService{}
+		fiberApp = fiber.New()
+		fiberApp.Use(func(ctx *fiber.Ctx) error {
+			ctx.Locals(builtin_app.CLAIMS_KEY, &auth.Claims{Tenant: "app-1"})
+			return ctx.Next()
+		})
+		fiberApp.Post("/v1/archive", builtin_app.NewArchiveHandler(archiveService))
+	})
+
+	When("body is not valid json", func() {
+		It("should return invalid data response", func() {
+			req := httptest.NewRequest(http.MethodPost, "/v1/archive", strings.NewReader(`{"files":`))
+			res, _ := fiberApp.Test(req)
+
+			Expect(res.StatusCode).To(Equal(fiber.StatusUnprocessableEntity))
+		})
+	})
+
+	When("no file requested", func() {
+		It("should return invalid data response", func() {
+			req := httptest.NewRequest(http.MethodPost, "/v1/archive", strings.NewReader(`{"files":[]}`))
+			res, _ := fiberApp.Test(req)
+
+			resEntity := UnmarshallResponseBody(res.Body)
+
+			Expect(res.StatusCode).To(Equal(fiber.StatusUnprocessableEntity))
+			Expect(resEntity.Error).To(HaveLen(1))
+		})
+	})
+
+	When("no file can be archived", func() {
+		It("should return not found response", func() {
+			req := httptest.NewRequest(http.MethodPost, "/v1/archive", strings.NewReader(`{"files":[{"identifier":"not-found"}]}`))
+			res, _ := fiberApp.Test(req)
+
+			Expect(res.StatusCode).To(Equal(fiber.StatusNotFound))
+		})
+	})
+
+	When("archive is prepared", func() {
+		It("should stream the zip archive", func() {
+			req := httptest.NewRequest(http.MethodPost, "/v1/archive", strings.NewReader(`{"files":[{"identifier":"report","folder":"docs"}]}`))
+			res, _ := fiberApp.Test(req)
+
+			body, _ := ioutil.ReadAll(res.Body)
+
+			Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+			Expect(res.Header.Get("Content-Type")).To(Equal("application/zip"))
+			Expect(res.Header.Get("Content-Disposition")).To(Equal(`attachment; filename="archive.zip"`))
+			Expect(res.Header.Get("X-Archive-Skipped")).To(Equal("1"))
+			Expect(string(body)).To(Equal("zip content"))
+			Expect(archiveService.param.Claims.Tenant).To(Equal("app-1"))
+			Expect(archiveService.param.Files[0].Folder).To(Equal("docs"))
+		})
+	})
+})
@@ -0,0 +1,95 @@
+package builtin_app
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"idaman.id/storage/internal/auth"
+	app_error "idaman.id/storage/internal/error"
+	response "idaman.id/storage/internal/response"
+)
+
+const CLAIMS_KEY = "claims"
+
+func NewAuthHandler(authenticator auth.Authenticator) Handler {
+	return func(ctx *Context) error {
+		header := ctx.Get(fiber.HeaderAuthorization)
+		token := strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+		isBearer := strings.HasPrefix(header, "Bearer ") && token != ""
+		if !isBearer {
+			err := app_error.NewUnauthenticatedError("Token")
+			responseEntity := response.NewErrorResponse(&response.ResponseParam{
+				Message: err.Error(),
+			})
+			return ctx.Status(fiber.StatusUnauthorized).JSON(responseEntity)
+		}
+
+		claims, err := authenticator.Authenticate(token)
+		if err != nil {
+			var responseEntity *response.ResponseEntity
+			var status int
+			switch err.(type) {
+			case *app_error.UnauthenticatedError:
+				status = fiber.StatusUnauthorized
+				responseEntity = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			default:
+				status = fiber.StatusBadRequest
+				responseEntity = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			}
+			return ctx.Status(status).JSON(responseEntity)
+		}
+
+		ctx.Locals(CLAIMS_KEY, claims)
+		return ctx.Next()
+	}
+}
+
+func NewScopeHandler(scope string) Handler {
+	return func(ctx *Context) error {
+		claims := GetClaims(ctx)
+		if claims == nil || !claims.HasScope(scope) {
+			err := app_error.NewForbiddenError(scope)
+			responseEntity := response.NewErrorResponse(&response.ResponseParam{
+				Message: err.Error(),
+			})
+			return ctx.Status(fiber.StatusForbidden).JSON(responseEntity)
+		}
+		return ctx.Next()
+	}
+}
+
+// NewScopedHandlers guards hs behind token authentication and the given scope,
+// hs are returned as is when no authenticator is configured
+func NewScopedHandlers(authenticator auth.Authenticator, scope string, hs ...Handler) []Handler {
+	if authenticator == nil {
+		return hs
+	}
+	return append([]Handler{NewAuthHandler(authenticator), NewScopeHandler(scope)}, hs...)
+}
+
+// GetPrincipal identifies the authenticated caller by the token subject, or by the tenant when the token has no subject,
+// it is empty for anonymous caller
+func GetPrincipal(ctx *Context) string {
+	claims := GetClaims(ctx)
+	switch {
+	case claims == nil:
+		return ""
+	case claims.Subject != "":
+		return "sub:" + claims.Subject
+	case claims.Tenant != "":
+		return "tenant:" + claims.Tenant
+	}
+	return ""
+}
+
+func GetClaims(ctx *Context) *auth.Claims {
+	claims, isAvailable := ctx.Locals(CLAIMS_KEY).(*auth.Claims)
+	if !isAvailable {
+		return nil
+	}
+	return claims
+}
@@ -0,0 +1,99 @@
+package builtin_app_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gofiber/fiber/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/auth"
+	builtin_app "idaman.id/storage/internal/builtin-app"
+	app_error "idaman.id/storage/internal/error"
+	response "idaman.id/storage/internal/response"
+)
+
+var _ = Describe("Auth Handler", func() {
+	var (
+		fiberApp      *fiber.App
+		authenticator auth.Authenticator
+	)
+
+	BeforeEach(func() {
+		fiberApp = fiber.New()
+		authenticator = &FakeAuthenticator{}
+		fiberApp.Get("/scoped", builtin_app.NewScopedHandlers(authenticator, auth.SCOPE_FILE_READ, func(ctx *builtin_app.Context) error {
+			claims := builtin_app.GetClaims(ctx)
+			return ctx.SendString(claims.Subject)
+		})...)
+	})
+
+	When("authorization header is missing", func() {
+		It("should return unauthenticated response", func() {
+			req := httptest.NewRequest(http.MethodGet, "/scoped", nil)
+			res, _ := fiberApp.Test(req)
+
+			resEntity := UnmarshallResponseBody(res.Body)
+
+			expected := response.NewErrorResponse(&response.ResponseParam{
+				Message: app_error.STATUS_UNAUTHENTICATED,
+			})
+
+			Expect(res.StatusCode).To(Equal(fiber.StatusUnauthorized))
+			Expect(resEntity).To(Equal(expected))
+		})
+	})
+
+	When("token is invalid", func() {
+		It("should return unauthenticated response", func() {
+			req := httptest.NewRequest(http.MethodGet, "/scoped", nil)
+			req.Header.Set("Authorization", "Bearer invalid")
+			res, _ := fiberApp.Test(req)
+
+			resEntity := UnmarshallResponseBody(res.Body)
+
+			expected := response.NewErrorResponse(&response.ResponseParam{
+				Message: app_error.STATUS_UNAUTHENTICATED,
+			})
+
+			Expect(res.StatusCode).To(Equal(fiber.StatusUnauthorized))
+			Expect(resEntity).To(Equal(expected))
+		})
+	})
+
+	When("token has no required scope", func() {
+		It("should return forbidden response", func() {
+			req := httptest.NewRequest(http.MethodGet, "/scoped", nil)
+			req.Header.Set("Authorization", "Bearer writer")
+			res, _ := fiberApp.Test(req)
+
+			resEntity := UnmarshallResponseBody(res.Body)
+
+			expected := response.NewErrorResponse(&response.ResponseParam{
+				Message: app_error.STATUS_FORBIDDEN,
+			})
+
+			Expect(res.StatusCode).To(Equal(fiber.StatusForbidden))
+			Expect(resEntity).To(Equal(expected))
+		})
+	})
+
+	When("token has required scope", func() {
+		It("should pass claims to the next handler", func() {
+			req := httptest.NewRequest(http.MethodGet, "/scoped", nil)
+			req.Header.Set("Authorization", "Bearer reader")
+			res, _ := fiberApp.Test(req)
+
+			Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+			Expect(StringifyResponse(res.Body)).To(Equal("reader"))
+		})
+	})
+
+	When("authenticator is not configured", func() {
+		It("should not guard the handler", func() {
+			handlers := builtin_app.NewScopedHandlers(nil, auth.SCOPE_FILE_READ, builtin_app.NewHomeHandler())
+
+			Expect(handlers).To(HaveLen(1))
+		})
+	})
+})
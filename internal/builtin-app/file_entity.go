@@ -2,15 +2,78 @@ package builtin_app
 
 import (
 	"time"
+
+	"idaman.id/storage/internal/extracting"
 )
 
 type FileDetailEntity struct {
-	UniqueId  string     `json:"unique_id"`
-	Name      string     `json:"name"`
-	Extension string     `json:"extension"`
-	Size      int64      `json:"size"`
-	Mimetype  string     `json:"mimetype"`
+	UniqueId          string                     `json:"unique_id"`
+	Slug              string                     `json:"slug"`
+	Name              string                     `json:"name"`
+	Extension         string                     `json:"extension"`
+	Size              int64                      `json:"size"`
+	Mimetype          string                     `json:"mimetype"`
+	ScanStatus        string                     `json:"scan_status"`
+	ExtractedMetadata *extracting.MetadataEntity `json:"extracted_metadata"`
+	Checksum          string                     `json:"checksum"`
+	MetadataStripped  bool                       `json:"metadata_stripped"`
+	Metadata          map[string]string          `json:"metadata"`
+	Tags              []string                   `json:"tags"`
+	Visibility        string                     `json:"visibility"`
+	Provider          string                     `json:"provider"`
+	Revision          int64                      `json:"revision"`
+	Version           int64                      `json:"version"`
+	Url               string                     `json:"url"`
+	CreatedAt         *time.Time                 `json:"created_at"`
+	UpdatedAt         *time.Time                 `json:"updated_at"`
+	ExpiresAt         *time.Time                 `json:"expires_at"`
+	Variants          []VariantDetailEntity      `json:"variants"`
+}
+
+type FileListEntity struct {
+	Files  []FileDetailEntity `json:"files"`
+	Total  int64              `json:"total"`
+	Limit  int                `json:"limit"`
+	Offset int                `json:"offset"`
+}
+
+type VersionDetailEntity struct {
+	Version    int64      `json:"version"`
+	Size       int64      `json:"size"`
+	Extension  string     `json:"extension"`
+	Mimetype   string     `json:"mimetype"`
+	ScanStatus string     `json:"scan_status"`
+	Checksum   string     `json:"checksum"`
+	Url        string     `json:"url"`
+	IsCurrent  bool       `json:"is_current"`
+	CreatedAt  *time.Time `json:"created_at"`
+}
+
+type UploadArchiveEntity struct {
+	Entries []ArchiveEntryDetailEntity `json:"entries"`
+}
+
+// ArchiveEntryDetailEntity is the upload result of an archive entry,
+// Message tells why it failed or was skipped and Error holds the failed validation items
+type ArchiveEntryDetailEntity struct {
+	Name    string            `json:"name"`
+	Status  string            `json:"status"`
+	File    *FileDetailEntity `json:"file,omitempty"`
+	Message string            `json:"message,omitempty"`
+	Error   interface{}       `json:"error,omitempty"`
+}
+
+type SignedUrlEntity struct {
 	Url       string     `json:"url"`
-	CreatedAt *time.Time `json:"created_at"`
-	UpdatedAt *time.Time `json:"updated_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type VariantDetailEntity struct {
+	Name      string `json:"name"`
+	Extension string `json:"extension"`
+	Size      int64  `json:"size"`
+	Mimetype  string `json:"mimetype"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Url       string `json:"url"`
 }
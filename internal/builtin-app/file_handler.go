@@ -1,17 +1,35 @@
 package builtin_app
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
+	"idaman.id/storage/internal/deleting"
 	app_error "idaman.id/storage/internal/error"
 	"idaman.id/storage/internal/file"
+	"idaman.id/storage/internal/imaging"
+	"idaman.id/storage/internal/quota"
 	response "idaman.id/storage/internal/response"
 	"idaman.id/storage/internal/retrieving"
+	"idaman.id/storage/internal/updating"
 	"idaman.id/storage/internal/uploading"
+	"idaman.id/storage/internal/versioning"
 )
 
 func NewFileGetDetailHandler(rService retrieving.FileGetter) Handler {
 	return func(ctx *Context) error {
-		fileDetail, err := rService.GetFile(ctx.Params("identifier"))
+		fileDetail, err := rService.GetFile(retrieving.GetFileParam{
+			Identifier: ctx.Params("identifier"),
+			Claims:     GetClaims(ctx),
+		})
 		if err != nil {
 			var statusCode int
 			var resBody *response.ResponseEntity
@@ -23,6 +41,11 @@ func NewFileGetDetailHandler(rService retrieving.FileGetter) Handler {
 				resBody = response.NewErrorResponse(&response.ResponseParam{
 					Message: notFoundError.Error(),
 				})
+			case *app_error.ForbiddenError:
+				statusCode = fiber.StatusForbidden
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
 			default:
 				statusCode = fiber.StatusBadRequest
 				resBody = response.NewErrorResponse(&response.ResponseParam{
@@ -33,26 +56,33 @@ func NewFileGetDetailHandler(rService retrieving.FileGetter) Handler {
 			return ctx.Status(statusCode).JSON(resBody)
 		}
 
-		fileEntity := &FileDetailEntity{
-			UniqueId:  fileDetail.UniqueId,
-			Name:      fileDetail.Name,
-			Extension: fileDetail.Extension,
-			Size:      fileDetail.Size,
-			Mimetype:  fileDetail.Mimetype,
-			Url:       fileDetail.Url,
-			CreatedAt: fileDetail.CreatedAt,
-			UpdatedAt: fileDetail.UpdatedAt,
-		}
+		fileEntity := newFileDetailEntity(fileDetail)
 		resBody := response.NewSuccessResponse(&response.ResponseParam{
 			Data: fileEntity,
 		})
+		ctx.Set(fiber.HeaderETag, revisionETag(fileDetail.Revision))
 		return ctx.JSON(resBody)
 	}
 }
 
 func NewGetResourceHandler(rService retrieving.FileRetriever) Handler {
 	return func(ctx *Context) error {
-		result, err := rService.RetrieveFile(ctx.Params("identifier"))
+		result, err := rService.RetrieveFile(retrieving.RetrieveFileParam{
+			Identifier: ctx.Params("identifier"),
+			Variant:    ctx.Query("variant"),
+			Transform: &imaging.TransformOption{
+				Width:   queryInt(ctx, "width"),
+				Height:  queryInt(ctx, "height"),
+				Fit:     ctx.Query("fit"),
+				Crop:    ctx.Query("crop"),
+				Quality: queryInt(ctx, "quality"),
+				Format:  ctx.Query("format"),
+			},
+			Version:   int64(queryInt(ctx, "version")),
+			Download:  queryBool(ctx, "download"),
+			Signature: ctx.Query("signature"),
+			ExpiresAt: int64(queryInt(ctx, "expires")),
+		})
 
 		if err != nil {
 			var responseEntity *response.ResponseEntity
@@ -65,6 +95,33 @@ func NewGetResourceHandler(rService retrieving.FileRetriever) Handler {
 				responseEntity = response.NewErrorResponse(&response.ResponseParam{
 					Message: notFoundError.Error(),
 				})
+			case *app_error.ExpiredError:
+				statusCode = fiber.StatusGone
+				responseEntity = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			case *app_error.UnavailableError:
+				statusCode = fiber.StatusForbidden
+				responseEntity = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			case *app_error.ForbiddenError:
+				statusCode = fiber.StatusForbidden
+				responseEntity = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			case *app_error.UnsupportedError:
+				statusCode = fiber.StatusUnsupportedMediaType
+				responseEntity = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			case *app_error.ValidationError:
+				validationError := err.(*app_error.ValidationError)
+				statusCode = fiber.StatusUnprocessableEntity
+				responseEntity = response.NewErrorResponse(&response.ResponseParam{
+					Message: validationError.Error(),
+					Error:   validationError.Items,
+				})
 			default:
 				statusCode = fiber.StatusBadRequest
 				responseEntity = response.NewErrorResponse(&response.ResponseParam{
@@ -75,73 +132,1060 @@ func NewGetResourceHandler(rService retrieving.FileRetriever) Handler {
 			return ctx.Status(statusCode).JSON(responseEntity)
 		}
 
+		if result.Redirect {
+			location := result.File.Url
+			if query := string(ctx.Request().URI().QueryString()); query != "" {
+				location = fmt.Sprintf("%s?%s", location, query)
+			}
+			return ctx.Redirect(location, fiber.StatusMovedPermanently)
+		}
+
+		dispositionType := "inline"
+		if result.IsAttachment {
+			dispositionType = "attachment"
+		}
 		ctx.Set("Content-Type", result.File.Mimetype)
+		ctx.Set(fiber.HeaderContentDisposition, contentDisposition(dispositionType, downloadName(result.File)))
+		ctx.Set(fiber.HeaderXContentTypeOptions, "nosniff")
 		return ctx.Send(result.FileData)
 	}
 }
 
+// NewUploadFileHandler accepts either a `multipart/form-data` or an `application/json` body,
+// both are turned into the same file entity before the upload
 func NewUploadFileHandler(uService uploading.UploadService, fService file.FileService) Handler {
 	return func(ctx *Context) error {
-
-		fileHeader, err := ctx.FormFile("file")
+		var form *uploadForm
+		var err error
+		if ctx.Is("json") {
+			form, err = parseJsonUploadForm(ctx, fService)
+		} else {
+			form, err = parseMultipartUploadForm(ctx, fService)
+		}
 		if err != nil {
-			err = app_error.NewNotfoundError("File")
+			if validationError, ok := err.(*app_error.ValidationError); ok {
+				responseEntity := response.NewErrorResponse(&response.ResponseParam{
+					Message: validationError.Error(),
+					Error:   validationError.Items,
+				})
+				return ctx.Status(fiber.StatusUnprocessableEntity).JSON(responseEntity)
+			}
 			responseEntity := response.NewErrorResponse(&response.ResponseParam{
 				Message: err.Error(),
 			})
 			return ctx.Status(fiber.StatusBadRequest).JSON(responseEntity)
 		}
 
-		fileEntity, err := file.NewFileFromMultipartHeader(fileHeader, fService)
+		if form.Extract {
+			return uploadArchive(ctx, uService, uploading.UploadArchiveParam{
+				File:      form.File,
+				Claims:    GetClaims(ctx),
+				Provider:  form.Provider,
+				Metadata:  form.Metadata,
+				Tags:      form.Tags,
+				ExpiresAt: form.ExpiresAt,
+				Ttl:       form.Ttl,
+			})
+		}
+
+		fileDetail, err := uService.UploadFile(uploading.UploadFileParam{
+			File:      form.File,
+			Claims:    GetClaims(ctx),
+			Provider:  form.Provider,
+			Metadata:  form.Metadata,
+			Tags:      form.Tags,
+			Slug:      form.Slug,
+			ExpiresAt: form.ExpiresAt,
+			Ttl:       form.Ttl,
+		})
 
 		if err != nil {
-			err = app_error.NewNotfoundError("File")
+			return newUploadErrorResponse(ctx, err)
+		}
+
+		res := newFileDetailEntity(fileDetail)
+		responseEntity := response.NewSuccessResponse(&response.ResponseParam{
+			Data: res,
+		})
+		return ctx.JSON(responseEntity)
+	}
+}
+
+// uploadForm is the upload request once parsed, whatever its content type
+type uploadForm struct {
+	File      *file.FileEntity
+	Provider  string
+	Metadata  map[string]string
+	Tags      []string
+	Slug      string
+	Extract   bool
+	ExpiresAt *time.Time
+	Ttl       int64
+}
+
+// parseMultipartUploadForm reads the content from the `file` field,
+// `metadata` is a JSON object and `tags` is either repeated or comma separated
+func parseMultipartUploadForm(ctx *Context, fService file.FileService) (*uploadForm, error) {
+	fileEntity, err := parseMultipartFile(ctx, fService)
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata map[string]string
+	if rawMetadata := ctx.FormValue("metadata"); rawMetadata != "" {
+		err = json.Unmarshal([]byte(rawMetadata), &metadata)
+		if err != nil {
+			return nil, newInvalidFieldError("metadata", "json")
+		}
+	}
+	var tags []string
+	if form, err := ctx.MultipartForm(); err == nil {
+		tags = normalizeTags(form.Value["tags"])
+	}
+	isExtract, _ := strconv.ParseBool(ctx.FormValue("extract"))
+	expiresAt, ttl, err := parseExpiry(ctx.FormValue("expires_at"), ctx.FormValue("ttl"))
+	if err != nil {
+		return nil, err
+	}
+
+	form := &uploadForm{
+		File:      fileEntity,
+		Provider:  ctx.FormValue("provider"),
+		Metadata:  metadata,
+		Tags:      tags,
+		Slug:      ctx.FormValue("slug"),
+		Extract:   isExtract,
+		ExpiresAt: expiresAt,
+		Ttl:       ttl,
+	}
+	return form, nil
+}
+
+// parseMultipartFile reads the `file` field of a `multipart/form-data` body
+func parseMultipartFile(ctx *Context, fService file.FileService) (*file.FileEntity, error) {
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		return nil, app_error.NewNotfoundError("File")
+	}
+
+	input, err := file.NewMultipartInput(fileHeader)
+	if err != nil {
+		return nil, app_error.NewNotfoundError("File")
+	}
+	fileEntity, err := file.NewFileFromInput(input, fService)
+	if err != nil {
+		return nil, app_error.NewNotfoundError("File")
+	}
+	return fileEntity, nil
+}
+
+// parseJsonUploadForm reads the content from `content` holding either base64 or a data uri,
+// the content is decoded while the body is parsed
+func parseJsonUploadForm(ctx *Context, fService file.FileService) (*uploadForm, error) {
+	body := struct {
+		Filename  string              `json:"filename"`
+		Mimetype  string              `json:"mimetype"`
+		Content   *file.Base64Content `json:"content"`
+		Provider  string              `json:"provider"`
+		Metadata  map[string]string   `json:"metadata"`
+		Tags      []string            `json:"tags"`
+		Slug      string              `json:"slug"`
+		Extract   bool                `json:"extract"`
+		ExpiresAt string              `json:"expires_at"`
+		Ttl       int64               `json:"ttl"`
+	}{}
+	err := json.Unmarshal(ctx.Body(), &body)
+	if err == file.ErrInvalidBase64 {
+		return nil, newInvalidFieldError("content", "base64")
+	}
+	if err != nil {
+		return nil, newInvalidFieldError("body", "json")
+	}
+	if body.Content == nil || len(body.Content.Data) == 0 {
+		return nil, app_error.NewNotfoundError("File")
+	}
+
+	expiresAt, err := parseExpiresAt(body.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	fileEntity, err := file.NewFileFromInput(file.NewBase64Input(body.Filename, body.Mimetype, body.Content), fService)
+	if err != nil {
+		return nil, app_error.NewNotfoundError("File")
+	}
+
+	form := &uploadForm{
+		File:      fileEntity,
+		Provider:  body.Provider,
+		Metadata:  body.Metadata,
+		Tags:      normalizeTags(body.Tags),
+		Slug:      body.Slug,
+		Extract:   body.Extract,
+		ExpiresAt: expiresAt,
+		Ttl:       body.Ttl,
+	}
+	return form, nil
+}
+
+// NewUploadRemoteFileHandler downloads the file at `url` and uploads it like a multipart upload
+func NewUploadRemoteFileHandler(uService uploading.UploadService) Handler {
+	return func(ctx *Context) error {
+		body := struct {
+			Url       string            `json:"url"`
+			Provider  string            `json:"provider"`
+			Metadata  map[string]string `json:"metadata"`
+			Tags      []string          `json:"tags"`
+			Slug      string            `json:"slug"`
+			ExpiresAt string            `json:"expires_at"`
+			Ttl       int64             `json:"ttl"`
+		}{}
+		err := json.Unmarshal(ctx.Body(), &body)
+		if err != nil {
+			return newInvalidJsonResponse(ctx, "body")
+		}
+		expiresAt, err := parseExpiresAt(body.ExpiresAt)
+		if err != nil {
+			return newInvalidFieldResponse(ctx, "expires_at", "datetime")
+		}
+
+		fileDetail, err := uService.UploadRemoteFile(uploading.UploadRemoteFileParam{
+			Url:       body.Url,
+			Claims:    GetClaims(ctx),
+			Provider:  body.Provider,
+			Metadata:  body.Metadata,
+			Tags:      normalizeTags(body.Tags),
+			Slug:      body.Slug,
+			ExpiresAt: expiresAt,
+			Ttl:       body.Ttl,
+		})
+		if err != nil {
+			return newUploadErrorResponse(ctx, err)
+		}
+
+		responseEntity := response.NewSuccessResponse(&response.ResponseParam{
+			Data: newFileDetailEntity(fileDetail),
+		})
+		return ctx.JSON(responseEntity)
+	}
+}
+
+// NewUploadRawFileHandler uploads the request body as is under the `:name` path param,
+// `Content-Type` is the declared mimetype and `Content-Digest` is verified when it is sent,
+// the other upload fields are given as query params.
+// `Content-Length` is required, a body within the body limit is read like the form uploads
+// while a larger one is stored while it is streamed, the digest is verified as it is read
+func NewUploadRawFileHandler(uService uploading.UploadService, fService file.FileService) Handler {
+	return func(ctx *Context) error {
+		name, err := url.PathUnescape(ctx.Params("name"))
+		if err != nil {
+			return newInvalidFieldResponse(ctx, "name", "url_encoded")
+		}
+		size := int64(ctx.Request().Header.ContentLength())
+		if size < 0 {
 			responseEntity := response.NewErrorResponse(&response.ResponseParam{
-				Message: err.Error(),
+				Message: app_error.STATUS_LENGTH_REQUIRED,
+			})
+			return ctx.Status(fiber.StatusLengthRequired).JSON(responseEntity)
+		}
+		if size == 0 {
+			responseEntity := response.NewErrorResponse(&response.ResponseParam{
+				Message: app_error.NewNotfoundError("File").Error(),
+			})
+			return ctx.Status(fiber.StatusBadRequest).JSON(responseEntity)
+		}
+
+		body := ctx.Context().RequestBodyStream()
+		if body == nil {
+			body = bytes.NewReader(ctx.Body())
+		}
+		if digest := ctx.Get("Content-Digest"); digest != "" {
+			body, err = file.NewDigestReader(digest, body)
+			if err != nil {
+				return newInvalidFieldResponse(ctx, "content-digest", "digest")
+			}
+		}
+
+		var metadata map[string]string
+		if rawMetadata := ctx.Query("metadata"); rawMetadata != "" {
+			err = json.Unmarshal([]byte(rawMetadata), &metadata)
+			if err != nil {
+				return newInvalidJsonResponse(ctx, "metadata")
+			}
+		}
+		expiresAt, ttl, err := parseExpiry(ctx.Query("expires_at"), ctx.Query("ttl"))
+		switch err.(type) {
+		case nil:
+		case *app_error.ValidationError:
+			validationError := err.(*app_error.ValidationError)
+			responseEntity := response.NewErrorResponse(&response.ResponseParam{
+				Message: validationError.Error(),
+				Error:   validationError.Items,
+			})
+			return ctx.Status(fiber.StatusUnprocessableEntity).JSON(responseEntity)
+		default:
+			return err
+		}
+		tags := []string{}
+		ctx.Context().QueryArgs().VisitAll(func(key, value []byte) {
+			if string(key) == "tags" {
+				tags = append(tags, string(value))
+			}
+		})
+
+		contentType := strings.TrimSpace(strings.SplitN(ctx.Get(fiber.HeaderContentType), ";", 2)[0])
+		input := file.NewRawInput(name, contentType, size, body)
+		fileEntity, err := file.NewStreamFromInput(input, fService)
+		if err == nil && size <= int64(ctx.App().Config().BodyLimit) {
+			// the body is already in memory, so its content is inspected like the form uploads
+			err = fileEntity.ReadStream()
+		}
+		if errors.Is(err, file.ErrDigestMismatch) {
+			return newInvalidFieldResponse(ctx, "content-digest", "digest")
+		}
+		if err != nil {
+			responseEntity := response.NewErrorResponse(&response.ResponseParam{
+				Message: app_error.NewNotfoundError("File").Error(),
 			})
 			return ctx.Status(fiber.StatusBadRequest).JSON(responseEntity)
 		}
 
 		fileDetail, err := uService.UploadFile(uploading.UploadFileParam{
-			File: fileEntity,
+			File:      fileEntity,
+			Claims:    GetClaims(ctx),
+			Provider:  ctx.Query("provider"),
+			Metadata:  metadata,
+			Tags:      normalizeTags(tags),
+			Slug:      ctx.Query("slug"),
+			ExpiresAt: expiresAt,
+			Ttl:       ttl,
+		})
+		if errors.Is(err, file.ErrDigestMismatch) {
+			return newInvalidFieldResponse(ctx, "content-digest", "digest")
+		}
+		if err != nil {
+			return newUploadErrorResponse(ctx, err)
+		}
+
+		responseEntity := response.NewSuccessResponse(&response.ResponseParam{
+			Data: newFileDetailEntity(fileDetail),
 		})
+		return ctx.JSON(responseEntity)
+	}
+}
 
+// uploadArchive answers with the result of every entry,
+// only an archive which can not be unpacked at all fails the request
+func uploadArchive(ctx *Context, uService uploading.UploadService, p uploading.UploadArchiveParam) error {
+	result, err := uService.UploadArchive(p)
+	if err != nil {
+		return newUploadErrorResponse(ctx, err)
+	}
+
+	entries := []ArchiveEntryDetailEntity{}
+	for _, entry := range result.Entries {
+		detail := ArchiveEntryDetailEntity{
+			Name:    entry.Name,
+			Status:  entry.Status,
+			Message: entry.Reason,
+		}
+		if entry.File != nil {
+			detail.File = newFileDetailEntity(entry.File)
+		}
+		if entry.Error != nil {
+			detail.Message = entry.Error.Error()
+			if validationError, ok := entry.Error.(*app_error.ValidationError); ok {
+				detail.Error = validationError.Items
+			}
+		}
+		entries = append(entries, detail)
+	}
+	responseEntity := response.NewSuccessResponse(&response.ResponseParam{
+		Data: &UploadArchiveEntity{
+			Entries: entries,
+		},
+	})
+	return ctx.JSON(responseEntity)
+}
+
+// NewReplaceFileContentHandler stores the uploaded `file` as the new content version,
+// only when the file is at the revision given by `If-Match` header when the header is present
+func NewReplaceFileContentHandler(uService uploading.UploadService, fService file.FileService) Handler {
+	return func(ctx *Context) error {
+		fileEntity, err := parseMultipartFile(ctx, fService)
 		if err != nil {
-			var responseEntity *response.ResponseEntity
-			var status int
+			responseEntity := response.NewErrorResponse(&response.ResponseParam{
+				Message: err.Error(),
+			})
+			return ctx.Status(fiber.StatusBadRequest).JSON(responseEntity)
+		}
 
+		fileDetail, err := uService.ReplaceFile(uploading.ReplaceFileParam{
+			Identifier: ctx.Params("identifier"),
+			File:       fileEntity,
+			Claims:     GetClaims(ctx),
+			Revision:   parseIfMatch(ctx.Get(fiber.HeaderIfMatch)),
+		})
+		if err != nil {
+			return newUploadErrorResponse(ctx, err)
+		}
+
+		responseEntity := response.NewSuccessResponse(&response.ResponseParam{
+			Data: newFileDetailEntity(fileDetail),
+		})
+		ctx.Set(fiber.HeaderETag, revisionETag(fileDetail.Revision))
+		return ctx.JSON(responseEntity)
+	}
+}
+
+// NewUpdateFileHandler only updates the file at the revision given by `If-Match` header
+// when the header is present
+func NewUpdateFileHandler(uService updating.UpdateService, rService retrieving.FileGetter) Handler {
+	return func(ctx *Context) error {
+		body := struct {
+			Name       string            `json:"name"`
+			Slug       string            `json:"slug"`
+			Visibility string            `json:"visibility"`
+			Metadata   map[string]string `json:"metadata"`
+			Tags       []string          `json:"tags"`
+		}{}
+		err := json.Unmarshal(ctx.Body(), &body)
+		if err != nil {
+			return newInvalidJsonResponse(ctx, "body")
+		}
+
+		err = uService.UpdateFile(updating.UpdateFileParam{
+			Identifier: ctx.Params("identifier"),
+			Claims:     GetClaims(ctx),
+			Name:       body.Name,
+			Slug:       body.Slug,
+			Visibility: body.Visibility,
+			Metadata:   body.Metadata,
+			Tags:       normalizeTags(body.Tags),
+			Revision:   parseIfMatch(ctx.Get(fiber.HeaderIfMatch)),
+		})
+		if err != nil {
+			var statusCode int
+			var resBody *response.ResponseEntity
 			switch err.(type) {
 			case *app_error.ValidationError:
-				status = fiber.StatusUnprocessableEntity
 				validationError := err.(*app_error.ValidationError)
-				responseEntity = response.NewErrorResponse(&response.ResponseParam{
+				statusCode = fiber.StatusUnprocessableEntity
+				resBody = response.NewErrorResponse(&response.ResponseParam{
 					Message: validationError.Error(),
 					Error:   validationError.Items,
 				})
+			case *app_error.NotfoundError:
+				statusCode = fiber.StatusNotFound
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			case *app_error.ForbiddenError:
+				statusCode = fiber.StatusForbidden
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			case *app_error.PreconditionFailedError:
+				statusCode = fiber.StatusPreconditionFailed
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			case *app_error.AlreadyExistsError:
+				statusCode = fiber.StatusConflict
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
 			default:
-				status = fiber.StatusBadRequest
-				responseEntity = response.NewErrorResponse(&response.ResponseParam{
+				statusCode = fiber.StatusBadRequest
+				resBody = response.NewErrorResponse(&response.ResponseParam{
 					Message: err.Error(),
 				})
 			}
+			return ctx.Status(statusCode).JSON(resBody)
+		}
+
+		// the previous slug no longer resolves once the slug is updated
+		identifier := ctx.Params("identifier")
+		if body.Slug != "" {
+			identifier = body.Slug
+		}
+		fileDetail, err := rService.GetFile(retrieving.GetFileParam{
+			Identifier: identifier,
+			Claims:     GetClaims(ctx),
+		})
+		if err != nil {
+			resBody := response.NewErrorResponse(&response.ResponseParam{
+				Message: err.Error(),
+			})
+			return ctx.Status(fiber.StatusBadRequest).JSON(resBody)
+		}
+
+		resBody := response.NewSuccessResponse(&response.ResponseParam{
+			Data: newFileDetailEntity(fileDetail),
+		})
+		ctx.Set(fiber.HeaderETag, revisionETag(fileDetail.Revision))
+		return ctx.JSON(resBody)
+	}
+}
+
+// NewFileListHandler filters files by repeated or comma separated `tag`
+// and `metadata.<key>` query parameters
+func NewFileListHandler(rService retrieving.FileLister) Handler {
+	return func(ctx *Context) error {
+		tags := []string{}
+		for _, tag := range ctx.Context().QueryArgs().PeekMulti("tag") {
+			tags = append(tags, string(tag))
+		}
+		metadata := map[string]string{}
+		ctx.Context().QueryArgs().VisitAll(func(key, value []byte) {
+			name := string(key)
+			if strings.HasPrefix(name, "metadata.") {
+				metadata[strings.TrimPrefix(name, "metadata.")] = string(value)
+			}
+		})
 
-			return ctx.Status(status).JSON(responseEntity)
+		result, err := rService.ListFiles(retrieving.ListFilesParam{
+			Claims:   GetClaims(ctx),
+			Tags:     normalizeTags(tags),
+			Metadata: metadata,
+			Limit:    queryInt(ctx, "limit"),
+			Offset:   queryInt(ctx, "offset"),
+		})
+		if err != nil {
+			if validationError, ok := err.(*app_error.ValidationError); ok {
+				resBody := response.NewErrorResponse(&response.ResponseParam{
+					Message: validationError.Error(),
+					Error:   validationError.Items,
+				})
+				return ctx.Status(fiber.StatusUnprocessableEntity).JSON(resBody)
+			}
+			resBody := response.NewErrorResponse(&response.ResponseParam{
+				Message: err.Error(),
+			})
+			return ctx.Status(fiber.StatusBadRequest).JSON(resBody)
 		}
 
-		res := &FileDetailEntity{
-			UniqueId:  fileDetail.UniqueId,
-			Name:      fileDetail.Name,
-			Extension: fileDetail.Extension,
-			Size:      fileDetail.Size,
-			Mimetype:  fileDetail.Mimetype,
-			Url:       fileDetail.Url,
-			CreatedAt: fileDetail.CreatedAt,
-			UpdatedAt: fileDetail.UpdatedAt,
+		list := &FileListEntity{
+			Files:  []FileDetailEntity{},
+			Total:  result.Total,
+			Limit:  result.Limit,
+			Offset: result.Offset,
+		}
+		for i := range result.Files {
+			list.Files = append(list.Files, *newFileDetailEntity(&result.Files[i]))
 		}
+		resBody := response.NewSuccessResponse(&response.ResponseParam{
+			Data: list,
+		})
+		return ctx.JSON(resBody)
+	}
+}
 
-		responseEntity := response.NewSuccessResponse(&response.ResponseParam{
+func NewFileVersionListHandler(vService versioning.VersionLister) Handler {
+	return func(ctx *Context) error {
+		versions, err := vService.ListVersions(versioning.ListVersionsParam{
+			Identifier: ctx.Params("identifier"),
+			Claims:     GetClaims(ctx),
+		})
+		if err != nil {
+			var statusCode int
+			var resBody *response.ResponseEntity
+
+			switch err.(type) {
+			case *app_error.NotfoundError:
+				statusCode = fiber.StatusNotFound
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			case *app_error.ForbiddenError:
+				statusCode = fiber.StatusForbidden
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			default:
+				statusCode = fiber.StatusBadRequest
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			}
+			return ctx.Status(statusCode).JSON(resBody)
+		}
+
+		res := []VersionDetailEntity{}
+		for _, v := range versions {
+			res = append(res, VersionDetailEntity{
+				Version:    v.Version,
+				Size:       v.Size,
+				Extension:  v.Extension,
+				Mimetype:   v.Mimetype,
+				ScanStatus: v.ScanStatus,
+				Checksum:   v.Checksum,
+				Url:        v.Url,
+				IsCurrent:  v.IsCurrent,
+				CreatedAt:  v.CreatedAt,
+			})
+		}
+
+		resBody := response.NewSuccessResponse(&response.ResponseParam{
 			Data: res,
 		})
-		return ctx.JSON(responseEntity)
+		return ctx.JSON(resBody)
 	}
 }
+
+// NewRestoreVersionHandler copies the `:version` content into a new current version,
+// only when the file is at the revision given by `If-Match` header when the header is present
+func NewRestoreVersionHandler(vService versioning.VersionRestorer, rService retrieving.FileGetter) Handler {
+	return func(ctx *Context) error {
+		version, err := strconv.ParseInt(ctx.Params("version"), 10, 64)
+		if err != nil || version <= 0 {
+			err = app_error.NewNotfoundError("Version")
+			resBody := response.NewErrorResponse(&response.ResponseParam{
+				Message: err.Error(),
+			})
+			return ctx.Status(fiber.StatusNotFound).JSON(resBody)
+		}
+
+		err = vService.RestoreVersion(versioning.RestoreVersionParam{
+			Identifier: ctx.Params("identifier"),
+			Claims:     GetClaims(ctx),
+			Version:    version,
+			Revision:   parseIfMatch(ctx.Get(fiber.HeaderIfMatch)),
+		})
+		if err != nil {
+			var statusCode int
+			var resBody *response.ResponseEntity
+
+			switch err.(type) {
+			case *app_error.NotfoundError:
+				statusCode = fiber.StatusNotFound
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			case *app_error.ForbiddenError, *app_error.UnavailableError:
+				statusCode = fiber.StatusForbidden
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			case *app_error.PreconditionFailedError:
+				statusCode = fiber.StatusPreconditionFailed
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			case *app_error.QuotaExceededError:
+				statusCode = fiber.StatusInsufficientStorage
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			default:
+				statusCode = fiber.StatusBadRequest
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			}
+			return ctx.Status(statusCode).JSON(resBody)
+		}
+
+		fileDetail, err := rService.GetFile(retrieving.GetFileParam{
+			Identifier: ctx.Params("identifier"),
+			Claims:     GetClaims(ctx),
+		})
+		if err != nil {
+			resBody := response.NewErrorResponse(&response.ResponseParam{
+				Message: err.Error(),
+			})
+			return ctx.Status(fiber.StatusBadRequest).JSON(resBody)
+		}
+
+		resBody := response.NewSuccessResponse(&response.ResponseParam{
+			Data: newFileDetailEntity(fileDetail),
+		})
+		ctx.Set(fiber.HeaderETag, revisionETag(fileDetail.Revision))
+		return ctx.JSON(resBody)
+	}
+}
+
+func NewDeleteFileHandler(dService deleting.DeleteService) Handler {
+	return func(ctx *Context) error {
+		err := dService.DeleteFile(deleting.DeleteFileParam{
+			Identifier: ctx.Params("identifier"),
+			Claims:     GetClaims(ctx),
+		})
+		if err != nil {
+			var statusCode int
+			var resBody *response.ResponseEntity
+			switch err.(type) {
+			case *app_error.NotfoundError:
+				statusCode = fiber.StatusNotFound
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			case *app_error.ForbiddenError:
+				statusCode = fiber.StatusForbidden
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			default:
+				statusCode = fiber.StatusBadRequest
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			}
+			return ctx.Status(statusCode).JSON(resBody)
+		}
+
+		resBody := response.NewSuccessResponse(nil)
+		return ctx.JSON(resBody)
+	}
+}
+
+// NewSignFileUrlHandler issues a temporary url serving the file even when it is private
+func NewSignFileUrlHandler(rService retrieving.FileSigner) Handler {
+	return func(ctx *Context) error {
+		body := struct {
+			ExpiresIn int    `json:"expires_in"`
+			Download  bool   `json:"download"`
+			Version   int64  `json:"version"`
+			Variant   string `json:"variant"`
+			Width     int    `json:"width"`
+			Height    int    `json:"height"`
+			Fit       string `json:"fit"`
+			Crop      string `json:"crop"`
+			Quality   int    `json:"quality"`
+			Format    string `json:"format"`
+		}{}
+		if len(ctx.Body()) > 0 {
+			err := json.Unmarshal(ctx.Body(), &body)
+			if err != nil {
+				return newInvalidJsonResponse(ctx, "body")
+			}
+		}
+
+		signedUrl, err := rService.SignFileUrl(retrieving.SignFileUrlParam{
+			Identifier: ctx.Params("identifier"),
+			Claims:     GetClaims(ctx),
+			Download:   body.Download,
+			ExpiresIn:  body.ExpiresIn,
+			Version:    body.Version,
+			Variant:    body.Variant,
+			Transform: &imaging.TransformOption{
+				Width:   body.Width,
+				Height:  body.Height,
+				Fit:     body.Fit,
+				Crop:    body.Crop,
+				Quality: body.Quality,
+				Format:  body.Format,
+			},
+		})
+		if err != nil {
+			var statusCode int
+			var resBody *response.ResponseEntity
+
+			switch err.(type) {
+			case *app_error.ValidationError:
+				validationError := err.(*app_error.ValidationError)
+				statusCode = fiber.StatusUnprocessableEntity
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: validationError.Error(),
+					Error:   validationError.Items,
+				})
+			case *app_error.NotfoundError:
+				statusCode = fiber.StatusNotFound
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			case *app_error.ForbiddenError:
+				statusCode = fiber.StatusForbidden
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			case *app_error.UnsupportedError:
+				statusCode = fiber.StatusNotImplemented
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			default:
+				statusCode = fiber.StatusBadRequest
+				resBody = response.NewErrorResponse(&response.ResponseParam{
+					Message: err.Error(),
+				})
+			}
+			return ctx.Status(statusCode).JSON(resBody)
+		}
+
+		resBody := response.NewSuccessResponse(&response.ResponseParam{
+			Data: &SignedUrlEntity{
+				Url:       signedUrl.Url,
+				ExpiresAt: signedUrl.ExpiresAt,
+			},
+		})
+		return ctx.JSON(resBody)
+	}
+}
+
+// queryInt returns 0 for missing query and -1 for non numeric one,
+// so the latter is rejected by the transformation rules
+func queryInt(ctx *Context, key string) int {
+	value := ctx.Query(key)
+	if value == "" {
+		return 0
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return -1
+	}
+	return i
+}
+
+func queryBool(ctx *Context, key string) bool {
+	value, err := strconv.ParseBool(ctx.Query(key))
+	return err == nil && value
+}
+
+// downloadName is the original file name carrying the extension of the served content,
+// which differs from the uploaded one for transformed image or previous version
+func downloadName(fileDetail *retrieving.FileEntity) string {
+	name := strings.TrimSuffix(fileDetail.OriginalName, filepath.Ext(fileDetail.OriginalName))
+	if name == "" {
+		name = fileDetail.Slug
+	}
+	if name == "" || fileDetail.Extension == "" {
+		return name
+	}
+	return fmt.Sprintf("%s.%s", name, fileDetail.Extension)
+}
+
+// contentDisposition formats the header as described by RFC 6266, the quoted filename is an ascii fallback
+// while non ascii name is also sent as RFC 5987 `filename*` which takes precedence in user agents
+func contentDisposition(dispositionType, filename string) string {
+	if filename == "" {
+		return dispositionType
+	}
+
+	fallback := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, filename)
+	res := fmt.Sprintf(`%s; filename="%s"`, dispositionType, fallback)
+	if fallback == filename {
+		return res
+	}
+
+	encoded := strings.Builder{}
+	for _, b := range []byte(filename) {
+		if isAttrChar(b) {
+			encoded.WriteByte(b)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return fmt.Sprintf("%s; filename*=UTF-8''%s", res, encoded.String())
+}
+
+// isAttrChar reports the characters allowed unencoded in RFC 5987 ext-value
+func isAttrChar(b byte) bool {
+	isAlphaNumeric := (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+	return isAlphaNumeric || strings.IndexByte("!#$&+-.^_`|~", b) >= 0
+}
+
+func newFileDetailEntity(fileDetail *retrieving.FileEntity) *FileDetailEntity {
+	fileEntity := &FileDetailEntity{
+		UniqueId:          fileDetail.UniqueId,
+		Slug:              fileDetail.Slug,
+		Name:              fileDetail.Name,
+		Extension:         fileDetail.Extension,
+		Size:              fileDetail.Size,
+		Mimetype:          fileDetail.Mimetype,
+		ScanStatus:        fileDetail.ScanStatus,
+		ExtractedMetadata: fileDetail.ExtractedMetadata,
+		Checksum:          fileDetail.Checksum,
+		MetadataStripped:  fileDetail.MetadataStripped,
+		Metadata:          fileDetail.Metadata,
+		Tags:              fileDetail.Tags,
+		Visibility:        fileDetail.Visibility,
+		Provider:          fileDetail.Provider,
+		Revision:          fileDetail.Revision,
+		Version:           fileDetail.Version,
+		Url:               fileDetail.Url,
+		CreatedAt:         fileDetail.CreatedAt,
+		UpdatedAt:         fileDetail.UpdatedAt,
+		ExpiresAt:         fileDetail.ExpiresAt,
+		Variants:          []VariantDetailEntity{},
+	}
+	if fileEntity.Metadata == nil {
+		fileEntity.Metadata = map[string]string{}
+	}
+	if fileEntity.Tags == nil {
+		fileEntity.Tags = []string{}
+	}
+	for _, v := range fileDetail.Variants {
+		fileEntity.Variants = append(fileEntity.Variants, VariantDetailEntity{
+			Name:      v.Name,
+			Extension: v.Extension,
+			Size:      v.Size,
+			Mimetype:  v.Mimetype,
+			Width:     v.Width,
+			Height:    v.Height,
+			Url:       v.Url,
+		})
+	}
+	return fileEntity
+}
+
+func revisionETag(revision int64) string {
+	return fmt.Sprintf(`"%d"`, revision)
+}
+
+// parseIfMatch returns the revision expected by `If-Match` header,
+// zero means no precondition while -1 never matches since weak or
+// unknown entity tags can not be compared against the revision
+func parseIfMatch(value string) int64 {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "*" {
+		return 0
+	}
+	if len(value) < 3 || !strings.HasPrefix(value, `"`) || !strings.HasSuffix(value, `"`) {
+		return -1
+	}
+	revision, err := strconv.ParseInt(value[1:len(value)-1], 10, 64)
+	if err != nil || revision <= 0 {
+		return -1
+	}
+	return revision
+}
+
+// normalizeTags splits comma separated values into lowercase unique tags,
+// nil is kept so services can tell missing tags apart from empty ones
+func normalizeTags(values []string) []string {
+	if values == nil {
+		return nil
+	}
+
+	tags := []string{}
+	found := map[string]bool{}
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if tag == "" || found[tag] {
+				continue
+			}
+			found[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// newUploadErrorResponse answers a failed upload, replacement or archive upload
+// with the status of the error, every upload endpoint reports errors alike
+func newUploadErrorResponse(ctx *Context, err error) error {
+	var responseEntity *response.ResponseEntity
+	var status int
+
+	switch err.(type) {
+	case *app_error.ValidationError:
+		status = fiber.StatusUnprocessableEntity
+		validationError := err.(*app_error.ValidationError)
+		responseEntity = response.NewErrorResponse(&response.ResponseParam{
+			Message: validationError.Error(),
+			Error:   validationError.Items,
+		})
+	case *app_error.QuotaExceededError:
+		quotaError := err.(*app_error.QuotaExceededError)
+		status = fiber.StatusInsufficientStorage
+		if quotaError.Context == quota.LIMIT_FILE_SIZE {
+			status = fiber.StatusRequestEntityTooLarge
+		}
+		responseEntity = response.NewErrorResponse(&response.ResponseParam{
+			Message: quotaError.Error(),
+		})
+	case *app_error.InfectedError:
+		status = fiber.StatusUnprocessableEntity
+		responseEntity = response.NewErrorResponse(&response.ResponseParam{
+			Message: err.Error(),
+		})
+	case *app_error.AlreadyExistsError:
+		status = fiber.StatusConflict
+		responseEntity = response.NewErrorResponse(&response.ResponseParam{
+			Message: err.Error(),
+		})
+	case *app_error.NotfoundError:
+		status = fiber.StatusNotFound
+		responseEntity = response.NewErrorResponse(&response.ResponseParam{
+			Message: err.Error(),
+		})
+	case *app_error.ForbiddenError:
+		status = fiber.StatusForbidden
+		responseEntity = response.NewErrorResponse(&response.ResponseParam{
+			Message: err.Error(),
+		})
+	case *app_error.PreconditionFailedError:
+		status = fiber.StatusPreconditionFailed
+		responseEntity = response.NewErrorResponse(&response.ResponseParam{
+			Message: err.Error(),
+		})
+	case *app_error.UnavailableError:
+		status = fiber.StatusBadGateway
+		responseEntity = response.NewErrorResponse(&response.ResponseParam{
+			Message: err.Error(),
+		})
+	default:
+		status = fiber.StatusBadRequest
+		responseEntity = response.NewErrorResponse(&response.ResponseParam{
+			Message: err.Error(),
+		})
+	}
+	return ctx.Status(status).JSON(responseEntity)
+}
+
+// parseExpiry reads the requested expiry of a form upload,
+// `expires_at` is an RFC 3339 date time and `ttl` the `second` the file lives after upload
+func parseExpiry(rawExpiresAt, rawTtl string) (*time.Time, int64, error) {
+	expiresAt, err := parseExpiresAt(rawExpiresAt)
+	if err != nil {
+		return nil, 0, err
+	}
+	var ttl int64
+	if rawTtl != "" {
+		ttl, err = strconv.ParseInt(rawTtl, 10, 64)
+		if err != nil {
+			return nil, 0, newInvalidFieldError("ttl", "number")
+		}
+	}
+	return expiresAt, ttl, nil
+}
+
+func parseExpiresAt(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	expiresAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, newInvalidFieldError("expires_at", "datetime")
+	}
+	return &expiresAt, nil
+}
+
+// newInvalidJsonResponse reports unparsable JSON field the same way as failed validation
+func newInvalidJsonResponse(ctx *Context, field string) error {
+	return newInvalidFieldResponse(ctx, field, "json")
+}
+
+func newInvalidFieldResponse(ctx *Context, field, tag string) error {
+	validationError := newInvalidFieldError(field, tag)
+	responseEntity := response.NewErrorResponse(&response.ResponseParam{
+		Message: validationError.Error(),
+		Error:   validationError.Items,
+	})
+	return ctx.Status(fiber.StatusUnprocessableEntity).JSON(responseEntity)
+}
+
+func newInvalidFieldError(field, tag string) *app_error.ValidationError {
+	return app_error.NewValidationError([]app_error.ValidationItem{
+		{
+			Field:   field,
+			Message: fmt.Sprintf("Key: '%s' Error:Field validation for '%s' failed on the '%s' tag", field, field, tag),
+		},
+	})
+}
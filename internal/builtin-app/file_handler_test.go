@@ -1,15 +1,24 @@
 package builtin_app_test
 
 import (
+	"bytes"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	builtin_app "idaman.id/storage/internal/builtin-app"
+	"idaman.id/storage/internal/deleting"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/file"
+	"idaman.id/storage/internal/imaging"
 	response "idaman.id/storage/internal/response"
 	"idaman.id/storage/internal/retrieving"
+	"idaman.id/storage/internal/text"
 )
 
 var _ = Describe("File Handler", func() {
@@ -50,6 +59,23 @@ var _ = Describe("File Handler", func() {
 			})
 		})
 
+		When("file belongs to another tenant", func() {
+			It("should return forbidden response", func() {
+				identifier = "forbidden"
+				req := httptest.NewRequest(http.MethodGet, "/v1/file/"+identifier, nil)
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				expected := response.NewErrorResponse(&response.ResponseParam{
+					Message: app_error.STATUS_FORBIDDEN,
+				})
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusForbidden))
+				Expect(resEntity).To(Equal(expected))
+			})
+		})
+
 		When("unexpected error happened", func() {
 			It("should return error response", func() {
 				identifier = "error"
@@ -81,6 +107,7 @@ var _ = Describe("File Handler", func() {
 				})
 
 				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(res.Header.Get("ETag")).To(Equal(`"3"`))
 				Expect(resEntity.Message).To(Equal(expected.Message))
 				Expect(resEntity.Data).ToNot(BeNil())
 				Expect(resEntity.Error).To(BeNil())
@@ -117,6 +144,74 @@ var _ = Describe("File Handler", func() {
 			})
 		})
 
+		When("file is not scanned yet or infected", func() {
+			It("should return forbidden response", func() {
+				identifier = "pending"
+				req := httptest.NewRequest(http.MethodGet, "/file/"+identifier, nil)
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				expected := response.NewErrorResponse(&response.ResponseParam{
+					Message: app_error.STATUS_UNAVAILABLE,
+				})
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusForbidden))
+				Expect(resEntity).To(Equal(expected))
+			})
+		})
+
+		When("file is private", func() {
+			It("should return forbidden response", func() {
+				identifier = "private"
+				req := httptest.NewRequest(http.MethodGet, "/file/"+identifier, nil)
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				expected := response.NewErrorResponse(&response.ResponseParam{
+					Message: app_error.STATUS_FORBIDDEN,
+				})
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusForbidden))
+				Expect(resEntity).To(Equal(expected))
+			})
+		})
+
+		When("file is expired", func() {
+			It("should return gone response", func() {
+				identifier = "expired"
+				req := httptest.NewRequest(http.MethodGet, "/file/"+identifier, nil)
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				expected := response.NewErrorResponse(&response.ResponseParam{
+					Message: app_error.STATUS_EXPIRED,
+				})
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusGone))
+				Expect(resEntity).To(Equal(expected))
+			})
+		})
+
+		When("file can not be transformed", func() {
+			It("should return unsupported media type response", func() {
+				identifier = "unsupported"
+				req := httptest.NewRequest(http.MethodGet, "/file/"+identifier+"?width=100", nil)
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				expected := response.NewErrorResponse(&response.ResponseParam{
+					Message: app_error.STATUS_NOT_SUPPORTED,
+				})
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusUnsupportedMediaType))
+				Expect(resEntity).To(Equal(expected))
+			})
+		})
+
 		When("unexpected error happened", func() {
 			It("should return error response", func() {
 				identifier = "error"
@@ -140,10 +235,870 @@ var _ = Describe("File Handler", func() {
 				res, _ := fiberApp.Test(req)
 
 				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(res.Header.Get("Content-Disposition")).To(Equal(`inline; filename="annual report.pdf"`))
+				Expect(res.Header.Get("X-Content-Type-Options")).To(Equal("nosniff"))
 				Expect(res.Body.Close()).To(BeNil())
 			})
 		})
 
+		When("download is requested", func() {
+			It("should return file as attachment", func() {
+				req := httptest.NewRequest(http.MethodGet, "/file/"+identifier+"?download=1", nil)
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(res.Header.Get("Content-Disposition")).To(Equal(`attachment; filename="annual report.pdf"`))
+			})
+		})
+
+		When("original name is not ascii", func() {
+			It("should return ascii fallback and encoded filename", func() {
+				req := httptest.NewRequest(http.MethodGet, "/file/unicode?download=true", nil)
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(res.Header.Get("Content-Disposition")).To(Equal(`attachment; filename="laporan _.pdf"; filename*=UTF-8''laporan%20%C3%B1.pdf`))
+			})
+		})
+
+		When("transformation is requested", func() {
+			It("should return transformed file", func() {
+				req := httptest.NewRequest(http.MethodGet, "/file/"+identifier+"?width=100&format=png", nil)
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(res.Header.Get("Content-Type")).To(Equal("image/png"))
+				Expect(res.Header.Get("Content-Disposition")).To(Equal(`inline; filename="annual report.png"`))
+			})
+		})
+
+		When("version does not exist", func() {
+			It("should return not found response", func() {
+				req := httptest.NewRequest(http.MethodGet, "/file/"+identifier+"?version=3", nil)
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				expected := response.NewErrorResponse(&response.ResponseParam{
+					Message: "Version is not found",
+				})
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusNotFound))
+				Expect(resEntity).To(Equal(expected))
+			})
+		})
+
+		When("file is requested by unique id while it has a slug", func() {
+			It("should redirect to the slug url keeping the query", func() {
+				req := httptest.NewRequest(http.MethodGet, "/file/moved?version=1", nil)
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusMovedPermanently))
+				Expect(res.Header.Get("Location")).To(Equal("http://localhost/file/annual-report.pdf?version=1"))
+			})
+		})
+
+		When("transformation parameter is invalid", func() {
+			It("should return invalid data response", func() {
+				req := httptest.NewRequest(http.MethodGet, "/file/"+identifier+"?width=abc", nil)
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusUnprocessableEntity))
+			})
+		})
+
+	})
+
+	Context("SignFileUrl Handler", func() {
+		var (
+			signerService *FakeFileSignerService
+		)
+
+		BeforeEach(func() {
+			signerService = &FakeFileSignerService{}
+			fiberApp.Post("/v1/file/:identifier/signed-url", builtin_app.NewSignFileUrlHandler(signerService))
+		})
+
+		When("file not found", func() {
+			It("should return not found response", func() {
+				req := httptest.NewRequest(http.MethodPost, "/v1/file/not-found/signed-url", nil)
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusNotFound))
+			})
+		})
+
+		When("signing is not configured", func() {
+			It("should return not implemented response", func() {
+				req := httptest.NewRequest(http.MethodPost, "/v1/file/unsupported/signed-url", nil)
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusNotImplemented))
+			})
+		})
+
+		When("expiration is invalid", func() {
+			It("should return invalid data response", func() {
+				req := httptest.NewRequest(http.MethodPost, "/v1/file/fake-identifier/signed-url", strings.NewReader(`{"expires_in": -1}`))
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusUnprocessableEntity))
+				Expect(resEntity.Error).To(HaveLen(1))
+			})
+		})
+
+		When("url is signed", func() {
+			It("should return the signed url", func() {
+				req := httptest.NewRequest(http.MethodPost, "/v1/file/fake-identifier/signed-url", strings.NewReader(`{"expires_in": 600, "download": true}`))
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+				data := resEntity.Data.(map[string]interface{})
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(signerService.param.ExpiresIn).To(Equal(600))
+				Expect(signerService.param.Download).To(BeTrue())
+				Expect(data["url"]).To(Equal("http://localhost/file/annual-report.pdf?expires=1640858210&signature=abc"))
+			})
+		})
+
+		When("rendition is requested", func() {
+			It("should sign the rendition", func() {
+				req := httptest.NewRequest(http.MethodPost, "/v1/file/fake-identifier/signed-url", strings.NewReader(`{"version": 2, "width": 150, "format": "png"}`))
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(signerService.param.Version).To(Equal(int64(2)))
+				Expect(signerService.param.Transform).To(Equal(&imaging.TransformOption{Width: 150, Format: "png"}))
+			})
+		})
+	})
+
+	Context("FileList Handler", func() {
+		var (
+			listerService *FakeFileListerService
+		)
+
+		BeforeEach(func() {
+			listerService = &FakeFileListerService{}
+			fiberApp.Get("/v1/file", builtin_app.NewFileListHandler(listerService))
+		})
+
+		When("filter is given", func() {
+			It("should pass normalized tags and metadata", func() {
+				req := httptest.NewRequest(http.MethodGet, "/v1/file?tag=Invoice,paid&tag=invoice&metadata.category=billing&limit=5", nil)
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(listerService.param.Tags).To(Equal([]string{"invoice", "paid"}))
+				Expect(listerService.param.Metadata).To(Equal(map[string]string{"category": "billing"}))
+				Expect(listerService.param.Limit).To(Equal(5))
+				data := resEntity.Data.(map[string]interface{})
+				Expect(data["total"]).To(Equal(float64(1)))
+				Expect(data["files"]).To(HaveLen(1))
+			})
+		})
+
+		When("metadata key is not valid", func() {
+			It("should return invalid data response", func() {
+				req := httptest.NewRequest(http.MethodGet, "/v1/file?metadata.1category=billing", nil)
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusUnprocessableEntity))
+				Expect(resEntity.Message).To(Equal(app_error.STATUS_INVALID_DATA))
+			})
+		})
+
+		When("unexpected error happened", func() {
+			It("should return error response", func() {
+				req := httptest.NewRequest(http.MethodGet, "/v1/file?metadata.category=error", nil)
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusBadRequest))
+				Expect(resEntity).To(Equal(response.NewErrorResponse(&response.ResponseParam{
+					Message: response.STATUS_ERROR,
+				})))
+			})
+		})
+	})
+
+	Context("UpdateFile Handler", func() {
+		var (
+			updateService *FakeUpdateService
+		)
+
+		BeforeEach(func() {
+			updateService = &FakeUpdateService{}
+			fiberApp.Patch("/v1/file/:identifier", builtin_app.NewUpdateFileHandler(updateService, &FakeFileGetterService{}))
+		})
+
+		When("body is not a valid json", func() {
+			It("should return invalid data response", func() {
+				req := httptest.NewRequest(http.MethodPatch, "/v1/file/fake-identifier", strings.NewReader("{"))
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusUnprocessableEntity))
+				Expect(resEntity.Error).To(HaveLen(1))
+			})
+		})
+
+		When("file not found", func() {
+			It("should return not found response", func() {
+				req := httptest.NewRequest(http.MethodPatch, "/v1/file/not-found", strings.NewReader(`{"tags": []}`))
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusNotFound))
+			})
+		})
+
+		When("file belongs to another tenant", func() {
+			It("should return forbidden response", func() {
+				req := httptest.NewRequest(http.MethodPatch, "/v1/file/forbidden", strings.NewReader(`{"tags": []}`))
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusForbidden))
+			})
+		})
+
+		When("metadata or tags are invalid", func() {
+			It("should return invalid data response", func() {
+				req := httptest.NewRequest(http.MethodPatch, "/v1/file/invalid", strings.NewReader(`{"tags": ["-"]}`))
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusUnprocessableEntity))
+				Expect(resEntity.Error).To(HaveLen(1))
+			})
+		})
+
+		When("only metadata is given", func() {
+			It("should keep the tags untouched", func() {
+				req := httptest.NewRequest(http.MethodPatch, "/v1/file/fake-identifier", strings.NewReader(`{"metadata": {"order_id": "1234"}}`))
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(updateService.param.Metadata).To(Equal(map[string]string{"order_id": "1234"}))
+				Expect(updateService.param.Tags).To(BeNil())
+				Expect(updateService.param.Revision).To(Equal(int64(0)))
+				Expect(resEntity.Data).ToNot(BeNil())
+			})
+		})
+
+		When("name and visibility are given", func() {
+			It("should pass them to the service", func() {
+				req := httptest.NewRequest(http.MethodPatch, "/v1/file/fake-identifier", strings.NewReader(`{"name": "Annual Report", "visibility": "private"}`))
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(updateService.param.Name).To(Equal("Annual Report"))
+				Expect(updateService.param.Visibility).To(Equal("private"))
+				Expect(res.Header.Get("ETag")).To(Equal(`"3"`))
+			})
+		})
+
+		When("slug is given", func() {
+			It("should return the file found by the new slug", func() {
+				req := httptest.NewRequest(http.MethodPatch, "/v1/file/fake-identifier", strings.NewReader(`{"slug": "annual-report"}`))
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(updateService.param.Slug).To(Equal("annual-report"))
+				Expect(resEntity.Data.(map[string]interface{})["slug"]).To(Equal("annual-report"))
+			})
+		})
+
+		When("slug is taken by another file", func() {
+			It("should return conflict response", func() {
+				req := httptest.NewRequest(http.MethodPatch, "/v1/file/fake-identifier", strings.NewReader(`{"slug": "taken"}`))
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				expected := response.NewErrorResponse(&response.ResponseParam{
+					Message: app_error.STATUS_ALREADY_EXISTS,
+				})
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusConflict))
+				Expect(resEntity).To(Equal(expected))
+			})
+		})
+
+		When("If-Match header is given", func() {
+			It("should pass the expected revision", func() {
+				req := httptest.NewRequest(http.MethodPatch, "/v1/file/fake-identifier", strings.NewReader(`{"tags": []}`))
+				req.Header.Set("If-Match", `"2"`)
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(updateService.param.Revision).To(Equal(int64(2)))
+			})
+		})
+
+		When("If-Match header is a weak entity tag", func() {
+			It("should pass a revision which never matches", func() {
+				req := httptest.NewRequest(http.MethodPatch, "/v1/file/fake-identifier", strings.NewReader(`{"tags": []}`))
+				req.Header.Set("If-Match", `W/"2"`)
+				fiberApp.Test(req)
+
+				Expect(updateService.param.Revision).To(Equal(int64(-1)))
+			})
+		})
+
+		When("file has been modified since the given revision", func() {
+			It("should return precondition failed response", func() {
+				req := httptest.NewRequest(http.MethodPatch, "/v1/file/stale", strings.NewReader(`{"tags": []}`))
+				req.Header.Set("If-Match", `"1"`)
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				expected := response.NewErrorResponse(&response.ResponseParam{
+					Message: app_error.STATUS_PRECONDITION_FAILED,
+				})
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusPreconditionFailed))
+				Expect(resEntity).To(Equal(expected))
+			})
+		})
+	})
+
+	Context("UploadFile Handler", func() {
+		var (
+			uploadService *FakeUploadService
+		)
+
+		BeforeEach(func() {
+			uploadService = &FakeUploadService{}
+			fileService := file.NewFileService(text.NewTextService())
+			fiberApp.Post("/v1/file", builtin_app.NewUploadFileHandler(uploadService, fileService))
+		})
+
+		newArchiveRequest := func(fileName string) *http.Request {
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			part, _ := writer.CreateFormFile("file", fileName)
+			part.Write([]byte("PK\x03\x04"))
+			writer.WriteField("extract", "true")
+			writer.WriteField("tags", "import")
+			writer.Close()
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/file", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			return req
+		}
+
+		newJsonRequest := func(body string) *http.Request {
+			req := httptest.NewRequest(http.MethodPost, "/v1/file", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			return req
+		}
+
+		When("json content is not base64", func() {
+			It("should return invalid data response", func() {
+				res, _ := fiberApp.Test(newJsonRequest(`{"filename":"report.pdf","content":"not base64!"}`))
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusUnprocessableEntity))
+				Expect(resEntity.Error).To(HaveLen(1))
+				Expect(resEntity.Error.([]interface{})[0].(map[string]interface{})["field"]).To(Equal("content"))
+			})
+		})
+
+		When("json content is missing", func() {
+			It("should return bad request response", func() {
+				res, _ := fiberApp.Test(newJsonRequest(`{"filename":"report.pdf"}`))
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusBadRequest))
+			})
+		})
+
+		When("json content is a data uri", func() {
+			It("should upload the decoded file", func() {
+				res, _ := fiberApp.Test(newJsonRequest(`{"filename":"Report.pdf","content":"data:application/pdf;base64,JVBERi0xLjQ=","metadata":{"order_id":"1234"},"tags":["Invoice"],"slug":"report"}`))
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(uploadService.uploadParam.File.Data).To(Equal([]byte("%PDF-1.4")))
+				Expect(uploadService.uploadParam.File.Name).To(Equal("report"))
+				Expect(uploadService.uploadParam.File.Extension).To(Equal("pdf"))
+				Expect(uploadService.uploadParam.File.Mimetype).To(Equal("application/pdf"))
+				Expect(uploadService.uploadParam.Metadata).To(Equal(map[string]string{"order_id": "1234"}))
+				Expect(uploadService.uploadParam.Tags).To(Equal([]string{"invoice"}))
+				Expect(uploadService.uploadParam.Slug).To(Equal("report"))
+			})
+		})
+
+		When("expiry date is not a valid date time", func() {
+			It("should return invalid data response", func() {
+				res, _ := fiberApp.Test(newJsonRequest(`{"filename":"report.pdf","content":"JVBERi0xLjQ=","expires_at":"tomorrow"}`))
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusUnprocessableEntity))
+				Expect(resEntity.Error).To(HaveLen(1))
+				Expect(resEntity.Error.([]interface{})[0].(map[string]interface{})["field"]).To(Equal("expires_at"))
+			})
+		})
+
+		When("expiry is given", func() {
+			It("should pass it to the service", func() {
+				res, _ := fiberApp.Test(newJsonRequest(`{"filename":"report.pdf","content":"JVBERi0xLjQ=","expires_at":"2030-01-02T03:04:05Z","ttl":3600}`))
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(uploadService.uploadParam.ExpiresAt.Unix()).To(Equal(int64(1893553445)))
+				Expect(uploadService.uploadParam.Ttl).To(Equal(int64(3600)))
+			})
+		})
+
+		When("archive can not be unpacked", func() {
+			It("should return invalid data response", func() {
+				res, _ := fiberApp.Test(newArchiveRequest("bomb.zip"))
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusUnprocessableEntity))
+				Expect(resEntity.Error).To(HaveLen(1))
+			})
+		})
+
+		When("archive is extracted", func() {
+			It("should return the result of every entry", func() {
+				res, _ := fiberApp.Test(newArchiveRequest("bundle.zip"))
+
+				resEntity := UnmarshallResponseBody(res.Body)
+				entries := resEntity.Data.(map[string]interface{})["entries"].([]interface{})
+				uploaded := entries[0].(map[string]interface{})
+				failed := entries[1].(map[string]interface{})
+				skipped := entries[2].(map[string]interface{})
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(uploadService.archiveParam.Tags).To(Equal([]string{"import"}))
+				Expect(uploaded["status"]).To(Equal("uploaded"))
+				Expect(uploaded["file"].(map[string]interface{})["unique_id"]).To(Equal("file-1"))
+				Expect(failed["status"]).To(Equal("failed"))
+				Expect(failed["error"]).To(HaveLen(1))
+				Expect(skipped["status"]).To(Equal("skipped"))
+				Expect(skipped["message"]).To(Equal("unsafe_path"))
+				Expect(skipped).NotTo(HaveKey("file"))
+			})
+		})
+	})
+
+	Context("UploadRemoteFile Handler", func() {
+		var (
+			uploadService *FakeUploadService
+		)
+
+		BeforeEach(func() {
+			uploadService = &FakeUploadService{}
+			fiberApp.Post("/v1/file/remote", builtin_app.NewUploadRemoteFileHandler(uploadService))
+		})
+
+		When("body is not valid json", func() {
+			It("should return invalid data response", func() {
+				req := httptest.NewRequest(http.MethodPost, "/v1/file/remote", strings.NewReader(`{"url":`))
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusUnprocessableEntity))
+			})
+		})
+
+		When("url points to a private address", func() {
+			It("should return invalid data response", func() {
+				req := httptest.NewRequest(http.MethodPost, "/v1/file/remote", strings.NewReader(`{"url":"http://127.0.0.1/report.pdf"}`))
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusUnprocessableEntity))
+				Expect(resEntity.Error).To(HaveLen(1))
+			})
+		})
+
+		When("remote file can not be downloaded", func() {
+			It("should return bad gateway response", func() {
+				req := httptest.NewRequest(http.MethodPost, "/v1/file/remote", strings.NewReader(`{"url":"https://example.com/missing"}`))
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusBadGateway))
+			})
+		})
+
+		When("remote file is uploaded", func() {
+			It("should return the uploaded file", func() {
+				req := httptest.NewRequest(http.MethodPost, "/v1/file/remote", strings.NewReader(`{"url":"https://example.com/report.pdf","tags":["Invoice"],"slug":"report"}`))
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(resEntity.Data.(map[string]interface{})["unique_id"]).To(Equal("file-1"))
+				Expect(uploadService.remoteParam.Tags).To(Equal([]string{"invoice"}))
+				Expect(uploadService.remoteParam.Slug).To(Equal("report"))
+			})
+		})
+	})
+
+	Context("UploadRawFile Handler", func() {
+		var (
+			uploadService *FakeUploadService
+		)
+
+		BeforeEach(func() {
+			uploadService = &FakeUploadService{}
+			fileService := file.NewFileService(text.NewTextService())
+			fiberApp.Put("/v1/file/:name", builtin_app.NewUploadRawFileHandler(uploadService, fileService))
+		})
+
+		When("body is empty", func() {
+			It("should return bad request response", func() {
+				req := httptest.NewRequest(http.MethodPut, "/v1/file/report.pdf", nil)
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusBadRequest))
+			})
+		})
+
+		When("content digest does not match the body", func() {
+			It("should return invalid data response", func() {
+				req := httptest.NewRequest(http.MethodPut, "/v1/file/report.pdf", strings.NewReader("%PDF-1.4"))
+				req.Header.Set("Content-Digest", "sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:")
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusUnprocessableEntity))
+				Expect(resEntity.Error).To(HaveLen(1))
+			})
+		})
+
+		When("ttl is not a number", func() {
+			It("should return invalid data response", func() {
+				req := httptest.NewRequest(http.MethodPut, "/v1/file/report.pdf?ttl=day", strings.NewReader("%PDF-1.4"))
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusUnprocessableEntity))
+				Expect(resEntity.Error.([]interface{})[0].(map[string]interface{})["field"]).To(Equal("ttl"))
+			})
+		})
+
+		When("body is uploaded", func() {
+			It("should return the uploaded file", func() {
+				req := httptest.NewRequest(http.MethodPut, "/v1/file/annual%20report.pdf?tags=Invoice,2021&slug=report", strings.NewReader("%PDF-1.4"))
+				req.Header.Set("Content-Type", "application/pdf")
+				req.Header.Set("Content-Digest", "sha-256=:4W+l2bUZKHVduFuRfwKXurryLHpH6X2SEq2rVuYboE4=:")
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(resEntity.Data.(map[string]interface{})["version"]).To(Equal(float64(1)))
+				Expect(uploadService.uploadParam.File.OriginalName).To(Equal("annual report.pdf"))
+				Expect(uploadService.uploadParam.File.Mimetype).To(Equal("application/pdf"))
+				Expect(uploadService.uploadParam.File.Size).To(Equal(int64(8)))
+				Expect(uploadService.uploadParam.Tags).To(Equal([]string{"invoice", "2021"}))
+				Expect(uploadService.uploadParam.Slug).To(Equal("report"))
+			})
+		})
+
+		When("body is streamed", func() {
+			BeforeEach(func() {
+				fiberApp = fiber.New(fiber.Config{
+					StreamRequestBody: true,
+					BodyLimit:         4,
+				})
+				fileService := file.NewFileService(text.NewTextService())
+				fiberApp.Use(builtin_app.NewStreamedBodyHandler(4))
+				fiberApp.Put("/v1/file/:name", builtin_app.NewUploadRawFileHandler(uploadService, fileService))
+			})
+
+			It("should upload the body as a stream", func() {
+				req := httptest.NewRequest(http.MethodPut, "/v1/file/report.pdf", strings.NewReader("%PDF-1.4"))
+				req.Header.Set("Content-Digest", "sha-256=:4W+l2bUZKHVduFuRfwKXurryLHpH6X2SEq2rVuYboE4=:")
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(uploadService.uploadParam.File.Data).To(BeNil())
+				Expect(uploadService.uploadParam.File.Size).To(Equal(int64(8)))
+				Expect(uploadService.uploadParam.File.Mimetype).To(Equal("application/pdf"))
+				Expect(string(uploadService.streamed)).To(Equal("%PDF-1.4"))
+			})
+
+			It("should return invalid data response when the content digest does not match", func() {
+				req := httptest.NewRequest(http.MethodPut, "/v1/file/report.pdf", strings.NewReader("%PDF-1.4"))
+				req.Header.Set("Content-Digest", "sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:")
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusUnprocessableEntity))
+				Expect(resEntity.Error.([]interface{})[0].(map[string]interface{})["field"]).To(Equal("content-digest"))
+			})
+
+			It("should return length required response when the body is chunked", func() {
+				req := httptest.NewRequest(http.MethodPut, "/v1/file/report.pdf", io.MultiReader(strings.NewReader("%PDF"), strings.NewReader("-1.4")))
+				req.ContentLength = -1
+				req.TransferEncoding = []string{"chunked"}
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusLengthRequired))
+			})
+		})
+	})
+
+	Context("ReplaceFileContent Handler", func() {
+		var (
+			uploadService *FakeUploadService
+		)
+
+		BeforeEach(func() {
+			uploadService = &FakeUploadService{}
+			fileService := file.NewFileService(text.NewTextService())
+			fiberApp.Put("/v1/file/:identifier/content", builtin_app.NewReplaceFileContentHandler(uploadService, fileService))
+		})
+
+		newContentRequest := func(identifier string) *http.Request {
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			part, _ := writer.CreateFormFile("file", "report.pdf")
+			part.Write([]byte("%PDF-1.4"))
+			writer.Close()
+
+			req := httptest.NewRequest(http.MethodPut, "/v1/file/"+identifier+"/content", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			return req
+		}
+
+		When("file is not uploaded", func() {
+			It("should return bad request response", func() {
+				req := httptest.NewRequest(http.MethodPut, "/v1/file/fake-identifier/content", nil)
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusBadRequest))
+			})
+		})
+
+		When("file not found", func() {
+			It("should return not found response", func() {
+				res, _ := fiberApp.Test(newContentRequest("not-found"))
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusNotFound))
+			})
+		})
+
+		When("file has been modified since the given revision", func() {
+			It("should return precondition failed response", func() {
+				req := newContentRequest("stale")
+				req.Header.Set("If-Match", `"1"`)
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusPreconditionFailed))
+				Expect(uploadService.replaceParam.Revision).To(Equal(int64(1)))
+			})
+		})
+
+		When("content is replaced", func() {
+			It("should return the new version", func() {
+				res, _ := fiberApp.Test(newContentRequest("fake-identifier"))
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(res.Header.Get("ETag")).To(Equal(`"4"`))
+				Expect(resEntity.Data.(map[string]interface{})["version"]).To(Equal(float64(2)))
+				Expect(uploadService.replaceParam.File.Extension).To(Equal("pdf"))
+			})
+		})
+	})
+
+	Context("FileVersionList Handler", func() {
+		BeforeEach(func() {
+			fiberApp.Get("/v1/file/:identifier/versions", builtin_app.NewFileVersionListHandler(&FakeVersionService{}))
+		})
+
+		When("file not found", func() {
+			It("should return not found response", func() {
+				req := httptest.NewRequest(http.MethodGet, "/v1/file/not-found/versions", nil)
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusNotFound))
+			})
+		})
+
+		When("file belongs to another tenant", func() {
+			It("should return forbidden response", func() {
+				req := httptest.NewRequest(http.MethodGet, "/v1/file/forbidden/versions", nil)
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusForbidden))
+			})
+		})
+
+		When("file has versions", func() {
+			It("should return every version", func() {
+				req := httptest.NewRequest(http.MethodGet, "/v1/file/fake-identifier/versions", nil)
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+				versions := resEntity.Data.([]interface{})
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(versions).To(HaveLen(2))
+				Expect(versions[1].(map[string]interface{})["is_current"]).To(BeTrue())
+			})
+		})
+	})
+
+	Context("RestoreVersion Handler", func() {
+		var (
+			versionService *FakeVersionService
+		)
+
+		BeforeEach(func() {
+			versionService = &FakeVersionService{}
+			fiberApp.Post("/v1/file/:identifier/versions/:version/restore", builtin_app.NewRestoreVersionHandler(versionService, &FakeFileGetterService{}))
+		})
+
+		When("version is not a number", func() {
+			It("should return not found response", func() {
+				req := httptest.NewRequest(http.MethodPost, "/v1/file/fake-identifier/versions/abc/restore", nil)
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusNotFound))
+			})
+		})
+
+		When("version not found", func() {
+			It("should return not found response", func() {
+				req := httptest.NewRequest(http.MethodPost, "/v1/file/not-found/versions/9/restore", nil)
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusNotFound))
+			})
+		})
+
+		When("version is not scanned yet or infected", func() {
+			It("should return forbidden response", func() {
+				req := httptest.NewRequest(http.MethodPost, "/v1/file/pending/versions/1/restore", nil)
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusForbidden))
+			})
+		})
+
+		When("file has been modified since the given revision", func() {
+			It("should return precondition failed response", func() {
+				req := httptest.NewRequest(http.MethodPost, "/v1/file/stale/versions/1/restore", nil)
+				req.Header.Set("If-Match", `"2"`)
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusPreconditionFailed))
+			})
+		})
+
+		When("version is restored", func() {
+			It("should return the file detail", func() {
+				req := httptest.NewRequest(http.MethodPost, "/v1/file/fake-identifier/versions/1/restore", nil)
+				res, _ := fiberApp.Test(req)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(res.Header.Get("ETag")).To(Equal(`"3"`))
+				Expect(versionService.restoreParam.Version).To(Equal(int64(1)))
+			})
+		})
+	})
+
+	Context("DeleteFile Handler", func() {
+		var (
+			identifier    string
+			deleteService deleting.DeleteService
+		)
+
+		BeforeEach(func() {
+			identifier = "fake-identifier"
+			deleteService = &FakeDeleteService{}
+			fiberApp.Delete("/v1/file/:identifier", builtin_app.NewDeleteFileHandler(deleteService))
+		})
+
+		When("file not found", func() {
+			It("should return not found response", func() {
+				identifier = "not-found"
+				req := httptest.NewRequest(http.MethodDelete, "/v1/file/"+identifier, nil)
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				expected := response.NewErrorResponse(&response.ResponseParam{
+					Message: "File is not found",
+				})
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusNotFound))
+				Expect(resEntity).To(Equal(expected))
+			})
+		})
+
+		When("file belongs to another tenant", func() {
+			It("should return forbidden response", func() {
+				identifier = "forbidden"
+				req := httptest.NewRequest(http.MethodDelete, "/v1/file/"+identifier, nil)
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				expected := response.NewErrorResponse(&response.ResponseParam{
+					Message: app_error.STATUS_FORBIDDEN,
+				})
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusForbidden))
+				Expect(resEntity).To(Equal(expected))
+			})
+		})
+
+		When("unexpected error happened", func() {
+			It("should return error response", func() {
+				identifier = "error"
+				req := httptest.NewRequest(http.MethodDelete, "/v1/file/"+identifier, nil)
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				expected := response.NewErrorResponse(&response.ResponseParam{
+					Message: response.STATUS_ERROR,
+				})
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusBadRequest))
+				Expect(resEntity).To(Equal(expected))
+			})
+		})
+
+		When("file is deleted", func() {
+			It("should return success response", func() {
+				req := httptest.NewRequest(http.MethodDelete, "/v1/file/"+identifier, nil)
+				res, _ := fiberApp.Test(req)
+
+				resEntity := UnmarshallResponseBody(res.Body)
+
+				Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+				Expect(resEntity).To(Equal(response.NewSuccessResponse(nil)))
+			})
+		})
 	})
 
 })
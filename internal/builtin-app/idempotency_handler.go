@@ -0,0 +1,171 @@
+package builtin_app
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/idempotency"
+	response "idaman.id/storage/internal/response"
+)
+
+const (
+	HEADER_IDEMPOTENCY_KEY     = "Idempotency-Key"
+	HEADER_IDEMPOTENT_REPLAYED = "Idempotent-Replayed"
+)
+
+// replayedHeaders are the response headers stored along the body
+var replayedHeaders = []string{fiber.HeaderContentType, fiber.HeaderETag, fiber.HeaderLocation}
+
+// NewIdempotencyHandler replays the stored response when a request is retried with the same `Idempotency-Key` header,
+// server failures are not stored so they can be retried,
+// it passes through when idempotency is disabled, the header is not sent or the store is unreachable
+func NewIdempotencyHandler(keeper idempotency.Keeper) Handler {
+	return func(ctx *Context) error {
+		key := ctx.Get(HEADER_IDEMPOTENCY_KEY)
+		if keeper == nil || key == "" {
+			return ctx.Next()
+		}
+
+		client := idempotencyClient(ctx)
+		fingerprint := requestFingerprint(ctx)
+		result, err := keeper.Begin(idempotency.BeginParam{
+			Client:      client,
+			Key:         key,
+			Fingerprint: fingerprint,
+		})
+		switch err.(type) {
+		case nil:
+		case *app_error.ValidationError:
+			validationError := err.(*app_error.ValidationError)
+			responseEntity := response.NewErrorResponse(&response.ResponseParam{
+				Message: validationError.Error(),
+				Error:   validationError.Items,
+			})
+			return ctx.Status(fiber.StatusUnprocessableEntity).JSON(responseEntity)
+		case *app_error.ConflictError:
+			responseEntity := response.NewErrorResponse(&response.ResponseParam{
+				Message: err.Error(),
+			})
+			return ctx.Status(fiber.StatusConflict).JSON(responseEntity)
+		default:
+			return ctx.Next()
+		}
+
+		if result.Response != nil {
+			for name, value := range result.Response.Headers {
+				ctx.Set(name, value)
+			}
+			ctx.Set(HEADER_IDEMPOTENT_REPLAYED, "true")
+			return ctx.Status(result.Response.StatusCode).Send(result.Response.Body)
+		}
+
+		err = ctx.Next()
+		statusCode := ctx.Response().StatusCode()
+		if err != nil || statusCode >= fiber.StatusInternalServerError {
+			keeper.Cancel(idempotency.CancelParam{
+				Client: client,
+				Key:    key,
+			})
+			return err
+		}
+
+		headers := map[string]string{}
+		for _, name := range replayedHeaders {
+			if value := ctx.Response().Header.Peek(name); len(value) > 0 {
+				headers[name] = string(value)
+			}
+		}
+		keeper.Complete(idempotency.CompleteParam{
+			Client:      client,
+			Key:         key,
+			Fingerprint: fingerprint,
+			Response: idempotency.ResponseEntity{
+				StatusCode: statusCode,
+				Headers:    headers,
+				Body:       append([]byte{}, ctx.Response().Body()...),
+			},
+		})
+		return nil
+	}
+}
+
+// idempotencyClient scopes keys to the authenticated principal so a response is never replayed to another caller,
+// anonymous callers are scoped by their ip the same way as the rate limit buckets
+func idempotencyClient(ctx *Context) string {
+	if principal := GetPrincipal(ctx); principal != "" {
+		return "principal:" + principal
+	}
+	return "ip:" + ctx.IP()
+}
+
+// requestFingerprint hashes what makes a request different from another one,
+// multipart bodies are hashed part by part since clients may pick a new boundary on every retry,
+// a body over the body limit is streamed to its handler so its length and digest stand for it
+func requestFingerprint(ctx *Context) string {
+	h := sha256.New()
+	writeField(h, ctx.Method())
+	writeField(h, ctx.Path())
+	writeField(h, string(ctx.Request().URI().QueryString()))
+	writeField(h, ctx.Get(fiber.HeaderIfMatch))
+
+	contentLength := ctx.Request().Header.ContentLength()
+	if contentLength < 0 || contentLength > ctx.App().Config().BodyLimit {
+		writeField(h, ctx.Get(fiber.HeaderContentType))
+		writeField(h, strconv.Itoa(contentLength))
+		writeField(h, ctx.Get("Content-Digest"))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		writeField(h, ctx.Get(fiber.HeaderContentType))
+		writeField(h, string(ctx.Body()))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	names := []string{}
+	for name := range form.Value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeField(h, name)
+		for _, value := range form.Value[name] {
+			writeField(h, value)
+		}
+	}
+
+	names = []string{}
+	for name := range form.File {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeField(h, name)
+		for _, fh := range form.File[name] {
+			writeField(h, fh.Filename)
+			writeField(h, fh.Header.Get(fiber.HeaderContentType))
+			writeField(h, strconv.FormatInt(fh.Size, 10))
+			if f, err := fh.Open(); err == nil {
+				io.Copy(h, f)
+				f.Close()
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeField writes the value length first so consecutive values can not be shifted into each other
+func writeField(h hash.Hash, value string) {
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+	h.Write(size[:])
+	io.WriteString(h, value)
+}
@@ -0,0 +1,215 @@
+package builtin_app_test
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	builtin_app "idaman.id/storage/internal/builtin-app"
+	"idaman.id/storage/internal/idempotency"
+	idempotency_memory "idaman.id/storage/internal/idempotency-memory"
+)
+
+var _ = Describe("Idempotency Handler", func() {
+	var (
+		fiberApp *fiber.App
+		calls    int
+		status   int
+	)
+
+	BeforeEach(func() {
+		calls = 0
+		status = fiber.StatusCreated
+		keeper := idempotency.NewIdempotencyService(idempotency_memory.NewMemoryStore(), &FakeConfig{values: map[string]interface{}{
+			"IDEMPOTENCY_TTL":          86400,
+			"IDEMPOTENCY_LOCK_TIMEOUT": 300,
+		}})
+		handler := func(ctx *builtin_app.Context) error {
+			calls++
+			ctx.Set(fiber.HeaderETag, `"1"`)
+			return ctx.Status(status).JSON(fiber.Map{"call": calls})
+		}
+		fiberApp = fiber.New()
+		fiberApp.Post("/v1/file", builtin_app.NewIdempotencyHandler(keeper), handler)
+		fiberApp.Post("/v1/plain", builtin_app.NewIdempotencyHandler(nil), handler)
+		fiberApp.Post("/v1/secured", builtin_app.NewAuthHandler(&FakeAuthenticator{}), builtin_app.NewIdempotencyHandler(keeper), handler)
+	})
+
+	newRequest := func(key, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/v1/file", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(builtin_app.HEADER_IDEMPOTENCY_KEY, key)
+		return req
+	}
+
+	newMultipartRequest := func(key string) *http.Request {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		writer.WriteField("provider", "local")
+		part, _ := writer.CreateFormFile("file", "report.pdf")
+		part.Write([]byte("%PDF-1.4"))
+		writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/file", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set(builtin_app.HEADER_IDEMPOTENCY_KEY, key)
+		return req
+	}
+
+	When("key is not sent", func() {
+		It("should handle every request", func() {
+			fiberApp.Test(httptest.NewRequest(http.MethodPost, "/v1/file", nil))
+			res, _ := fiberApp.Test(httptest.NewRequest(http.MethodPost, "/v1/file", nil))
+
+			Expect(res.StatusCode).To(Equal(fiber.StatusCreated))
+			Expect(calls).To(Equal(2))
+		})
+	})
+
+	When("another principal sends the same key", func() {
+		It("should not replay the response of the first principal", func() {
+			newSecuredRequest := func(token string) *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/v1/secured", strings.NewReader(`{}`))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+				req.Header.Set("X-Api-Key", "shared")
+				req.Header.Set(builtin_app.HEADER_IDEMPOTENCY_KEY, "key-1")
+				return req
+			}
+			fiberApp.Test(newSecuredRequest("writer"))
+			res, _ := fiberApp.Test(newSecuredRequest("reader"))
+
+			Expect(res.StatusCode).To(Equal(fiber.StatusCreated))
+			Expect(res.Header.Get(builtin_app.HEADER_IDEMPOTENT_REPLAYED)).To(Equal(""))
+			Expect(calls).To(Equal(2))
+		})
+	})
+
+	When("idempotency is disabled", func() {
+		It("should handle every request", func() {
+			req := newRequest("key-1", `{}`)
+			fiberApp.Test(req)
+			req = httptest.NewRequest(http.MethodPost, "/v1/plain", strings.NewReader(`{}`))
+			req.Header.Set(builtin_app.HEADER_IDEMPOTENCY_KEY, "key-1")
+			res, _ := fiberApp.Test(req)
+
+			Expect(res.StatusCode).To(Equal(fiber.StatusCreated))
+			Expect(res.Header.Get(builtin_app.HEADER_IDEMPOTENT_REPLAYED)).To(Equal(""))
+			Expect(calls).To(Equal(2))
+		})
+	})
+
+	When("request is retried with the same key", func() {
+		It("should replay the first response", func() {
+			fiberApp.Test(newRequest("key-1", `{"slug":"report"}`))
+			res, _ := fiberApp.Test(newRequest("key-1", `{"slug":"report"}`))
+
+			resBody := new(bytes.Buffer)
+			resBody.ReadFrom(res.Body)
+
+			Expect(res.StatusCode).To(Equal(fiber.StatusCreated))
+			Expect(resBody.String()).To(Equal(`{"call":1}`))
+			Expect(res.Header.Get(fiber.HeaderETag)).To(Equal(`"1"`))
+			Expect(res.Header.Get(fiber.HeaderContentType)).To(Equal(fiber.MIMEApplicationJSON))
+			Expect(res.Header.Get(builtin_app.HEADER_IDEMPOTENT_REPLAYED)).To(Equal("true"))
+			Expect(calls).To(Equal(1))
+		})
+	})
+
+	When("multipart request is retried with a new boundary", func() {
+		It("should replay the first response", func() {
+			fiberApp.Test(newMultipartRequest("key-1"))
+			res, _ := fiberApp.Test(newMultipartRequest("key-1"))
+
+			Expect(res.StatusCode).To(Equal(fiber.StatusCreated))
+			Expect(res.Header.Get(builtin_app.HEADER_IDEMPOTENT_REPLAYED)).To(Equal("true"))
+			Expect(calls).To(Equal(1))
+		})
+	})
+
+	When("streamed request is retried with the same key", func() {
+		It("should replay the first response without reading the body", func() {
+			keeper := idempotency.NewIdempotencyService(idempotency_memory.NewMemoryStore(), &FakeConfig{values: map[string]interface{}{
+				"IDEMPOTENCY_TTL":          86400,
+				"IDEMPOTENCY_LOCK_TIMEOUT": 300,
+			}})
+			streamed := ""
+			fiberApp = fiber.New(fiber.Config{
+				StreamRequestBody: true,
+				BodyLimit:         4,
+			})
+			fiberApp.Put("/v1/file/:name", builtin_app.NewIdempotencyHandler(keeper), func(ctx *builtin_app.Context) error {
+				body, _ := io.ReadAll(ctx.Context().RequestBodyStream())
+				streamed = string(body)
+				return ctx.SendStatus(fiber.StatusCreated)
+			})
+			newStreamedRequest := func() *http.Request {
+				req := httptest.NewRequest(http.MethodPut, "/v1/file/report.pdf", strings.NewReader("%PDF-1.4"))
+				req.Header.Set("Content-Digest", "sha-256=:4W+l2bUZKHVduFuRfwKXurryLHpH6X2SEq2rVuYboE4=:")
+				req.Header.Set(builtin_app.HEADER_IDEMPOTENCY_KEY, "key-1")
+				return req
+			}
+			fiberApp.Test(newStreamedRequest())
+			res, _ := fiberApp.Test(newStreamedRequest())
+
+			Expect(res.StatusCode).To(Equal(fiber.StatusCreated))
+			Expect(res.Header.Get(builtin_app.HEADER_IDEMPOTENT_REPLAYED)).To(Equal("true"))
+			Expect(streamed).To(Equal("%PDF-1.4"))
+		})
+	})
+
+	When("key is reused with a different payload", func() {
+		It("should return invalid data response", func() {
+			fiberApp.Test(newRequest("key-1", `{"slug":"report"}`))
+			res, _ := fiberApp.Test(newRequest("key-1", `{"slug":"invoice"}`))
+
+			resEntity := UnmarshallResponseBody(res.Body)
+
+			Expect(res.StatusCode).To(Equal(fiber.StatusUnprocessableEntity))
+			Expect(resEntity.Error).To(HaveLen(1))
+			Expect(calls).To(Equal(1))
+		})
+	})
+
+	When("first request failed on the server", func() {
+		It("should handle the retry", func() {
+			status = fiber.StatusServiceUnavailable
+			fiberApp.Test(newRequest("key-1", `{}`))
+			status = fiber.StatusCreated
+			res, _ := fiberApp.Test(newRequest("key-1", `{}`))
+
+			Expect(res.StatusCode).To(Equal(fiber.StatusCreated))
+			Expect(res.Header.Get(builtin_app.HEADER_IDEMPOTENT_REPLAYED)).To(Equal(""))
+			Expect(calls).To(Equal(2))
+		})
+	})
+
+	When("first request is still in progress", func() {
+		It("should return conflict response", func() {
+			keeper := idempotency.NewIdempotencyService(idempotency_memory.NewMemoryStore(), &FakeConfig{values: map[string]interface{}{
+				"IDEMPOTENCY_LOCK_TIMEOUT": 300,
+			}})
+			fiberApp.Post("/v1/slow", builtin_app.NewIdempotencyHandler(keeper), func(ctx *builtin_app.Context) error {
+				req := httptest.NewRequest(http.MethodPost, "/v1/slow", nil)
+				req.Header.Set(builtin_app.HEADER_IDEMPOTENCY_KEY, "key-1")
+				res, _ := fiberApp.Test(req)
+				return ctx.SendString(strconv.Itoa(res.StatusCode))
+			})
+			req := httptest.NewRequest(http.MethodPost, "/v1/slow", nil)
+			req.Header.Set(builtin_app.HEADER_IDEMPOTENCY_KEY, "key-1")
+			res, _ := fiberApp.Test(req)
+
+			resBody := new(bytes.Buffer)
+			resBody.ReadFrom(res.Body)
+
+			Expect(resBody.String()).To(Equal("409"))
+		})
+	})
+})
@@ -0,0 +1,10 @@
+package builtin_app
+
+type QuotaEntity struct {
+	ApplicationId string `json:"application_id"`
+	MaxTotalSize  int64  `json:"max_total_size"`
+	MaxFileCount  int64  `json:"max_file_count"`
+	MaxFileSize   int64  `json:"max_file_size"`
+	TotalSize     int64  `json:"total_size"`
+	FileCount     int64  `json:"file_count"`
+}
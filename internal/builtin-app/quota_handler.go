@@ -0,0 +1,38 @@
+package builtin_app
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"idaman.id/storage/internal/quota"
+	response "idaman.id/storage/internal/response"
+)
+
+func NewGetQuotaHandler(qService quota.QuotaGetter) Handler {
+	return func(ctx *Context) error {
+		applicationId := ""
+		claims := GetClaims(ctx)
+		if claims != nil {
+			applicationId = claims.Tenant
+		}
+
+		q, err := qService.GetQuota(applicationId)
+		if err != nil {
+			resBody := response.NewErrorResponse(&response.ResponseParam{
+				Message: err.Error(),
+			})
+			return ctx.Status(fiber.StatusBadRequest).JSON(resBody)
+		}
+
+		quotaEntity := &QuotaEntity{
+			ApplicationId: q.ApplicationId,
+			MaxTotalSize:  q.MaxTotalSize,
+			MaxFileCount:  q.MaxFileCount,
+			MaxFileSize:   q.MaxFileSize,
+			TotalSize:     q.TotalSize,
+			FileCount:     q.FileCount,
+		}
+		resBody := response.NewSuccessResponse(&response.ResponseParam{
+			Data: quotaEntity,
+		})
+		return ctx.JSON(resBody)
+	}
+}
@@ -0,0 +1,66 @@
+package builtin_app_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gofiber/fiber/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/auth"
+	builtin_app "idaman.id/storage/internal/builtin-app"
+	response "idaman.id/storage/internal/response"
+)
+
+var _ = Describe("Quota Handler", func() {
+	var (
+		fiberApp *fiber.App
+		tenant   string
+	)
+
+	BeforeEach(func() {
+		tenant = "app-1"
+		fiberApp = fiber.New()
+		fiberApp.Use(func(ctx *fiber.Ctx) error {
+			ctx.Locals(builtin_app.CLAIMS_KEY, &auth.Claims{Tenant: tenant})
+			return ctx.Next()
+		})
+		fiberApp.Get("/v1/quota", builtin_app.NewGetQuotaHandler(&FakeQuotaGetterService{}))
+	})
+
+	When("unexpected error happened", func() {
+		It("should return error response", func() {
+			tenant = "error"
+			req := httptest.NewRequest(http.MethodGet, "/v1/quota", nil)
+			res, _ := fiberApp.Test(req)
+
+			resEntity := UnmarshallResponseBody(res.Body)
+
+			expected := response.NewErrorResponse(&response.ResponseParam{
+				Message: response.STATUS_ERROR,
+			})
+
+			Expect(res.StatusCode).To(Equal(fiber.StatusBadRequest))
+			Expect(resEntity).To(Equal(expected))
+		})
+	})
+
+	When("quota is available", func() {
+		It("should return quota of the token tenant", func() {
+			req := httptest.NewRequest(http.MethodGet, "/v1/quota", nil)
+			res, _ := fiberApp.Test(req)
+
+			resEntity := UnmarshallResponseBody(res.Body)
+
+			Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+			Expect(resEntity.Data).To(Equal(map[string]interface{}{
+				"application_id": "app-1",
+				"max_total_size": float64(100),
+				"max_file_count": float64(0),
+				"max_file_size":  float64(0),
+				"total_size":     float64(40),
+				"file_count":     float64(2),
+			}))
+		})
+	})
+})
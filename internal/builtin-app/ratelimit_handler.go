@@ -0,0 +1,96 @@
+package builtin_app
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/ratelimit"
+	response "idaman.id/storage/internal/response"
+)
+
+const (
+	HEADER_RATE_LIMIT_LIMIT     = "RateLimit-Limit"
+	HEADER_RATE_LIMIT_REMAINING = "RateLimit-Remaining"
+	HEADER_RATE_LIMIT_RESET     = "RateLimit-Reset"
+
+	RATE_LIMIT_KEY = "rate-limit"
+)
+
+// NewIpRateLimitHandler consumes the client ip budget ahead of authentication,
+// so unauthenticated floods are limited as well
+func NewIpRateLimitHandler(limiter ratelimit.Limiter) Handler {
+	return func(ctx *Context) error {
+		if limiter == nil {
+			return ctx.Next()
+		}
+
+		result, err := limiter.LimitIp(ctx.IP())
+		return handleRateLimit(ctx, result, err)
+	}
+}
+
+// NewRateLimitHandler consumes the caller budgets of the given route, the caller is the authenticated principal
+// or the client ip for anonymous routes, it passes through when rate limiting is disabled or the limiter store is unreachable
+func NewRateLimitHandler(limiter ratelimit.Limiter, route string, isUpload bool) Handler {
+	return func(ctx *Context) error {
+		if limiter == nil {
+			return ctx.Next()
+		}
+
+		// the length of a streamed chunked body is only known once it is read
+		uploadSize := int64(ctx.Request().Header.ContentLength())
+		if isUpload && uploadSize < 0 && ctx.Request().IsBodyStream() {
+			responseEntity := response.NewErrorResponse(&response.ResponseParam{
+				Message: app_error.STATUS_LENGTH_REQUIRED,
+			})
+			return ctx.Status(fiber.StatusLengthRequired).JSON(responseEntity)
+		}
+		if uploadSize < 0 {
+			uploadSize = int64(len(ctx.Body()))
+		}
+
+		result, err := limiter.Limit(ratelimit.LimitParam{
+			Route:      route,
+			Ip:         ctx.IP(),
+			Principal:  GetPrincipal(ctx),
+			IsUpload:   isUpload,
+			UploadSize: uploadSize,
+		})
+		return handleRateLimit(ctx, result, err)
+	}
+}
+
+// handleRateLimit answers the headers of the most exhausted bucket consumed by the request so far
+func handleRateLimit(ctx *Context, result *ratelimit.LimitResult, err error) error {
+	previous, _ := ctx.Locals(RATE_LIMIT_KEY).(*ratelimit.LimitResult)
+	if _, isDenied := err.(*app_error.TooManyRequestError); !isDenied {
+		result = ratelimit.MostRestrictive(previous, result)
+	}
+	if result != nil && result.Limit > 0 {
+		ctx.Locals(RATE_LIMIT_KEY, result)
+		ctx.Set(HEADER_RATE_LIMIT_LIMIT, strconv.FormatInt(result.Limit, 10))
+		ctx.Set(HEADER_RATE_LIMIT_REMAINING, strconv.FormatInt(result.Remaining, 10))
+		ctx.Set(HEADER_RATE_LIMIT_RESET, formatSeconds(result.ResetAfter))
+	}
+
+	switch err.(type) {
+	case nil:
+		return ctx.Next()
+	case *app_error.TooManyRequestError:
+		tooManyRequestError := err.(*app_error.TooManyRequestError)
+		ctx.Set(fiber.HeaderRetryAfter, formatSeconds(tooManyRequestError.RetryAfter))
+		responseEntity := response.NewErrorResponse(&response.ResponseParam{
+			Message: tooManyRequestError.Error(),
+		})
+		return ctx.Status(fiber.StatusTooManyRequests).JSON(responseEntity)
+	}
+	return ctx.Next()
+}
+
+func formatSeconds(d time.Duration) string {
+	s := int64(math.Ceil(d.Seconds()))
+	return strconv.FormatInt(s, 10)
+}
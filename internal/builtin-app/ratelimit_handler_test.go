@@ -0,0 +1,98 @@
+package builtin_app_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gofiber/fiber/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	builtin_app "idaman.id/storage/internal/builtin-app"
+	app_error "idaman.id/storage/internal/error"
+	response "idaman.id/storage/internal/response"
+)
+
+var _ = Describe("Rate Limit Handler", func() {
+	var (
+		fiberApp *fiber.App
+		limiter  *FakeLimiter
+	)
+
+	BeforeEach(func() {
+		limiter = &FakeLimiter{deniedPrincipal: "sub:writer"}
+		fiberApp = fiber.New()
+		fiberApp.Use(builtin_app.NewIpRateLimitHandler(limiter))
+		fiberApp.Get("/limited", builtin_app.NewRateLimitHandler(limiter, "limited", false), builtin_app.NewHomeHandler())
+		fiberApp.Get("/authenticated",
+			builtin_app.NewAuthHandler(&FakeAuthenticator{}),
+			builtin_app.NewRateLimitHandler(limiter, "authenticated", false),
+			builtin_app.NewHomeHandler(),
+		)
+	})
+
+	When("budget is available", func() {
+		It("should return rate limit headers of the most exhausted bucket", func() {
+			req := httptest.NewRequest(http.MethodGet, "/limited", nil)
+			res, _ := fiberApp.Test(req)
+
+			Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+			Expect(res.Header.Get(builtin_app.HEADER_RATE_LIMIT_LIMIT)).To(Equal("10"))
+			Expect(res.Header.Get(builtin_app.HEADER_RATE_LIMIT_REMAINING)).To(Equal("3"))
+			Expect(res.Header.Get(builtin_app.HEADER_RATE_LIMIT_RESET)).To(Equal("6"))
+			Expect(res.Header.Get(fiber.HeaderRetryAfter)).To(Equal(""))
+		})
+	})
+
+	When("principal budget is exhausted", func() {
+		It("should return too many request response", func() {
+			req := httptest.NewRequest(http.MethodGet, "/authenticated", nil)
+			req.Header.Set(fiber.HeaderAuthorization, "Bearer writer")
+			res, _ := fiberApp.Test(req)
+
+			resEntity := UnmarshallResponseBody(res.Body)
+
+			expected := response.NewErrorResponse(&response.ResponseParam{
+				Message: app_error.STATUS_TOO_MANY_REQUEST,
+			})
+
+			Expect(res.StatusCode).To(Equal(fiber.StatusTooManyRequests))
+			Expect(resEntity).To(Equal(expected))
+			Expect(res.Header.Get(fiber.HeaderRetryAfter)).To(Equal("2"))
+			Expect(res.Header.Get(builtin_app.HEADER_RATE_LIMIT_REMAINING)).To(Equal("0"))
+		})
+	})
+
+	When("ip budget is exhausted", func() {
+		It("should answer before authenticating the caller", func() {
+			limiter.deniedIp = "0.0.0.0"
+			req := httptest.NewRequest(http.MethodGet, "/authenticated", nil)
+			res, _ := fiberApp.Test(req)
+
+			Expect(res.StatusCode).To(Equal(fiber.StatusTooManyRequests))
+			Expect(res.Header.Get(fiber.HeaderRetryAfter)).To(Equal("2"))
+		})
+	})
+
+	When("caller sends a client supplied key", func() {
+		It("should not be used as principal", func() {
+			req := httptest.NewRequest(http.MethodGet, "/limited", nil)
+			req.Header.Set("X-Api-Key", "sub:writer")
+			res, _ := fiberApp.Test(req)
+
+			Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+		})
+	})
+
+	When("rate limiting is disabled", func() {
+		It("should not return rate limit headers", func() {
+			fiberApp = fiber.New()
+			fiberApp.Use(builtin_app.NewIpRateLimitHandler(nil))
+			fiberApp.Get("/unlimited", builtin_app.NewRateLimitHandler(nil, "unlimited", false), builtin_app.NewHomeHandler())
+			req := httptest.NewRequest(http.MethodGet, "/unlimited", nil)
+			res, _ := fiberApp.Test(req)
+
+			Expect(res.StatusCode).To(Equal(fiber.StatusOK))
+			Expect(res.Header.Get(builtin_app.HEADER_RATE_LIMIT_LIMIT)).To(Equal(""))
+		})
+	})
+})
@@ -18,6 +18,11 @@ func (s *viperConfig) GetInt(key string) int {
 	return value
 }
 
+func (s *viperConfig) GetBool(key string) bool {
+	value := viper.GetBool(key)
+	return value
+}
+
 func (s *viperConfig) Get(key string) interface{} {
 	value := viper.Get(key)
 	return value
@@ -54,6 +59,69 @@ func NewViperConfig(fn string) (*viperConfig, error) {
 	s.SetDefault("MAX_UPLOADED_FILE", 5)
 	s.SetDefault("MIN_FILE_SIZE", 1)
 	s.SetDefault("MAX_FILE_SIZE", 134217728)
+	s.SetDefault("METADATA_MAX_KEYS", 32)
+	s.SetDefault("METADATA_MAX_SIZE", 4096)
+	s.SetDefault("TAGS_MAX_COUNT", 20)
+	s.SetDefault("UNPACK_MAX_ENTRIES", 1000)
+	s.SetDefault("UNPACK_MAX_SIZE", 1073741824)
+	s.SetDefault("UNPACK_MAX_RATIO", 100)
+	s.SetDefault("REMOTE_FETCH_TIMEOUT", 30)
+	s.SetDefault("REMOTE_FETCH_MAX_SIZE", 134217728)
+	s.SetDefault("REMOTE_FETCH_MAX_REDIRECTS", 3)
+	s.SetDefault("STORAGE_PROVIDERS", "local")
+	s.SetDefault("DEFAULT_PROVIDER", "local")
+	s.SetDefault("STORAGE_LOCAL_DIR", "storage/file")
+	s.SetDefault("AUTH_ENABLED", false)
+	s.SetDefault("AUTH_JWT_LEEWAY", 60)
+	s.SetDefault("AUTH_JWT_TENANT_CLAIM", "tenant")
+	s.SetDefault("RATE_LIMIT_ENABLED", false)
+	s.SetDefault("RATE_LIMIT_STORE", "memory")
+	s.SetDefault("RATE_LIMIT_IP_LIMIT", 300)
+	s.SetDefault("RATE_LIMIT_IP_PERIOD", 60)
+	s.SetDefault("RATE_LIMIT_PRINCIPAL_LIMIT", 600)
+	s.SetDefault("RATE_LIMIT_PRINCIPAL_PERIOD", 60)
+	s.SetDefault("RATE_LIMIT_ROUTE_LIMIT", 120)
+	s.SetDefault("RATE_LIMIT_ROUTE_PERIOD", 60)
+	s.SetDefault("RATE_LIMIT_UPLOAD_COUNT_LIMIT", 100)
+	s.SetDefault("RATE_LIMIT_UPLOAD_COUNT_PERIOD", 3600)
+	s.SetDefault("RATE_LIMIT_UPLOAD_BYTES_LIMIT", 1073741824)
+	s.SetDefault("RATE_LIMIT_UPLOAD_BYTES_PERIOD", 3600)
+	s.SetDefault("IDEMPOTENCY_ENABLED", false)
+	s.SetDefault("IDEMPOTENCY_STORE", "memory")
+	s.SetDefault("IDEMPOTENCY_TTL", 86400)
+	s.SetDefault("IDEMPOTENCY_LOCK_TIMEOUT", 300)
+	s.SetDefault("EXPIRY_REAPER_ENABLED", false)
+	s.SetDefault("EXPIRY_REAPER_INTERVAL", 300)
+	s.SetDefault("EXPIRY_REAPER_BATCH_SIZE", 100)
+	s.SetDefault("EXPIRY_REAPER_MAX_BATCHES", 10)
+	s.SetDefault("METRICS_ENABLED", false)
+	s.SetDefault("TIERING_ENABLED", false)
+	s.SetDefault("TIERING_INTERVAL", 3600)
+	s.SetDefault("TIERING_BATCH_SIZE", 100)
+	s.SetDefault("TIERING_MAX_BATCHES", 10)
+	s.SetDefault("ACCESS_TRACKING_INTERVAL", 3600)
+	s.SetDefault("REPLICATION_ENABLED", false)
+	s.SetDefault("REPLICATION_MODE", "sync")
+	s.SetDefault("REPLICATION_QUEUE_SIZE", 1000)
+	s.SetDefault("REPLICATION_PENDING_TIMEOUT", 3600)
+	s.SetDefault("REPLICATION_REPAIR_INTERVAL", 3600)
+	s.SetDefault("REPLICATION_REPAIR_BATCH_SIZE", 100)
+	s.SetDefault("REPLICATION_REPAIR_MAX_BATCHES", 10)
+	s.SetDefault("REDIS_PORT", 6379)
+	s.SetDefault("IMAGE_MAX_WIDTH", 4096)
+	s.SetDefault("IMAGE_MAX_HEIGHT", 4096)
+	s.SetDefault("IMAGE_MAX_SOURCE_PIXELS", 50000000)
+	s.SetDefault("IMAGE_DEFAULT_QUALITY", 85)
+	s.SetDefault("URL_SIGNING_DEFAULT_TTL", 3600)
+	s.SetDefault("URL_SIGNING_MAX_TTL", 604800)
+	s.SetDefault("DOWNLOAD_ATTACHMENT_MIMETYPES", "text/html,application/xhtml+xml,image/svg+xml,text/xml,application/xml,text/javascript,application/javascript")
+	s.SetDefault("ARCHIVE_MAX_FILES", 100)
+	s.SetDefault("ARCHIVE_MAX_SIZE", 1073741824)
+	s.SetDefault("SCAN_ENABLED", false)
+	s.SetDefault("SCAN_MODE", "sync")
+	s.SetDefault("SCAN_QUARANTINE_DIR", "storage/quarantine")
+	s.SetDefault("CLAMAV_ADDRESS", "tcp://127.0.0.1:3310")
+	s.SetDefault("CLAMAV_TIMEOUT", 30)
 
 	return s, nil
 }
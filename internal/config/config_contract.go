@@ -13,6 +13,7 @@ type Defaultable interface {
 type Getter interface {
 	GetString(key string) string
 	GetInt(key string) int
+	GetBool(key string) bool
 	Get(key string) interface{}
 }
 
@@ -0,0 +1,5 @@
+package database
+
+type RedisClient interface {
+	Do(args ...interface{}) (interface{}, error)
+}
@@ -0,0 +1,150 @@
+package database_test
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDatabase(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Database Package")
+}
+
+type FakeConfig struct {
+	values map[string]interface{}
+}
+
+func (c *FakeConfig) GetString(key string) string {
+	value, _ := c.values[key].(string)
+	return value
+}
+
+func (c *FakeConfig) GetInt(key string) int {
+	value, _ := c.values[key].(int)
+	return value
+}
+
+func (c *FakeConfig) GetBool(key string) bool {
+	value, _ := c.values[key].(bool)
+	return value
+}
+
+func (c *FakeConfig) Get(key string) interface{} {
+	return c.values[key]
+}
+
+func (c *FakeConfig) Set(key string, value interface{}) {
+	c.values[key] = value
+}
+
+func (c *FakeConfig) SetDefault(key string, value interface{}) {
+}
+
+// FakeRedisServer answers every RESP command with the raw reply returned by reply,
+// an empty reply closes the connection
+type FakeRedisServer struct {
+	listener    net.Listener
+	reply       func(args []string) string
+	mu          sync.Mutex
+	commands    [][]string
+	connections int
+}
+
+func (s *FakeRedisServer) Host() string {
+	host, _, _ := net.SplitHostPort(s.listener.Addr().String())
+	return host
+}
+
+func (s *FakeRedisServer) Port() string {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return port
+}
+
+func (s *FakeRedisServer) Commands() [][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]string{}, s.commands...)
+}
+
+func (s *FakeRedisServer) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections
+}
+
+func (s *FakeRedisServer) Close() {
+	s.listener.Close()
+}
+
+func (s *FakeRedisServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.connections++
+		s.mu.Unlock()
+		go s.handle(conn)
+	}
+}
+
+func (s *FakeRedisServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.commands = append(s.commands, args)
+		s.mu.Unlock()
+
+		reply := s.reply(args)
+		if reply == "" {
+			return
+		}
+		conn.Write([]byte(reply))
+	}
+}
+
+// readCommand reads an array of bulk strings, the only form sent by clients
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	size, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	args := make([]string, size)
+	for i := range args {
+		line, err = r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		length, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		data := make([]byte, length+2)
+		_, err = io.ReadFull(r, data)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:length])
+	}
+	return args, nil
+}
+
+func NewFakeRedisServer(reply func(args []string) string) *FakeRedisServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).To(BeNil())
+	s := &FakeRedisServer{listener: listener, reply: reply}
+	go s.serve()
+	return s
+}
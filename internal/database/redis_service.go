@@ -0,0 +1,155 @@
+package database
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"idaman.id/storage/internal/config"
+)
+
+// redisClient speaks the RESP protocol so any Redis compatible server can be used,
+// commands are serialized over a single connection which is re-established on failure
+type redisClient struct {
+	address  string
+	password string
+	database int
+	timeout  time.Duration
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+type RedisError struct {
+	Message string
+}
+
+func (error *RedisError) Error() string {
+	return error.Message
+}
+
+func (c *redisClient) Do(args ...interface{}) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		err := c.connect()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := c.do(args...)
+	_, isRedisError := err.(*RedisError)
+	if err != nil && !isRedisError {
+		c.conn.Close()
+		c.conn = nil
+	}
+	return res, err
+}
+
+func (c *redisClient) connect() error {
+	conn, err := net.DialTimeout("tcp", c.address, c.timeout)
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	c.reader = bufio.NewReader(conn)
+
+	if c.password != "" {
+		_, err = c.do("AUTH", c.password)
+	}
+	if err == nil && c.database > 0 {
+		_, err = c.do("SELECT", c.database)
+	}
+	if err != nil {
+		conn.Close()
+		c.conn = nil
+	}
+	return err
+}
+
+func (c *redisClient) do(args ...interface{}) (interface{}, error) {
+	err := c.conn.SetDeadline(time.Now().Add(c.timeout))
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := fmt.Sprintf("*%d\r\n", len(args))
+	for _, arg := range args {
+		s := fmt.Sprint(arg)
+		cmd += fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+	}
+	_, err = c.conn.Write([]byte(cmd))
+	if err != nil {
+		return nil, err
+	}
+	return readReply(c.reader)
+}
+
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 {
+		return nil, errors.New("invalid redis reply")
+	}
+	line = line[:len(line)-2]
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, &RedisError{Message: line[1:]}
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		_, err = io.ReadFull(r, data)
+		if err != nil {
+			return nil, err
+		}
+		return string(data[:size]), nil
+	case '*':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i], err = readReply(r)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	}
+	return nil, errors.New("invalid redis reply")
+}
+
+func NewRedisClient(configService config.ConfigService) (RedisClient, error) {
+	h := configService.GetString("REDIS_HOST")
+	po := configService.GetString("REDIS_PORT")
+	if h == "" {
+		return nil, errors.New("redis host is not configured")
+	}
+
+	c := &redisClient{
+		address:  net.JoinHostPort(h, po),
+		password: configService.GetString("REDIS_PASSWORD"),
+		database: configService.GetInt("REDIS_DATABASE"),
+		timeout:  time.Second * 3,
+	}
+	return c, nil
+}
@@ -0,0 +1,159 @@
+package database_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/database"
+)
+
+var _ = Describe("Redis Service", func() {
+	var (
+		server  *FakeRedisServer
+		replies map[string]string
+		config  *FakeConfig
+		client  database.RedisClient
+	)
+
+	BeforeEach(func() {
+		replies = map[string]string{
+			"AUTH":   "+OK\r\n",
+			"SELECT": "+OK\r\n",
+			"PING":   "+PONG\r\n",
+			"SET":    "+OK\r\n",
+			"INCR":   ":5\r\n",
+			"GET":    "$5\r\nhello\r\n",
+			"MISS":   "$-1\r\n",
+			"LIST":   "*3\r\n$1\r\na\r\n:1\r\n*1\r\n$0\r\n\r\n",
+			"FAIL":   "-ERR wrong number of arguments\r\n",
+			"DROP":   "",
+		}
+		serverReplies := replies
+		server = NewFakeRedisServer(func(args []string) string {
+			return serverReplies[args[0]]
+		})
+		config = &FakeConfig{values: map[string]interface{}{
+			"REDIS_HOST": server.Host(),
+			"REDIS_PORT": server.Port(),
+		}}
+
+		var err error
+		client, err = database.NewRedisClient(config)
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("NewRedisClient function", func() {
+		When("host is not configured", func() {
+			It("should return error", func() {
+				config.values["REDIS_HOST"] = ""
+				res, err := database.NewRedisClient(config)
+
+				Expect(res).To(BeNil())
+				Expect(err).To(MatchError("redis host is not configured"))
+			})
+		})
+	})
+
+	Context("Do method", func() {
+		It("should send the arguments as bulk strings", func() {
+			_, err := client.Do("SET", "key", 10, "PX", int64(500))
+
+			Expect(err).To(BeNil())
+			Expect(server.Commands()).To(Equal([][]string{{"SET", "key", "10", "PX", "500"}}))
+		})
+
+		It("should parse simple string reply", func() {
+			res, err := client.Do("PING")
+
+			Expect(err).To(BeNil())
+			Expect(res).To(Equal("PONG"))
+		})
+
+		It("should parse integer reply", func() {
+			res, err := client.Do("INCR", "counter")
+
+			Expect(err).To(BeNil())
+			Expect(res).To(Equal(int64(5)))
+		})
+
+		It("should parse bulk string reply", func() {
+			res, err := client.Do("GET", "key")
+
+			Expect(err).To(BeNil())
+			Expect(res).To(Equal("hello"))
+		})
+
+		It("should parse nil bulk string reply", func() {
+			res, err := client.Do("MISS", "key")
+
+			Expect(err).To(BeNil())
+			Expect(res).To(BeNil())
+		})
+
+		It("should parse nested array reply", func() {
+			res, err := client.Do("LIST")
+
+			Expect(err).To(BeNil())
+			Expect(res).To(Equal([]interface{}{"a", int64(1), []interface{}{""}}))
+		})
+
+		When("server replies with error", func() {
+			It("should return redis error and keep the connection", func() {
+				res, err := client.Do("FAIL")
+				Expect(res).To(BeNil())
+				Expect(err).To(Equal(&database.RedisError{Message: "ERR wrong number of arguments"}))
+
+				res, err = client.Do("PING")
+
+				Expect(err).To(BeNil())
+				Expect(res).To(Equal("PONG"))
+				Expect(server.Connections()).To(Equal(1))
+			})
+		})
+
+		When("connection is dropped", func() {
+			It("should reconnect on the next command", func() {
+				_, err := client.Do("DROP")
+				Expect(err).ToNot(BeNil())
+
+				res, err := client.Do("PING")
+
+				Expect(err).To(BeNil())
+				Expect(res).To(Equal("PONG"))
+				Expect(server.Connections()).To(Equal(2))
+			})
+		})
+
+		When("password and database are configured", func() {
+			It("should authenticate and select the database on connect", func() {
+				config.values["REDIS_PASSWORD"] = "s3cr3t"
+				config.values["REDIS_DATABASE"] = 2
+				client, _ = database.NewRedisClient(config)
+
+				_, err := client.Do("PING")
+
+				Expect(err).To(BeNil())
+				Expect(server.Commands()).To(Equal([][]string{
+					{"AUTH", "s3cr3t"},
+					{"SELECT", "2"},
+					{"PING"},
+				}))
+			})
+		})
+
+		When("authentication fails", func() {
+			It("should return the redis error", func() {
+				replies["AUTH"] = "-WRONGPASS invalid password\r\n"
+				config.values["REDIS_PASSWORD"] = "wrong"
+				client, _ = database.NewRedisClient(config)
+
+				_, err := client.Do("PING")
+
+				Expect(err).To(Equal(&database.RedisError{Message: "WRONGPASS invalid password"}))
+			})
+		})
+	})
+})
@@ -0,0 +1,12 @@
+package deleting
+
+import "idaman.id/storage/internal/auth"
+
+type DeleteService interface {
+	DeleteFile(p DeleteFileParam) error
+}
+
+type DeleteFileParam struct {
+	Identifier string
+	Claims     *auth.Claims
+}
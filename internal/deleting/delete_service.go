@@ -0,0 +1,129 @@
+package deleting
+
+import (
+	"fmt"
+	"time"
+
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/quota"
+	"idaman.id/storage/internal/repository"
+	"idaman.id/storage/internal/storage"
+	"idaman.id/storage/internal/variant"
+)
+
+type deleteService struct {
+	fileRepo       repository.FileRepository
+	storageDeleter storage.Deleter
+	quotaReserver  quota.QuotaReserver
+	variantRemover variant.VariantRemover
+	versionRepo    repository.VersionRepository
+	transformRepo  repository.TransformRepository
+}
+
+func (s *deleteService) DeleteFile(p DeleteFileParam) error {
+	fileRecord, err := s.fileRepo.FindByIdentifier(p.Identifier)
+	if err != nil {
+		return err
+	}
+
+	if !p.Claims.CanAccessTenant(fileRecord.TenantId) {
+		return app_error.NewForbiddenError("File")
+	}
+
+	deletedAt := time.Now()
+	err = s.fileRepo.Delete(repository.DeleteFileParam{
+		UniqueId:  fileRecord.UniqueId,
+		DeletedAt: &deletedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.quotaReserver.ReleaseQuota(quota.ReleaseQuotaParam{
+		ApplicationId: fileRecord.TenantId,
+		Size:          fileRecord.Size,
+	})
+	if err != nil {
+		return err
+	}
+
+	localPath := fmt.Sprintf("%s/%s", fileRecord.FileLocation, fileRecord.FileName)
+	err = s.storageDeleter.DeleteFile(localPath)
+	if _, isNotFound := err.(*app_error.NotfoundError); err != nil && !isNotFound {
+		return err
+	}
+
+	err = s.deletePreviousVersions(fileRecord)
+	if err != nil {
+		return err
+	}
+
+	err = s.deleteTransforms(fileRecord)
+	if err != nil {
+		return err
+	}
+
+	return s.variantRemover.RemoveVariants(variant.RemoveVariantsParam{
+		UniqueId: fileRecord.UniqueId,
+	})
+}
+
+// deletePreviousVersions removes the kept content versions and gives back their size,
+// the current version is already removed together with the file
+func (s *deleteService) deletePreviousVersions(fileRecord *repository.FileModel) error {
+	versions, err := s.versionRepo.FindByFile(fileRecord.UniqueId)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range versions {
+		err = s.versionRepo.Delete(fileRecord.UniqueId, v.Version)
+		if err != nil {
+			return err
+		}
+		if v.Version == fileRecord.Version {
+			continue
+		}
+
+		err = s.quotaReserver.ReleaseQuota(quota.ReleaseQuotaParam{
+			ApplicationId: fileRecord.TenantId,
+			Size:          v.Size,
+			IsVersion:     true,
+		})
+		if err != nil {
+			return err
+		}
+		err = s.storageDeleter.DeleteFile(fmt.Sprintf("%s/%s", v.FileLocation, v.FileName))
+		if _, isNotFound := err.(*app_error.NotfoundError); err != nil && !isNotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteTransforms removes the images transformed on demand from every content version of the file
+func (s *deleteService) deleteTransforms(fileRecord *repository.FileModel) error {
+	transforms, err := s.transformRepo.FindByFile(fileRecord.UniqueId)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range transforms {
+		err = s.storageDeleter.DeleteFile(fmt.Sprintf("%s/%s", t.FileLocation, t.FileName))
+		if _, isNotFound := err.(*app_error.NotfoundError); err != nil && !isNotFound {
+			return err
+		}
+	}
+	return s.transformRepo.DeleteByFile(fileRecord.UniqueId)
+}
+
+func NewDeleteService(fr repository.FileRepository, sd storage.Deleter, qr quota.QuotaReserver, vr variant.VariantRemover, vsr repository.VersionRepository, tfr repository.TransformRepository) DeleteService {
+	return &deleteService{
+		fileRepo:       fr,
+		storageDeleter: sd,
+		quotaReserver:  qr,
+		variantRemover: vr,
+		versionRepo:    vsr,
+		transformRepo:  tfr,
+	}
+}
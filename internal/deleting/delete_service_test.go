@@ -0,0 +1,138 @@
+package deleting_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/auth"
+	"idaman.id/storage/internal/deleting"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/quota"
+	"idaman.id/storage/internal/repository"
+)
+
+var _ = Describe("Delete Service", func() {
+	var (
+		fileRepo       *FakeFileRepository
+		versionRepo    *FakeVersionRepository
+		transformRepo  *FakeTransformRepository
+		fileStorage    *FakeStorage
+		quotaReserver  *FakeQuotaReserver
+		variantRemover *FakeVariantRemover
+		s              deleting.DeleteService
+		p              deleting.DeleteFileParam
+	)
+
+	BeforeEach(func() {
+		fileRepo = &FakeFileRepository{files: map[string]*repository.FileModel{
+			"file-1": {
+				UniqueId:     "file-1",
+				TenantId:     "tenant-1",
+				Size:         300,
+				Version:      2,
+				FileLocation: "storage/file",
+				FileName:     "file-1.v2.png",
+			},
+		}}
+		versionRepo = &FakeVersionRepository{versions: []repository.VersionModel{
+			{FileUniqueId: "file-1", Version: 1, Size: 200, FileLocation: "storage/file", FileName: "file-1.png"},
+			{FileUniqueId: "file-1", Version: 2, Size: 300, FileLocation: "storage/file", FileName: "file-1.v2.png"},
+			{FileUniqueId: "file-2", Version: 1, Size: 100, FileLocation: "storage/file", FileName: "file-2.png"},
+		}}
+		transformRepo = &FakeTransformRepository{transforms: []repository.TransformModel{
+			{FileUniqueId: "file-1", FileLocation: "storage/file", FileName: "file-1.9f86d081884c7d65.png"},
+			{FileUniqueId: "file-2", FileLocation: "storage/file", FileName: "file-2.9f86d081884c7d65.png"},
+		}}
+		fileStorage = &FakeStorage{}
+		quotaReserver = &FakeQuotaReserver{}
+		variantRemover = &FakeVariantRemover{}
+		s = deleting.NewDeleteService(fileRepo, fileStorage, quotaReserver, variantRemover, versionRepo, transformRepo)
+		p = deleting.DeleteFileParam{
+			Identifier: "file-1",
+			Claims:     &auth.Claims{Tenant: "tenant-1"},
+		}
+	})
+
+	Context("DeleteFile method", func() {
+		When("file is not found", func() {
+			It("should return not found error", func() {
+				p.Identifier = "missing"
+				err := s.DeleteFile(p)
+
+				Expect(err).To(Equal(app_error.NewNotfoundError("File")))
+				Expect(fileRepo.deleted).To(BeEmpty())
+				Expect(quotaReserver.released).To(BeEmpty())
+				Expect(fileStorage.deleted).To(BeEmpty())
+			})
+		})
+
+		When("file belongs to another tenant", func() {
+			It("should return forbidden error", func() {
+				p.Claims = &auth.Claims{Tenant: "tenant-2"}
+				err := s.DeleteFile(p)
+
+				Expect(err).To(Equal(app_error.NewForbiddenError("File")))
+				Expect(fileRepo.deleted).To(BeEmpty())
+			})
+		})
+
+		When("file has previous versions", func() {
+			It("should release the quota of the file and every previous version", func() {
+				err := s.DeleteFile(p)
+
+				Expect(err).To(BeNil())
+				Expect(fileRepo.deleted).To(Equal([]string{"file-1"}))
+				Expect(quotaReserver.released).To(Equal([]quota.ReleaseQuotaParam{
+					{ApplicationId: "tenant-1", Size: 300},
+					{ApplicationId: "tenant-1", Size: 200, IsVersion: true},
+				}))
+			})
+
+			It("should delete the content of every version", func() {
+				err := s.DeleteFile(p)
+
+				Expect(err).To(BeNil())
+				Expect(fileStorage.deleted).To(ContainElements("storage/file/file-1.v2.png", "storage/file/file-1.png"))
+				Expect(versionRepo.versions).To(Equal([]repository.VersionModel{
+					{FileUniqueId: "file-2", Version: 1, Size: 100, FileLocation: "storage/file", FileName: "file-2.png"},
+				}))
+			})
+		})
+
+		When("file has variants and cached transforms", func() {
+			It("should remove them", func() {
+				err := s.DeleteFile(p)
+
+				Expect(err).To(BeNil())
+				Expect(variantRemover.removed).To(Equal([]string{"file-1"}))
+				Expect(fileStorage.deleted).To(ContainElement("storage/file/file-1.9f86d081884c7d65.png"))
+				Expect(fileStorage.deleted).NotTo(ContainElement("storage/file/file-2.9f86d081884c7d65.png"))
+				Expect(transformRepo.transforms).To(HaveLen(1))
+			})
+		})
+
+		When("content is already missing from the storage", func() {
+			It("should still delete the file", func() {
+				fileStorage.missing = map[string]bool{"storage/file/file-1.v2.png": true}
+				err := s.DeleteFile(p)
+
+				Expect(err).To(BeNil())
+				Expect(fileStorage.deleted).To(ContainElement("storage/file/file-1.png"))
+				Expect(variantRemover.removed).To(Equal([]string{"file-1"}))
+			})
+		})
+
+		When("failed to delete the content from the storage", func() {
+			It("should return the error without removing the versions and variants", func() {
+				fileStorage.failing = map[string]bool{"storage/file/file-1.v2.png": true}
+				err := s.DeleteFile(p)
+
+				Expect(err).To(Equal(errors.New("storage error")))
+				Expect(versionRepo.versions).To(HaveLen(3))
+				Expect(variantRemover.removed).To(BeEmpty())
+				Expect(transformRepo.transforms).To(HaveLen(2))
+			})
+		})
+	})
+})
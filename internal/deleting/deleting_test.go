@@ -0,0 +1,126 @@
+package deleting_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/quota"
+	"idaman.id/storage/internal/repository"
+	"idaman.id/storage/internal/variant"
+)
+
+func TestDeleting(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Deleting Package")
+}
+
+type FakeFileRepository struct {
+	repository.FileRepository
+	files   map[string]*repository.FileModel
+	deleted []string
+}
+
+func (r *FakeFileRepository) FindByIdentifier(identifier string) (*repository.FileModel, error) {
+	file, ok := r.files[identifier]
+	if !ok {
+		return nil, app_error.NewNotfoundError("File")
+	}
+	return file, nil
+}
+
+func (r *FakeFileRepository) Delete(p repository.DeleteFileParam) error {
+	r.deleted = append(r.deleted, p.UniqueId)
+	return nil
+}
+
+type FakeVersionRepository struct {
+	repository.VersionRepository
+	versions []repository.VersionModel
+}
+
+func (r *FakeVersionRepository) FindByFile(fileUniqueId string) ([]repository.VersionModel, error) {
+	res := []repository.VersionModel{}
+	for _, v := range r.versions {
+		if v.FileUniqueId == fileUniqueId {
+			res = append(res, v)
+		}
+	}
+	return res, nil
+}
+
+func (r *FakeVersionRepository) Delete(fileUniqueId string, version int64) error {
+	res := []repository.VersionModel{}
+	for _, v := range r.versions {
+		if v.FileUniqueId != fileUniqueId || v.Version != version {
+			res = append(res, v)
+		}
+	}
+	r.versions = res
+	return nil
+}
+
+type FakeTransformRepository struct {
+	repository.TransformRepository
+	transforms []repository.TransformModel
+}
+
+func (r *FakeTransformRepository) FindByFile(fileUniqueId string) ([]repository.TransformModel, error) {
+	res := []repository.TransformModel{}
+	for _, t := range r.transforms {
+		if t.FileUniqueId == fileUniqueId {
+			res = append(res, t)
+		}
+	}
+	return res, nil
+}
+
+func (r *FakeTransformRepository) DeleteByFile(fileUniqueId string) error {
+	res := []repository.TransformModel{}
+	for _, t := range r.transforms {
+		if t.FileUniqueId != fileUniqueId {
+			res = append(res, t)
+		}
+	}
+	r.transforms = res
+	return nil
+}
+
+// FakeStorage fails to delete the paths listed by failing and reports missing ones as not found
+type FakeStorage struct {
+	deleted []string
+	missing map[string]bool
+	failing map[string]bool
+}
+
+func (s *FakeStorage) DeleteFile(localPath string) error {
+	if s.failing[localPath] {
+		return errors.New("storage error")
+	}
+	if s.missing[localPath] {
+		return app_error.NewNotfoundError("File")
+	}
+	s.deleted = append(s.deleted, localPath)
+	return nil
+}
+
+type FakeQuotaReserver struct {
+	quota.QuotaReserver
+	released []quota.ReleaseQuotaParam
+}
+
+func (r *FakeQuotaReserver) ReleaseQuota(p quota.ReleaseQuotaParam) error {
+	r.released = append(r.released, p)
+	return nil
+}
+
+type FakeVariantRemover struct {
+	removed []string
+}
+
+func (r *FakeVariantRemover) RemoveVariants(p variant.RemoveVariantsParam) error {
+	r.removed = append(r.removed, p.UniqueId)
+	return nil
+}
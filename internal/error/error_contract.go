@@ -1,9 +1,18 @@
 package error
 
 const (
-	STATUS_INVALID_DATA     = "INVALID_DATA"
-	STATUS_TOO_MANY_REQUEST = "TOO_MANY_REQUEST"
-	STATUS_NOT_FOUND        = "NOT_FOUND"
-	STATUS_NOT_SUPPORTED    = "NOT_SUPPORTED"
-	STATUS_ALREADY_EXISTS   = "ALREADY_EXISTS"
+	STATUS_INVALID_DATA        = "INVALID_DATA"
+	STATUS_TOO_MANY_REQUEST    = "TOO_MANY_REQUEST"
+	STATUS_NOT_FOUND           = "NOT_FOUND"
+	STATUS_NOT_SUPPORTED       = "NOT_SUPPORTED"
+	STATUS_ALREADY_EXISTS      = "ALREADY_EXISTS"
+	STATUS_UNAUTHENTICATED     = "UNAUTHENTICATED"
+	STATUS_FORBIDDEN           = "FORBIDDEN"
+	STATUS_QUOTA_EXCEEDED      = "QUOTA_EXCEEDED"
+	STATUS_INFECTED            = "INFECTED"
+	STATUS_UNAVAILABLE         = "UNAVAILABLE"
+	STATUS_PRECONDITION_FAILED = "PRECONDITION_FAILED"
+	STATUS_CONFLICT            = "CONFLICT"
+	STATUS_EXPIRED             = "EXPIRED"
+	STATUS_LENGTH_REQUIRED     = "LENGTH_REQUIRED"
 )
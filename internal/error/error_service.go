@@ -1,6 +1,9 @@
 package error
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type ValidationItem struct {
 	Field   string `json:"field"`
@@ -70,3 +73,149 @@ func NewAlreadyExistsError(context string) *AlreadyExistsError {
 		Context: context,
 	}
 }
+
+type UnauthenticatedError struct {
+	Message string
+	Context string
+}
+
+func (error *UnauthenticatedError) Error() string {
+	return error.Message
+}
+
+func NewUnauthenticatedError(context string) *UnauthenticatedError {
+	return &UnauthenticatedError{
+		Message: STATUS_UNAUTHENTICATED,
+		Context: context,
+	}
+}
+
+type ForbiddenError struct {
+	Message string
+	Context string
+}
+
+func (error *ForbiddenError) Error() string {
+	return error.Message
+}
+
+func NewForbiddenError(context string) *ForbiddenError {
+	return &ForbiddenError{
+		Message: STATUS_FORBIDDEN,
+		Context: context,
+	}
+}
+
+type TooManyRequestError struct {
+	Message    string
+	Context    string
+	RetryAfter time.Duration
+}
+
+func (error *TooManyRequestError) Error() string {
+	return error.Message
+}
+
+func NewTooManyRequestError(context string, retryAfter time.Duration) *TooManyRequestError {
+	return &TooManyRequestError{
+		Message:    STATUS_TOO_MANY_REQUEST,
+		Context:    context,
+		RetryAfter: retryAfter,
+	}
+}
+
+type QuotaExceededError struct {
+	Message string
+	Context string
+}
+
+func (error *QuotaExceededError) Error() string {
+	return error.Message
+}
+
+func NewQuotaExceededError(context string) *QuotaExceededError {
+	return &QuotaExceededError{
+		Message: STATUS_QUOTA_EXCEEDED,
+		Context: context,
+	}
+}
+
+type InfectedError struct {
+	Message string
+	Context string
+}
+
+func (error *InfectedError) Error() string {
+	return error.Message
+}
+
+func NewInfectedError(context string) *InfectedError {
+	return &InfectedError{
+		Message: STATUS_INFECTED,
+		Context: context,
+	}
+}
+
+type UnavailableError struct {
+	Message string
+	Context string
+}
+
+func (error *UnavailableError) Error() string {
+	return error.Message
+}
+
+func NewUnavailableError(context string) *UnavailableError {
+	return &UnavailableError{
+		Message: STATUS_UNAVAILABLE,
+		Context: context,
+	}
+}
+
+type PreconditionFailedError struct {
+	Message string
+	Context string
+}
+
+func (error *PreconditionFailedError) Error() string {
+	return error.Message
+}
+
+func NewPreconditionFailedError(context string) *PreconditionFailedError {
+	return &PreconditionFailedError{
+		Message: STATUS_PRECONDITION_FAILED,
+		Context: context,
+	}
+}
+
+type ConflictError struct {
+	Message string
+	Context string
+}
+
+func (error *ConflictError) Error() string {
+	return error.Message
+}
+
+func NewConflictError(context string) *ConflictError {
+	return &ConflictError{
+		Message: STATUS_CONFLICT,
+		Context: context,
+	}
+}
+
+type ExpiredError struct {
+	Message string
+	Context string
+}
+
+func (error *ExpiredError) Error() string {
+	return error.Message
+}
+
+func NewExpiredError(context string) *ExpiredError {
+	return &ExpiredError{
+		Message: STATUS_EXPIRED,
+		Context: context,
+	}
+}
@@ -1,6 +1,8 @@
 package error_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"idaman.id/storage/internal/error"
@@ -14,6 +16,15 @@ var _ = Describe("App Contract", func() {
 			Expect(error.STATUS_NOT_FOUND).To(Equal("NOT_FOUND"))
 			Expect(error.STATUS_NOT_SUPPORTED).To(Equal("NOT_SUPPORTED"))
 			Expect(error.STATUS_ALREADY_EXISTS).To(Equal("ALREADY_EXISTS"))
+			Expect(error.STATUS_UNAUTHENTICATED).To(Equal("UNAUTHENTICATED"))
+			Expect(error.STATUS_FORBIDDEN).To(Equal("FORBIDDEN"))
+			Expect(error.STATUS_QUOTA_EXCEEDED).To(Equal("QUOTA_EXCEEDED"))
+			Expect(error.STATUS_INFECTED).To(Equal("INFECTED"))
+			Expect(error.STATUS_UNAVAILABLE).To(Equal("UNAVAILABLE"))
+			Expect(error.STATUS_PRECONDITION_FAILED).To(Equal("PRECONDITION_FAILED"))
+			Expect(error.STATUS_CONFLICT).To(Equal("CONFLICT"))
+			Expect(error.STATUS_EXPIRED).To(Equal("EXPIRED"))
+			Expect(error.STATUS_LENGTH_REQUIRED).To(Equal("LENGTH_REQUIRED"))
 		})
 	})
 })
@@ -195,4 +206,394 @@ var _ = Describe("Error Service", func() {
 		})
 	})
 
+	Describe("Unauthenticated Error", func() {
+		Context("UnauthenticatedError struct", func() {
+			var (
+				err *error.UnauthenticatedError
+			)
+
+			BeforeEach(func() {
+				err = &error.UnauthenticatedError{
+					Message: error.STATUS_UNAUTHENTICATED,
+				}
+			})
+
+			When("Error method called", func() {
+				It("should return error message", func() {
+
+					Expect(err.Error()).To(Equal(error.STATUS_UNAUTHENTICATED))
+				})
+			})
+		})
+
+		Context("NewUnauthenticatedError function", func() {
+			var (
+				context string
+			)
+
+			BeforeEach(func() {
+				context = "Token"
+			})
+
+			When("function called", func() {
+				It("should return UnauthenticatedError instance", func() {
+					expected := &error.UnauthenticatedError{
+						Message: error.STATUS_UNAUTHENTICATED,
+						Context: context,
+					}
+					err := error.NewUnauthenticatedError(context)
+
+					Expect(err).To(MatchError(expected))
+				})
+			})
+		})
+	})
+
+	Describe("Forbidden Error", func() {
+		Context("ForbiddenError struct", func() {
+			var (
+				err *error.ForbiddenError
+			)
+
+			BeforeEach(func() {
+				err = &error.ForbiddenError{
+					Message: error.STATUS_FORBIDDEN,
+				}
+			})
+
+			When("Error method called", func() {
+				It("should return error message", func() {
+
+					Expect(err.Error()).To(Equal(error.STATUS_FORBIDDEN))
+				})
+			})
+		})
+
+		Context("NewForbiddenError function", func() {
+			var (
+				context string
+			)
+
+			BeforeEach(func() {
+				context = "File"
+			})
+
+			When("function called", func() {
+				It("should return ForbiddenError instance", func() {
+					expected := &error.ForbiddenError{
+						Message: error.STATUS_FORBIDDEN,
+						Context: context,
+					}
+					err := error.NewForbiddenError(context)
+
+					Expect(err).To(MatchError(expected))
+				})
+			})
+		})
+	})
+
+	Describe("TooManyRequest Error", func() {
+		Context("TooManyRequestError struct", func() {
+			var (
+				err *error.TooManyRequestError
+			)
+
+			BeforeEach(func() {
+				err = &error.TooManyRequestError{
+					Message: error.STATUS_TOO_MANY_REQUEST,
+				}
+			})
+
+			When("Error method called", func() {
+				It("should return error message", func() {
+
+					Expect(err.Error()).To(Equal(error.STATUS_TOO_MANY_REQUEST))
+				})
+			})
+		})
+
+		Context("NewTooManyRequestError function", func() {
+			var (
+				context    string
+				retryAfter time.Duration
+			)
+
+			BeforeEach(func() {
+				context = "ip"
+				retryAfter = 3 * time.Second
+			})
+
+			When("function called", func() {
+				It("should return TooManyRequestError instance", func() {
+					expected := &error.TooManyRequestError{
+						Message:    error.STATUS_TOO_MANY_REQUEST,
+						Context:    context,
+						RetryAfter: retryAfter,
+					}
+					err := error.NewTooManyRequestError(context, retryAfter)
+
+					Expect(err).To(MatchError(expected))
+				})
+			})
+		})
+	})
+
+	Describe("QuotaExceeded Error", func() {
+		Context("QuotaExceededError struct", func() {
+			var (
+				err *error.QuotaExceededError
+			)
+
+			BeforeEach(func() {
+				err = &error.QuotaExceededError{
+					Message: error.STATUS_QUOTA_EXCEEDED,
+				}
+			})
+
+			When("Error method called", func() {
+				It("should return error message", func() {
+
+					Expect(err.Error()).To(Equal(error.STATUS_QUOTA_EXCEEDED))
+				})
+			})
+		})
+
+		Context("NewQuotaExceededError function", func() {
+			var (
+				context string
+			)
+
+			BeforeEach(func() {
+				context = "total_size"
+			})
+
+			When("function called", func() {
+				It("should return QuotaExceededError instance", func() {
+					expected := &error.QuotaExceededError{
+						Message: error.STATUS_QUOTA_EXCEEDED,
+						Context: context,
+					}
+					err := error.NewQuotaExceededError(context)
+
+					Expect(err).To(MatchError(expected))
+				})
+			})
+		})
+	})
+
+	Describe("Infected Error", func() {
+		Context("InfectedError struct", func() {
+			var (
+				err *error.InfectedError
+			)
+
+			BeforeEach(func() {
+				err = &error.InfectedError{
+					Message: error.STATUS_INFECTED,
+				}
+			})
+
+			When("Error method called", func() {
+				It("should return error message", func() {
+
+					Expect(err.Error()).To(Equal(error.STATUS_INFECTED))
+				})
+			})
+		})
+
+		Context("NewInfectedError function", func() {
+			var (
+				context string
+			)
+
+			BeforeEach(func() {
+				context = "File"
+			})
+
+			When("function called", func() {
+				It("should return InfectedError instance", func() {
+					expected := &error.InfectedError{
+						Message: error.STATUS_INFECTED,
+						Context: context,
+					}
+					err := error.NewInfectedError(context)
+
+					Expect(err).To(MatchError(expected))
+				})
+			})
+		})
+	})
+
+	Describe("Unavailable Error", func() {
+		Context("UnavailableError struct", func() {
+			var (
+				err *error.UnavailableError
+			)
+
+			BeforeEach(func() {
+				err = &error.UnavailableError{
+					Message: error.STATUS_UNAVAILABLE,
+				}
+			})
+
+			When("Error method called", func() {
+				It("should return error message", func() {
+
+					Expect(err.Error()).To(Equal(error.STATUS_UNAVAILABLE))
+				})
+			})
+		})
+
+		Context("NewUnavailableError function", func() {
+			var (
+				context string
+			)
+
+			BeforeEach(func() {
+				context = "File"
+			})
+
+			When("function called", func() {
+				It("should return UnavailableError instance", func() {
+					expected := &error.UnavailableError{
+						Message: error.STATUS_UNAVAILABLE,
+						Context: context,
+					}
+					err := error.NewUnavailableError(context)
+
+					Expect(err).To(MatchError(expected))
+				})
+			})
+		})
+	})
+
+	Describe("Precondition Failed Error", func() {
+		Context("PreconditionFailedError struct", func() {
+			var (
+				err *error.PreconditionFailedError
+			)
+
+			BeforeEach(func() {
+				err = &error.PreconditionFailedError{
+					Message: error.STATUS_PRECONDITION_FAILED,
+				}
+			})
+
+			When("Error method called", func() {
+				It("should return error message", func() {
+
+					Expect(err.Error()).To(Equal(error.STATUS_PRECONDITION_FAILED))
+				})
+			})
+		})
+
+		Context("NewPreconditionFailedError function", func() {
+			var (
+				context string
+			)
+
+			BeforeEach(func() {
+				context = "File"
+			})
+
+			When("function called", func() {
+				It("should return PreconditionFailedError instance", func() {
+					expected := &error.PreconditionFailedError{
+						Message: error.STATUS_PRECONDITION_FAILED,
+						Context: context,
+					}
+					err := error.NewPreconditionFailedError(context)
+
+					Expect(err).To(MatchError(expected))
+				})
+			})
+		})
+	})
+
+	Describe("Conflict Error", func() {
+		Context("ConflictError struct", func() {
+			var (
+				err *error.ConflictError
+			)
+
+			BeforeEach(func() {
+				err = &error.ConflictError{
+					Message: error.STATUS_CONFLICT,
+				}
+			})
+
+			When("Error method called", func() {
+				It("should return error message", func() {
+
+					Expect(err.Error()).To(Equal(error.STATUS_CONFLICT))
+				})
+			})
+		})
+
+		Context("NewConflictError function", func() {
+			var (
+				context string
+			)
+
+			BeforeEach(func() {
+				context = "Request"
+			})
+
+			When("function called", func() {
+				It("should return ConflictError instance", func() {
+					expected := &error.ConflictError{
+						Message: error.STATUS_CONFLICT,
+						Context: context,
+					}
+					err := error.NewConflictError(context)
+
+					Expect(err).To(MatchError(expected))
+				})
+			})
+		})
+	})
+
+	Describe("Expired Error", func() {
+		Context("ExpiredError struct", func() {
+			var (
+				err *error.ExpiredError
+			)
+
+			BeforeEach(func() {
+				err = &error.ExpiredError{
+					Message: error.STATUS_EXPIRED,
+				}
+			})
+
+			When("Error method called", func() {
+				It("should return error message", func() {
+
+					Expect(err.Error()).To(Equal(error.STATUS_EXPIRED))
+				})
+			})
+		})
+
+		Context("NewExpiredError function", func() {
+			var (
+				context string
+			)
+
+			BeforeEach(func() {
+				context = "File"
+			})
+
+			When("function called", func() {
+				It("should return ExpiredError instance", func() {
+					expected := &error.ExpiredError{
+						Message: error.STATUS_EXPIRED,
+						Context: context,
+					}
+					err := error.NewExpiredError(context)
+
+					Expect(err).To(MatchError(expected))
+				})
+			})
+		})
+	})
+
 })
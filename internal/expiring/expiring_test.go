@@ -0,0 +1,75 @@
+package expiring_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/deleting"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/repository"
+)
+
+func TestExpiring(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Expiring Package")
+}
+
+type FakeConfig struct {
+	values map[string]interface{}
+}
+
+func (c *FakeConfig) GetString(key string) string {
+	value, _ := c.values[key].(string)
+	return value
+}
+
+func (c *FakeConfig) GetInt(key string) int {
+	value, _ := c.values[key].(int)
+	return value
+}
+
+func (c *FakeConfig) GetBool(key string) bool {
+	value, _ := c.values[key].(bool)
+	return value
+}
+
+func (c *FakeConfig) Get(key string) interface{} {
+	return c.values[key]
+}
+
+// FakeFileRepository returns the expired files which are not deleted yet, oldest first
+type FakeFileRepository struct {
+	repository.FileRepository
+	files   []repository.FileModel
+	deleted map[string]bool
+	queries []repository.FindExpiredFileParam
+}
+
+func (r *FakeFileRepository) FindExpired(p repository.FindExpiredFileParam) ([]repository.FileModel, error) {
+	r.queries = append(r.queries, p)
+	files := []repository.FileModel{}
+	for _, f := range r.files {
+		if !r.deleted[f.UniqueId] && len(files) < p.Limit {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+type FakeDeleteService struct {
+	fileRepo *FakeFileRepository
+	broken   map[string]bool
+}
+
+func (s *FakeDeleteService) DeleteFile(p deleting.DeleteFileParam) error {
+	if s.broken[p.Identifier] {
+		return errors.New("storage is unreachable")
+	}
+	if s.fileRepo.deleted[p.Identifier] {
+		return app_error.NewNotfoundError("File")
+	}
+	s.fileRepo.deleted[p.Identifier] = true
+	return nil
+}
@@ -0,0 +1,13 @@
+package expiring
+
+type Reaper interface {
+	// Reap deletes the files expired at the start of the run in batches,
+	// a file which can not be deleted is counted as failed and retried on the next run
+	Reap() (*ReapResult, error)
+}
+
+type ReapResult struct {
+	DeletedFiles int64
+	DeletedSize  int64
+	FailedFiles  int64
+}
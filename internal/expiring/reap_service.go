@@ -0,0 +1,97 @@
+package expiring
+
+import (
+	"time"
+
+	"idaman.id/storage/internal/config"
+	"idaman.id/storage/internal/deleting"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/metrics"
+	"idaman.id/storage/internal/repository"
+)
+
+type reapMetrics struct {
+	runs         metrics.Counter
+	deletedFiles metrics.Counter
+	deletedSize  metrics.Counter
+	failedFiles  metrics.Counter
+	lastRun      metrics.Gauge
+	lastDuration metrics.Gauge
+}
+
+type reapService struct {
+	configGetter config.Getter
+	fileRepo     repository.FileRepository
+	deleter      deleting.DeleteService
+	metrics      reapMetrics
+	now          func() time.Time
+}
+
+func (s *reapService) Reap() (*ReapResult, error) {
+	startedAt := s.now()
+	res := &ReapResult{}
+	err := s.reap(startedAt, res)
+
+	s.metrics.runs.Add(1)
+	s.metrics.deletedFiles.Add(float64(res.DeletedFiles))
+	s.metrics.deletedSize.Add(float64(res.DeletedSize))
+	s.metrics.failedFiles.Add(float64(res.FailedFiles))
+	s.metrics.lastRun.Set(float64(startedAt.Unix()))
+	s.metrics.lastDuration.Set(s.now().Sub(startedAt).Seconds())
+	return res, err
+}
+
+// reap stops once a batch is not full, EXPIRY_REAPER_MAX_BATCHES is reached
+// or a batch does not delete anything, e.g: the storage is unreachable
+func (s *reapService) reap(expiredAt time.Time, res *ReapResult) error {
+	batchSize := s.configGetter.GetInt("EXPIRY_REAPER_BATCH_SIZE")
+	maxBatches := s.configGetter.GetInt("EXPIRY_REAPER_MAX_BATCHES")
+	for batch := 0; batch < maxBatches; batch++ {
+		files, err := s.fileRepo.FindExpired(repository.FindExpiredFileParam{
+			ExpiredAt: &expiredAt,
+			Limit:     batchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		deleted := 0
+		for _, f := range files {
+			err = s.deleter.DeleteFile(deleting.DeleteFileParam{
+				Identifier: f.UniqueId,
+			})
+			if _, isNotFound := err.(*app_error.NotfoundError); isNotFound {
+				continue
+			}
+			if err != nil {
+				res.FailedFiles++
+				continue
+			}
+			deleted++
+			res.DeletedFiles++
+			res.DeletedSize += f.Size
+		}
+
+		if len(files) < batchSize || deleted == 0 {
+			break
+		}
+	}
+	return nil
+}
+
+func NewReapService(cg config.Getter, fr repository.FileRepository, d deleting.DeleteService, r metrics.Registry) Reaper {
+	return &reapService{
+		configGetter: cg,
+		fileRepo:     fr,
+		deleter:      d,
+		metrics: reapMetrics{
+			runs:         r.Counter("storage_expiry_reap_runs_total", "Number of expired file reaper runs"),
+			deletedFiles: r.Counter("storage_expiry_deleted_files_total", "Number of expired files deleted"),
+			deletedSize:  r.Counter("storage_expiry_deleted_bytes_total", "Size of the expired files deleted"),
+			failedFiles:  r.Counter("storage_expiry_failed_files_total", "Number of expired files which could not be deleted"),
+			lastRun:      r.Gauge("storage_expiry_last_run_timestamp_seconds", "Start time of the last reaper run"),
+			lastDuration: r.Gauge("storage_expiry_last_run_duration_seconds", "Duration of the last reaper run"),
+		},
+		now: time.Now,
+	}
+}
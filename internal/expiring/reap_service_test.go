@@ -0,0 +1,96 @@
+package expiring_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/expiring"
+	"idaman.id/storage/internal/metrics"
+	"idaman.id/storage/internal/repository"
+)
+
+var _ = Describe("Reap Service", func() {
+	var (
+		reaper        expiring.Reaper
+		fileRepo      *FakeFileRepository
+		deleteService *FakeDeleteService
+		registry      metrics.Registry
+		config        *FakeConfig
+	)
+
+	BeforeEach(func() {
+		fileRepo = &FakeFileRepository{
+			files: []repository.FileModel{
+				{UniqueId: "a", Size: 10},
+				{UniqueId: "b", Size: 20},
+				{UniqueId: "c", Size: 30},
+				{UniqueId: "d", Size: 40},
+				{UniqueId: "e", Size: 50},
+			},
+			deleted: map[string]bool{},
+		}
+		deleteService = &FakeDeleteService{fileRepo: fileRepo, broken: map[string]bool{}}
+		registry = metrics.NewRegistry()
+		config = &FakeConfig{values: map[string]interface{}{
+			"EXPIRY_REAPER_BATCH_SIZE":  2,
+			"EXPIRY_REAPER_MAX_BATCHES": 10,
+		}}
+		reaper = expiring.NewReapService(config, fileRepo, deleteService, registry)
+	})
+
+	Context("Reap method", func() {
+		When("files are expired", func() {
+			It("should delete them batch by batch", func() {
+				res, err := reaper.Reap()
+
+				Expect(err).To(BeNil())
+				Expect(res.DeletedFiles).To(Equal(int64(5)))
+				Expect(res.DeletedSize).To(Equal(int64(150)))
+				Expect(res.FailedFiles).To(Equal(int64(0)))
+				Expect(fileRepo.queries).To(HaveLen(3))
+				Expect(fileRepo.queries[0].Limit).To(Equal(2))
+				Expect(fileRepo.queries[0].ExpiredAt).To(Equal(fileRepo.queries[2].ExpiredAt))
+			})
+		})
+
+		When("max batches is reached", func() {
+			It("should leave the other files to the next run", func() {
+				config.values["EXPIRY_REAPER_MAX_BATCHES"] = 1
+				res, err := reaper.Reap()
+
+				Expect(err).To(BeNil())
+				Expect(res.DeletedFiles).To(Equal(int64(2)))
+				Expect(fileRepo.deleted).To(HaveLen(2))
+			})
+		})
+
+		When("files can not be deleted", func() {
+			It("should count them as failed and stop", func() {
+				deleteService.broken["a"] = true
+				deleteService.broken["b"] = true
+				res, err := reaper.Reap()
+
+				Expect(err).To(BeNil())
+				Expect(res.DeletedFiles).To(Equal(int64(0)))
+				Expect(res.FailedFiles).To(Equal(int64(2)))
+				Expect(fileRepo.queries).To(HaveLen(1))
+			})
+		})
+
+		When("reaper has run", func() {
+			It("should record the metrics", func() {
+				deleteService.broken["e"] = true
+				reaper.Reap()
+
+				w := &bytes.Buffer{}
+				registry.WriteText(w)
+
+				Expect(w.String()).To(ContainSubstring("storage_expiry_reap_runs_total 1\n"))
+				Expect(w.String()).To(ContainSubstring("storage_expiry_deleted_files_total 4\n"))
+				Expect(w.String()).To(ContainSubstring("storage_expiry_deleted_bytes_total 100\n"))
+				Expect(w.String()).To(ContainSubstring("storage_expiry_failed_files_total 1\n"))
+			})
+		})
+	})
+})
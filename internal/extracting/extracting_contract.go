@@ -0,0 +1,10 @@
+package extracting
+
+type Extractor interface {
+	ExtractMetadata(p ExtractMetadataParam) *MetadataEntity
+}
+
+type ExtractMetadataParam struct {
+	Mimetype string
+	FileData []byte
+}
@@ -0,0 +1,67 @@
+package extracting
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+)
+
+// MAX_OBJECT_STREAM_SIZE bounds each inflated object stream to avoid compression bomb
+const MAX_OBJECT_STREAM_SIZE = 16 * 1024 * 1024
+
+var pdfPageRegexp = regexp.MustCompile(`/Type\s*/Page[^s]`)
+
+// extractPdf counts the page objects, including the ones packed into compressed object streams
+func extractPdf(data []byte) *DocumentMetadataEntity {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return nil
+	}
+
+	plain, objectStreams := splitObjectStreams(data)
+	count := len(pdfPageRegexp.FindAllIndex(plain, -1))
+	for _, objectStream := range objectStreams {
+		count += len(pdfPageRegexp.FindAllIndex(objectStream, -1))
+	}
+	if count == 0 {
+		return nil
+	}
+	return &DocumentMetadataEntity{PageCount: count}
+}
+
+// splitObjectStreams separates the inflated object streams from the rest of the document,
+// so objects are not counted twice when the raw stream happens to be readable
+func splitObjectStreams(data []byte) ([]byte, [][]byte) {
+	plain := []byte{}
+	streams := [][]byte{}
+	rest := data
+	for {
+		i := bytes.Index(rest, []byte("/ObjStm"))
+		if i < 0 {
+			break
+		}
+		start := bytes.Index(rest[i:], []byte("stream"))
+		if start < 0 {
+			break
+		}
+		start += i + len("stream")
+		for start < len(rest) && (rest[start] == '\r' || rest[start] == '\n') {
+			start++
+		}
+		end := bytes.Index(rest[start:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		end += start
+
+		r, err := zlib.NewReader(bytes.NewReader(rest[start:end]))
+		if err == nil {
+			inflated, _ := io.ReadAll(io.LimitReader(r, MAX_OBJECT_STREAM_SIZE))
+			streams = append(streams, inflated)
+			r.Close()
+		}
+		plain = append(plain, rest[:start]...)
+		rest = rest[end:]
+	}
+	return append(plain, rest...), streams
+}
@@ -0,0 +1,37 @@
+package extracting
+
+import "time"
+
+// MetadataEntity is stored as JSON document, every group is omitted when it can not be extracted
+type MetadataEntity struct {
+	Image    *ImageMetadataEntity    `json:"image,omitempty"`
+	Exif     *ExifMetadataEntity     `json:"exif,omitempty"`
+	Document *DocumentMetadataEntity `json:"document,omitempty"`
+	Media    *MediaMetadataEntity    `json:"media,omitempty"`
+}
+
+func (e *MetadataEntity) IsEmpty() bool {
+	return e == nil || *e == MetadataEntity{}
+}
+
+type ImageMetadataEntity struct {
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Orientation int    `json:"orientation,omitempty"`
+	ColorModel  string `json:"color_model"`
+}
+
+type ExifMetadataEntity struct {
+	CapturedAt  *time.Time `json:"captured_at,omitempty"`
+	CameraMake  string     `json:"camera_make,omitempty"`
+	CameraModel string     `json:"camera_model,omitempty"`
+}
+
+type DocumentMetadataEntity struct {
+	PageCount int `json:"page_count"`
+}
+
+type MediaMetadataEntity struct {
+	// Duration is in second
+	Duration float64 `json:"duration"`
+}
@@ -0,0 +1,268 @@
+package extracting
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"strings"
+	"time"
+)
+
+const (
+	TAG_MAKE               = 0x010F
+	TAG_MODEL              = 0x0110
+	TAG_ORIENTATION        = 0x0112
+	TAG_DATETIME           = 0x0132
+	TAG_EXIF_IFD           = 0x8769
+	TAG_DATETIME_ORIGINAL  = 0x9003
+	TAG_OFFSET_TIME_ORIGIN = 0x9011
+)
+
+func extractImage(data []byte) *ImageMetadataEntity {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	res := &ImageMetadataEntity{
+		Width:      cfg.Width,
+		Height:     cfg.Height,
+		ColorModel: colorModelName(cfg.ColorModel),
+	}
+	return res
+}
+
+func colorModelName(m color.Model) string {
+	if _, isPalette := m.(color.Palette); isPalette {
+		return "paletted"
+	}
+	switch m {
+	case color.RGBAModel:
+		return "rgba"
+	case color.RGBA64Model:
+		return "rgba64"
+	case color.NRGBAModel:
+		return "nrgba"
+	case color.NRGBA64Model:
+		return "nrgba64"
+	case color.GrayModel:
+		return "gray"
+	case color.Gray16Model:
+		return "gray16"
+	case color.YCbCrModel:
+		return "ycbcr"
+	case color.CMYKModel:
+		return "cmyk"
+	}
+	return "unknown"
+}
+
+// extractJpegExif looks for the APP1 Exif segment before the image data,
+// it returns the exif metadata and the image orientation
+func extractJpegExif(data []byte) (*ExifMetadataEntity, int) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, 0
+	}
+
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			return nil, 0
+		}
+		marker := data[i+1]
+		// start of scan or end of image, no more metadata segment
+		if marker == 0xDA || marker == 0xD9 {
+			return nil, 0
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return nil, 0
+		}
+		segment := data[i+4 : i+2+size]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return parseExif(segment[6:])
+		}
+		i += 2 + size
+	}
+	return nil, 0
+}
+
+// extractPngExif looks for the eXIf chunk holding the same tiff structure as jpeg exif
+func extractPngExif(data []byte) (*ExifMetadataEntity, int) {
+	if !bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")) {
+		return nil, 0
+	}
+
+	pos := 8
+	for pos+8 <= len(data) {
+		size := int(binary.BigEndian.Uint32(data[pos:]))
+		kind := string(data[pos+4 : pos+8])
+		end := pos + 8 + size
+		if size < 0 || end+4 > len(data) || kind == "IDAT" {
+			return nil, 0
+		}
+		if kind == "eXIf" {
+			return parseExif(data[pos+8 : end])
+		}
+		// skip the chunk content and crc
+		pos = end + 4
+	}
+	return nil, 0
+}
+
+// extractWebp reads the canvas size of the VP8X chunk and the EXIF chunk holding the tiff structure,
+// simple webp has neither of them
+func extractWebp(data []byte) (*ImageMetadataEntity, *ExifMetadataEntity, int) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, nil, 0
+	}
+
+	var img *ImageMetadataEntity
+	pos := 12
+	for pos+8 <= len(data) {
+		kind := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size
+		if size < 0 || end > len(data) {
+			return img, nil, 0
+		}
+		chunk := data[pos+8 : end]
+		if kind == "VP8X" && size >= 10 {
+			img = &ImageMetadataEntity{
+				Width:      1 + (int(chunk[4]) | int(chunk[5])<<8 | int(chunk[6])<<16),
+				Height:     1 + (int(chunk[7]) | int(chunk[8])<<8 | int(chunk[9])<<16),
+				ColorModel: "unknown",
+			}
+		}
+		if kind == "EXIF" {
+			exif, orientation := parseExif(chunk)
+			return img, exif, orientation
+		}
+		// chunks are padded to even size
+		pos = end + size%2
+	}
+	return img, nil, 0
+}
+
+type tiffEntry struct {
+	kind  uint16
+	count uint32
+	value []byte
+}
+
+type tiffReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+func parseExif(data []byte) (*ExifMetadataEntity, int) {
+	if len(data) < 8 {
+		return nil, 0
+	}
+	r := &tiffReader{data: data}
+	switch string(data[:2]) {
+	case "II":
+		r.order = binary.LittleEndian
+	case "MM":
+		r.order = binary.BigEndian
+	default:
+		return nil, 0
+	}
+	if r.order.Uint16(data[2:]) != 42 {
+		return nil, 0
+	}
+
+	ifd0 := r.readIfd(r.order.Uint32(data[4:]))
+	exif := &ExifMetadataEntity{
+		CameraMake:  r.ascii(ifd0[TAG_MAKE]),
+		CameraModel: r.ascii(ifd0[TAG_MODEL]),
+	}
+	orientation := int(r.short(ifd0[TAG_ORIENTATION]))
+
+	dateTime := r.ascii(ifd0[TAG_DATETIME])
+	offsetTime := ""
+	exifIfdEntry, hasExifIfd := ifd0[TAG_EXIF_IFD]
+	if hasExifIfd && len(exifIfdEntry.value) == 4 {
+		exifIfd := r.readIfd(r.order.Uint32(exifIfdEntry.value))
+		if original := r.ascii(exifIfd[TAG_DATETIME_ORIGINAL]); original != "" {
+			dateTime = original
+		}
+		offsetTime = r.ascii(exifIfd[TAG_OFFSET_TIME_ORIGIN])
+	}
+	exif.CapturedAt = parseExifTime(dateTime, offsetTime)
+
+	if exif.CapturedAt == nil && exif.CameraMake == "" && exif.CameraModel == "" {
+		return nil, orientation
+	}
+	return exif, orientation
+}
+
+func (r *tiffReader) readIfd(offset uint32) map[uint16]tiffEntry {
+	entries := map[uint16]tiffEntry{}
+	start := int(offset)
+	if start <= 0 || start+2 > len(r.data) {
+		return entries
+	}
+
+	total := int(r.order.Uint16(r.data[start:]))
+	for n := 0; n < total; n++ {
+		pos := start + 2 + n*12
+		if pos+12 > len(r.data) {
+			break
+		}
+		tag := r.order.Uint16(r.data[pos:])
+		entries[tag] = tiffEntry{
+			kind:  r.order.Uint16(r.data[pos+2:]),
+			count: r.order.Uint32(r.data[pos+4:]),
+			value: r.data[pos+8 : pos+12],
+		}
+	}
+	return entries
+}
+
+func (r *tiffReader) ascii(e tiffEntry) string {
+	// 2 is the ASCII type, value longer than 4 bytes is stored at the given offset
+	if e.kind != 2 || e.count == 0 {
+		return ""
+	}
+	value := e.value
+	if e.count > 4 {
+		offset := int(r.order.Uint32(e.value))
+		end := offset + int(e.count)
+		if offset < 0 || end > len(r.data) || end < offset {
+			return ""
+		}
+		value = r.data[offset:end]
+	} else {
+		value = value[:e.count]
+	}
+	return strings.TrimSpace(strings.TrimRight(string(value), "\x00"))
+}
+
+func (r *tiffReader) short(e tiffEntry) uint16 {
+	// 3 is the SHORT type
+	if e.kind != 3 || e.count == 0 {
+		return 0
+	}
+	return r.order.Uint16(e.value)
+}
+
+func parseExifTime(dateTime, offsetTime string) *time.Time {
+	if dateTime == "" {
+		return nil
+	}
+	if offsetTime != "" {
+		t, err := time.Parse("2006:01:02 15:04:05-07:00", dateTime+offsetTime)
+		if err == nil {
+			return &t
+		}
+	}
+	t, err := time.Parse("2006:01:02 15:04:05", dateTime)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
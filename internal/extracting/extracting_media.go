@@ -0,0 +1,115 @@
+package extracting
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+func extractWav(data []byte) *MediaMetadataEntity {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil
+	}
+
+	var byteRate uint32
+	pos := 12
+	for pos+8 <= len(data) {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		body := pos + 8
+
+		switch id {
+		case "fmt ":
+			if body+12 > len(data) {
+				return nil
+			}
+			byteRate = binary.LittleEndian.Uint32(data[body+8:])
+		case "data":
+			if byteRate == 0 {
+				return nil
+			}
+			// streamed wav may declare a larger data chunk than the actual content
+			if available := len(data) - body; size > available || size < 0 {
+				size = available
+			}
+			return &MediaMetadataEntity{Duration: roundDuration(float64(size) / float64(byteRate))}
+		}
+		// chunks are word aligned
+		pos = body + size + size%2
+	}
+	return nil
+}
+
+func extractFlac(data []byte) *MediaMetadataEntity {
+	// STREAMINFO is always the first metadata block
+	if len(data) < 26 || string(data[:4]) != "fLaC" || data[4]&0x7F != 0 {
+		return nil
+	}
+
+	info := binary.BigEndian.Uint64(data[18:26])
+	sampleRate := info >> 44
+	totalSamples := info & (1<<36 - 1)
+	if sampleRate == 0 || totalSamples == 0 {
+		return nil
+	}
+	return &MediaMetadataEntity{Duration: roundDuration(float64(totalSamples) / float64(sampleRate))}
+}
+
+// extractMp4 reads the movie duration from moov/mvhd box of ISO base media file
+func extractMp4(data []byte) *MediaMetadataEntity {
+	moov := findBox(data, "moov")
+	if moov == nil {
+		return nil
+	}
+	mvhd := findBox(moov, "mvhd")
+	if len(mvhd) < 20 {
+		return nil
+	}
+
+	var timescale uint32
+	var duration uint64
+	if mvhd[0] == 1 {
+		if len(mvhd) < 32 {
+			return nil
+		}
+		timescale = binary.BigEndian.Uint32(mvhd[20:])
+		duration = binary.BigEndian.Uint64(mvhd[24:])
+	} else {
+		timescale = binary.BigEndian.Uint32(mvhd[12:])
+		duration = uint64(binary.BigEndian.Uint32(mvhd[16:]))
+	}
+	if timescale == 0 {
+		return nil
+	}
+	return &MediaMetadataEntity{Duration: roundDuration(float64(duration) / float64(timescale))}
+}
+
+// findBox returns the content of the first box with the given type on the current level
+func findBox(data []byte, kind string) []byte {
+	pos := 0
+	for pos+8 <= len(data) {
+		size := uint64(binary.BigEndian.Uint32(data[pos:]))
+		header := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data) - pos)
+		case 1:
+			if pos+16 > len(data) {
+				return nil
+			}
+			size = binary.BigEndian.Uint64(data[pos+8:])
+			header = 16
+		}
+		if size < header || uint64(pos)+size > uint64(len(data)) {
+			return nil
+		}
+		if bytes.Equal(data[pos+4:pos+8], []byte(kind)) {
+			return data[uint64(pos)+header : uint64(pos)+size]
+		}
+		pos += int(size)
+	}
+	return nil
+}
+
+func roundDuration(d float64) float64 {
+	return float64(int64(d*1000+0.5)) / 1000
+}
@@ -0,0 +1,50 @@
+package extracting
+
+import (
+	"strings"
+
+	"idaman.id/storage/internal/file"
+)
+
+type extractingService struct {
+}
+
+// ExtractMetadata reads metadata based on the file mimetype,
+// malformed or unsupported content results in empty metadata instead of error
+func (s *extractingService) ExtractMetadata(p ExtractMetadataParam) *MetadataEntity {
+	mimetype := file.NormalizeMimeType(p.Mimetype)
+	metadata := &MetadataEntity{}
+
+	switch {
+	case strings.HasPrefix(mimetype, "image/"):
+		metadata.Image = extractImage(p.FileData)
+		var exif *ExifMetadataEntity
+		var orientation int
+		switch mimetype {
+		case "image/jpeg":
+			exif, orientation = extractJpegExif(p.FileData)
+		case "image/png":
+			exif, orientation = extractPngExif(p.FileData)
+		case "image/webp":
+			// the standard library has no webp decoder, the size is read from the container
+			metadata.Image, exif, orientation = extractWebp(p.FileData)
+		}
+		metadata.Exif = exif
+		if metadata.Image != nil {
+			metadata.Image.Orientation = orientation
+		}
+	case mimetype == "application/pdf":
+		metadata.Document = extractPdf(p.FileData)
+	case mimetype == "audio/wave":
+		metadata.Media = extractWav(p.FileData)
+	case mimetype == "audio/flac":
+		metadata.Media = extractFlac(p.FileData)
+	case mimetype == "video/mp4" || mimetype == "audio/mp4" || mimetype == "video/quicktime":
+		metadata.Media = extractMp4(p.FileData)
+	}
+	return metadata
+}
+
+func NewExtractingService() Extractor {
+	return &extractingService{}
+}
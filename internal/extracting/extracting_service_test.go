@@ -0,0 +1,154 @@
+package extracting_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/extracting"
+)
+
+var _ = Describe("Extracting Service", func() {
+	var (
+		extractor extracting.Extractor
+	)
+
+	BeforeEach(func() {
+		extractor = extracting.NewExtractingService()
+	})
+
+	Context("ExtractMetadata method", func() {
+		When("file is png image", func() {
+			It("should return image dimension and color model", func() {
+				img := image.NewGray(image.Rect(0, 0, 40, 30))
+				img.Set(0, 0, color.Gray{128})
+				buf := bytes.Buffer{}
+				Expect(png.Encode(&buf, img)).To(Succeed())
+
+				res := extractor.ExtractMetadata(extracting.ExtractMetadataParam{
+					Mimetype: "image/png",
+					FileData: buf.Bytes(),
+				})
+
+				Expect(res).To(Equal(&extracting.MetadataEntity{
+					Image: &extracting.ImageMetadataEntity{Width: 40, Height: 30, ColorModel: "gray"},
+				}))
+			})
+		})
+
+		When("file is jpeg image with exif", func() {
+			It("should return exif and orientation", func() {
+				res := extractor.ExtractMetadata(extracting.ExtractMetadataParam{
+					Mimetype: "image/jpg",
+					FileData: NewExifJpeg(32, 16),
+				})
+
+				capturedAt := time.Date(2021, 7, 15, 10, 20, 30, 0, time.UTC)
+				Expect(res.Image).To(Equal(&extracting.ImageMetadataEntity{
+					Width: 32, Height: 16, Orientation: 6, ColorModel: "ycbcr",
+				}))
+				Expect(res.Exif.CameraMake).To(Equal("Canon"))
+				Expect(res.Exif.CameraModel).To(Equal("EOS 5D Mark IV"))
+				Expect(res.Exif.CapturedAt.Equal(capturedAt)).To(BeTrue())
+			})
+		})
+
+		When("file is webp image with exif", func() {
+			It("should return canvas size, exif and orientation", func() {
+				res := extractor.ExtractMetadata(extracting.ExtractMetadataParam{
+					Mimetype: "image/webp",
+					FileData: NewExifWebp(256, 160),
+				})
+
+				Expect(res.Image).To(Equal(&extracting.ImageMetadataEntity{
+					Width: 256, Height: 160, Orientation: 6, ColorModel: "unknown",
+				}))
+				Expect(res.Exif.CameraMake).To(Equal("Canon"))
+			})
+		})
+
+		When("file is jpeg image without exif", func() {
+			It("should return image metadata only", func() {
+				res := extractor.ExtractMetadata(extracting.ExtractMetadataParam{
+					Mimetype: "image/jpeg",
+					FileData: NewJpeg(8, 8),
+				})
+
+				Expect(res.Exif).To(BeNil())
+				Expect(res.Image.Orientation).To(Equal(0))
+			})
+		})
+
+		When("file is pdf document", func() {
+			It("should count plain and packed pages", func() {
+				res := extractor.ExtractMetadata(extracting.ExtractMetadataParam{
+					Mimetype: "application/pdf",
+					FileData: NewPdf(2, 3),
+				})
+
+				Expect(res).To(Equal(&extracting.MetadataEntity{
+					Document: &extracting.DocumentMetadataEntity{PageCount: 5},
+				}))
+			})
+		})
+
+		When("file is wav audio", func() {
+			It("should return duration", func() {
+				res := extractor.ExtractMetadata(extracting.ExtractMetadataParam{
+					Mimetype: "audio/wave",
+					FileData: NewWav(8000, 12000),
+				})
+
+				Expect(res.Media).To(Equal(&extracting.MediaMetadataEntity{Duration: 1.5}))
+			})
+		})
+
+		When("file is flac audio", func() {
+			It("should return duration", func() {
+				res := extractor.ExtractMetadata(extracting.ExtractMetadataParam{
+					Mimetype: "audio/x-flac",
+					FileData: NewFlac(44100, 441000),
+				})
+
+				Expect(res.Media).To(Equal(&extracting.MediaMetadataEntity{Duration: 10}))
+			})
+		})
+
+		When("file is mp4 video", func() {
+			It("should return duration", func() {
+				res := extractor.ExtractMetadata(extracting.ExtractMetadataParam{
+					Mimetype: "video/mp4",
+					FileData: NewMp4(1000, 2500),
+				})
+
+				Expect(res.Media).To(Equal(&extracting.MediaMetadataEntity{Duration: 2.5}))
+			})
+		})
+
+		When("file content is malformed", func() {
+			It("should return empty metadata", func() {
+				res := extractor.ExtractMetadata(extracting.ExtractMetadataParam{
+					Mimetype: "video/mp4",
+					FileData: []byte("\x00\x00\x00\xffmoov"),
+				})
+
+				Expect(res.IsEmpty()).To(BeTrue())
+			})
+		})
+
+		When("file type is not supported", func() {
+			It("should return empty metadata", func() {
+				res := extractor.ExtractMetadata(extracting.ExtractMetadataParam{
+					Mimetype: "text/plain",
+					FileData: []byte("hello"),
+				})
+
+				Expect(res.IsEmpty()).To(BeTrue())
+			})
+		})
+	})
+})
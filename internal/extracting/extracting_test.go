@@ -0,0 +1,183 @@
+package extracting_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestExtracting(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Extracting Package")
+}
+
+func NewJpeg(width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{255, 0, 0, 255})
+		}
+	}
+	buf := bytes.Buffer{}
+	Expect(jpeg.Encode(&buf, img, nil)).To(Succeed())
+	return buf.Bytes()
+}
+
+// NewExifJpeg inserts little endian APP1 exif segment holding camera, orientation and capture date
+func NewExifJpeg(width, height int) []byte {
+	segment := append([]byte("Exif\x00\x00"), NewExifTiff()...)
+	header := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(header[2:], uint16(len(segment)+2))
+
+	data := NewJpeg(width, height)
+	res := append([]byte{}, data[:2]...)
+	res = append(res, header...)
+	res = append(res, segment...)
+	return append(res, data[2:]...)
+}
+
+// NewExifWebp creates extended webp container holding the canvas size and the exif chunk
+func NewExifWebp(width, height int) []byte {
+	le := binary.LittleEndian
+	vp8x := []byte{0x08, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	vp8x[4], vp8x[5], vp8x[6] = byte(width-1), byte((width-1)>>8), byte((width-1)>>16)
+	vp8x[7], vp8x[8], vp8x[9] = byte(height-1), byte((height-1)>>8), byte((height-1)>>16)
+	tiff := NewExifTiff()
+
+	body := &bytes.Buffer{}
+	body.WriteString("WEBPVP8X")
+	binary.Write(body, le, uint32(len(vp8x)))
+	body.Write(vp8x)
+	body.WriteString("EXIF")
+	binary.Write(body, le, uint32(len(tiff)))
+	body.Write(tiff)
+	if len(tiff)%2 == 1 {
+		body.WriteByte(0)
+	}
+
+	res := &bytes.Buffer{}
+	res.WriteString("RIFF")
+	binary.Write(res, le, uint32(body.Len()))
+	res.Write(body.Bytes())
+	return res.Bytes()
+}
+
+// NewExifTiff creates little endian tiff structure holding camera, orientation and capture date
+func NewExifTiff() []byte {
+	le := binary.LittleEndian
+	tiff := &bytes.Buffer{}
+	tiff.WriteString("II")
+	binary.Write(tiff, le, uint16(42))
+	binary.Write(tiff, le, uint32(8))
+
+	// ifd0 at 8: 4 entries, exif ifd at 62, values from 80
+	cameraMake := "Canon\x00"
+	model := "EOS 5D Mark IV\x00"
+	binary.Write(tiff, le, uint16(4))
+	writeEntry(tiff, 0x010F, 2, uint32(len(cameraMake)), 80)
+	writeEntry(tiff, 0x0110, 2, uint32(len(model)), uint32(80+len(cameraMake)))
+	writeEntry(tiff, 0x0112, 3, 1, 6)
+	writeEntry(tiff, 0x8769, 4, 1, 62)
+	binary.Write(tiff, le, uint32(0))
+
+	// exif ifd at 62: 1 entry, values from 80 + make + model
+	date := "2021:07:15 10:20:30\x00"
+	binary.Write(tiff, le, uint16(1))
+	writeEntry(tiff, 0x9003, 2, uint32(len(date)), uint32(80+len(cameraMake)+len(model)))
+	binary.Write(tiff, le, uint32(0))
+	tiff.WriteString(cameraMake + model + date)
+	return tiff.Bytes()
+}
+
+func writeEntry(b *bytes.Buffer, tag, kind uint16, count, value uint32) {
+	binary.Write(b, binary.LittleEndian, tag)
+	binary.Write(b, binary.LittleEndian, kind)
+	binary.Write(b, binary.LittleEndian, count)
+	if kind == 3 {
+		binary.Write(b, binary.LittleEndian, uint16(value))
+		binary.Write(b, binary.LittleEndian, uint16(0))
+		return
+	}
+	binary.Write(b, binary.LittleEndian, value)
+}
+
+// NewPdf creates pdf having plain page objects and pages packed into compressed object stream
+func NewPdf(plainPages, packedPages int) []byte {
+	b := &bytes.Buffer{}
+	b.WriteString("%PDF-1.7\n1 0 obj\n<< /Type /Pages /Count 0 >>\nendobj\n")
+	for i := 0; i < plainPages; i++ {
+		fmt.Fprintf(b, "%d 0 obj\n<< /Type /Page /Parent 1 0 R >>\nendobj\n", i+2)
+	}
+	if packedPages > 0 {
+		objects := &bytes.Buffer{}
+		for i := 0; i < packedPages; i++ {
+			objects.WriteString("<</Type/Page/Parent 1 0 R>>\n")
+		}
+		compressed := &bytes.Buffer{}
+		w := zlib.NewWriter(compressed)
+		w.Write(objects.Bytes())
+		w.Close()
+		fmt.Fprintf(b, "99 0 obj\n<< /Type /ObjStm /N %d /Filter /FlateDecode /Length %d >>\nstream\r\n", packedPages, compressed.Len())
+		b.Write(compressed.Bytes())
+		b.WriteString("\r\nendstream\nendobj\n")
+	}
+	b.WriteString("%%EOF\n")
+	return b.Bytes()
+}
+
+func NewWav(byteRate uint32, dataSize int) []byte {
+	le := binary.LittleEndian
+	b := &bytes.Buffer{}
+	b.WriteString("RIFF")
+	binary.Write(b, le, uint32(36+dataSize))
+	b.WriteString("WAVEfmt ")
+	binary.Write(b, le, uint32(16))
+	binary.Write(b, le, uint16(1))
+	binary.Write(b, le, uint16(1))
+	binary.Write(b, le, byteRate)
+	binary.Write(b, le, byteRate)
+	binary.Write(b, le, uint16(1))
+	binary.Write(b, le, uint16(8))
+	b.WriteString("data")
+	binary.Write(b, le, uint32(dataSize))
+	b.Write(make([]byte, dataSize))
+	return b.Bytes()
+}
+
+func NewFlac(sampleRate, totalSamples uint64) []byte {
+	b := &bytes.Buffer{}
+	b.WriteString("fLaC")
+	b.Write([]byte{0x80, 0, 0, 34})
+	b.Write(make([]byte, 10))
+	binary.Write(b, binary.BigEndian, sampleRate<<44|1<<41|15<<36|totalSamples)
+	b.Write(make([]byte, 16))
+	return b.Bytes()
+}
+
+func NewMp4(timescale, duration uint32) []byte {
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:], timescale)
+	binary.BigEndian.PutUint32(mvhd[16:], duration)
+
+	b := &bytes.Buffer{}
+	writeBox(b, "ftyp", []byte("isom\x00\x00\x02\x00isomiso2mp41"))
+	writeBox(b, "mdat", make([]byte, 32))
+	moov := &bytes.Buffer{}
+	writeBox(moov, "mvhd", mvhd)
+	writeBox(b, "moov", moov.Bytes())
+	return b.Bytes()
+}
+
+func writeBox(b *bytes.Buffer, kind string, content []byte) {
+	binary.Write(b, binary.BigEndian, uint32(len(content)+8))
+	b.WriteString(kind)
+	b.Write(content)
+}
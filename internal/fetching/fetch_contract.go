@@ -0,0 +1,19 @@
+package fetching
+
+type Fetcher interface {
+	// Fetch downloads the remote file into memory, private and loopback addresses
+	// are refused unless their host or range is explicitly allowed
+	Fetch(p FetchParam) (*FetchResult, error)
+}
+
+type FetchParam struct {
+	Url string
+}
+
+// FetchResult holds the downloaded content, FileName is taken from `Content-Disposition` or the last url path segment
+// and Mimetype is the `Content-Type` declared by the remote server
+type FetchResult struct {
+	FileName string
+	Mimetype string
+	Data     []byte
+}
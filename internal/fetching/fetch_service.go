@@ -0,0 +1,260 @@
+package fetching
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"idaman.id/storage/internal/config"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/file"
+)
+
+var (
+	errDeniedAddress     = errors.New("denied address")
+	errTooManyRedirects  = errors.New("too many redirects")
+	errUnsupportedScheme = errors.New("unsupported scheme")
+)
+
+// deniedNets complements the net.IP checks with special purpose ranges
+var deniedNets = parseCIDRs(
+	"0.0.0.0/8",
+	"100.64.0.0/10",
+	"192.0.0.0/24",
+	"198.18.0.0/15",
+	"240.0.0.0/4",
+	"64:ff9b::/96",
+)
+
+type fetchService struct {
+	configGetter config.Getter
+}
+
+// allowlist holds `REMOTE_FETCH_ALLOWED_HOSTS`, each item is either a host name, an ip or a cidr range
+type allowlist struct {
+	hosts map[string]bool
+	nets  []*net.IPNet
+}
+
+func (s *fetchService) Fetch(p FetchParam) (*FetchResult, error) {
+	u, err := url.Parse(p.Url)
+	if err != nil || !isHttpScheme(u.Scheme) || u.Hostname() == "" {
+		return nil, newInvalidUrlError("url")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, newInvalidUrlError("url")
+	}
+	req.Header.Set("User-Agent", "goseidon")
+
+	res, err := s.newClient().Do(req)
+	if errors.Is(err, errDeniedAddress) {
+		return nil, newInvalidUrlError("public_address")
+	}
+	if err != nil {
+		return nil, app_error.NewUnavailableError("Remote file")
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, app_error.NewUnavailableError("Remote file")
+	}
+
+	maxSize := int64(s.configGetter.GetInt("REMOTE_FETCH_MAX_SIZE"))
+	if res.ContentLength > maxSize {
+		return nil, newInvalidUrlError("max_size")
+	}
+	fileData, err := io.ReadAll(io.LimitReader(res.Body, maxSize+1))
+	if err != nil {
+		return nil, app_error.NewUnavailableError("Remote file")
+	}
+	if int64(len(fileData)) > maxSize {
+		return nil, newInvalidUrlError("max_size")
+	}
+
+	result := &FetchResult{
+		FileName: fileName(res, fileData),
+		Mimetype: res.Header.Get("Content-Type"),
+		Data:     fileData,
+	}
+	return result, nil
+}
+
+// newClient refuses proxies since they would resolve the address on our behalf,
+// every redirect goes through the same address check when it is dialed
+func (s *fetchService) newClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+	}
+	allowed := newAllowlist(s.configGetter.GetString("REMOTE_FETCH_ALLOWED_HOSTS"))
+	maxRedirects := s.configGetter.GetInt("REMOTE_FETCH_MAX_REDIRECTS")
+
+	transport := &http.Transport{
+		Proxy:               nil,
+		DialContext:         allowed.dialContext(dialer),
+		TLSHandshakeTimeout: 10 * time.Second,
+		DisableKeepAlives:   true,
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   time.Duration(s.configGetter.GetInt("REMOTE_FETCH_TIMEOUT")) * time.Second,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return errTooManyRedirects
+			}
+			if !isHttpScheme(req.URL.Scheme) {
+				return errUnsupportedScheme
+			}
+			return nil
+		},
+	}
+}
+
+// dialContext resolves the host itself and dials the checked ip,
+// so the host can not resolve to another address once it has been checked
+func (a *allowlist) dialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			if !a.isAllowed(host, ip.IP) {
+				return nil, errDeniedAddress
+			}
+		}
+
+		for _, ip := range ips {
+			conn, dialErr := dialer.DialContext(ctx, network, net.JoinHostPort(ip.IP.String(), port))
+			if dialErr == nil {
+				return conn, nil
+			}
+			err = dialErr
+		}
+		if err == nil {
+			err = fmt.Errorf("no address found for %s", host)
+		}
+		return nil, err
+	}
+}
+
+func (a *allowlist) isAllowed(host string, ip net.IP) bool {
+	if a.hosts[strings.ToLower(host)] {
+		return true
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	for _, n := range a.nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return !isDenied(ip)
+}
+
+func isDenied(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return true
+	}
+	for _, n := range deniedNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func isHttpScheme(scheme string) bool {
+	return scheme == "http" || scheme == "https"
+}
+
+// fileName prefers the name sent by the remote server, the extension is derived
+// from the sniffed content when the name does not have one
+func fileName(res *http.Response, fileData []byte) string {
+	name := ""
+	if _, params, err := mime.ParseMediaType(res.Header.Get("Content-Disposition")); err == nil {
+		name = params["filename"]
+	}
+	if name == "" && res.Request != nil {
+		name = res.Request.URL.Path
+	}
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" || name == "" {
+		name = "remote"
+	}
+
+	if ext := file.ExtensionByMimeType(file.DetectMimeType(fileData)); path.Ext(name) == "" && ext != "" {
+		name = fmt.Sprintf("%s.%s", name, ext)
+	}
+	return name
+}
+
+// NewFileInput adapts the downloaded file, the remote `Content-Type` is the declared mimetype
+// so it is compared against the sniffed content like the one of a multipart upload
+func NewFileInput(r *FetchResult) *file.FileInput {
+	contentType := file.NormalizeMimeType(r.Mimetype)
+	if contentType == file.MIME_UNKNOWN {
+		contentType = ""
+	}
+	return file.NewDataInput(r.FileName, contentType, r.Data)
+}
+
+func newAllowlist(value string) *allowlist {
+	a := &allowlist{
+		hosts: map[string]bool{},
+		nets:  []*net.IPNet{},
+	}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		if _, n, err := net.ParseCIDR(item); err == nil {
+			a.nets = append(a.nets, n)
+		} else if ip := net.ParseIP(item); ip != nil {
+			a.nets = append(a.nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+		} else {
+			a.hosts[item] = true
+		}
+	}
+	return a
+}
+
+func parseCIDRs(values ...string) []*net.IPNet {
+	nets := []*net.IPNet{}
+	for _, v := range values {
+		_, n, _ := net.ParseCIDR(v)
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+func newInvalidUrlError(tag string) error {
+	return app_error.NewValidationError([]app_error.ValidationItem{
+		{
+			Field:   "url",
+			Message: fmt.Sprintf("Key: 'url' Error:Field validation for 'url' failed on the '%s' tag", tag),
+		},
+	})
+}
+
+func NewFetchService(cg config.Getter) Fetcher {
+	return &fetchService{
+		configGetter: cg,
+	}
+}
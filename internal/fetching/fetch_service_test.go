@@ -0,0 +1,139 @@
+package fetching_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/fetching"
+)
+
+var _ = Describe("Fetch Service", func() {
+	var (
+		service fetching.Fetcher
+		config  *FakeConfig
+		server  *httptest.Server
+	)
+
+	BeforeEach(func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/files/report.pdf", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte("%PDF-1.4"))
+		})
+		mux.HandleFunc("/download", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Disposition", `attachment; filename="invoice.pdf"`)
+			w.Write([]byte("%PDF-1.4"))
+		})
+		mux.HandleFunc("/image", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("\x89PNG\r\n\x1a\n"))
+		})
+		mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(strings.Repeat("a", 200)))
+		})
+		mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/redirect", http.StatusFound)
+		})
+		mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+		server = httptest.NewServer(mux)
+
+		config = &FakeConfig{values: map[string]interface{}{
+			"REMOTE_FETCH_TIMEOUT":       5,
+			"REMOTE_FETCH_MAX_SIZE":      100,
+			"REMOTE_FETCH_MAX_REDIRECTS": 2,
+			"REMOTE_FETCH_ALLOWED_HOSTS": "127.0.0.1",
+		}}
+		service = fetching.NewFetchService(config)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	When("url is not http", func() {
+		It("should return validation error", func() {
+			res, err := service.Fetch(fetching.FetchParam{Url: "file:///etc/passwd"})
+
+			Expect(res).To(BeNil())
+			Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+		})
+	})
+
+	When("address is private and not allowed", func() {
+		It("should return validation error", func() {
+			config.values["REMOTE_FETCH_ALLOWED_HOSTS"] = ""
+			res, err := service.Fetch(fetching.FetchParam{Url: server.URL + "/files/report.pdf"})
+
+			Expect(res).To(BeNil())
+			Expect(err.(*app_error.ValidationError).Items[0].Message).To(ContainSubstring("public_address"))
+		})
+	})
+
+	When("remote file is larger than the maximum", func() {
+		It("should return validation error", func() {
+			res, err := service.Fetch(fetching.FetchParam{Url: server.URL + "/large"})
+
+			Expect(res).To(BeNil())
+			Expect(err.(*app_error.ValidationError).Items[0].Message).To(ContainSubstring("max_size"))
+		})
+	})
+
+	When("remote server redirects too many times", func() {
+		It("should return unavailable error", func() {
+			res, err := service.Fetch(fetching.FetchParam{Url: server.URL + "/redirect"})
+
+			Expect(res).To(BeNil())
+			Expect(err).To(Equal(app_error.NewUnavailableError("Remote file")))
+		})
+	})
+
+	When("remote file is not found", func() {
+		It("should return unavailable error", func() {
+			res, err := service.Fetch(fetching.FetchParam{Url: server.URL + "/missing"})
+
+			Expect(res).To(BeNil())
+			Expect(err).To(Equal(app_error.NewUnavailableError("Remote file")))
+		})
+	})
+
+	When("remote file is downloaded", func() {
+		It("should name the file after the url path", func() {
+			res, err := service.Fetch(fetching.FetchParam{Url: server.URL + "/files/report.pdf?token=abc"})
+
+			Expect(err).To(BeNil())
+			Expect(res.FileName).To(Equal("report.pdf"))
+			Expect(res.Mimetype).To(Equal("application/pdf"))
+			Expect(res.Data).To(Equal([]byte("%PDF-1.4")))
+		})
+
+		It("should prefer the content disposition name", func() {
+			res, err := service.Fetch(fetching.FetchParam{Url: server.URL + "/download"})
+
+			Expect(err).To(BeNil())
+			Expect(res.FileName).To(Equal("invoice.pdf"))
+		})
+
+		It("should adapt the result as file input", func() {
+			res, err := service.Fetch(fetching.FetchParam{Url: server.URL + "/files/report.pdf"})
+			Expect(err).To(BeNil())
+
+			input := fetching.NewFileInput(res)
+
+			Expect(input.Name).To(Equal("report.pdf"))
+			Expect(input.ContentType).To(Equal("application/pdf"))
+			Expect(input.Size).To(Equal(int64(8)))
+		})
+
+		It("should derive the missing extension from the content", func() {
+			res, err := service.Fetch(fetching.FetchParam{Url: server.URL + "/image"})
+
+			Expect(err).To(BeNil())
+			Expect(res.FileName).To(Equal("image.png"))
+		})
+	})
+})
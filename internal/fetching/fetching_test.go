@@ -0,0 +1,36 @@
+package fetching_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestFetching(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Fetching Package")
+}
+
+type FakeConfig struct {
+	values map[string]interface{}
+}
+
+func (c *FakeConfig) GetString(key string) string {
+	v, _ := c.values[key].(string)
+	return v
+}
+
+func (c *FakeConfig) GetInt(key string) int {
+	v, _ := c.values[key].(int)
+	return v
+}
+
+func (c *FakeConfig) GetBool(key string) bool {
+	v, _ := c.values[key].(bool)
+	return v
+}
+
+func (c *FakeConfig) Get(key string) interface{} {
+	return c.values[key]
+}
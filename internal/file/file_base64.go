@@ -0,0 +1,62 @@
+package file
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+)
+
+var ErrInvalidBase64 = errors.New("invalid base64 content")
+
+// Base64Content is a JSON string holding base64 encoded content or a `data:` uri,
+// it is decoded straight from the raw JSON so the encoded payload is not copied once more
+type Base64Content struct {
+	Data []byte
+	// Mimetype is the one declared by the data uri
+	Mimetype string
+}
+
+func (c *Base64Content) UnmarshalJSON(raw []byte) error {
+	if bytes.Equal(raw, []byte("null")) {
+		return nil
+	}
+	if len(raw) < 2 || raw[0] != '"' || raw[len(raw)-1] != '"' {
+		return ErrInvalidBase64
+	}
+
+	encoded := raw[1 : len(raw)-1]
+	if bytes.IndexByte(encoded, '\\') >= 0 {
+		// escaped characters, e.g: `\/` or `\n`, need the regular string decoding
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return ErrInvalidBase64
+		}
+		encoded = []byte(s)
+	}
+
+	mimetype := ""
+	if bytes.HasPrefix(encoded, []byte("data:")) {
+		i := bytes.IndexByte(encoded, ',')
+		if i < 0 {
+			return ErrInvalidBase64
+		}
+		header := string(encoded[len("data:"):i])
+		if !strings.HasSuffix(header, ";base64") {
+			return ErrInvalidBase64
+		}
+		mimetype = NormalizeMimeType(strings.TrimSuffix(header, ";base64"))
+		encoded = encoded[i+1:]
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, base64.StdEncoding.DecodedLen(len(encoded))))
+	_, err := io.Copy(buf, base64.NewDecoder(base64.StdEncoding, bytes.NewReader(encoded)))
+	if err != nil {
+		return ErrInvalidBase64
+	}
+	c.Data = buf.Bytes()
+	c.Mimetype = mimetype
+	return nil
+}
@@ -0,0 +1,55 @@
+package file_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/file"
+)
+
+var _ = Describe("File Base64", func() {
+	Context("Base64Content UnmarshalJSON function", func() {
+		When("content is plain base64", func() {
+			It("should decode the content", func() {
+				content := file.Base64Content{}
+				err := json.Unmarshal([]byte(`"JVBERi0xLjQ="`), &content)
+
+				Expect(err).To(BeNil())
+				Expect(content.Data).To(Equal([]byte("%PDF-1.4")))
+				Expect(content.Mimetype).To(Equal(""))
+			})
+		})
+
+		When("content is a data uri", func() {
+			It("should decode the content and the mimetype", func() {
+				content := file.Base64Content{}
+				err := json.Unmarshal([]byte(`"data:text/plain;charset=utf-8;base64,aGVsbG8="`), &content)
+
+				Expect(err).To(BeNil())
+				Expect(content.Data).To(Equal([]byte("hello")))
+				Expect(content.Mimetype).To(Equal("text/plain"))
+			})
+		})
+
+		When("content has escaped characters", func() {
+			It("should decode the content", func() {
+				content := file.Base64Content{}
+				err := json.Unmarshal([]byte(`"aGVs\nbG8="`), &content)
+
+				Expect(err).To(BeNil())
+				Expect(content.Data).To(Equal([]byte("hello")))
+			})
+		})
+
+		When("content is not base64", func() {
+			It("should return invalid base64 error", func() {
+				content := file.Base64Content{}
+
+				Expect(json.Unmarshal([]byte(`"not base64!"`), &content)).To(Equal(file.ErrInvalidBase64))
+				Expect(json.Unmarshal([]byte(`"data:text/plain,hello"`), &content)).To(Equal(file.ErrInvalidBase64))
+				Expect(json.Unmarshal([]byte(`123`), &content)).To(Equal(file.ErrInvalidBase64))
+			})
+		})
+	})
+})
@@ -1,18 +1,22 @@
 package file
 
-import "mime/multipart"
+const (
+	VISIBILITY_PUBLIC  = "public"
+	VISIBILITY_PRIVATE = "private"
+)
 
 type FileService interface {
 	FileParser
 	FileRemover
 }
 
+// FileParser reads the file attributes from a transport neutral input
 type FileParser interface {
-	ParseOriginalName(fh *multipart.FileHeader) string
-	ParseName(fh *multipart.FileHeader) string
-	ParseSize(fh *multipart.FileHeader) int64
-	ParseMimeType(fh *multipart.FileHeader) string
-	ParseExtension(fh *multipart.FileHeader) string
+	ParseOriginalName(in *FileInput) string
+	ParseName(in *FileInput) string
+	ParseSize(in *FileInput) int64
+	ParseMimeType(in *FileInput) string
+	ParseExtension(in *FileInput) string
 }
 
 type FileRemover interface {
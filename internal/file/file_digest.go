@@ -0,0 +1,92 @@
+package file
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"io"
+	"strings"
+)
+
+var (
+	ErrInvalidDigest  = errors.New("invalid content digest")
+	ErrDigestMismatch = errors.New("content digest mismatch")
+)
+
+var digestAlgorithms = map[string]func() hash.Hash{
+	"sha-256": sha256.New,
+	"sha-512": sha512.New,
+}
+
+type contentDigest struct {
+	hash     hash.Hash
+	expected []byte
+}
+
+// digestReader hashes the content while it is read and compares the digests once it ends
+type digestReader struct {
+	reader  io.Reader
+	digests []contentDigest
+}
+
+func (r *digestReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	for _, digest := range r.digests {
+		digest.hash.Write(p[:n])
+	}
+	if err != io.EOF {
+		return n, err
+	}
+	for _, digest := range r.digests {
+		if subtle.ConstantTimeCompare(digest.hash.Sum(nil), digest.expected) != 1 {
+			return n, ErrDigestMismatch
+		}
+	}
+	return n, err
+}
+
+// VerifyContentDigest checks the content against a `Content-Digest` header value (RFC 9530),
+// e.g: `sha-256=:base64:`, every sha-256 and sha-512 digest must match while other algorithms are ignored
+func VerifyContentDigest(value string, data []byte) error {
+	reader, err := NewDigestReader(value, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(io.Discard, reader)
+	return err
+}
+
+// NewDigestReader checks the content against a `Content-Digest` header value while it is read,
+// the end of a content which does not match is reported as ErrDigestMismatch instead of io.EOF
+func NewDigestReader(value string, r io.Reader) (io.Reader, error) {
+	digests := []contentDigest{}
+	for _, item := range strings.Split(value, ",") {
+		pair := strings.SplitN(strings.TrimSpace(item), "=", 2)
+		if len(pair) != 2 {
+			return nil, ErrInvalidDigest
+		}
+		newHash, isSupported := digestAlgorithms[strings.ToLower(strings.TrimSpace(pair[0]))]
+		if !isSupported {
+			continue
+		}
+
+		encoded := strings.TrimSpace(pair[1])
+		if len(encoded) < 2 || encoded[0] != ':' || encoded[len(encoded)-1] != ':' {
+			return nil, ErrInvalidDigest
+		}
+		expected, err := base64.StdEncoding.DecodeString(encoded[1 : len(encoded)-1])
+		if err != nil {
+			return nil, ErrInvalidDigest
+		}
+		digests = append(digests, contentDigest{hash: newHash(), expected: expected})
+	}
+
+	if len(digests) == 0 {
+		return nil, ErrInvalidDigest
+	}
+	return &digestReader{reader: r, digests: digests}, nil
+}
@@ -0,0 +1,88 @@
+package file_test
+
+import (
+	"io"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/file"
+)
+
+var _ = Describe("File Digest", func() {
+	Context("VerifyContentDigest function", func() {
+		var (
+			data []byte
+		)
+
+		BeforeEach(func() {
+			data = []byte("hello")
+		})
+
+		When("digest matches the content", func() {
+			It("should return nil", func() {
+				value := "sha-256=:LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=:"
+
+				Expect(file.VerifyContentDigest(value, data)).To(BeNil())
+			})
+		})
+
+		When("unknown algorithm is sent along a supported one", func() {
+			It("should only verify the supported one", func() {
+				value := "md5=:XUFAKrxLKna5cZ2REBfFkg==:, sha-256=:LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=:"
+
+				Expect(file.VerifyContentDigest(value, data)).To(BeNil())
+			})
+		})
+
+		When("digest does not match the content", func() {
+			It("should return mismatch error", func() {
+				value := "sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:"
+
+				Expect(file.VerifyContentDigest(value, data)).To(Equal(file.ErrDigestMismatch))
+			})
+		})
+
+		When("digest is malformed or not supported", func() {
+			It("should return invalid digest error", func() {
+				Expect(file.VerifyContentDigest("sha-256=LPJNul", data)).To(Equal(file.ErrInvalidDigest))
+				Expect(file.VerifyContentDigest("sha-256=:not base64:", data)).To(Equal(file.ErrInvalidDigest))
+				Expect(file.VerifyContentDigest("md5=:XUFAKrxLKna5cZ2REBfFkg==:", data)).To(Equal(file.ErrInvalidDigest))
+			})
+		})
+	})
+
+	Context("NewDigestReader function", func() {
+		When("digest matches the content", func() {
+			It("should read the whole content", func() {
+				reader, err := file.NewDigestReader("sha-256=:LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=:", strings.NewReader("hello"))
+				Expect(err).To(BeNil())
+
+				res, err := io.ReadAll(reader)
+
+				Expect(err).To(BeNil())
+				Expect(res).To(Equal([]byte("hello")))
+			})
+		})
+
+		When("digest does not match the content", func() {
+			It("should return mismatch error at the end of the content", func() {
+				reader, err := file.NewDigestReader("sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:", strings.NewReader("hello"))
+				Expect(err).To(BeNil())
+
+				_, err = io.ReadAll(reader)
+
+				Expect(err).To(Equal(file.ErrDigestMismatch))
+			})
+		})
+
+		When("digest is malformed", func() {
+			It("should return invalid digest error", func() {
+				reader, err := file.NewDigestReader("sha-256=LPJNul", strings.NewReader("hello"))
+
+				Expect(reader).To(BeNil())
+				Expect(err).To(Equal(file.ErrInvalidDigest))
+			})
+		})
+	})
+})
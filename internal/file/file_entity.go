@@ -1,44 +1,100 @@
 package file
 
 import (
+	"bufio"
 	"io"
-	"mime/multipart"
+	"path/filepath"
 )
 
+// SNIFF_SIZE is the amount of leading bytes of a stream read to detect its mimetype
+const SNIFF_SIZE = 4096
+
 type FileEntity struct {
 	OriginalName string
 	Size         int64
 	Data         []byte
+	// Stream gives the content instead of Data when it is stored while it is read,
+	// the leading bytes are already read into DetectedMimetype
+	Stream io.Reader
 
 	Name      string
 	Extension string
 	Mimetype  string
+
+	DetectedMimetype string
 }
 
-func NewFileFromMultipartHeader(fh *multipart.FileHeader, fs FileService) (*FileEntity, error) {
-	mFile, err := fh.Open()
-	if err != nil {
-		return nil, err
+// NewFileFromInput reads the whole content and parses the input the same way for every transport
+func NewFileFromInput(in *FileInput, fp FileParser) (*FileEntity, error) {
+	fileData := []byte{}
+	if in.Reader != nil {
+		if closer, ok := in.Reader.(io.Closer); ok {
+			defer closer.Close()
+		}
+		var err error
+		fileData, err = io.ReadAll(in.Reader)
+		if err != nil {
+			return nil, err
+		}
 	}
-	defer mFile.Close()
 
-	fileData, err := io.ReadAll(mFile)
-	if err != nil {
-		return nil, err
+	size := fp.ParseSize(in)
+	if in.Size < 0 {
+		size = int64(len(fileData))
 	}
 
-	name := fs.ParseName(fh)
-	ext := fs.ParseExtension(fh)
-	mime := fs.ParseMimeType(fh)
-
 	file := &FileEntity{
-		OriginalName: fh.Filename,
-		Size:         fh.Size,
+		OriginalName: in.Name,
+		Size:         size,
 		Data:         fileData,
 
-		Name:      name,
-		Extension: ext,
-		Mimetype:  mime,
+		Name:      fp.ParseName(in),
+		Extension: fp.ParseExtension(in),
+		Mimetype:  fp.ParseMimeType(in),
+
+		DetectedMimetype: DetectMimeType(fileData),
+	}
+	return file, nil
+}
+
+// NewStreamFromInput keeps the content as a stream of the declared size, only the leading bytes are read
+// to detect the mimetype, empty content type is guessed from the extension when the sniffed content agrees with it
+func NewStreamFromInput(in *FileInput, fp FileParser) (*FileEntity, error) {
+	reader := bufio.NewReaderSize(in.Reader, SNIFF_SIZE)
+	head, err := reader.Peek(SNIFF_SIZE)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+
+	detectedMimetype := DetectMimeType(head)
+	if in.ContentType == "" {
+		in.ContentType = GuessMimeType(filepath.Ext(in.Name), detectedMimetype)
+	}
+	file := &FileEntity{
+		OriginalName: in.Name,
+		Size:         fp.ParseSize(in),
+		Stream:       reader,
+
+		Name:      fp.ParseName(in),
+		Extension: fp.ParseExtension(in),
+		Mimetype:  fp.ParseMimeType(in),
+
+		DetectedMimetype: detectedMimetype,
 	}
 	return file, nil
 }
+
+// ReadStream reads the rest of the stream into Data, for contents which are inspected before they are stored
+func (f *FileEntity) ReadStream() error {
+	if f.Stream == nil {
+		return nil
+	}
+	data, err := io.ReadAll(f.Stream)
+	if err != nil {
+		return err
+	}
+	f.Data = data
+	f.Size = int64(len(data))
+	f.Stream = nil
+	return nil
+}
@@ -0,0 +1,75 @@
+package file
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+)
+
+// FileInput is the uploaded file whatever the transport it came from,
+// e.g: multipart form, raw request body, JSON body, remote url, command line or message queue
+type FileInput struct {
+	// Name is the client file name including its extension
+	Name string
+	// ContentType is the declared mimetype, empty when the transport does not declare one
+	ContentType string
+	// Size is the declared content size, negative when it is not known upfront
+	Size int64
+	// Reader gives the content, it is closed once read when it is an io.Closer
+	Reader io.Reader
+}
+
+// NewMultipartInput adapts a `multipart/form-data` file, the content type is the one of the part
+func NewMultipartInput(fh *multipart.FileHeader) (*FileInput, error) {
+	mFile, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := ""
+	if values := fh.Header["Content-Type"]; len(values) > 0 {
+		contentType = values[0]
+	}
+	input := &FileInput{
+		Name:        fh.Filename,
+		ContentType: contentType,
+		Size:        fh.Size,
+		Reader:      mFile,
+	}
+	return input, nil
+}
+
+// NewRawInput adapts a content streamed as is, e.g: a raw request body,
+// size is negative when the transport does not tell it
+func NewRawInput(name, contentType string, size int64, r io.Reader) *FileInput {
+	return &FileInput{
+		Name:        name,
+		ContentType: contentType,
+		Size:        size,
+		Reader:      r,
+	}
+}
+
+// NewDataInput adapts content already in memory, e.g: an archive entry,
+// empty content type is guessed from the extension when the sniffed content agrees with it
+func NewDataInput(name, contentType string, data []byte) *FileInput {
+	if contentType == "" {
+		contentType = GuessMimeType(filepath.Ext(name), DetectMimeType(data))
+	}
+	return &FileInput{
+		Name:        name,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Reader:      bytes.NewReader(data),
+	}
+}
+
+// NewBase64Input adapts content sent inside a JSON body,
+// empty content type falls back to the one of the data uri
+func NewBase64Input(name, contentType string, content *Base64Content) *FileInput {
+	if contentType == "" {
+		contentType = content.Mimetype
+	}
+	return NewDataInput(name, contentType, content.Data)
+}
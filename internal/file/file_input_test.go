@@ -0,0 +1,105 @@
+package file_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/file"
+	"idaman.id/storage/internal/text"
+)
+
+var _ = Describe("File Input", func() {
+	var (
+		fileService file.FileService
+	)
+
+	BeforeEach(func() {
+		fileService = file.NewFileService(text.NewTextService())
+	})
+
+	Context("NewMultipartInput function", func() {
+		It("should take the part file name and content type", func() {
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			header := textproto.MIMEHeader{}
+			header.Set("Content-Disposition", `form-data; name="file"; filename="Report.pdf"`)
+			header.Set("Content-Type", "application/pdf")
+			part, _ := writer.CreatePart(header)
+			part.Write([]byte("%PDF-1.4"))
+			writer.Close()
+
+			form, err := multipart.NewReader(body, writer.Boundary()).ReadForm(1024)
+			Expect(err).To(BeNil())
+
+			input, err := file.NewMultipartInput(form.File["file"][0])
+			Expect(err).To(BeNil())
+			res, err := file.NewFileFromInput(input, fileService)
+
+			Expect(err).To(BeNil())
+			Expect(res.OriginalName).To(Equal("Report.pdf"))
+			Expect(res.Name).To(Equal("report"))
+			Expect(res.Extension).To(Equal("pdf"))
+			Expect(res.Mimetype).To(Equal("application/pdf"))
+			Expect(res.Size).To(Equal(int64(8)))
+			Expect(res.Data).To(Equal([]byte("%PDF-1.4")))
+		})
+	})
+
+	Context("NewRawInput function", func() {
+		When("size is not known", func() {
+			It("should take the size of the content", func() {
+				input := file.NewRawInput("notes.txt", "text/plain", -1, strings.NewReader("hello"))
+				res, err := file.NewFileFromInput(input, fileService)
+
+				Expect(err).To(BeNil())
+				Expect(res.Size).To(Equal(int64(5)))
+				Expect(res.Mimetype).To(Equal("text/plain"))
+				Expect(res.DetectedMimetype).To(Equal(file.MIME_TEXT))
+			})
+		})
+
+		When("content is kept as a stream", func() {
+			It("should only sniff the leading bytes", func() {
+				content := "%PDF-1.4" + strings.Repeat(" ", file.SNIFF_SIZE)
+				input := file.NewRawInput("Report.pdf", "", int64(len(content)), strings.NewReader(content))
+				res, err := file.NewStreamFromInput(input, fileService)
+
+				Expect(err).To(BeNil())
+				Expect(res.Data).To(BeNil())
+				Expect(res.Size).To(Equal(int64(len(content))))
+				Expect(res.Mimetype).To(Equal("application/pdf"))
+				Expect(res.DetectedMimetype).To(Equal("application/pdf"))
+
+				err = res.ReadStream()
+
+				Expect(err).To(BeNil())
+				Expect(res.Stream).To(BeNil())
+				Expect(string(res.Data)).To(Equal(content))
+			})
+		})
+	})
+
+	Context("NewDataInput function", func() {
+		When("content type is not declared", func() {
+			It("should guess the content type", func() {
+				input := file.NewDataInput("data.csv", "", []byte("a,b\n1,2\n"))
+
+				Expect(input.ContentType).To(Equal("text/csv"))
+				Expect(input.Size).To(Equal(int64(8)))
+			})
+		})
+	})
+
+	Context("NewBase64Input function", func() {
+		It("should fall back to the data uri mimetype", func() {
+			content := &file.Base64Content{Data: []byte("%PDF-1.4"), Mimetype: "application/pdf"}
+
+			Expect(file.NewBase64Input("report", "", content).ContentType).To(Equal("application/pdf"))
+			Expect(file.NewBase64Input("report", "application/x-pdf", content).ContentType).To(Equal("application/x-pdf"))
+		})
+	})
+})
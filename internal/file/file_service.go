@@ -1,7 +1,6 @@
 package file
 
 import (
-	"mime/multipart"
 	"path/filepath"
 	"strings"
 
@@ -12,50 +11,44 @@ type fileService struct {
 	slugger text.Slugger
 }
 
-func (s *fileService) ParseOriginalName(fh *multipart.FileHeader) string {
-	if fh == nil {
+func (s *fileService) ParseOriginalName(in *FileInput) string {
+	if in == nil {
 		return ""
 	}
-	fn := strings.ToLower(fh.Filename)
+	fn := strings.ToLower(in.Name)
 	return fn
 }
 
-func (s *fileService) ParseName(fh *multipart.FileHeader) string {
-	fn := s.ParseOriginalName(fh)
+func (s *fileService) ParseName(in *FileInput) string {
+	fn := s.ParseOriginalName(in)
 	fileNameWithoutExtension := s.RemoveFileExtension(fn)
 	fn = s.slugger.Slugify(fileNameWithoutExtension)
 	return fn
 }
 
-func (s *fileService) ParseSize(fh *multipart.FileHeader) int64 {
-	if fh == nil {
+func (s *fileService) ParseSize(in *FileInput) int64 {
+	if in == nil {
 		return 0
 	}
-	size := fh.Size
+	size := in.Size
 	if size < 0 {
 		return 0
 	}
 	return size
 }
 
-func (s *fileService) ParseMimeType(fh *multipart.FileHeader) string {
-	if fh == nil {
+func (s *fileService) ParseMimeType(in *FileInput) string {
+	if in == nil {
 		return ""
 	}
-	contentType, isAvailable := fh.Header["Content-Type"]
-	isMimeAvailable := isAvailable && len(contentType) > 0
-
-	if !isMimeAvailable {
-		return ""
-	}
-	return contentType[0]
+	return in.ContentType
 }
 
-func (s *fileService) ParseExtension(fh *multipart.FileHeader) string {
-	if fh == nil {
+func (s *fileService) ParseExtension(in *FileInput) string {
+	if in == nil {
 		return ""
 	}
-	ext := filepath.Ext(fh.Filename)
+	ext := filepath.Ext(in.Name)
 	extWithoutDot := strings.ReplaceAll(ext, ".", "")
 	lExt := strings.ToLower(extWithoutDot)
 	return lExt
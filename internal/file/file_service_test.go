@@ -1,8 +1,6 @@
 package file_test
 
 import (
-	"mime/multipart"
-
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"idaman.id/storage/internal/file"
@@ -22,50 +20,50 @@ var _ = Describe("File Service", func() {
 	Context("ParseOriginalName function", func() {
 
 		var (
-			fileHeader *multipart.FileHeader
+			input *file.FileInput
 		)
 
 		BeforeEach(func() {
-			fileHeader = &multipart.FileHeader{
-				Filename: "image.jpeg",
+			input = &file.FileInput{
+				Name: "image.jpeg",
 			}
 		})
 
-		When("fileHeader is null", func() {
+		When("input is null", func() {
 			It("should return empty string", func() {
-				fileHeader = nil
-				res := fileService.ParseOriginalName(fileHeader)
+				input = nil
+				res := fileService.ParseOriginalName(input)
 
 				Expect(res).To(Equal(""))
 			})
 		})
 
-		When("fileHeader contain empty file name", func() {
+		When("input contain empty file name", func() {
 			It("should return empty string", func() {
-				fileHeader = &multipart.FileHeader{
-					Filename: "",
+				input = &file.FileInput{
+					Name: "",
 				}
-				res := fileService.ParseOriginalName(fileHeader)
+				res := fileService.ParseOriginalName(input)
 
 				Expect(res).To(Equal(""))
 			})
 		})
 
-		When("fileHeader is filled", func() {
+		When("input is filled", func() {
 			It("should return original file name", func() {
-				res := fileService.ParseOriginalName(fileHeader)
+				res := fileService.ParseOriginalName(input)
 
 				Expect(res).To(Equal("image.jpeg"))
 			})
 		})
 
-		When("fileHeader is containing capitcal word", func() {
+		When("input is containing capitcal word", func() {
 			It("should return lowercased file name", func() {
-				fileHeader = &multipart.FileHeader{
-					Filename: "Blue Dolpin.jpeg",
+				input = &file.FileInput{
+					Name: "Blue Dolpin.jpeg",
 				}
 
-				res := fileService.ParseOriginalName(fileHeader)
+				res := fileService.ParseOriginalName(input)
 
 				Expect(res).To(Equal("blue dolpin.jpeg"))
 			})
@@ -75,49 +73,49 @@ var _ = Describe("File Service", func() {
 
 	Context("ParseName function", func() {
 		var (
-			fileHeader *multipart.FileHeader
+			input *file.FileInput
 		)
 
 		BeforeEach(func() {
-			fileHeader = &multipart.FileHeader{
-				Filename: "image.jpeg",
+			input = &file.FileInput{
+				Name: "image.jpeg",
 			}
 		})
 
-		When("fileHeader is null", func() {
+		When("input is null", func() {
 			It("should return empty string", func() {
-				fileHeader = nil
-				res := fileService.ParseName(fileHeader)
+				input = nil
+				res := fileService.ParseName(input)
 
 				Expect(res).To(Equal(""))
 			})
 		})
 
-		When("fileHeader contain empty file name", func() {
+		When("input contain empty file name", func() {
 			It("should return empty string", func() {
-				fileHeader = &multipart.FileHeader{
-					Filename: "",
+				input = &file.FileInput{
+					Name: "",
 				}
-				res := fileService.ParseName(fileHeader)
+				res := fileService.ParseName(input)
 
 				Expect(res).To(Equal(""))
 			})
 		})
 
-		When("fileHeader contain file extension", func() {
+		When("input contain file extension", func() {
 			It("should return file name without extension", func() {
-				res := fileService.ParseName(fileHeader)
+				res := fileService.ParseName(input)
 
 				Expect(res).To(Equal("image"))
 			})
 		})
 
-		When("fileHeader contain multiple word seperated by spaces", func() {
+		When("input contain multiple word seperated by spaces", func() {
 			It("should return slugged file name with dashes", func() {
-				fileHeader = &multipart.FileHeader{
-					Filename: "Blue Dolpin.jpeg",
+				input = &file.FileInput{
+					Name: "Blue Dolpin.jpeg",
 				}
-				res := fileService.ParseName(fileHeader)
+				res := fileService.ParseName(input)
 
 				Expect(res).To(Equal("blue-dolpin"))
 			})
@@ -126,38 +124,38 @@ var _ = Describe("File Service", func() {
 
 	Context("ParseSize function", func() {
 		var (
-			fileHeader *multipart.FileHeader
+			input *file.FileInput
 		)
 
 		BeforeEach(func() {
-			fileHeader = &multipart.FileHeader{
+			input = &file.FileInput{
 				Size: 23456,
 			}
 		})
 
-		When("fileHeader is null", func() {
+		When("input is null", func() {
 			It("should return 0 number", func() {
-				fileHeader = nil
-				res := fileService.ParseSize(fileHeader)
+				input = nil
+				res := fileService.ParseSize(input)
 
 				Expect(res).To(Equal(int64(0)))
 			})
 		})
 
-		When("fileHeader is filled", func() {
+		When("input is filled", func() {
 			It("should return original file size", func() {
-				res := fileService.ParseSize(fileHeader)
+				res := fileService.ParseSize(input)
 
 				Expect(res).To(Equal(int64(23456)))
 			})
 		})
 
-		When("fileHeader is contain negative file size", func() {
+		When("input is contain negative file size", func() {
 			It("should return 0 number", func() {
-				fileHeader = &multipart.FileHeader{
+				input = &file.FileInput{
 					Size: -928374,
 				}
-				res := fileService.ParseSize(fileHeader)
+				res := fileService.ParseSize(input)
 
 				Expect(res).To(Equal(int64(0)))
 			})
@@ -166,69 +164,36 @@ var _ = Describe("File Service", func() {
 
 	Context("ParseMimeType function", func() {
 		var (
-			fileHeader *multipart.FileHeader
+			input *file.FileInput
 		)
 
 		BeforeEach(func() {
-			fileHeader = &multipart.FileHeader{
-				Header: map[string][]string{
-					"Content-Type": {
-						"image/jpeg",
-					},
-				},
+			input = &file.FileInput{
+				ContentType: "image/jpeg",
 			}
 		})
 
-		When("fileHeader is null", func() {
-			It("should return empty string", func() {
-				fileHeader = nil
-				res := fileService.ParseMimeType(fileHeader)
-
-				Expect(res).To(Equal(""))
-			})
-		})
-
-		When("fileHeader has no header", func() {
+		When("input is null", func() {
 			It("should return empty string", func() {
-				fileHeader = &multipart.FileHeader{}
-				res := fileService.ParseMimeType(fileHeader)
+				input = nil
+				res := fileService.ParseMimeType(input)
 
 				Expect(res).To(Equal(""))
 			})
 		})
 
-		When("fileHeader has no content type header", func() {
+		When("input has no content type", func() {
 			It("should return empty string", func() {
-				fileHeader = &multipart.FileHeader{
-					Header: map[string][]string{
-						"Content-Disposition": {},
-					},
-				}
-				res := fileService.ParseMimeType(fileHeader)
+				input = &file.FileInput{}
+				res := fileService.ParseMimeType(input)
 
 				Expect(res).To(Equal(""))
 			})
 		})
 
-		When("fileHeader contain more than one content type value", func() {
-			It("should return first mime type", func() {
-				fileHeader = &multipart.FileHeader{
-					Header: map[string][]string{
-						"Content-Type": {
-							"image/jpeg",
-							"image/jpg",
-						},
-					},
-				}
-				res := fileService.ParseMimeType(fileHeader)
-
-				Expect(res).To(Equal("image/jpeg"))
-			})
-		})
-
-		When("fileHeader has content type", func() {
+		When("input has content type", func() {
 			It("should return mime type", func() {
-				res := fileService.ParseMimeType(fileHeader)
+				res := fileService.ParseMimeType(input)
 
 				Expect(res).To(Equal("image/jpeg"))
 			})
@@ -238,52 +203,52 @@ var _ = Describe("File Service", func() {
 
 	Context("ParseExtension function", func() {
 		var (
-			fileHeader *multipart.FileHeader
+			input *file.FileInput
 		)
 
 		BeforeEach(func() {
-			fileHeader = &multipart.FileHeader{
-				Filename: "Blue Dolpin.jpeg",
+			input = &file.FileInput{
+				Name: "Blue Dolpin.jpeg",
 			}
 		})
 
-		When("fileHeader is null", func() {
+		When("input is null", func() {
 			It("should return empty string", func() {
-				fileHeader = nil
-				res := fileService.ParseExtension(fileHeader)
+				input = nil
+				res := fileService.ParseExtension(input)
 
 				Expect(res).To(Equal(""))
 			})
 		})
 
-		When("fileHeader contain empty file name", func() {
+		When("input contain empty file name", func() {
 			It("should return empty string", func() {
-				fileHeader = &multipart.FileHeader{
-					Filename: "",
+				input = &file.FileInput{
+					Name: "",
 				}
-				res := fileService.ParseExtension(fileHeader)
+				res := fileService.ParseExtension(input)
 
 				Expect(res).To(Equal(""))
 			})
 		})
 
-		When("fileHeader contain multiple dot file name", func() {
+		When("input contain multiple dot file name", func() {
 			It("should return last suffix extension", func() {
-				fileHeader = &multipart.FileHeader{
-					Filename: "Blue.Dolpin.mkv.jpeg",
+				input = &file.FileInput{
+					Name: "Blue.Dolpin.mkv.jpeg",
 				}
-				res := fileService.ParseExtension(fileHeader)
+				res := fileService.ParseExtension(input)
 
 				Expect(res).To(Equal("jpeg"))
 			})
 		})
 
-		When("fileHeader contain uppercase file extension", func() {
+		When("input contain uppercase file extension", func() {
 			It("should return lowercased file extension", func() {
-				fileHeader = &multipart.FileHeader{
-					Filename: "Image.JPEG",
+				input = &file.FileInput{
+					Name: "Image.JPEG",
 				}
-				res := fileService.ParseExtension(fileHeader)
+				res := fileService.ParseExtension(input)
 
 				Expect(res).To(Equal("jpeg"))
 			})
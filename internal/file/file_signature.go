@@ -0,0 +1,280 @@
+package file
+
+import (
+	"bytes"
+	"encoding/binary"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const (
+	MIME_UNKNOWN     = "application/octet-stream"
+	MIME_TEXT        = "text/plain"
+	MIME_ZIP         = "application/zip"
+	MIME_OLE         = "application/x-ole-storage"
+	MIME_ELF         = "application/x-executable"
+	MIME_PE          = "application/x-msdownload"
+	MIME_MACH_O      = "application/x-mach-binary"
+	MIME_SHELLSCRIPT = "text/x-shellscript"
+)
+
+type fileSignature struct {
+	offset   int
+	magic    []byte
+	mimetype string
+	// isValid confirms a magic which is too short to be told apart from text on its own
+	isValid func(data []byte) bool
+}
+
+// signatures complements http.DetectContentType which does not know executables and a few containers
+var signatures = []fileSignature{
+	{0, []byte("\x7fELF"), MIME_ELF, nil},
+	{0, []byte("MZ"), MIME_PE, isPortableExecutable},
+	{0, []byte("\xfe\xed\xfa\xce"), MIME_MACH_O, nil},
+	{0, []byte("\xfe\xed\xfa\xcf"), MIME_MACH_O, nil},
+	{0, []byte("\xce\xfa\xed\xfe"), MIME_MACH_O, nil},
+	{0, []byte("\xcf\xfa\xed\xfe"), MIME_MACH_O, nil},
+	{0, []byte("#!"), MIME_SHELLSCRIPT, nil},
+	{0, []byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1"), MIME_OLE, nil},
+	{0, []byte("7z\xbc\xaf\x27\x1c"), "application/x-7z-compressed", nil},
+	{0, []byte("II*\x00"), "image/tiff", nil},
+	{0, []byte("MM\x00*"), "image/tiff", nil},
+	{4, []byte("ftypheic"), "image/heic", nil},
+	{4, []byte("ftypheix"), "image/heic", nil},
+	{4, []byte("ftypmif1"), "image/heif", nil},
+	{4, []byte("ftypavif"), "image/avif", nil},
+	{4, []byte("ftypqt  "), "video/quicktime", nil},
+	{0, []byte("fLaC"), "audio/flac", nil},
+	{257, []byte("ustar"), "application/x-tar", nil},
+}
+
+var mimeAliases = map[string]string{
+	"image/jpg":                    "image/jpeg",
+	"image/pjpeg":                  "image/jpeg",
+	"image/x-png":                  "image/png",
+	"image/x-ms-bmp":               "image/bmp",
+	"image/vnd.microsoft.icon":     "image/x-icon",
+	"audio/mp3":                    "audio/mpeg",
+	"audio/x-wav":                  "audio/wave",
+	"audio/wav":                    "audio/wave",
+	"audio/x-flac":                 "audio/flac",
+	"application/x-zip-compressed": MIME_ZIP,
+	"application/gzip":             "application/x-gzip",
+	"application/x-pdf":            "application/pdf",
+	"application/vnd.rar":          "application/x-rar-compressed",
+	"application/x-msdos-program":  MIME_PE,
+	"application/x-dosexec":        MIME_PE,
+	"application/x-sh":             MIME_SHELLSCRIPT,
+	"text/xml":                     "application/xml",
+}
+
+var extensionMimes = map[string][]string{
+	"jpg":  {"image/jpeg"},
+	"jpeg": {"image/jpeg"},
+	"png":  {"image/png"},
+	"gif":  {"image/gif"},
+	"webp": {"image/webp"},
+	"bmp":  {"image/bmp"},
+	"ico":  {"image/x-icon"},
+	"tif":  {"image/tiff"},
+	"tiff": {"image/tiff"},
+	"heic": {"image/heic", "image/heif"},
+	"heif": {"image/heif", "image/heic"},
+	"avif": {"image/avif"},
+	"svg":  {"image/svg+xml"},
+	"pdf":  {"application/pdf"},
+	"zip":  {MIME_ZIP},
+	"gz":   {"application/x-gzip"},
+	"tgz":  {"application/x-gzip"},
+	"tar":  {"application/x-tar"},
+	"rar":  {"application/x-rar-compressed"},
+	"7z":   {"application/x-7z-compressed"},
+	"mp3":  {"audio/mpeg"},
+	"wav":  {"audio/wave"},
+	"flac": {"audio/flac"},
+	"ogg":  {"application/ogg", "audio/ogg", "video/ogg"},
+	"mp4":  {"video/mp4", "audio/mp4"},
+	"m4a":  {"audio/mp4", "video/mp4"},
+	"webm": {"video/webm", "audio/webm"},
+	"avi":  {"video/avi"},
+	"mov":  {"video/quicktime"},
+	"txt":  {MIME_TEXT},
+	"csv":  {"text/csv"},
+	"json": {"application/json"},
+	"xml":  {"application/xml"},
+	"html": {"text/html"},
+	"htm":  {"text/html"},
+	"doc":  {"application/msword"},
+	"xls":  {"application/vnd.ms-excel"},
+	"ppt":  {"application/vnd.ms-powerpoint"},
+	"docx": {"application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+	"xlsx": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+	"pptx": {"application/vnd.openxmlformats-officedocument.presentationml.presentation"},
+	"odt":  {"application/vnd.oasis.opendocument.text"},
+	"ods":  {"application/vnd.oasis.opendocument.spreadsheet"},
+	"epub": {"application/epub+zip"},
+	"apk":  {"application/vnd.android.package-archive"},
+	"jar":  {"application/java-archive"},
+	"exe":  {MIME_PE},
+	"dll":  {MIME_PE},
+	"msi":  {MIME_OLE},
+	"sh":   {MIME_SHELLSCRIPT},
+}
+
+// DetectMimeType sniffs the mimetype from the leading bytes of the file content
+func DetectMimeType(data []byte) string {
+	for _, sig := range signatures {
+		end := sig.offset + len(sig.magic)
+		if len(data) < end || !bytes.Equal(data[sig.offset:end], sig.magic) {
+			continue
+		}
+		if sig.isValid == nil || sig.isValid(data) {
+			return sig.mimetype
+		}
+	}
+	return NormalizeMimeType(http.DetectContentType(data))
+}
+
+// NormalizeMimeType strips parameters and resolves known aliases
+func NormalizeMimeType(mimetype string) string {
+	mediaType := strings.ToLower(strings.TrimSpace(mimetype))
+	if i := strings.Index(mediaType, ";"); i >= 0 {
+		mediaType = strings.TrimSpace(mediaType[:i])
+	}
+	alias, isAlias := mimeAliases[mediaType]
+	if isAlias {
+		return alias
+	}
+	return mediaType
+}
+
+// IsMimeTypeCompatible tells whether the declared mimetype can be the sniffed one,
+// unknown content never contradicts while textual and zip based formats are matched by family
+func IsMimeTypeCompatible(declared, detected string) bool {
+	declared = NormalizeMimeType(declared)
+	detected = NormalizeMimeType(detected)
+
+	if detected == "" || detected == MIME_UNKNOWN || declared == detected {
+		return true
+	}
+	if declared == MIME_UNKNOWN || declared == "" {
+		return !isExecutable(detected)
+	}
+
+	switch detected {
+	case MIME_TEXT:
+		return isTextual(declared)
+	case MIME_SHELLSCRIPT:
+		// a script is only refused when it is not declared as text, e.g: a python or a shell source
+		return isTextual(declared)
+	case "application/xml", "text/html":
+		return isTextual(declared) && declared != MIME_TEXT
+	case MIME_ZIP:
+		return isZipBased(declared)
+	case MIME_OLE:
+		return isOleBased(declared)
+	case "audio/mp4", "video/mp4":
+		return declared == "audio/mp4" || declared == "video/mp4"
+	case "application/ogg":
+		return strings.HasSuffix(declared, "/ogg")
+	case "video/webm":
+		return strings.HasSuffix(declared, "/webm")
+	}
+	return false
+}
+
+// IsExtensionCompatible tells whether the file extension can hold the sniffed mimetype,
+// unknown extension only refuses binary executables, a script is judged by its declared mimetype
+func IsExtensionCompatible(ext, detected string) bool {
+	ext = strings.ToLower(strings.TrimPrefix(ext, "."))
+	mimetypes, isAvailable := extensionMimes[ext]
+	if !isAvailable {
+		byExtension := mime.TypeByExtension("." + ext)
+		if byExtension == "" {
+			detected = NormalizeMimeType(detected)
+			return detected == MIME_SHELLSCRIPT || !isExecutable(detected)
+		}
+		mimetypes = []string{byExtension}
+	}
+
+	for _, mimetype := range mimetypes {
+		if IsMimeTypeCompatible(mimetype, detected) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtensionByMimeType is the shortest known extension whose main mimetype is the given one,
+// empty when the mimetype is unknown
+func ExtensionByMimeType(mimetype string) string {
+	mimetype = NormalizeMimeType(mimetype)
+	res := ""
+	for ext, mimetypes := range extensionMimes {
+		if mimetypes[0] != mimetype {
+			continue
+		}
+		if res == "" || len(ext) < len(res) || (len(ext) == len(res) && ext < res) {
+			res = ext
+		}
+	}
+	return res
+}
+
+// GuessMimeType declares the mimetype of content which came without one, e.g: an archive entry,
+// the extension mimetype is preferred when the sniffed content agrees with it
+func GuessMimeType(ext, detected string) string {
+	ext = strings.ToLower(strings.TrimPrefix(ext, "."))
+	mimetypes := extensionMimes[ext]
+	if byExtension := mime.TypeByExtension("." + ext); byExtension != "" {
+		mimetypes = append(mimetypes, NormalizeMimeType(byExtension))
+	}
+	for _, mimetype := range mimetypes {
+		if IsMimeTypeCompatible(mimetype, detected) {
+			return mimetype
+		}
+	}
+	if detected == "" {
+		return MIME_UNKNOWN
+	}
+	return detected
+}
+
+// isPortableExecutable expects the dos header to point at the "PE\0\0" signature,
+// so a text merely starting with "MZ" is not taken as an executable
+func isPortableExecutable(data []byte) bool {
+	if len(data) < 0x40 {
+		return false
+	}
+	offset := int64(binary.LittleEndian.Uint32(data[0x3C:]))
+	return offset+4 <= int64(len(data)) && bytes.Equal(data[offset:offset+4], []byte("PE\x00\x00"))
+}
+
+func isExecutable(mimetype string) bool {
+	return mimetype == MIME_ELF || mimetype == MIME_PE || mimetype == MIME_MACH_O || mimetype == MIME_SHELLSCRIPT
+}
+
+func isTextual(mimetype string) bool {
+	return strings.HasPrefix(mimetype, "text/") ||
+		strings.HasSuffix(mimetype, "+xml") ||
+		strings.HasSuffix(mimetype, "+json") ||
+		mimetype == "application/json" ||
+		mimetype == "application/xml" ||
+		mimetype == "application/javascript"
+}
+
+func isZipBased(mimetype string) bool {
+	return strings.HasPrefix(mimetype, "application/vnd.openxmlformats-officedocument.") ||
+		strings.HasPrefix(mimetype, "application/vnd.oasis.opendocument.") ||
+		strings.HasSuffix(mimetype, "+zip") ||
+		mimetype == "application/java-archive" ||
+		mimetype == "application/vnd.android.package-archive"
+}
+
+func isOleBased(mimetype string) bool {
+	return mimetype == "application/msword" ||
+		mimetype == "application/vnd.ms-excel" ||
+		mimetype == "application/vnd.ms-powerpoint" ||
+		mimetype == "application/vnd.ms-outlook"
+}
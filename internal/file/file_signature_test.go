@@ -0,0 +1,168 @@
+package file_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/file"
+)
+
+var _ = Describe("File Signature", func() {
+
+	Context("DetectMimeType function", func() {
+		When("content is an image", func() {
+			It("should return image mimetype", func() {
+				png := []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+				res := file.DetectMimeType(png)
+
+				Expect(res).To(Equal("image/png"))
+			})
+		})
+
+		When("content is a media container", func() {
+			It("should return media mimetype", func() {
+				Expect(file.DetectMimeType([]byte("fLaC\x00\x00\x00\x22"))).To(Equal("audio/flac"))
+				Expect(file.DetectMimeType([]byte("\x00\x00\x00\x14ftypqt  \x00\x00\x02\x00"))).To(Equal("video/quicktime"))
+			})
+		})
+
+		When("content is an executable", func() {
+			It("should return executable mimetype", func() {
+				Expect(file.DetectMimeType([]byte("\x7fELF\x02\x01\x01"))).To(Equal(file.MIME_ELF))
+				Expect(file.DetectMimeType(NewPortableExecutable())).To(Equal(file.MIME_PE))
+				Expect(file.DetectMimeType([]byte("\xcf\xfa\xed\xfe\x07"))).To(Equal(file.MIME_MACH_O))
+				Expect(file.DetectMimeType([]byte("#!/bin/sh\nrm -rf /"))).To(Equal(file.MIME_SHELLSCRIPT))
+			})
+		})
+
+		When("content starts with dos magic without pe header", func() {
+			It("should not return executable mimetype", func() {
+				Expect(file.DetectMimeType([]byte("MZ is the postal code prefix of the region"))).To(Equal(file.MIME_TEXT))
+				Expect(file.DetectMimeType(NewPortableExecutable()[:0x80])).NotTo(Equal(file.MIME_PE))
+			})
+		})
+
+		When("content is a plain text", func() {
+			It("should return text mimetype without charset", func() {
+				res := file.DetectMimeType([]byte("hello world"))
+
+				Expect(res).To(Equal(file.MIME_TEXT))
+			})
+		})
+
+		When("content is unknown", func() {
+			It("should return octet stream", func() {
+				res := file.DetectMimeType([]byte{0x00, 0x01, 0x02, 0x03})
+
+				Expect(res).To(Equal(file.MIME_UNKNOWN))
+			})
+		})
+	})
+
+	Context("IsMimeTypeCompatible function", func() {
+		When("declared mimetype is an alias of detected mimetype", func() {
+			It("should return true", func() {
+				res := file.IsMimeTypeCompatible("image/jpg", "image/jpeg")
+
+				Expect(res).To(BeTrue())
+			})
+		})
+
+		When("declared mimetype contains parameter", func() {
+			It("should return true", func() {
+				res := file.IsMimeTypeCompatible("text/csv; charset=utf-8", file.MIME_TEXT)
+
+				Expect(res).To(BeTrue())
+			})
+		})
+
+		When("declared mimetype is zip based document", func() {
+			It("should return true", func() {
+				res := file.IsMimeTypeCompatible("application/vnd.openxmlformats-officedocument.wordprocessingml.document", file.MIME_ZIP)
+
+				Expect(res).To(BeTrue())
+			})
+		})
+
+		When("detected content is unknown", func() {
+			It("should return true", func() {
+				res := file.IsMimeTypeCompatible("image/png", file.MIME_UNKNOWN)
+
+				Expect(res).To(BeTrue())
+			})
+		})
+
+		When("declared mimetype is generic and detected content is executable", func() {
+			It("should return false", func() {
+				res := file.IsMimeTypeCompatible(file.MIME_UNKNOWN, file.MIME_PE)
+
+				Expect(res).To(BeFalse())
+			})
+		})
+
+		When("detected content is a script", func() {
+			It("should only accept textual declared mimetype", func() {
+				Expect(file.IsMimeTypeCompatible(file.MIME_TEXT, file.MIME_SHELLSCRIPT)).To(BeTrue())
+				Expect(file.IsMimeTypeCompatible("text/x-python", file.MIME_SHELLSCRIPT)).To(BeTrue())
+				Expect(file.IsMimeTypeCompatible(file.MIME_UNKNOWN, file.MIME_SHELLSCRIPT)).To(BeFalse())
+				Expect(file.IsMimeTypeCompatible("image/png", file.MIME_SHELLSCRIPT)).To(BeFalse())
+			})
+		})
+
+		When("declared mimetype contradicts detected mimetype", func() {
+			It("should return false", func() {
+				res := file.IsMimeTypeCompatible("image/png", file.MIME_ELF)
+
+				Expect(res).To(BeFalse())
+			})
+		})
+	})
+
+	Context("IsExtensionCompatible function", func() {
+		When("extension matches detected mimetype", func() {
+			It("should return true", func() {
+				Expect(file.IsExtensionCompatible("jpg", "image/jpeg")).To(BeTrue())
+				Expect(file.IsExtensionCompatible("docx", file.MIME_ZIP)).To(BeTrue())
+				Expect(file.IsExtensionCompatible("csv", file.MIME_TEXT)).To(BeTrue())
+			})
+		})
+
+		When("extension contradicts detected mimetype", func() {
+			It("should return false", func() {
+				Expect(file.IsExtensionCompatible("jpg", file.MIME_PE)).To(BeFalse())
+				Expect(file.IsExtensionCompatible("pdf", "image/png")).To(BeFalse())
+			})
+		})
+
+		When("extension is unknown", func() {
+			It("should only reject executable content", func() {
+				Expect(file.IsExtensionCompatible("custom", "image/png")).To(BeTrue())
+				Expect(file.IsExtensionCompatible("custom", file.MIME_ELF)).To(BeFalse())
+				Expect(file.IsExtensionCompatible("custom", file.MIME_SHELLSCRIPT)).To(BeTrue())
+			})
+		})
+	})
+
+	Context("ExtensionByMimeType function", func() {
+		It("should return the shortest extension", func() {
+			Expect(file.ExtensionByMimeType("image/jpeg")).To(Equal("jpg"))
+			Expect(file.ExtensionByMimeType("application/pdf")).To(Equal("pdf"))
+			Expect(file.ExtensionByMimeType("application/x-unknown")).To(Equal(""))
+		})
+	})
+
+	Context("GuessMimeType function", func() {
+		When("extension agrees with detected mimetype", func() {
+			It("should return extension mimetype", func() {
+				Expect(file.GuessMimeType("csv", file.MIME_TEXT)).To(Equal("text/csv"))
+				Expect(file.GuessMimeType("docx", file.MIME_ZIP)).To(Equal("application/vnd.openxmlformats-officedocument.wordprocessingml.document"))
+			})
+		})
+
+		When("extension contradicts detected mimetype", func() {
+			It("should return detected mimetype", func() {
+				Expect(file.GuessMimeType("jpg", "image/png")).To(Equal("image/png"))
+				Expect(file.GuessMimeType("custom", "")).To(Equal(file.MIME_UNKNOWN))
+			})
+		})
+	})
+})
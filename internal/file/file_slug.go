@@ -0,0 +1,24 @@
+package file
+
+import (
+	"regexp"
+	"strings"
+)
+
+const SLUG_MAX_LENGTH = 200
+
+var slugRegexp = regexp.MustCompile(`^[a-z0-9]([a-z0-9_-]*[a-z0-9])?$`)
+
+// IsValidSlug accepts lowercase letters, digits, dash and underscore
+// starting and ending with letter or digit, as produced by text.Slugger
+func IsValidSlug(slug string) bool {
+	return len(slug) <= SLUG_MAX_LENGTH && slugRegexp.MatchString(slug)
+}
+
+// TruncateSlug shortens slug to SLUG_MAX_LENGTH without leaving a leading or trailing separator
+func TruncateSlug(slug string) string {
+	if len(slug) > SLUG_MAX_LENGTH {
+		slug = slug[:SLUG_MAX_LENGTH]
+	}
+	return strings.Trim(slug, "-_")
+}
@@ -0,0 +1,50 @@
+package file_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/file"
+)
+
+var _ = Describe("File Slug", func() {
+
+	Context("IsValidSlug function", func() {
+		When("slug is valid", func() {
+			It("should return true", func() {
+				Expect(file.IsValidSlug("annual-report-2021")).To(BeTrue())
+				Expect(file.IsValidSlug("invoice")).To(BeTrue())
+				Expect(file.IsValidSlug("tax_invoice")).To(BeTrue())
+			})
+		})
+
+		When("slug is invalid", func() {
+			It("should return false", func() {
+				Expect(file.IsValidSlug("")).To(BeFalse())
+				Expect(file.IsValidSlug("Invoice")).To(BeFalse())
+				Expect(file.IsValidSlug("invoice.pdf")).To(BeFalse())
+				Expect(file.IsValidSlug("-invoice")).To(BeFalse())
+				Expect(file.IsValidSlug("invoice-")).To(BeFalse())
+				Expect(file.IsValidSlug(strings.Repeat("a", file.SLUG_MAX_LENGTH+1))).To(BeFalse())
+			})
+		})
+	})
+
+	Context("TruncateSlug function", func() {
+		When("slug is short", func() {
+			It("should return the slug", func() {
+				Expect(file.TruncateSlug("invoice")).To(Equal("invoice"))
+			})
+		})
+
+		When("slug is too long", func() {
+			It("should cut the slug without trailing separator", func() {
+				slug := strings.Repeat("a", file.SLUG_MAX_LENGTH-1) + "-bcd"
+				res := file.TruncateSlug(slug)
+
+				Expect(res).To(Equal(strings.Repeat("a", file.SLUG_MAX_LENGTH-1)))
+			})
+		})
+	})
+})
@@ -1,6 +1,7 @@
 package file_test
 
 import (
+	"encoding/binary"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -11,3 +12,12 @@ func TestFile(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "File Package")
 }
+
+// NewPortableExecutable creates dos header whose e_lfanew points at the pe signature
+func NewPortableExecutable() []byte {
+	data := make([]byte, 0x84)
+	copy(data, "MZ\x90\x00\x03")
+	binary.LittleEndian.PutUint32(data[0x3C:], 0x80)
+	copy(data[0x80:], "PE\x00\x00")
+	return data
+}
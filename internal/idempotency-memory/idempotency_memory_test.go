@@ -0,0 +1,13 @@
+package idempotency_memory_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestIdempotencyMemory(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "IdempotencyMemory Package")
+}
@@ -0,0 +1,76 @@
+package idempotency_memory
+
+import (
+	"sync"
+	"time"
+
+	"idaman.id/storage/internal/idempotency"
+)
+
+type entry struct {
+	record    idempotency.RecordEntity
+	expiresAt time.Time
+}
+
+type memoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]*entry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func (s *memoryStore) Reserve(p idempotency.ReserveParam) (*idempotency.RecordEntity, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	e, isAvailable := s.entries[p.Key]
+	if isAvailable && now.Before(e.expiresAt) {
+		record := e.record
+		return &record, nil
+	}
+
+	s.entries[p.Key] = &entry{record: p.Record, expiresAt: now.Add(p.Ttl)}
+	return nil, nil
+}
+
+func (s *memoryStore) Save(p idempotency.SaveParam) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.entries[p.Key] = &entry{record: p.Record, expiresAt: now.Add(p.Ttl)}
+	return nil
+}
+
+func (s *memoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// sweep drops expired entries, they behave exactly like unknown keys
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, e := range s.entries {
+		if !now.Before(e.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+func NewMemoryStore() idempotency.Store {
+	return &memoryStore{
+		entries:   map[string]*entry{},
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
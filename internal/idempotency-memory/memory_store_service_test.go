@@ -0,0 +1,94 @@
+package idempotency_memory_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/idempotency"
+	idempotency_memory "idaman.id/storage/internal/idempotency-memory"
+)
+
+var _ = Describe("Memory Store", func() {
+	var (
+		store idempotency.Store
+		param idempotency.ReserveParam
+	)
+
+	BeforeEach(func() {
+		store = idempotency_memory.NewMemoryStore()
+		param = idempotency.ReserveParam{
+			Key: "key:user-1:9b2c8f0e",
+			Record: idempotency.RecordEntity{
+				Status:      idempotency.STATUS_IN_PROGRESS,
+				Fingerprint: "fingerprint-1",
+			},
+			Ttl: time.Minute,
+		}
+	})
+
+	Context("Reserve method", func() {
+		When("key is not used yet", func() {
+			It("should reserve the key", func() {
+				record, err := store.Reserve(param)
+
+				Expect(err).To(BeNil())
+				Expect(record).To(BeNil())
+			})
+		})
+
+		When("key is already reserved", func() {
+			It("should return the reserved record", func() {
+				store.Reserve(param)
+				param.Record.Fingerprint = "fingerprint-2"
+				record, err := store.Reserve(param)
+
+				Expect(err).To(BeNil())
+				Expect(record.Status).To(Equal(idempotency.STATUS_IN_PROGRESS))
+				Expect(record.Fingerprint).To(Equal("fingerprint-1"))
+			})
+		})
+
+		When("reservation is expired", func() {
+			It("should reserve the key again", func() {
+				param.Ttl = 0
+				store.Reserve(param)
+				record, err := store.Reserve(param)
+
+				Expect(err).To(BeNil())
+				Expect(record).To(BeNil())
+			})
+		})
+
+		When("key is completed", func() {
+			It("should return the saved response", func() {
+				store.Reserve(param)
+				store.Save(idempotency.SaveParam{
+					Key: param.Key,
+					Record: idempotency.RecordEntity{
+						Status:      idempotency.STATUS_COMPLETED,
+						Fingerprint: "fingerprint-1",
+						Response:    &idempotency.ResponseEntity{StatusCode: 200},
+					},
+					Ttl: time.Hour,
+				})
+				record, err := store.Reserve(param)
+
+				Expect(err).To(BeNil())
+				Expect(record.Status).To(Equal(idempotency.STATUS_COMPLETED))
+				Expect(record.Response.StatusCode).To(Equal(200))
+			})
+		})
+
+		When("key is deleted", func() {
+			It("should reserve the key again", func() {
+				store.Reserve(param)
+				store.Delete(param.Key)
+				record, err := store.Reserve(param)
+
+				Expect(err).To(BeNil())
+				Expect(record).To(BeNil())
+			})
+		})
+	})
+})
@@ -0,0 +1,72 @@
+package idempotency_redis
+
+import (
+	"idaman.id/storage/internal/database"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/idempotency"
+	"idaman.id/storage/internal/serialization"
+)
+
+type redisStore struct {
+	client     database.RedisClient
+	serializer serialization.Serializer
+	prefix     string
+}
+
+// Reserve relies on `SET NX` so only one of the concurrent requests gets the key,
+// the read is retried once in case the record expires in between, then the key is reported unavailable
+func (s *redisStore) Reserve(p idempotency.ReserveParam) (*idempotency.RecordEntity, error) {
+	value, err := s.serializer.Encode(p.Record)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < 2; i++ {
+		reply, err := s.client.Do("SET", s.prefix+p.Key, string(value), "PX", p.Ttl.Milliseconds(), "NX")
+		if err != nil {
+			return nil, err
+		}
+		if reply != nil {
+			return nil, nil
+		}
+
+		reply, err = s.client.Do("GET", s.prefix+p.Key)
+		if err != nil {
+			return nil, err
+		}
+		stored, isString := reply.(string)
+		if !isString {
+			continue
+		}
+
+		record := &idempotency.RecordEntity{}
+		err = s.serializer.Decode([]byte(stored), record)
+		if err != nil {
+			return nil, err
+		}
+		return record, nil
+	}
+	return nil, app_error.NewUnavailableError("Idempotency key")
+}
+
+func (s *redisStore) Save(p idempotency.SaveParam) error {
+	value, err := s.serializer.Encode(p.Record)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.Do("SET", s.prefix+p.Key, string(value), "PX", p.Ttl.Milliseconds())
+	return err
+}
+
+func (s *redisStore) Delete(key string) error {
+	_, err := s.client.Do("DEL", s.prefix+key)
+	return err
+}
+
+func NewRedisStore(c database.RedisClient, s serialization.Serializer) idempotency.Store {
+	return &redisStore{
+		client:     c,
+		serializer: s,
+		prefix:     "goseidon:idempotency:",
+	}
+}
@@ -0,0 +1,145 @@
+package idempotency_redis_test
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/idempotency"
+	idempotency_redis "idaman.id/storage/internal/idempotency-redis"
+	"idaman.id/storage/internal/serialization"
+)
+
+var _ = Describe("Redis Store", func() {
+	var (
+		server *FakeRedisServer
+		store  idempotency.Store
+		record idempotency.RecordEntity
+	)
+
+	BeforeEach(func() {
+		// values emulates the SET, GET and DEL commands, expiry is only recorded by the commands
+		values := map[string]string{}
+		mu := &sync.Mutex{}
+		server = NewFakeRedisServer(func(args []string) string {
+			mu.Lock()
+			defer mu.Unlock()
+			switch args[0] {
+			case "SET":
+				_, isExists := values[args[1]]
+				if len(args) > 5 && args[5] == "NX" && isExists {
+					return "$-1\r\n"
+				}
+				values[args[1]] = args[2]
+				return "+OK\r\n"
+			case "GET":
+				value, isExists := values[args[1]]
+				if !isExists {
+					return "$-1\r\n"
+				}
+				return "$" + strconv.Itoa(len(value)) + "\r\n" + value + "\r\n"
+			case "DEL":
+				delete(values, args[1])
+				return ":1\r\n"
+			}
+			return "-ERR unknown command\r\n"
+		})
+		store = idempotency_redis.NewRedisStore(NewFakeRedisClient(server), serialization.NewJsonSerialization())
+		record = idempotency.RecordEntity{
+			Status:      "processing",
+			Fingerprint: "abc",
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("Reserve method", func() {
+		When("key is not used yet", func() {
+			It("should save the record with NX and PX", func() {
+				res, err := store.Reserve(idempotency.ReserveParam{Key: "key-1", Record: record, Ttl: 5 * time.Minute})
+
+				Expect(err).To(BeNil())
+				Expect(res).To(BeNil())
+				commands := server.Commands()
+				Expect(commands).To(HaveLen(1))
+				Expect(commands[0][0:2]).To(Equal([]string{"SET", "goseidon:idempotency:key-1"}))
+				Expect(commands[0][3:]).To(Equal([]string{"PX", "300000", "NX"}))
+			})
+		})
+
+		When("key is already reserved", func() {
+			It("should return the saved record", func() {
+				store.Reserve(idempotency.ReserveParam{Key: "key-1", Record: record, Ttl: time.Minute})
+
+				other := record
+				other.Fingerprint = "def"
+				res, err := store.Reserve(idempotency.ReserveParam{Key: "key-1", Record: other, Ttl: time.Minute})
+
+				Expect(err).To(BeNil())
+				Expect(res).To(Equal(&record))
+				Expect(server.Commands()[2]).To(Equal([]string{"GET", "goseidon:idempotency:key-1"}))
+			})
+		})
+
+		When("record keeps expiring between the write and the read", func() {
+			It("should return unavailable error", func() {
+				server.Close()
+				server = NewFakeRedisServer(func(args []string) string {
+					return "$-1\r\n"
+				})
+				store = idempotency_redis.NewRedisStore(NewFakeRedisClient(server), serialization.NewJsonSerialization())
+				res, err := store.Reserve(idempotency.ReserveParam{Key: "key-1", Record: record, Ttl: time.Minute})
+
+				Expect(res).To(BeNil())
+				Expect(err).To(MatchError(app_error.NewUnavailableError("Idempotency key")))
+				Expect(server.Commands()).To(HaveLen(4))
+			})
+		})
+
+		When("server replies with error", func() {
+			It("should return the error", func() {
+				server.Close()
+				res, err := store.Reserve(idempotency.ReserveParam{Key: "key-1", Record: record, Ttl: time.Minute})
+
+				Expect(res).To(BeNil())
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+
+	Context("Save method", func() {
+		It("should override the record with PX", func() {
+			store.Reserve(idempotency.ReserveParam{Key: "key-1", Record: record, Ttl: time.Minute})
+
+			record.Status = "completed"
+			record.Response = &idempotency.ResponseEntity{StatusCode: 201, Body: []byte(`{"id":1}`)}
+			err := store.Save(idempotency.SaveParam{Key: "key-1", Record: record, Ttl: 24 * time.Hour})
+			Expect(err).To(BeNil())
+			Expect(server.Commands()[1][3:]).To(Equal([]string{"PX", "86400000"}))
+
+			res, err := store.Reserve(idempotency.ReserveParam{Key: "key-1", Record: record, Ttl: time.Minute})
+
+			Expect(err).To(BeNil())
+			Expect(res).To(Equal(&record))
+		})
+	})
+
+	Context("Delete method", func() {
+		It("should release the key", func() {
+			store.Reserve(idempotency.ReserveParam{Key: "key-1", Record: record, Ttl: time.Minute})
+
+			err := store.Delete("key-1")
+			Expect(err).To(BeNil())
+
+			res, err := store.Reserve(idempotency.ReserveParam{Key: "key-1", Record: record, Ttl: time.Minute})
+
+			Expect(err).To(BeNil())
+			Expect(res).To(BeNil())
+		})
+	})
+})
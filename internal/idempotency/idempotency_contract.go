@@ -0,0 +1,56 @@
+package idempotency
+
+import "time"
+
+type Keeper interface {
+	// Begin reserves the key for the request,
+	// the stored response is returned when the same request has already been completed
+	Begin(p BeginParam) (*BeginResult, error)
+	// Complete stores the response so it is replayed to the next retries
+	Complete(p CompleteParam) error
+	// Cancel releases the key so the request can be retried, e.g: after a server failure
+	Cancel(p CancelParam) error
+}
+
+type BeginParam struct {
+	Client      string
+	Key         string
+	Fingerprint string
+}
+
+type BeginResult struct {
+	// Response is nil when the request has to be handled
+	Response *ResponseEntity
+}
+
+type CompleteParam struct {
+	Client      string
+	Key         string
+	Fingerprint string
+	Response    ResponseEntity
+}
+
+type CancelParam struct {
+	Client string
+	Key    string
+}
+
+type Store interface {
+	// Reserve saves the record only when the key is not used yet,
+	// otherwise the record already saved under the key is returned
+	Reserve(p ReserveParam) (*RecordEntity, error)
+	Save(p SaveParam) error
+	Delete(key string) error
+}
+
+type ReserveParam struct {
+	Key    string
+	Record RecordEntity
+	Ttl    time.Duration
+}
+
+type SaveParam struct {
+	Key    string
+	Record RecordEntity
+	Ttl    time.Duration
+}
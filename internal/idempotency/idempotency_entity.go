@@ -0,0 +1,18 @@
+package idempotency
+
+const (
+	STATUS_IN_PROGRESS = "in_progress"
+	STATUS_COMPLETED   = "completed"
+)
+
+type RecordEntity struct {
+	Status      string          `json:"status"`
+	Fingerprint string          `json:"fingerprint"`
+	Response    *ResponseEntity `json:"response,omitempty"`
+}
+
+type ResponseEntity struct {
+	StatusCode int               `json:"status_code"`
+	Headers    map[string]string `json:"headers"`
+	Body       []byte            `json:"body"`
+}
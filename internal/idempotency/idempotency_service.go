@@ -0,0 +1,85 @@
+package idempotency
+
+import (
+	"fmt"
+	"time"
+
+	"idaman.id/storage/internal/config"
+	app_error "idaman.id/storage/internal/error"
+)
+
+const MAX_KEY_LENGTH = 255
+
+type idempotencyService struct {
+	store        Store
+	configGetter config.Getter
+}
+
+func (s *idempotencyService) Begin(p BeginParam) (*BeginResult, error) {
+	if p.Key == "" {
+		return nil, newKeyError("required")
+	}
+	if len(p.Key) > MAX_KEY_LENGTH {
+		return nil, newKeyError("max")
+	}
+
+	record, err := s.store.Reserve(ReserveParam{
+		Key: storeKey(p.Client, p.Key),
+		Record: RecordEntity{
+			Status:      STATUS_IN_PROGRESS,
+			Fingerprint: p.Fingerprint,
+		},
+		Ttl: time.Duration(s.configGetter.GetInt("IDEMPOTENCY_LOCK_TIMEOUT")) * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return &BeginResult{}, nil
+	}
+
+	if record.Fingerprint != p.Fingerprint {
+		return nil, newKeyError("fingerprint")
+	}
+	if record.Status != STATUS_COMPLETED || record.Response == nil {
+		return nil, app_error.NewConflictError("Request")
+	}
+	return &BeginResult{Response: record.Response}, nil
+}
+
+func (s *idempotencyService) Complete(p CompleteParam) error {
+	return s.store.Save(SaveParam{
+		Key: storeKey(p.Client, p.Key),
+		Record: RecordEntity{
+			Status:      STATUS_COMPLETED,
+			Fingerprint: p.Fingerprint,
+			Response:    &p.Response,
+		},
+		Ttl: time.Duration(s.configGetter.GetInt("IDEMPOTENCY_TTL")) * time.Second,
+	})
+}
+
+func (s *idempotencyService) Cancel(p CancelParam) error {
+	return s.store.Delete(storeKey(p.Client, p.Key))
+}
+
+// storeKey scopes the key to the client, different clients may pick the same key
+func storeKey(client, key string) string {
+	return client + ":" + key
+}
+
+func newKeyError(tag string) *app_error.ValidationError {
+	return app_error.NewValidationError([]app_error.ValidationItem{
+		{
+			Field:   "idempotency-key",
+			Message: fmt.Sprintf("Key: 'idempotency-key' Error:Field validation for 'idempotency-key' failed on the '%s' tag", tag),
+		},
+	})
+}
+
+func NewIdempotencyService(s Store, cg config.Getter) Keeper {
+	return &idempotencyService{
+		store:        s,
+		configGetter: cg,
+	}
+}
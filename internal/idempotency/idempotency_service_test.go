@@ -0,0 +1,144 @@
+package idempotency_test
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/idempotency"
+)
+
+var _ = Describe("Idempotency Service", func() {
+	var (
+		store  *FakeStore
+		keeper idempotency.Keeper
+		param  idempotency.BeginParam
+	)
+
+	BeforeEach(func() {
+		store = &FakeStore{records: map[string]idempotency.RecordEntity{}}
+		keeper = idempotency.NewIdempotencyService(store, &FakeConfig{values: map[string]int{
+			"IDEMPOTENCY_TTL":          86400,
+			"IDEMPOTENCY_LOCK_TIMEOUT": 300,
+		}})
+		param = idempotency.BeginParam{
+			Client:      "key:user-1",
+			Key:         "9b2c8f0e",
+			Fingerprint: "fingerprint-1",
+		}
+	})
+
+	Context("Begin method", func() {
+		When("key is empty", func() {
+			It("should return required validation error", func() {
+				param.Key = ""
+				res, err := keeper.Begin(param)
+
+				Expect(res).To(BeNil())
+				Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+				Expect(err.(*app_error.ValidationError).Items[0].Message).To(ContainSubstring("'required' tag"))
+			})
+		})
+
+		When("key is too long", func() {
+			It("should return validation error", func() {
+				param.Key = strings.Repeat("k", 256)
+				res, err := keeper.Begin(param)
+
+				Expect(res).To(BeNil())
+				Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+				Expect(err.(*app_error.ValidationError).Items[0].Message).To(ContainSubstring("'max' tag"))
+			})
+		})
+
+		When("key is not used yet", func() {
+			It("should reserve the key for the client", func() {
+				res, err := keeper.Begin(param)
+
+				Expect(err).To(BeNil())
+				Expect(res.Response).To(BeNil())
+				Expect(store.reserved).To(HaveLen(1))
+				Expect(store.reserved[0].Key).To(Equal("key:user-1:9b2c8f0e"))
+				Expect(store.reserved[0].Record.Status).To(Equal(idempotency.STATUS_IN_PROGRESS))
+				Expect(store.reserved[0].Ttl).To(Equal(300 * time.Second))
+			})
+		})
+
+		When("key is used by another request", func() {
+			It("should return validation error", func() {
+				store.records["key:user-1:9b2c8f0e"] = idempotency.RecordEntity{
+					Status:      idempotency.STATUS_COMPLETED,
+					Fingerprint: "fingerprint-2",
+				}
+				res, err := keeper.Begin(param)
+
+				validationError, _ := err.(*app_error.ValidationError)
+
+				Expect(res).To(BeNil())
+				Expect(validationError).ToNot(BeNil())
+				Expect(validationError.Items[0].Message).To(ContainSubstring("'fingerprint' tag"))
+			})
+		})
+
+		When("same request is still in progress", func() {
+			It("should return conflict error", func() {
+				store.records["key:user-1:9b2c8f0e"] = idempotency.RecordEntity{
+					Status:      idempotency.STATUS_IN_PROGRESS,
+					Fingerprint: "fingerprint-1",
+				}
+				res, err := keeper.Begin(param)
+
+				Expect(res).To(BeNil())
+				Expect(err).To(Equal(app_error.NewConflictError("Request")))
+			})
+		})
+
+		When("same request is completed", func() {
+			It("should return the stored response", func() {
+				store.records["key:user-1:9b2c8f0e"] = idempotency.RecordEntity{
+					Status:      idempotency.STATUS_COMPLETED,
+					Fingerprint: "fingerprint-1",
+					Response:    &idempotency.ResponseEntity{StatusCode: 200, Body: []byte(`{}`)},
+				}
+				res, err := keeper.Begin(param)
+
+				Expect(err).To(BeNil())
+				Expect(res.Response.StatusCode).To(Equal(200))
+				Expect(res.Response.Body).To(Equal([]byte(`{}`)))
+			})
+		})
+	})
+
+	Context("Complete method", func() {
+		When("response is completed", func() {
+			It("should save the response for the ttl", func() {
+				err := keeper.Complete(idempotency.CompleteParam{
+					Client:      "key:user-1",
+					Key:         "9b2c8f0e",
+					Fingerprint: "fingerprint-1",
+					Response:    idempotency.ResponseEntity{StatusCode: 201},
+				})
+
+				Expect(err).To(BeNil())
+				Expect(store.saved).To(HaveLen(1))
+				Expect(store.saved[0].Key).To(Equal("key:user-1:9b2c8f0e"))
+				Expect(store.saved[0].Record.Status).To(Equal(idempotency.STATUS_COMPLETED))
+				Expect(store.saved[0].Record.Response.StatusCode).To(Equal(201))
+				Expect(store.saved[0].Ttl).To(Equal(24 * time.Hour))
+			})
+		})
+	})
+
+	Context("Cancel method", func() {
+		When("request is cancelled", func() {
+			It("should release the key", func() {
+				err := keeper.Cancel(idempotency.CancelParam{Client: "key:user-1", Key: "9b2c8f0e"})
+
+				Expect(err).To(BeNil())
+				Expect(store.deleted).To(Equal([]string{"key:user-1:9b2c8f0e"}))
+			})
+		})
+	})
+})
@@ -0,0 +1,59 @@
+package idempotency_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/idempotency"
+)
+
+func TestIdempotency(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Idempotency Package")
+}
+
+type FakeConfig struct {
+	values map[string]int
+}
+
+func (c *FakeConfig) GetString(key string) string {
+	return ""
+}
+
+func (c *FakeConfig) GetInt(key string) int {
+	return c.values[key]
+}
+
+func (c *FakeConfig) GetBool(key string) bool {
+	return false
+}
+
+func (c *FakeConfig) Get(key string) interface{} {
+	return c.values[key]
+}
+
+type FakeStore struct {
+	records  map[string]idempotency.RecordEntity
+	reserved []idempotency.ReserveParam
+	saved    []idempotency.SaveParam
+	deleted  []string
+}
+
+func (s *FakeStore) Reserve(p idempotency.ReserveParam) (*idempotency.RecordEntity, error) {
+	s.reserved = append(s.reserved, p)
+	if record, ok := s.records[p.Key]; ok {
+		return &record, nil
+	}
+	return nil, nil
+}
+
+func (s *FakeStore) Save(p idempotency.SaveParam) error {
+	s.saved = append(s.saved, p)
+	return nil
+}
+
+func (s *FakeStore) Delete(key string) error {
+	s.deleted = append(s.deleted, key)
+	return nil
+}
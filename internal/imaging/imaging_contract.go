@@ -0,0 +1,25 @@
+package imaging
+
+const (
+	FIT_CONTAIN = "contain"
+	FIT_COVER   = "cover"
+	FIT_FILL    = "fill"
+
+	FORMAT_JPEG = "jpeg"
+	FORMAT_PNG  = "png"
+	FORMAT_GIF  = "gif"
+)
+
+type Transformer interface {
+	// Normalize validates the option against the source mimetype and fills the default values
+	Normalize(o TransformOption, mimetype string) (*TransformOption, error)
+	Transform(data []byte, o TransformOption) (*TransformResult, error)
+}
+
+type TransformResult struct {
+	Data      []byte
+	Mimetype  string
+	Extension string
+	Width     int
+	Height    int
+}
@@ -0,0 +1,49 @@
+package imaging
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+var outputMimetypes = map[string]string{
+	FORMAT_JPEG: "image/jpeg",
+	FORMAT_PNG:  "image/png",
+	FORMAT_GIF:  "image/gif",
+}
+
+var outputExtensions = map[string]string{
+	FORMAT_JPEG: "jpg",
+	FORMAT_PNG:  "png",
+	FORMAT_GIF:  "gif",
+}
+
+// TransformOption describes the requested output, zero value means unspecified,
+// Crop is a source region formatted as "x,y,width,height"
+type TransformOption struct {
+	Width   int
+	Height  int
+	Fit     string
+	Crop    string
+	Quality int
+	Format  string
+}
+
+func (o *TransformOption) IsEmpty() bool {
+	return o == nil || *o == TransformOption{}
+}
+
+// Key identifies the option set, normalized options producing the same output share the same key
+func (o *TransformOption) Key() string {
+	raw := fmt.Sprintf("w=%d;h=%d;fit=%s;crop=%s;q=%d;fm=%s", o.Width, o.Height, o.Fit, o.Crop, o.Quality, o.Format)
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (o *TransformOption) Mimetype() string {
+	return outputMimetypes[o.Format]
+}
+
+func (o *TransformOption) Extension() string {
+	return outputExtensions[o.Format]
+}
@@ -0,0 +1,118 @@
+package imaging
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// resize scales the image using a separable triangle filter,
+// the filter support grows with the downscale factor so shrinking averages every source pixel
+func resize(src image.Image, width, height int) *image.RGBA {
+	rgba := toRGBA(src)
+	if rgba.Rect.Dx() == width && rgba.Rect.Dy() == height {
+		return rgba
+	}
+	tmp := scaleAxis(rgba, width, rgba.Rect.Dy(), true)
+	return scaleAxis(tmp, width, height, false)
+}
+
+func toRGBA(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Rect, src, b.Min, draw.Src)
+	return dst
+}
+
+type weight struct {
+	index int
+	value float64
+}
+
+func computeWeights(srcLen, dstLen int) [][]weight {
+	scale := float64(srcLen) / float64(dstLen)
+	support := math.Max(scale, 1)
+
+	weights := make([][]weight, dstLen)
+	for i := range weights {
+		center := (float64(i)+0.5)*scale - 0.5
+		start := int(math.Floor(center - support))
+		end := int(math.Ceil(center + support))
+
+		total := 0.0
+		ws := []weight{}
+		for j := start; j <= end; j++ {
+			v := 1 - math.Abs(float64(j)-center)/support
+			if v <= 0 {
+				continue
+			}
+			index := j
+			if index < 0 {
+				index = 0
+			} else if index >= srcLen {
+				index = srcLen - 1
+			}
+			ws = append(ws, weight{index, v})
+			total += v
+		}
+		if total == 0 {
+			index := int(math.Min(math.Max(math.Round(center), 0), float64(srcLen-1)))
+			ws = []weight{{index, 1}}
+			total = 1
+		}
+		for k := range ws {
+			ws[k].value /= total
+		}
+		weights[i] = ws
+	}
+	return weights
+}
+
+func scaleAxis(src *image.RGBA, width, height int, horizontal bool) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	var weights [][]weight
+	if horizontal {
+		weights = computeWeights(src.Rect.Dx(), width)
+	} else {
+		weights = computeWeights(src.Rect.Dy(), height)
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var r, g, b, a float64
+			var ws []weight
+			if horizontal {
+				ws = weights[x]
+			} else {
+				ws = weights[y]
+			}
+			for _, w := range ws {
+				offset := src.PixOffset(w.index, y)
+				if !horizontal {
+					offset = src.PixOffset(x, w.index)
+				}
+				r += float64(src.Pix[offset]) * w.value
+				g += float64(src.Pix[offset+1]) * w.value
+				b += float64(src.Pix[offset+2]) * w.value
+				a += float64(src.Pix[offset+3]) * w.value
+			}
+			offset := dst.PixOffset(x, y)
+			dst.Pix[offset] = clamp(r)
+			dst.Pix[offset+1] = clamp(g)
+			dst.Pix[offset+2] = clamp(b)
+			dst.Pix[offset+3] = clamp(a)
+		}
+	}
+	return dst
+}
+
+func clamp(v float64) uint8 {
+	v = math.Round(v)
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v)
+}
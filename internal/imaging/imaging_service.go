@@ -0,0 +1,237 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+
+	_ "golang.org/x/image/webp"
+	"idaman.id/storage/internal/config"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/file"
+	"idaman.id/storage/internal/validation"
+)
+
+var sourceFormats = map[string]string{
+	"image/jpeg": FORMAT_JPEG,
+	"image/png":  FORMAT_PNG,
+	"image/gif":  FORMAT_GIF,
+	// webp has no encoder, so a webp source is transformed into png by default
+	"image/webp": FORMAT_PNG,
+}
+
+type imagingService struct {
+	validator    validation.Validator
+	configGetter config.Getter
+}
+
+func (s *imagingService) Normalize(o TransformOption, mimetype string) (*TransformOption, error) {
+	sourceFormat, isAvailable := sourceFormats[file.NormalizeMimeType(mimetype)]
+	if !isAvailable {
+		return nil, app_error.NewUnsupportedError("Image")
+	}
+
+	data := map[string]interface{}{
+		"width":   o.Width,
+		"height":  o.Height,
+		"fit":     o.Fit,
+		"quality": o.Quality,
+		"format":  o.Format,
+	}
+	rules := map[string]string{
+		"width":   "min=0,max=" + strconv.Itoa(s.configGetter.GetInt("IMAGE_MAX_WIDTH")),
+		"height":  "min=0,max=" + strconv.Itoa(s.configGetter.GetInt("IMAGE_MAX_HEIGHT")),
+		"fit":     "omitempty,oneof=contain cover fill",
+		"quality": "min=0,max=100",
+		"format":  "omitempty,oneof=jpeg png gif",
+	}
+	err := s.validator.ValidateRules(data, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Crop != "" {
+		_, err = parseCrop(o.Crop)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res := o
+	if res.Format == "" {
+		res.Format = sourceFormat
+	}
+	if res.Fit == "" {
+		res.Fit = FIT_CONTAIN
+	}
+	if res.Width == 0 || res.Height == 0 {
+		res.Fit = ""
+	}
+	if res.Format == FORMAT_JPEG && res.Quality == 0 {
+		res.Quality = s.configGetter.GetInt("IMAGE_DEFAULT_QUALITY")
+	}
+	if res.Format != FORMAT_JPEG {
+		res.Quality = 0
+	}
+	return &res, nil
+}
+
+func (s *imagingService) Transform(data []byte, o TransformOption) (*TransformResult, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, app_error.NewUnsupportedError("Image")
+	}
+	maxPixels := s.configGetter.GetInt("IMAGE_MAX_SOURCE_PIXELS")
+	if maxPixels > 0 && cfg.Width*cfg.Height > maxPixels {
+		return nil, app_error.NewUnsupportedError("Image size")
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, app_error.NewUnsupportedError("Image")
+	}
+
+	if o.Crop != "" {
+		region, err := parseCrop(o.Crop)
+		if err != nil {
+			return nil, err
+		}
+		region = region.Add(src.Bounds().Min).Intersect(src.Bounds())
+		if region.Empty() {
+			return nil, newCropError()
+		}
+		src = cropImage(src, region)
+	}
+
+	width, height := s.targetSize(src.Bounds().Dx(), src.Bounds().Dy(), o)
+	if o.Fit == FIT_COVER {
+		src = cropImage(src, coverRegion(src.Bounds(), width, height))
+	}
+	dst := resize(src, width, height)
+
+	buf := bytes.Buffer{}
+	switch o.Format {
+	case FORMAT_JPEG:
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: o.Quality})
+	case FORMAT_PNG:
+		err = png.Encode(&buf, dst)
+	case FORMAT_GIF:
+		err = gif.Encode(&buf, dst, nil)
+	default:
+		err = app_error.NewUnsupportedError("Format")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := &TransformResult{
+		Data:      buf.Bytes(),
+		Mimetype:  o.Mimetype(),
+		Extension: o.Extension(),
+		Width:     dst.Bounds().Dx(),
+		Height:    dst.Bounds().Dy(),
+	}
+	return res, nil
+}
+
+// targetSize keeps the aspect ratio when only one side is given or fit is contain,
+// derived side never exceeds the configured output limit
+func (s *imagingService) targetSize(srcWidth, srcHeight int, o TransformOption) (int, int) {
+	width, height := o.Width, o.Height
+	ratio := float64(srcWidth) / float64(srcHeight)
+
+	switch {
+	case width == 0 && height == 0:
+		width, height = srcWidth, srcHeight
+	case width == 0:
+		width = scaleLength(height, ratio)
+	case height == 0:
+		height = scaleLength(width, 1/ratio)
+	case o.Fit == FIT_CONTAIN:
+		scale := math.Min(float64(width)/float64(srcWidth), float64(height)/float64(srcHeight))
+		width, height = scaleLength(srcWidth, scale), scaleLength(srcHeight, scale)
+	}
+
+	maxWidth := s.configGetter.GetInt("IMAGE_MAX_WIDTH")
+	maxHeight := s.configGetter.GetInt("IMAGE_MAX_HEIGHT")
+	if maxWidth > 0 && width > maxWidth {
+		height = scaleLength(height, float64(maxWidth)/float64(width))
+		width = maxWidth
+	}
+	if maxHeight > 0 && height > maxHeight {
+		width = scaleLength(width, float64(maxHeight)/float64(height))
+		height = maxHeight
+	}
+	return width, height
+}
+
+// coverRegion is the centered part of the bounds having the aspect ratio of the target size,
+// so the cover result is resized straight to the target size whatever the source aspect ratio is
+func coverRegion(bounds image.Rectangle, width, height int) image.Rectangle {
+	regionWidth, regionHeight := bounds.Dx(), bounds.Dy()
+	if regionWidth*height > regionHeight*width {
+		regionWidth = scaleLength(regionHeight, float64(width)/float64(height))
+	} else {
+		regionHeight = scaleLength(regionWidth, float64(height)/float64(width))
+	}
+	x := bounds.Min.X + (bounds.Dx()-regionWidth)/2
+	y := bounds.Min.Y + (bounds.Dy()-regionHeight)/2
+	return image.Rect(x, y, x+regionWidth, y+regionHeight)
+}
+
+func cropImage(src image.Image, region image.Rectangle) *image.RGBA {
+	cropped := image.NewRGBA(image.Rect(0, 0, region.Dx(), region.Dy()))
+	draw.Draw(cropped, cropped.Rect, src, region.Min, draw.Src)
+	return cropped
+}
+
+func scaleLength(length int, scale float64) int {
+	res := int(math.Round(float64(length) * scale))
+	if res < 1 {
+		return 1
+	}
+	return res
+}
+
+func parseCrop(crop string) (image.Rectangle, error) {
+	parts := strings.Split(crop, ",")
+	if len(parts) != 4 {
+		return image.Rectangle{}, newCropError()
+	}
+
+	values := make([]int, 4)
+	for i, part := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || v < 0 {
+			return image.Rectangle{}, newCropError()
+		}
+		values[i] = v
+	}
+	if values[2] == 0 || values[3] == 0 {
+		return image.Rectangle{}, newCropError()
+	}
+	return image.Rect(values[0], values[1], values[0]+values[2], values[1]+values[3]), nil
+}
+
+func newCropError() error {
+	return app_error.NewValidationError([]app_error.ValidationItem{
+		{
+			Field:   "crop",
+			Message: fmt.Sprintf("Key: '%s' Error:Field validation for '%s' failed on the '%s' tag", "crop", "crop", "region"),
+		},
+	})
+}
+
+func NewImagingService(v validation.Validator, cg config.Getter) Transformer {
+	return &imagingService{
+		validator:    v,
+		configGetter: cg,
+	}
+}
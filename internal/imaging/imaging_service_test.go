@@ -0,0 +1,229 @@
+package imaging_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	_ "image/jpeg"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/imaging"
+	validation_go "idaman.id/storage/internal/validation-go"
+)
+
+var _ = Describe("Imaging Service", func() {
+	var (
+		cfg         *FakeConfig
+		transformer imaging.Transformer
+	)
+
+	BeforeEach(func() {
+		cfg = &FakeConfig{values: map[string]interface{}{
+			"IMAGE_MAX_WIDTH":         400,
+			"IMAGE_MAX_HEIGHT":        300,
+			"IMAGE_MAX_SOURCE_PIXELS": 1000000,
+			"IMAGE_DEFAULT_QUALITY":   85,
+		}}
+		validator, err := validation_go.NewGoValidator(cfg)
+		Expect(err).To(BeNil())
+		transformer = imaging.NewImagingService(validator, cfg)
+	})
+
+	Context("Normalize method", func() {
+		When("option is partially filled", func() {
+			It("should fill the default values", func() {
+				res, err := transformer.Normalize(imaging.TransformOption{Width: 100, Height: 100}, "image/jpeg")
+
+				Expect(err).To(BeNil())
+				Expect(res).To(Equal(&imaging.TransformOption{
+					Width:   100,
+					Height:  100,
+					Fit:     imaging.FIT_CONTAIN,
+					Quality: 85,
+					Format:  imaging.FORMAT_JPEG,
+				}))
+			})
+		})
+
+		When("option produces lossless output", func() {
+			It("should ignore quality and fit of a single side", func() {
+				res, err := transformer.Normalize(imaging.TransformOption{Width: 100, Fit: imaging.FIT_COVER, Quality: 50}, "image/png")
+
+				Expect(err).To(BeNil())
+				Expect(res).To(Equal(&imaging.TransformOption{Width: 100, Format: imaging.FORMAT_PNG}))
+			})
+		})
+
+		When("source is not an image", func() {
+			It("should return unsupported error", func() {
+				res, err := transformer.Normalize(imaging.TransformOption{Width: 100}, "application/pdf")
+
+				Expect(res).To(BeNil())
+				Expect(err).To(MatchError(app_error.NewUnsupportedError("Image")))
+			})
+		})
+
+		When("source is a webp image", func() {
+			It("should default to png output", func() {
+				res, err := transformer.Normalize(imaging.TransformOption{Width: 100}, "image/webp")
+
+				Expect(err).To(BeNil())
+				Expect(res).To(Equal(&imaging.TransformOption{Width: 100, Format: imaging.FORMAT_PNG}))
+			})
+		})
+
+		When("output format has no encoder", func() {
+			It("should return validation error", func() {
+				res, err := transformer.Normalize(imaging.TransformOption{Format: "webp"}, "image/png")
+
+				Expect(res).To(BeNil())
+				Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+				Expect(err.(*app_error.ValidationError).Items[0].Field).To(Equal("format"))
+			})
+		})
+
+		When("dimension exceeds the limit", func() {
+			It("should return validation error", func() {
+				res, err := transformer.Normalize(imaging.TransformOption{Width: 401, Fit: "stretch"}, "image/png")
+
+				Expect(res).To(BeNil())
+				Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+				Expect(err.(*app_error.ValidationError).Items).To(HaveLen(2))
+			})
+		})
+
+		When("crop region is malformed", func() {
+			It("should return validation error", func() {
+				res, err := transformer.Normalize(imaging.TransformOption{Crop: "0,0,10"}, "image/png")
+
+				Expect(res).To(BeNil())
+				Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+			})
+		})
+	})
+
+	Context("Transform method", func() {
+		var (
+			source []byte
+		)
+
+		BeforeEach(func() {
+			source = NewPng(200, 100)
+		})
+
+		When("fit is contain", func() {
+			It("should keep the aspect ratio inside the box", func() {
+				res, err := transformer.Transform(source, imaging.TransformOption{Width: 100, Height: 100, Fit: imaging.FIT_CONTAIN, Format: imaging.FORMAT_PNG})
+
+				Expect(err).To(BeNil())
+				Expect(res.Width).To(Equal(100))
+				Expect(res.Height).To(Equal(50))
+				Expect(res.Mimetype).To(Equal("image/png"))
+				Expect(res.Extension).To(Equal("png"))
+			})
+		})
+
+		When("fit is cover", func() {
+			It("should fill the box and crop the overflow", func() {
+				res, err := transformer.Transform(source, imaging.TransformOption{Width: 100, Height: 100, Fit: imaging.FIT_COVER, Format: imaging.FORMAT_PNG})
+
+				Expect(err).To(BeNil())
+				cfg, _, err := image.DecodeConfig(bytes.NewReader(res.Data))
+				Expect(err).To(BeNil())
+				Expect(cfg.Width).To(Equal(100))
+				Expect(cfg.Height).To(Equal(100))
+			})
+		})
+
+		When("fit is cover and the source has an extreme aspect ratio", func() {
+			It("should crop the source before resizing to the box", func() {
+				source = NewPng(10000, 10)
+				res, err := transformer.Transform(source, imaging.TransformOption{Width: 400, Height: 300, Fit: imaging.FIT_COVER, Format: imaging.FORMAT_PNG})
+
+				Expect(err).To(BeNil())
+				Expect(res.Width).To(Equal(400))
+				Expect(res.Height).To(Equal(300))
+			})
+		})
+
+		When("fit is fill", func() {
+			It("should stretch into the box", func() {
+				res, err := transformer.Transform(source, imaging.TransformOption{Width: 50, Height: 80, Fit: imaging.FIT_FILL, Format: imaging.FORMAT_GIF})
+
+				Expect(err).To(BeNil())
+				Expect(res.Width).To(Equal(50))
+				Expect(res.Height).To(Equal(80))
+				Expect(res.Mimetype).To(Equal("image/gif"))
+			})
+		})
+
+		When("only height is given", func() {
+			It("should derive the width", func() {
+				res, err := transformer.Transform(source, imaging.TransformOption{Height: 50, Format: imaging.FORMAT_JPEG, Quality: 80})
+
+				Expect(err).To(BeNil())
+				img, format, err := image.Decode(bytes.NewReader(res.Data))
+				Expect(err).To(BeNil())
+				Expect(format).To(Equal("jpeg"))
+				Expect(img.Bounds().Dx()).To(Equal(100))
+			})
+		})
+
+		When("crop is given", func() {
+			It("should only keep the region", func() {
+				res, err := transformer.Transform(source, imaging.TransformOption{Crop: "150,0,50,100", Format: imaging.FORMAT_PNG})
+
+				Expect(err).To(BeNil())
+				img, _, err := image.Decode(bytes.NewReader(res.Data))
+				Expect(err).To(BeNil())
+				Expect(img.Bounds().Dx()).To(Equal(50))
+				r, g, b, _ := img.At(0, 0).RGBA()
+				Expect([]uint32{r, g, b}).To(Equal([]uint32{0, 0, 0xffff}))
+			})
+		})
+
+		When("crop is outside the image", func() {
+			It("should return validation error", func() {
+				res, err := transformer.Transform(source, imaging.TransformOption{Crop: "500,500,10,10", Format: imaging.FORMAT_PNG})
+
+				Expect(res).To(BeNil())
+				Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+			})
+		})
+
+		When("source is a webp image", func() {
+			It("should decode and encode into the output format", func() {
+				source, err := base64.StdEncoding.DecodeString("UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA==")
+				Expect(err).To(BeNil())
+				res, err := transformer.Transform(source, imaging.TransformOption{Width: 10, Format: imaging.FORMAT_PNG})
+
+				Expect(err).To(BeNil())
+				Expect(res.Mimetype).To(Equal("image/png"))
+				Expect(res.Width).To(Equal(10))
+				Expect(res.Height).To(Equal(10))
+			})
+		})
+
+		When("source has too many pixels", func() {
+			It("should return unsupported error", func() {
+				cfg.values["IMAGE_MAX_SOURCE_PIXELS"] = 100
+				res, err := transformer.Transform(source, imaging.TransformOption{Width: 10, Format: imaging.FORMAT_PNG})
+
+				Expect(res).To(BeNil())
+				Expect(err).To(MatchError(app_error.NewUnsupportedError("Image size")))
+			})
+		})
+	})
+
+	Context("TransformOption Key method", func() {
+		It("should differ for different options", func() {
+			a := &imaging.TransformOption{Width: 100, Format: imaging.FORMAT_PNG}
+			b := &imaging.TransformOption{Width: 101, Format: imaging.FORMAT_PNG}
+
+			Expect(a.Key()).To(Equal((&imaging.TransformOption{Width: 100, Format: imaging.FORMAT_PNG}).Key()))
+			Expect(a.Key()).ToNot(Equal(b.Key()))
+		})
+	})
+})
@@ -0,0 +1,57 @@
+package imaging_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestImaging(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Imaging Package")
+}
+
+type FakeConfig struct {
+	values map[string]interface{}
+}
+
+func (c *FakeConfig) GetString(key string) string {
+	v, _ := c.values[key].(string)
+	return v
+}
+
+func (c *FakeConfig) GetInt(key string) int {
+	v, _ := c.values[key].(int)
+	return v
+}
+
+func (c *FakeConfig) GetBool(key string) bool {
+	v, _ := c.values[key].(bool)
+	return v
+}
+
+func (c *FakeConfig) Get(key string) interface{} {
+	return c.values[key]
+}
+
+// NewPng creates png image with red left half and blue right half
+func NewPng(width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.RGBA{255, 0, 0, 255}
+			if x >= width/2 {
+				c = color.RGBA{0, 0, 255, 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	buf := bytes.Buffer{}
+	Expect(png.Encode(&buf, img)).To(Succeed())
+	return buf.Bytes()
+}
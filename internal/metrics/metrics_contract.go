@@ -0,0 +1,20 @@
+package metrics
+
+import "io"
+
+type Counter interface {
+	Add(delta float64)
+}
+
+type Gauge interface {
+	Set(value float64)
+}
+
+type Registry interface {
+	// Counter returns the counter registered under name, it is registered on the first call
+	Counter(name, help string) Counter
+	// Gauge returns the gauge registered under name, it is registered on the first call
+	Gauge(name, help string) Gauge
+	// WriteText writes every metric in the Prometheus text exposition format
+	WriteText(w io.Writer) error
+}
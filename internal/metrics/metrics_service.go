@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+const (
+	TYPE_COUNTER = "counter"
+	TYPE_GAUGE   = "gauge"
+)
+
+type metric struct {
+	mu         sync.Mutex
+	name       string
+	help       string
+	metricType string
+	value      float64
+}
+
+func (m *metric) Add(delta float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.value += delta
+}
+
+func (m *metric) Set(value float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.value = value
+}
+
+func (m *metric) get() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.value
+}
+
+type registry struct {
+	mu      sync.Mutex
+	metrics map[string]*metric
+}
+
+func (r *registry) Counter(name, help string) Counter {
+	return r.register(name, help, TYPE_COUNTER)
+}
+
+func (r *registry) Gauge(name, help string) Gauge {
+	return r.register(name, help, TYPE_GAUGE)
+}
+
+func (r *registry) register(name, help, metricType string) *metric {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, isAvailable := r.metrics[name]
+	if !isAvailable {
+		m = &metric{name: name, help: help, metricType: metricType}
+		r.metrics[name] = m
+	}
+	return m
+}
+
+func (r *registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	names := []string{}
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	r.mu.Unlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		r.mu.Lock()
+		m := r.metrics[name]
+		r.mu.Unlock()
+
+		value := strconv.FormatFloat(m.get(), 'g', -1, 64)
+		_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", m.name, m.help, m.name, m.metricType, m.name, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func NewRegistry() Registry {
+	return &registry{
+		metrics: map[string]*metric{},
+	}
+}
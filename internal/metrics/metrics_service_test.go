@@ -0,0 +1,51 @@
+package metrics_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/metrics"
+)
+
+var _ = Describe("Metrics Registry", func() {
+	var (
+		registry metrics.Registry
+	)
+
+	BeforeEach(func() {
+		registry = metrics.NewRegistry()
+	})
+
+	Context("WriteText method", func() {
+		When("metrics are registered", func() {
+			It("should write them sorted by name", func() {
+				registry.Gauge("storage_last_run_seconds", "Last run duration").Set(1.5)
+				registry.Counter("storage_deleted_total", "Deleted files").Add(2)
+				registry.Counter("storage_deleted_total", "Deleted files").Add(1)
+
+				w := &bytes.Buffer{}
+				err := registry.WriteText(w)
+
+				Expect(err).To(BeNil())
+				Expect(w.String()).To(Equal("" +
+					"# HELP storage_deleted_total Deleted files\n" +
+					"# TYPE storage_deleted_total counter\n" +
+					"storage_deleted_total 3\n" +
+					"# HELP storage_last_run_seconds Last run duration\n" +
+					"# TYPE storage_last_run_seconds gauge\n" +
+					"storage_last_run_seconds 1.5\n"))
+			})
+		})
+
+		When("no metric is registered", func() {
+			It("should write nothing", func() {
+				w := &bytes.Buffer{}
+				err := registry.WriteText(w)
+
+				Expect(err).To(BeNil())
+				Expect(w.Len()).To(Equal(0))
+			})
+		})
+	})
+})
@@ -0,0 +1,35 @@
+package quota
+
+const (
+	LIMIT_TOTAL_SIZE = "total_size"
+	LIMIT_FILE_COUNT = "file_count"
+	LIMIT_FILE_SIZE  = "file_size"
+)
+
+type QuotaGetter interface {
+	GetQuota(applicationId string) (*QuotaEntity, error)
+}
+
+type QuotaReserver interface {
+	ReserveQuota(p ReserveQuotaParam) error
+	ReleaseQuota(p ReleaseQuotaParam) error
+}
+
+type QuotaService interface {
+	QuotaGetter
+	QuotaReserver
+}
+
+// ReserveQuotaParam books one more file, IsVersion only books the size
+// of a new content version of an existing file
+type ReserveQuotaParam struct {
+	ApplicationId string
+	Size          int64
+	IsVersion     bool
+}
+
+type ReleaseQuotaParam struct {
+	ApplicationId string
+	Size          int64
+	IsVersion     bool
+}
@@ -0,0 +1,10 @@
+package quota
+
+type QuotaEntity struct {
+	ApplicationId string
+	MaxTotalSize  int64
+	MaxFileCount  int64
+	MaxFileSize   int64
+	TotalSize     int64
+	FileCount     int64
+}
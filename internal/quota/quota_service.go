@@ -0,0 +1,91 @@
+package quota
+
+import (
+	"idaman.id/storage/internal/application"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/repository"
+)
+
+type quotaService struct {
+	appGetter application.ApplicationGetter
+	usageRepo repository.UsageRepository
+}
+
+func (s *quotaService) GetQuota(applicationId string) (*QuotaEntity, error) {
+	app := s.appGetter.GetApplication(applicationId)
+
+	usage, err := s.usageRepo.FindUsage(app.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	quota := &QuotaEntity{
+		ApplicationId: app.Id,
+		MaxTotalSize:  app.Quota.MaxTotalSize,
+		MaxFileCount:  app.Quota.MaxFileCount,
+		MaxFileSize:   app.Quota.MaxFileSize,
+		TotalSize:     usage.TotalSize,
+		FileCount:     usage.FileCount,
+	}
+	return quota, nil
+}
+
+// ReserveQuota books the usage of one file before it is stored,
+// limits equal to zero are considered unlimited
+func (s *quotaService) ReserveQuota(p ReserveQuotaParam) error {
+	app := s.appGetter.GetApplication(p.ApplicationId)
+
+	if app.Quota.MaxFileSize > 0 && p.Size > app.Quota.MaxFileSize {
+		return app_error.NewQuotaExceededError(LIMIT_FILE_SIZE)
+	}
+
+	count := fileCount(p.IsVersion)
+	isReserved, err := s.usageRepo.IncrementUsage(repository.IncrementUsageParam{
+		ApplicationId: app.Id,
+		Size:          p.Size,
+		Count:         count,
+		MaxTotalSize:  app.Quota.MaxTotalSize,
+		MaxFileCount:  app.Quota.MaxFileCount,
+	})
+	if err != nil {
+		return err
+	}
+	if isReserved {
+		return nil
+	}
+
+	usage, err := s.usageRepo.FindUsage(app.Id)
+	if err != nil {
+		return err
+	}
+	isCountExceeded := app.Quota.MaxFileCount > 0 && usage.FileCount+count > app.Quota.MaxFileCount
+	if isCountExceeded {
+		return app_error.NewQuotaExceededError(LIMIT_FILE_COUNT)
+	}
+	return app_error.NewQuotaExceededError(LIMIT_TOTAL_SIZE)
+}
+
+func (s *quotaService) ReleaseQuota(p ReleaseQuotaParam) error {
+	app := s.appGetter.GetApplication(p.ApplicationId)
+
+	return s.usageRepo.DecrementUsage(repository.DecrementUsageParam{
+		ApplicationId: app.Id,
+		Size:          p.Size,
+		Count:         fileCount(p.IsVersion),
+	})
+}
+
+// fileCount tells how many files are booked, a content version shares the count of its file
+func fileCount(isVersion bool) int64 {
+	if isVersion {
+		return 0
+	}
+	return 1
+}
+
+func NewQuotaService(ag application.ApplicationGetter, ur repository.UsageRepository) QuotaService {
+	return &quotaService{
+		appGetter: ag,
+		usageRepo: ur,
+	}
+}
@@ -0,0 +1,128 @@
+package quota_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/application"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/quota"
+	"idaman.id/storage/internal/repository"
+)
+
+var _ = Describe("Quota Service", func() {
+	var (
+		appGetter    *FakeApplicationGetter
+		usageRepo    *FakeUsageRepository
+		quotaService quota.QuotaService
+	)
+
+	BeforeEach(func() {
+		appGetter = &FakeApplicationGetter{
+			quota: application.QuotaEntity{
+				MaxTotalSize: 100,
+				MaxFileCount: 2,
+				MaxFileSize:  60,
+			},
+		}
+		usageRepo = &FakeUsageRepository{}
+		quotaService = quota.NewQuotaService(appGetter, usageRepo)
+	})
+
+	Context("ReserveQuota method", func() {
+		When("quota is available", func() {
+			It("should increase usage", func() {
+				err := quotaService.ReserveQuota(quota.ReserveQuotaParam{ApplicationId: "app-1", Size: 50})
+
+				Expect(err).To(BeNil())
+				Expect(usageRepo.usage.TotalSize).To(Equal(int64(50)))
+				Expect(usageRepo.usage.FileCount).To(Equal(int64(1)))
+			})
+		})
+
+		When("file is larger than max file size", func() {
+			It("should return file size quota error", func() {
+				err := quotaService.ReserveQuota(quota.ReserveQuotaParam{ApplicationId: "app-1", Size: 61})
+
+				Expect(err).To(MatchError(app_error.NewQuotaExceededError(quota.LIMIT_FILE_SIZE)))
+				Expect(usageRepo.usage.FileCount).To(Equal(int64(0)))
+			})
+		})
+
+		When("total size is exceeded", func() {
+			It("should return total size quota error", func() {
+				usageRepo.usage = repository.UsageModel{TotalSize: 60, FileCount: 1}
+				err := quotaService.ReserveQuota(quota.ReserveQuotaParam{ApplicationId: "app-1", Size: 50})
+
+				Expect(err).To(MatchError(app_error.NewQuotaExceededError(quota.LIMIT_TOTAL_SIZE)))
+			})
+		})
+
+		When("file count is exceeded", func() {
+			It("should return file count quota error", func() {
+				usageRepo.usage = repository.UsageModel{TotalSize: 2, FileCount: 2}
+				err := quotaService.ReserveQuota(quota.ReserveQuotaParam{ApplicationId: "app-1", Size: 1})
+
+				Expect(err).To(MatchError(app_error.NewQuotaExceededError(quota.LIMIT_FILE_COUNT)))
+			})
+		})
+
+		When("a new version of an existing file is stored", func() {
+			It("should only increase total size", func() {
+				usageRepo.usage = repository.UsageModel{TotalSize: 2, FileCount: 2}
+				err := quotaService.ReserveQuota(quota.ReserveQuotaParam{ApplicationId: "app-1", Size: 10, IsVersion: true})
+
+				Expect(err).To(BeNil())
+				Expect(usageRepo.usage.TotalSize).To(Equal(int64(12)))
+				Expect(usageRepo.usage.FileCount).To(Equal(int64(2)))
+			})
+		})
+
+		When("quota is unlimited", func() {
+			It("should increase usage", func() {
+				appGetter.quota = application.QuotaEntity{}
+				usageRepo.usage = repository.UsageModel{TotalSize: 1000, FileCount: 1000}
+				err := quotaService.ReserveQuota(quota.ReserveQuotaParam{ApplicationId: "app-1", Size: 1000})
+
+				Expect(err).To(BeNil())
+				Expect(usageRepo.usage.FileCount).To(Equal(int64(1001)))
+			})
+		})
+	})
+
+	Context("ReleaseQuota method", func() {
+		It("should decrease usage", func() {
+			usageRepo.usage = repository.UsageModel{TotalSize: 60, FileCount: 2}
+			err := quotaService.ReleaseQuota(quota.ReleaseQuotaParam{ApplicationId: "app-1", Size: 10})
+
+			Expect(err).To(BeNil())
+			Expect(usageRepo.usage.TotalSize).To(Equal(int64(50)))
+			Expect(usageRepo.usage.FileCount).To(Equal(int64(1)))
+		})
+
+		It("should keep file count of a released version", func() {
+			usageRepo.usage = repository.UsageModel{TotalSize: 60, FileCount: 2}
+			err := quotaService.ReleaseQuota(quota.ReleaseQuotaParam{ApplicationId: "app-1", Size: 10, IsVersion: true})
+
+			Expect(err).To(BeNil())
+			Expect(usageRepo.usage.TotalSize).To(Equal(int64(50)))
+			Expect(usageRepo.usage.FileCount).To(Equal(int64(2)))
+		})
+	})
+
+	Context("GetQuota method", func() {
+		It("should return limits and usage of the application", func() {
+			usageRepo.usage = repository.UsageModel{TotalSize: 60, FileCount: 2}
+			res, err := quotaService.GetQuota("")
+
+			Expect(err).To(BeNil())
+			Expect(res).To(Equal(&quota.QuotaEntity{
+				ApplicationId: application.DEFAULT_APPLICATION,
+				MaxTotalSize:  100,
+				MaxFileCount:  2,
+				MaxFileSize:   60,
+				TotalSize:     60,
+				FileCount:     2,
+			}))
+		})
+	})
+})
@@ -0,0 +1,56 @@
+package quota_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/application"
+	"idaman.id/storage/internal/repository"
+)
+
+func TestQuota(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Quota Package")
+}
+
+type FakeApplicationGetter struct {
+	quota application.QuotaEntity
+}
+
+func (stub *FakeApplicationGetter) GetApplication(id string) *application.ApplicationEntity {
+	if id == "" {
+		id = application.DEFAULT_APPLICATION
+	}
+	return &application.ApplicationEntity{
+		Id:    id,
+		Quota: stub.quota,
+	}
+}
+
+type FakeUsageRepository struct {
+	usage repository.UsageModel
+}
+
+func (stub *FakeUsageRepository) FindUsage(applicationId string) (*repository.UsageModel, error) {
+	usage := stub.usage
+	usage.ApplicationId = applicationId
+	return &usage, nil
+}
+
+func (stub *FakeUsageRepository) IncrementUsage(p repository.IncrementUsageParam) (bool, error) {
+	isSizeExceeded := p.MaxTotalSize > 0 && stub.usage.TotalSize+p.Size > p.MaxTotalSize
+	isCountExceeded := p.MaxFileCount > 0 && stub.usage.FileCount+p.Count > p.MaxFileCount
+	if isSizeExceeded || isCountExceeded {
+		return false, nil
+	}
+	stub.usage.TotalSize += p.Size
+	stub.usage.FileCount += p.Count
+	return true, nil
+}
+
+func (stub *FakeUsageRepository) DecrementUsage(p repository.DecrementUsageParam) error {
+	stub.usage.TotalSize -= p.Size
+	stub.usage.FileCount -= p.Count
+	return nil
+}
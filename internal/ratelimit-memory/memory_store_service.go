@@ -0,0 +1,87 @@
+package ratelimit_memory
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"idaman.id/storage/internal/ratelimit"
+)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	period time.Duration
+}
+
+type memoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func (s *memoryStore) Take(p ratelimit.TakeParam) (*ratelimit.TakeResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	capacity := float64(p.Limit)
+	rate := capacity / p.Period.Seconds()
+
+	b, isAvailable := s.buckets[p.Key]
+	if !isAvailable {
+		b = &bucket{tokens: capacity, last: now}
+		s.buckets[p.Key] = b
+	}
+	b.period = p.Period
+
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+elapsed*rate)
+	}
+	b.last = now
+
+	cost := float64(p.Cost)
+	res := &ratelimit.TakeResult{}
+	if b.tokens >= cost {
+		// a refund never fills the bucket over its capacity
+		b.tokens = math.Min(capacity, b.tokens-cost)
+		res.Allowed = true
+	} else if cost > capacity {
+		res.RetryAfter = p.Period
+	} else {
+		res.RetryAfter = secondsToDuration((cost - b.tokens) / rate)
+	}
+	res.Remaining = int64(math.Floor(b.tokens))
+	res.ResetAfter = secondsToDuration((capacity - b.tokens) / rate)
+	return res, nil
+}
+
+// sweep drops buckets which are already refilled, they behave exactly like new ones
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if now.Sub(b.last) >= b.period {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
+
+func NewMemoryStore() ratelimit.Store {
+	return &memoryStore{
+		buckets:   map[string]*bucket{},
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
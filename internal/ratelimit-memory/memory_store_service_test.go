@@ -0,0 +1,91 @@
+package ratelimit_memory_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/ratelimit"
+	ratelimit_memory "idaman.id/storage/internal/ratelimit-memory"
+)
+
+var _ = Describe("Memory Store", func() {
+	var (
+		store ratelimit.Store
+		param ratelimit.TakeParam
+	)
+
+	BeforeEach(func() {
+		store = ratelimit_memory.NewMemoryStore()
+		param = ratelimit.TakeParam{
+			Key:    "ip:127.0.0.1",
+			Limit:  2,
+			Period: time.Minute,
+			Cost:   1,
+		}
+	})
+
+	Context("Take method", func() {
+		When("bucket has enough token", func() {
+			It("should allow and decrease remaining token", func() {
+				res, err := store.Take(param)
+
+				Expect(err).To(BeNil())
+				Expect(res.Allowed).To(BeTrue())
+				Expect(res.Remaining).To(Equal(int64(1)))
+				Expect(res.RetryAfter).To(Equal(time.Duration(0)))
+				Expect(res.ResetAfter).To(Equal(30 * time.Second))
+			})
+		})
+
+		When("bucket is exhausted", func() {
+			It("should deny with retry after", func() {
+				store.Take(param)
+				store.Take(param)
+				res, err := store.Take(param)
+
+				Expect(err).To(BeNil())
+				Expect(res.Allowed).To(BeFalse())
+				Expect(res.Remaining).To(Equal(int64(0)))
+				Expect(res.RetryAfter).To(BeNumerically("~", 30*time.Second, time.Second))
+			})
+		})
+
+		When("cost is larger than the bucket capacity", func() {
+			It("should deny with retry after of one period", func() {
+				param.Cost = 3
+				res, err := store.Take(param)
+
+				Expect(err).To(BeNil())
+				Expect(res.Allowed).To(BeFalse())
+				Expect(res.Remaining).To(Equal(int64(2)))
+				Expect(res.RetryAfter).To(Equal(time.Minute))
+			})
+		})
+
+		When("cost is refunded", func() {
+			It("should give the tokens back without exceeding the capacity", func() {
+				store.Take(param)
+				param.Cost = -1
+				store.Take(param)
+				res, err := store.Take(param)
+
+				Expect(err).To(BeNil())
+				Expect(res.Allowed).To(BeTrue())
+				Expect(res.Remaining).To(Equal(int64(2)))
+			})
+		})
+
+		When("different key is used", func() {
+			It("should use separated bucket", func() {
+				store.Take(param)
+				store.Take(param)
+				param.Key = "ip:127.0.0.2"
+				res, err := store.Take(param)
+
+				Expect(err).To(BeNil())
+				Expect(res.Allowed).To(BeTrue())
+			})
+		})
+	})
+})
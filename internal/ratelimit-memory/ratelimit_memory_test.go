@@ -0,0 +1,13 @@
+package ratelimit_memory_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRatelimitMemory(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RatelimitMemory Package")
+}
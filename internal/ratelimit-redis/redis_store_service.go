@@ -0,0 +1,85 @@
+package ratelimit_redis
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"idaman.id/storage/internal/database"
+	"idaman.id/storage/internal/ratelimit"
+)
+
+const tokenBucketScript = `
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+local now = tonumber(ARGV[4])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or limit
+local ts = tonumber(state[2]) or now
+tokens = math.min(limit, tokens + math.max(0, now - ts) * limit / period)
+local allowed = 0
+if tokens >= cost then
+	tokens = math.min(limit, tokens - cost)
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], period)
+return {allowed, tostring(tokens)}
+`
+
+type redisStore struct {
+	client database.RedisClient
+	prefix string
+	now    func() time.Time
+}
+
+func (s *redisStore) Take(p ratelimit.TakeParam) (*ratelimit.TakeResult, error) {
+	period := p.Period.Milliseconds()
+	now := s.now().UnixNano() / int64(time.Millisecond)
+
+	reply, err := s.client.Do("EVAL", tokenBucketScript, 1, s.prefix+p.Key, p.Limit, period, p.Cost, now)
+	if err != nil {
+		return nil, err
+	}
+
+	list, isList := reply.([]interface{})
+	if !isList || len(list) != 2 {
+		return nil, errors.New("invalid rate limit reply")
+	}
+	allowed, _ := list[0].(int64)
+	tokenString, _ := list[1].(string)
+	tokens, err := strconv.ParseFloat(tokenString, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	capacity := float64(p.Limit)
+	rate := capacity / p.Period.Seconds()
+	cost := float64(p.Cost)
+
+	res := &ratelimit.TakeResult{
+		Allowed:    allowed == 1,
+		Remaining:  int64(math.Floor(tokens)),
+		ResetAfter: secondsToDuration((capacity - tokens) / rate),
+	}
+	if !res.Allowed && cost > capacity {
+		res.RetryAfter = p.Period
+	} else if !res.Allowed {
+		res.RetryAfter = secondsToDuration((cost - tokens) / rate)
+	}
+	return res, nil
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
+
+func NewRedisStore(c database.RedisClient) ratelimit.Store {
+	return &redisStore{
+		client: c,
+		prefix: "goseidon:ratelimit:",
+		now:    time.Now,
+	}
+}
@@ -0,0 +1,120 @@
+package ratelimit_redis_test
+
+import (
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/database"
+	"idaman.id/storage/internal/ratelimit"
+	ratelimit_redis "idaman.id/storage/internal/ratelimit-redis"
+)
+
+var _ = Describe("Redis Store", func() {
+	var (
+		server *FakeRedisServer
+		reply  string
+		store  ratelimit.Store
+		param  ratelimit.TakeParam
+	)
+
+	BeforeEach(func() {
+		reply = "*2\r\n:1\r\n$3\r\n1.5\r\n"
+		serverReply := &reply
+		server = NewFakeRedisServer(func(args []string) string {
+			return *serverReply
+		})
+		store = ratelimit_redis.NewRedisStore(NewFakeRedisClient(server))
+		param = ratelimit.TakeParam{
+			Key:    "ip:127.0.0.1",
+			Limit:  2,
+			Period: time.Minute,
+			Cost:   1,
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("Take method", func() {
+		When("bucket has enough token", func() {
+			It("should run the token bucket script and allow", func() {
+				res, err := store.Take(param)
+
+				Expect(err).To(BeNil())
+				Expect(res).To(Equal(&ratelimit.TakeResult{
+					Allowed:    true,
+					Remaining:  1,
+					ResetAfter: 15 * time.Second,
+				}))
+
+				commands := server.Commands()
+				Expect(commands).To(HaveLen(1))
+				Expect(commands[0]).To(HaveLen(8))
+				Expect(commands[0][0]).To(Equal("EVAL"))
+				Expect(commands[0][2:7]).To(Equal([]string{"1", "goseidon:ratelimit:ip:127.0.0.1", "2", "60000", "1"}))
+				now, err := strconv.ParseInt(commands[0][7], 10, 64)
+				Expect(err).To(BeNil())
+				Expect(now).To(BeNumerically("~", time.Now().UnixNano()/int64(time.Millisecond), 5000))
+			})
+		})
+
+		When("bucket is exhausted", func() {
+			It("should deny with retry after", func() {
+				reply = "*2\r\n:0\r\n$3\r\n0.5\r\n"
+				res, err := store.Take(param)
+
+				Expect(err).To(BeNil())
+				Expect(res.Allowed).To(BeFalse())
+				Expect(res.Remaining).To(Equal(int64(0)))
+				Expect(res.RetryAfter).To(Equal(15 * time.Second))
+			})
+		})
+
+		When("cost is larger than the bucket capacity", func() {
+			It("should deny with retry after of one period", func() {
+				reply = "*2\r\n:0\r\n$1\r\n2\r\n"
+				param.Cost = 3
+				res, err := store.Take(param)
+
+				Expect(err).To(BeNil())
+				Expect(res.Allowed).To(BeFalse())
+				Expect(res.RetryAfter).To(Equal(time.Minute))
+			})
+		})
+
+		When("cost is refunded", func() {
+			It("should send the negative cost", func() {
+				reply = "*2\r\n:1\r\n$1\r\n2\r\n"
+				param.Cost = -1
+				res, err := store.Take(param)
+
+				Expect(err).To(BeNil())
+				Expect(res.Remaining).To(Equal(int64(2)))
+				Expect(server.Commands()[0][6]).To(Equal("-1"))
+			})
+		})
+
+		When("reply is not the script result", func() {
+			It("should return error", func() {
+				reply = "+OK\r\n"
+				res, err := store.Take(param)
+
+				Expect(res).To(BeNil())
+				Expect(err).To(MatchError("invalid rate limit reply"))
+			})
+		})
+
+		When("server replies with error", func() {
+			It("should return the redis error", func() {
+				reply = "-ERR scripting is disabled\r\n"
+				res, err := store.Take(param)
+
+				Expect(res).To(BeNil())
+				Expect(err).To(Equal(&database.RedisError{Message: "ERR scripting is disabled"}))
+			})
+		})
+	})
+})
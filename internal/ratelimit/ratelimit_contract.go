@@ -0,0 +1,45 @@
+package ratelimit
+
+import "time"
+
+type Limiter interface {
+	// LimitIp consumes the budget of the client ip, it is checked before the caller is authenticated
+	LimitIp(ip string) (*LimitResult, error)
+	// Limit consumes the route and upload budgets of the authenticated principal,
+	// anonymous callers are identified by their ip
+	Limit(p LimitParam) (*LimitResult, error)
+}
+
+type LimitParam struct {
+	Route      string
+	Ip         string
+	Principal  string
+	IsUpload   bool
+	UploadSize int64
+}
+
+type LimitResult struct {
+	Limit      int64
+	Remaining  int64
+	ResetAfter time.Duration
+	RetryAfter time.Duration
+}
+
+type Store interface {
+	Take(p TakeParam) (*TakeResult, error)
+}
+
+// TakeParam takes Cost tokens from the bucket of Key, a negative Cost refunds them without exceeding Limit
+type TakeParam struct {
+	Key    string
+	Limit  int64
+	Period time.Duration
+	Cost   int64
+}
+
+type TakeResult struct {
+	Allowed    bool
+	Remaining  int64
+	ResetAfter time.Duration
+	RetryAfter time.Duration
+}
@@ -0,0 +1,138 @@
+package ratelimit
+
+import (
+	"time"
+
+	"idaman.id/storage/internal/config"
+	app_error "idaman.id/storage/internal/error"
+)
+
+type bucketPolicy struct {
+	name   string
+	limit  int64
+	period time.Duration
+}
+
+type rateLimitService struct {
+	store           Store
+	ipPolicy        bucketPolicy
+	principalPolicy bucketPolicy
+	routePolicy     bucketPolicy
+	countPolicy     bucketPolicy
+	bytesPolicy     bucketPolicy
+}
+
+type bucketTake struct {
+	policy bucketPolicy
+	key    string
+	cost   int64
+}
+
+func (s *rateLimitService) LimitIp(ip string) (*LimitResult, error) {
+	return s.take([]bucketTake{
+		{policy: s.ipPolicy, key: ip, cost: 1},
+	})
+}
+
+func (s *rateLimitService) Limit(p LimitParam) (*LimitResult, error) {
+	client := "ip:" + p.Ip
+	if p.Principal != "" {
+		client = "principal:" + p.Principal
+	}
+
+	takes := []bucketTake{
+		{policy: s.routePolicy, key: p.Route + ":" + client, cost: 1},
+	}
+	if p.Principal != "" {
+		takes = append(takes, bucketTake{policy: s.principalPolicy, key: p.Principal, cost: 1})
+	}
+	if p.IsUpload {
+		takes = append(takes,
+			bucketTake{policy: s.countPolicy, key: client, cost: 1},
+			bucketTake{policy: s.bytesPolicy, key: client, cost: p.UploadSize},
+		)
+	}
+	return s.take(takes)
+}
+
+// take consumes every bucket or none of them,
+// the tokens already taken are refunded once a bucket denies the request
+func (s *rateLimitService) take(takes []bucketTake) (*LimitResult, error) {
+	var result *LimitResult
+	taken := []TakeParam{}
+	for _, t := range takes {
+		if t.policy.limit <= 0 || t.policy.period <= 0 {
+			continue
+		}
+
+		param := TakeParam{
+			Key:    t.policy.name + ":" + t.key,
+			Limit:  t.policy.limit,
+			Period: t.policy.period,
+			Cost:   t.cost,
+		}
+		res, err := s.store.Take(param)
+		if err != nil {
+			s.refund(taken)
+			return nil, err
+		}
+
+		current := &LimitResult{
+			Limit:      t.policy.limit,
+			Remaining:  res.Remaining,
+			ResetAfter: res.ResetAfter,
+			RetryAfter: res.RetryAfter,
+		}
+		if !res.Allowed {
+			s.refund(taken)
+			return current, app_error.NewTooManyRequestError(t.policy.name, res.RetryAfter)
+		}
+		taken = append(taken, param)
+		result = MostRestrictive(result, current)
+	}
+
+	if result == nil {
+		result = &LimitResult{}
+	}
+	return result, nil
+}
+
+func (s *rateLimitService) refund(taken []TakeParam) {
+	for _, param := range taken {
+		param.Cost = -param.Cost
+		s.store.Take(param)
+	}
+}
+
+// MostRestrictive returns the result having the lowest remaining ratio, nil result is ignored
+func MostRestrictive(a, b *LimitResult) *LimitResult {
+	if a == nil || a.Limit <= 0 {
+		return b
+	}
+	if b == nil || b.Limit <= 0 {
+		return a
+	}
+	if b.Remaining*a.Limit < a.Remaining*b.Limit {
+		return b
+	}
+	return a
+}
+
+func newBucketPolicy(cg config.Getter, name, prefix string) bucketPolicy {
+	return bucketPolicy{
+		name:   name,
+		limit:  int64(cg.GetInt(prefix + "_LIMIT")),
+		period: time.Duration(cg.GetInt(prefix+"_PERIOD")) * time.Second,
+	}
+}
+
+func NewRateLimitService(s Store, cg config.Getter) Limiter {
+	return &rateLimitService{
+		store:           s,
+		ipPolicy:        newBucketPolicy(cg, "ip", "RATE_LIMIT_IP"),
+		principalPolicy: newBucketPolicy(cg, "principal", "RATE_LIMIT_PRINCIPAL"),
+		routePolicy:     newBucketPolicy(cg, "route", "RATE_LIMIT_ROUTE"),
+		countPolicy:     newBucketPolicy(cg, "upload-count", "RATE_LIMIT_UPLOAD_COUNT"),
+		bytesPolicy:     newBucketPolicy(cg, "upload-bytes", "RATE_LIMIT_UPLOAD_BYTES"),
+	}
+}
@@ -0,0 +1,112 @@
+package ratelimit_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/ratelimit"
+)
+
+var _ = Describe("Rate Limit Service", func() {
+	var (
+		store   *FakeStore
+		limiter ratelimit.Limiter
+		param   ratelimit.LimitParam
+	)
+
+	BeforeEach(func() {
+		store = &FakeStore{}
+		limiter = ratelimit.NewRateLimitService(store, &FakeConfig{values: map[string]int{
+			"RATE_LIMIT_IP_LIMIT":            10,
+			"RATE_LIMIT_IP_PERIOD":           60,
+			"RATE_LIMIT_PRINCIPAL_LIMIT":     20,
+			"RATE_LIMIT_PRINCIPAL_PERIOD":    60,
+			"RATE_LIMIT_ROUTE_LIMIT":         5,
+			"RATE_LIMIT_ROUTE_PERIOD":        60,
+			"RATE_LIMIT_UPLOAD_COUNT_LIMIT":  3,
+			"RATE_LIMIT_UPLOAD_COUNT_PERIOD": 3600,
+			"RATE_LIMIT_UPLOAD_BYTES_LIMIT":  1000,
+			"RATE_LIMIT_UPLOAD_BYTES_PERIOD": 3600,
+		}})
+		param = ratelimit.LimitParam{
+			Route: "get-resource",
+			Ip:    "127.0.0.1",
+		}
+	})
+
+	Context("LimitIp method", func() {
+		When("ip bucket is available", func() {
+			It("should take the ip bucket only", func() {
+				res, err := limiter.LimitIp("127.0.0.1")
+
+				Expect(err).To(BeNil())
+				Expect(store.taken).To(HaveLen(1))
+				Expect(store.taken[0].Key).To(Equal("ip:127.0.0.1"))
+				Expect(res.Limit).To(Equal(int64(10)))
+			})
+		})
+
+		When("ip bucket is exhausted", func() {
+			It("should return too many request error", func() {
+				store.denied = "ip:127.0.0.1"
+				res, err := limiter.LimitIp("127.0.0.1")
+
+				Expect(res.Limit).To(Equal(int64(10)))
+				Expect(err).To(BeAssignableToTypeOf(&app_error.TooManyRequestError{}))
+			})
+		})
+	})
+
+	Context("Limit method", func() {
+		When("request is anonymous", func() {
+			It("should take the route bucket of the ip", func() {
+				res, err := limiter.Limit(param)
+
+				Expect(err).To(BeNil())
+				Expect(store.taken).To(HaveLen(1))
+				Expect(store.taken[0].Key).To(Equal("route:get-resource:ip:127.0.0.1"))
+				Expect(res.Limit).To(Equal(int64(5)))
+				Expect(res.Remaining).To(Equal(int64(4)))
+			})
+		})
+
+		When("request is an upload of a principal", func() {
+			It("should take upload budgets by count and bytes", func() {
+				param.Principal = "sub:user-1"
+				param.IsUpload = true
+				param.UploadSize = 400
+				res, err := limiter.Limit(param)
+
+				Expect(err).To(BeNil())
+				Expect(store.taken).To(HaveLen(4))
+				Expect(store.taken[0].Key).To(Equal("route:get-resource:principal:sub:user-1"))
+				Expect(store.taken[1].Key).To(Equal("principal:sub:user-1"))
+				Expect(store.taken[2].Key).To(Equal("upload-count:principal:sub:user-1"))
+				Expect(store.taken[3].Key).To(Equal("upload-bytes:principal:sub:user-1"))
+				Expect(store.taken[3].Cost).To(Equal(int64(400)))
+				Expect(res.Limit).To(Equal(int64(1000)))
+				Expect(res.Remaining).To(Equal(int64(600)))
+			})
+		})
+
+		When("a later bucket is exhausted", func() {
+			It("should refund the buckets taken before", func() {
+				param.Principal = "sub:user-1"
+				param.IsUpload = true
+				param.UploadSize = 400
+				store.denied = "upload-bytes:principal:sub:user-1"
+				res, err := limiter.Limit(param)
+
+				Expect(res.Limit).To(Equal(int64(1000)))
+				Expect(err).To(BeAssignableToTypeOf(&app_error.TooManyRequestError{}))
+				Expect(store.taken).To(HaveLen(7))
+				Expect(store.taken[4]).To(Equal(ratelimit.TakeParam{Key: "route:get-resource:principal:sub:user-1", Limit: 5, Period: time.Minute, Cost: -1}))
+				Expect(store.taken[5].Key).To(Equal("principal:sub:user-1"))
+				Expect(store.taken[6].Key).To(Equal("upload-count:principal:sub:user-1"))
+				Expect(store.taken[6].Cost).To(Equal(int64(-1)))
+			})
+		})
+	})
+})
@@ -0,0 +1,48 @@
+package ratelimit_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/ratelimit"
+)
+
+func TestRatelimit(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Ratelimit Package")
+}
+
+type FakeConfig struct {
+	values map[string]int
+}
+
+func (c *FakeConfig) GetString(key string) string {
+	return ""
+}
+
+func (c *FakeConfig) GetInt(key string) int {
+	return c.values[key]
+}
+
+func (c *FakeConfig) GetBool(key string) bool {
+	return false
+}
+
+func (c *FakeConfig) Get(key string) interface{} {
+	return c.values[key]
+}
+
+type FakeStore struct {
+	taken  []ratelimit.TakeParam
+	denied string
+}
+
+func (s *FakeStore) Take(p ratelimit.TakeParam) (*ratelimit.TakeResult, error) {
+	s.taken = append(s.taken, p)
+	res := &ratelimit.TakeResult{
+		Allowed:   p.Key != s.denied,
+		Remaining: p.Limit - p.Cost,
+	}
+	return res, nil
+}
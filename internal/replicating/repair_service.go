@@ -0,0 +1,140 @@
+package replicating
+
+import (
+	"fmt"
+	"time"
+
+	"idaman.id/storage/internal/config"
+	"idaman.id/storage/internal/metrics"
+	"idaman.id/storage/internal/repository"
+	"idaman.id/storage/internal/storage"
+)
+
+type repairMetrics struct {
+	runs         metrics.Counter
+	repaired     metrics.Counter
+	failed       metrics.Counter
+	lastRun      metrics.Gauge
+	lastDuration metrics.Gauge
+}
+
+type repairService struct {
+	configGetter config.Getter
+	replicaRepo  repository.ReplicaRepository
+	registry     storage.Registry
+	retriever    storage.Retriever
+	metrics      repairMetrics
+	now          func() time.Time
+	// cursorId and cursorProvider are the version id and provider of the last replica repaired
+	cursorId       int64
+	cursorProvider string
+}
+
+func (s *repairService) Repair() (*RepairResult, error) {
+	startedAt := s.now()
+	res := &RepairResult{}
+	err := s.repair(startedAt, res)
+
+	s.metrics.runs.Add(1)
+	s.metrics.repaired.Add(float64(res.RepairedReplicas))
+	s.metrics.failed.Add(float64(res.FailedReplicas))
+	s.metrics.lastRun.Set(float64(startedAt.Unix()))
+	s.metrics.lastDuration.Set(s.now().Sub(startedAt).Seconds())
+	return res, err
+}
+
+// repair checks at most REPLICATION_REPAIR_MAX_BATCHES batches of unsynced replicas,
+// the cursor goes back to the first replica once the last one is checked
+func (s *repairService) repair(now time.Time, res *RepairResult) error {
+	providers := ParseProviders(s.configGetter.GetString("REPLICATION_PROVIDERS"))
+	pendingBefore := now.Add(-time.Duration(s.configGetter.GetInt("REPLICATION_PENDING_TIMEOUT")) * time.Second)
+
+	batchSize := s.configGetter.GetInt("REPLICATION_REPAIR_BATCH_SIZE")
+	maxBatches := s.configGetter.GetInt("REPLICATION_REPAIR_MAX_BATCHES")
+	for batch := 0; batch < maxBatches; batch++ {
+		replicas, err := s.replicaRepo.FindUnsynced(repository.FindUnsyncedReplicaParam{
+			Providers:     providers,
+			AfterId:       s.cursorId,
+			AfterProvider: s.cursorProvider,
+			PendingBefore: &pendingBefore,
+			Limit:         batchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		// the replicas of the last version may continue in the next batch,
+		// which starts right after the provider of the last replica
+		isFull := len(replicas) >= batchSize
+		sources := map[string]storage.BinaryFile{}
+		for _, replica := range replicas {
+			s.cursorId = replica.VersionId
+			s.cursorProvider = replica.Provider
+			if s.repairReplica(replica, sources, now) {
+				res.RepairedReplicas++
+			} else {
+				res.FailedReplicas++
+			}
+		}
+
+		if !isFull {
+			s.cursorId = 0
+			s.cursorProvider = ""
+			break
+		}
+	}
+	return nil
+}
+
+// repairReplica reads the content once for every replica of the version,
+// the source may be read from another replica when the primary copy is lost
+func (s *repairService) repairReplica(replica repository.ReplicaModel, sources map[string]storage.BinaryFile, now time.Time) bool {
+	status := REPLICA_STATUS_FAILED
+	defer func() {
+		s.replicaRepo.SaveStatus(repository.SaveReplicaStatusParam{
+			FileName:  replica.FileName,
+			Provider:  replica.Provider,
+			Status:    status,
+			UpdatedAt: &now,
+		})
+	}()
+
+	provider, err := s.registry.Provider(replica.Provider)
+	if err != nil {
+		return false
+	}
+
+	fileData, ok := sources[replica.FileName]
+	if !ok {
+		fileData, err = s.retriever.RetrieveFile(fmt.Sprintf("%s/%s", replica.FileLocation, replica.FileName))
+		if err != nil {
+			return false
+		}
+		sources[replica.FileName] = fileData
+	}
+
+	err = copyContent(provider, replica.FileName, fileData)
+	if err != nil {
+		return false
+	}
+	status = REPLICA_STATUS_SYNCED
+	return true
+}
+
+// NewRepairService reads the source contents through sr, which is expected to be the Replicator
+func NewRepairService(cg config.Getter, rr repository.ReplicaRepository, r storage.Registry, sr storage.Retriever, mr metrics.Registry) Repairer {
+	return &repairService{
+		configGetter: cg,
+		replicaRepo:  rr,
+		registry:     r,
+		retriever:    sr,
+		metrics: repairMetrics{
+			runs:         mr.Counter("storage_replication_repair_runs_total", "Number of replica repair runs"),
+			repaired:     mr.Counter("storage_replication_repaired_total", "Number of replicas copied by the repair job"),
+			failed:       mr.Counter("storage_replication_repair_failed_total", "Number of replicas which could not be repaired"),
+			lastRun:      mr.Gauge("storage_replication_repair_last_run_timestamp_seconds", "Start time of the last replica repair run"),
+			lastDuration: mr.Gauge("storage_replication_repair_last_run_duration_seconds", "Duration of the last replica repair run"),
+		},
+		now: time.Now,
+	}
+}
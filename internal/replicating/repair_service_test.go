@@ -0,0 +1,119 @@
+package replicating_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/metrics"
+	"idaman.id/storage/internal/replicating"
+	"idaman.id/storage/internal/repository"
+	"idaman.id/storage/internal/storage"
+)
+
+var _ = Describe("Repair Service", func() {
+	var (
+		repairer      replicating.Repairer
+		replicaRepo   *FakeReplicaRepository
+		localStorage  *FakeStorage
+		backupStorage *FakeStorage
+		remoteStorage *FakeStorage
+		config        *FakeConfig
+	)
+
+	newReplica := func(versionId int64, fileName, provider string) repository.ReplicaModel {
+		return repository.ReplicaModel{
+			VersionId:    versionId,
+			FileLocation: "storage/file",
+			FileName:     fileName,
+			Provider:     provider,
+		}
+	}
+
+	BeforeEach(func() {
+		replicaRepo = &FakeReplicaRepository{
+			statuses: map[string]string{},
+			unsynced: []repository.ReplicaModel{
+				newReplica(1, "a.txt", "backup"),
+				newReplica(1, "a.txt", "remote"),
+				newReplica(2, "b.txt", "backup"),
+				newReplica(2, "b.txt", "remote"),
+				newReplica(3, "c.txt", "backup"),
+			},
+		}
+		localStorage = &FakeStorage{location: "storage/file", files: map[string][]byte{
+			"storage/file/a.txt": []byte("a"),
+			"storage/file/b.txt": []byte("b"),
+			"storage/file/c.txt": []byte("c"),
+		}}
+		backupStorage = &FakeStorage{location: "storage/backup", files: map[string][]byte{}}
+		remoteStorage = &FakeStorage{location: "storage/remote", files: map[string][]byte{}}
+		registry, _ := storage.NewProviderRegistry("local", []storage.ProviderEntity{
+			{Name: "local", Location: "storage/file", Storage: localStorage},
+			{Name: "backup", Location: "storage/backup", Storage: backupStorage},
+			{Name: "remote", Location: "storage/remote", Storage: remoteStorage},
+		})
+		config = &FakeConfig{values: map[string]interface{}{
+			"DEFAULT_PROVIDER":               "local",
+			"REPLICATION_PROVIDERS":          "backup, remote",
+			"REPLICATION_PENDING_TIMEOUT":    3600,
+			"REPLICATION_REPAIR_BATCH_SIZE":  3,
+			"REPLICATION_REPAIR_MAX_BATCHES": 10,
+		}}
+		mr := metrics.NewRegistry()
+		replicator := replicating.NewReplicateService(config, registry, replicaRepo, mr)
+		repairer = replicating.NewRepairService(config, replicaRepo, registry, replicator, mr)
+	})
+
+	Context("Repair method", func() {
+		It("should copy every unsynced replica", func() {
+			res, err := repairer.Repair()
+
+			Expect(err).To(BeNil())
+			Expect(res).To(Equal(&replicating.RepairResult{RepairedReplicas: 5}))
+			Expect(backupStorage.files).To(HaveLen(3))
+			Expect(remoteStorage.files["storage/remote/b.txt"]).To(Equal([]byte("b")))
+			Expect(replicaRepo.statuses["c.txt@backup"]).To(Equal("synced"))
+		})
+
+		It("should continue the next batch after the last repaired replica", func() {
+			_, err := repairer.Repair()
+
+			Expect(err).To(BeNil())
+			Expect(replicaRepo.queries[0].AfterId).To(Equal(int64(0)))
+			Expect(replicaRepo.queries[1].AfterId).To(Equal(int64(2)))
+			Expect(replicaRepo.queries[1].AfterProvider).To(Equal("backup"))
+		})
+
+		It("should repair every replica of a version larger than the batch", func() {
+			config.values["REPLICATION_REPAIR_BATCH_SIZE"] = 1
+
+			res, err := repairer.Repair()
+
+			Expect(err).To(BeNil())
+			Expect(res).To(Equal(&replicating.RepairResult{RepairedReplicas: 5}))
+			Expect(replicaRepo.statuses["a.txt@remote"]).To(Equal("synced"))
+			Expect(replicaRepo.statuses["b.txt@remote"]).To(Equal("synced"))
+		})
+
+		It("should read the source from a replica when the primary copy is lost", func() {
+			delete(localStorage.files, "storage/file/a.txt")
+			backupStorage.files["storage/backup/a.txt"] = []byte("a")
+			replicaRepo.statuses["a.txt@backup"] = "synced"
+
+			res, err := repairer.Repair()
+
+			Expect(err).To(BeNil())
+			Expect(res.RepairedReplicas).To(Equal(int64(4)))
+			Expect(remoteStorage.files["storage/remote/a.txt"]).To(Equal([]byte("a")))
+		})
+
+		It("should record the replicas which could not be repaired", func() {
+			remoteStorage.isDown = true
+
+			res, err := repairer.Repair()
+
+			Expect(err).To(BeNil())
+			Expect(res).To(Equal(&replicating.RepairResult{RepairedReplicas: 3, FailedReplicas: 2}))
+			Expect(replicaRepo.statuses["a.txt@remote"]).To(Equal("failed"))
+		})
+	})
+})
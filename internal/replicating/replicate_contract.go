@@ -0,0 +1,31 @@
+package replicating
+
+import "idaman.id/storage/internal/storage"
+
+const (
+	REPLICATION_MODE_SYNC  = "sync"
+	REPLICATION_MODE_ASYNC = "async"
+
+	REPLICA_STATUS_PENDING = "pending"
+	REPLICA_STATUS_SYNCED  = "synced"
+	REPLICA_STATUS_FAILED  = "failed"
+)
+
+// Replicator is the storage writing to the primary provider and copying every saved content to the replica providers,
+// contents are read from the primary provider first and from the replicas when it fails
+type Replicator interface {
+	storage.StreamStorage
+	// ProcessQueue copies the contents queued by async replication, it blocks forever
+	ProcessQueue()
+}
+
+type Repairer interface {
+	// Repair copies the contents whose replica is missing, failed or pending for too long,
+	// every run continues after the last version repaired by the previous run and wraps around at the end
+	Repair() (*RepairResult, error)
+}
+
+type RepairResult struct {
+	RepairedReplicas int64
+	FailedReplicas   int64
+}
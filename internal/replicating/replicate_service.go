@@ -0,0 +1,247 @@
+package replicating
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"idaman.id/storage/internal/config"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/metrics"
+	"idaman.id/storage/internal/repository"
+	"idaman.id/storage/internal/storage"
+)
+
+type replicateMetrics struct {
+	synced        metrics.Counter
+	failed        metrics.Counter
+	dropped       metrics.Counter
+	readFallbacks metrics.Counter
+}
+
+// replicateJob copies fileData, or the primary copy under filePath when the content was streamed
+type replicateJob struct {
+	filePath  string
+	fileName  string
+	fileData  storage.BinaryFile
+	providers []*storage.ProviderEntity
+}
+
+type replicateService struct {
+	configGetter config.Getter
+	registry     storage.Registry
+	replicaRepo  repository.ReplicaRepository
+	metrics      replicateMetrics
+	queue        chan replicateJob
+	now          func() time.Time
+}
+
+// SaveFile saves to the primary provider before copying to the replicas,
+// the content is saved once the primary copy is, whatever happens to the replicas
+func (s *replicateService) SaveFile(p storage.SaveFileParam) (*storage.SaveFileResult, error) {
+	res, err := s.registry.SaveFile(p)
+	if err != nil {
+		return nil, err
+	}
+	s.replicateSaved(p.Provider, res, p.FileData)
+	return res, nil
+}
+
+// SaveStream streams to the primary provider, the replicas are copied from the primary copy
+// since the streamed content is not kept in memory
+func (s *replicateService) SaveStream(p storage.SaveStreamParam) (*storage.SaveFileResult, error) {
+	res, err := s.registry.SaveStream(p)
+	if err != nil {
+		return nil, err
+	}
+	s.replicateSaved(p.Provider, res, nil)
+	return res, nil
+}
+
+// replicateSaved copies a content saved to the primary provider, nil fileData is read from the primary copy
+func (s *replicateService) replicateSaved(primary string, res *storage.SaveFileResult, fileData storage.BinaryFile) {
+	if primary == "" {
+		primary = s.configGetter.GetString("DEFAULT_PROVIDER")
+	}
+	providers := s.replicaProviders(primary, res.FileLocation)
+	if len(providers) == 0 {
+		return
+	}
+
+	job := replicateJob{
+		filePath:  fmt.Sprintf("%s/%s", res.FileLocation, res.FileName),
+		fileName:  res.FileName,
+		fileData:  fileData,
+		providers: providers,
+	}
+	if s.configGetter.GetString("REPLICATION_MODE") != REPLICATION_MODE_ASYNC {
+		s.replicateJob(job)
+		return
+	}
+
+	for _, provider := range providers {
+		s.saveStatus(res.FileName, provider.Name, REPLICA_STATUS_PENDING)
+	}
+	select {
+	case s.queue <- job:
+	default:
+		// the replicas stay pending and are copied by the repair job
+		s.metrics.dropped.Add(float64(len(providers)))
+	}
+}
+
+// RetrieveFile reads the same file name from every replica when the primary copy can not be read,
+// the primary error is returned when no replica holds the content
+func (s *replicateService) RetrieveFile(localPath string) (storage.BinaryFile, error) {
+	fileData, err := s.registry.RetrieveFile(localPath)
+	if err == nil {
+		return fileData, nil
+	}
+
+	for _, provider := range s.replicaProviders("", "") {
+		replicaPath := fmt.Sprintf("%s/%s", provider.Location, path.Base(localPath))
+		if replicaPath == localPath {
+			continue
+		}
+		replicaData, replicaErr := provider.Storage.RetrieveFile(replicaPath)
+		if replicaErr == nil {
+			s.metrics.readFallbacks.Add(1)
+			return replicaData, nil
+		}
+	}
+	return nil, err
+}
+
+// DeleteFile deletes the replicas on a best effort basis, only the primary error is returned
+func (s *replicateService) DeleteFile(localPath string) error {
+	err := s.registry.DeleteFile(localPath)
+
+	fileName := path.Base(localPath)
+	for _, provider := range s.replicaProviders("", "") {
+		replicaPath := fmt.Sprintf("%s/%s", provider.Location, fileName)
+		if replicaPath != localPath {
+			provider.Storage.DeleteFile(replicaPath)
+		}
+	}
+	s.replicaRepo.DeleteByFileName(fileName)
+	return err
+}
+
+func (s *replicateService) ProcessQueue() {
+	for job := range s.queue {
+		s.replicateJob(job)
+	}
+}
+
+// replicateJob records every replica as failed when the streamed content can not be read back,
+// so the repair job copies them later
+func (s *replicateService) replicateJob(job replicateJob) {
+	fileData := job.fileData
+	if fileData == nil {
+		var err error
+		fileData, err = s.registry.RetrieveFile(job.filePath)
+		if err != nil {
+			for _, provider := range job.providers {
+				s.metrics.failed.Add(1)
+				s.saveStatus(job.fileName, provider.Name, REPLICA_STATUS_FAILED)
+			}
+			return
+		}
+	}
+	for _, provider := range job.providers {
+		s.replicate(provider, job.fileName, fileData)
+	}
+}
+
+func (s *replicateService) replicate(provider *storage.ProviderEntity, fileName string, fileData storage.BinaryFile) bool {
+	err := copyContent(provider, fileName, fileData)
+	if err != nil {
+		s.metrics.failed.Add(1)
+		s.saveStatus(fileName, provider.Name, REPLICA_STATUS_FAILED)
+		return false
+	}
+	s.metrics.synced.Add(1)
+	s.saveStatus(fileName, provider.Name, REPLICA_STATUS_SYNCED)
+	return true
+}
+
+func (s *replicateService) saveStatus(fileName, provider, status string) {
+	updatedAt := s.now()
+	s.replicaRepo.SaveStatus(repository.SaveReplicaStatusParam{
+		FileName:  fileName,
+		Provider:  provider,
+		Status:    status,
+		UpdatedAt: &updatedAt,
+	})
+}
+
+// replicaProviders returns the configured REPLICATION_PROVIDERS except the primary one,
+// providers which are not configured are validated on start up and skipped here
+func (s *replicateService) replicaProviders(primary, primaryLocation string) []*storage.ProviderEntity {
+	providers := []*storage.ProviderEntity{}
+	for _, name := range ParseProviders(s.configGetter.GetString("REPLICATION_PROVIDERS")) {
+		if name == primary {
+			continue
+		}
+		provider, err := s.registry.Provider(name)
+		if err != nil || provider.Location == primaryLocation {
+			continue
+		}
+		providers = append(providers, provider)
+	}
+	return providers
+}
+
+// copyContent saves the content to the provider,
+// a different content left under the same name is replaced while the same one is kept
+func copyContent(provider *storage.ProviderEntity, fileName string, fileData storage.BinaryFile) error {
+	param := storage.SaveFileParam{
+		FileName: fileName,
+		FileData: fileData,
+	}
+	_, err := provider.Storage.SaveFile(param)
+	if _, isExists := err.(*app_error.AlreadyExistsError); !isExists {
+		return err
+	}
+
+	replicaPath := fmt.Sprintf("%s/%s", provider.Location, fileName)
+	replicaData, err := provider.Storage.RetrieveFile(replicaPath)
+	if err == nil && bytes.Equal(replicaData, fileData) {
+		return nil
+	}
+	err = provider.Storage.DeleteFile(replicaPath)
+	if err != nil {
+		return err
+	}
+	_, err = provider.Storage.SaveFile(param)
+	return err
+}
+
+// ParseProviders splits the comma separated provider names
+func ParseProviders(value string) []string {
+	providers := []string{}
+	for _, provider := range strings.Split(value, ",") {
+		if provider = strings.TrimSpace(provider); provider != "" {
+			providers = append(providers, provider)
+		}
+	}
+	return providers
+}
+
+func NewReplicateService(cg config.Getter, r storage.Registry, rr repository.ReplicaRepository, mr metrics.Registry) Replicator {
+	return &replicateService{
+		configGetter: cg,
+		registry:     r,
+		replicaRepo:  rr,
+		metrics: replicateMetrics{
+			synced:        mr.Counter("storage_replication_synced_total", "Number of contents copied to a replica provider"),
+			failed:        mr.Counter("storage_replication_failed_total", "Number of contents which could not be copied to a replica provider"),
+			dropped:       mr.Counter("storage_replication_dropped_total", "Number of replica copies left to the repair job because the queue was full"),
+			readFallbacks: mr.Counter("storage_replication_read_fallbacks_total", "Number of contents read from a replica because the primary copy could not be read"),
+		},
+		queue: make(chan replicateJob, cg.GetInt("REPLICATION_QUEUE_SIZE")),
+		now:   time.Now,
+	}
+}
@@ -0,0 +1,223 @@
+package replicating_test
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/metrics"
+	"idaman.id/storage/internal/replicating"
+	"idaman.id/storage/internal/storage"
+)
+
+var _ = Describe("Replicate Service", func() {
+	var (
+		replicator    replicating.Replicator
+		replicaRepo   *FakeReplicaRepository
+		localStorage  *FakeStorage
+		backupStorage *FakeStorage
+		remoteStorage *FakeStorage
+		config        *FakeConfig
+	)
+
+	BeforeEach(func() {
+		replicaRepo = &FakeReplicaRepository{statuses: map[string]string{}}
+		localStorage = &FakeStorage{location: "storage/file", files: map[string][]byte{}}
+		backupStorage = &FakeStorage{location: "storage/backup", files: map[string][]byte{}}
+		remoteStorage = &FakeStorage{location: "storage/remote", files: map[string][]byte{}}
+		registry, _ := storage.NewProviderRegistry("local", []storage.ProviderEntity{
+			{Name: "local", Location: "storage/file", Storage: localStorage},
+			{Name: "backup", Location: "storage/backup", Storage: backupStorage},
+			{Name: "remote", Location: "storage/remote", Storage: remoteStorage},
+		})
+		config = &FakeConfig{values: map[string]interface{}{
+			"DEFAULT_PROVIDER":       "local",
+			"REPLICATION_PROVIDERS":  "backup, remote",
+			"REPLICATION_MODE":       "sync",
+			"REPLICATION_QUEUE_SIZE": 1,
+		}}
+		replicator = replicating.NewReplicateService(config, registry, replicaRepo, metrics.NewRegistry())
+	})
+
+	Context("SaveFile method", func() {
+		When("replication is sync", func() {
+			It("should copy the content to every replica provider", func() {
+				res, err := replicator.SaveFile(storage.SaveFileParam{FileName: "a.txt", FileData: []byte("content")})
+
+				Expect(err).To(BeNil())
+				Expect(res.FileLocation).To(Equal("storage/file"))
+				Expect(localStorage.files["storage/file/a.txt"]).To(Equal([]byte("content")))
+				Expect(backupStorage.files["storage/backup/a.txt"]).To(Equal([]byte("content")))
+				Expect(remoteStorage.files["storage/remote/a.txt"]).To(Equal([]byte("content")))
+				Expect(replicaRepo.statuses).To(Equal(map[string]string{
+					"a.txt@backup": "synced",
+					"a.txt@remote": "synced",
+				}))
+			})
+		})
+
+		When("a replica provider is down", func() {
+			It("should save the file and record the failed replica", func() {
+				remoteStorage.isDown = true
+
+				res, err := replicator.SaveFile(storage.SaveFileParam{FileName: "a.txt", FileData: []byte("content")})
+
+				Expect(err).To(BeNil())
+				Expect(res.FileName).To(Equal("a.txt"))
+				Expect(replicaRepo.statuses["a.txt@backup"]).To(Equal("synced"))
+				Expect(replicaRepo.statuses["a.txt@remote"]).To(Equal("failed"))
+			})
+		})
+
+		When("the file is saved to a replica provider", func() {
+			It("should not replicate to the primary provider", func() {
+				_, err := replicator.SaveFile(storage.SaveFileParam{FileName: "a.txt", FileData: []byte("content"), Provider: "backup"})
+
+				Expect(err).To(BeNil())
+				Expect(localStorage.files).To(BeEmpty())
+				Expect(replicaRepo.statuses).To(Equal(map[string]string{"a.txt@remote": "synced"}))
+			})
+		})
+
+		When("a replica holds a different content under the same name", func() {
+			It("should replace the replica", func() {
+				backupStorage.files["storage/backup/a.txt"] = []byte("stale")
+
+				_, err := replicator.SaveFile(storage.SaveFileParam{FileName: "a.txt", FileData: []byte("content")})
+
+				Expect(err).To(BeNil())
+				Expect(backupStorage.files["storage/backup/a.txt"]).To(Equal([]byte("content")))
+				Expect(replicaRepo.statuses["a.txt@backup"]).To(Equal("synced"))
+			})
+		})
+
+		When("the primary provider is down", func() {
+			It("should return error without replicating", func() {
+				localStorage.isDown = true
+
+				res, err := replicator.SaveFile(storage.SaveFileParam{FileName: "a.txt", FileData: []byte("content")})
+
+				Expect(res).To(BeNil())
+				Expect(err).To(MatchError("storage is down"))
+				Expect(backupStorage.files).To(BeEmpty())
+				Expect(replicaRepo.statuses).To(BeEmpty())
+			})
+		})
+
+		When("replication is async", func() {
+			BeforeEach(func() {
+				config.values["REPLICATION_MODE"] = "async"
+			})
+
+			It("should record pending replicas and copy them from the queue", func() {
+				_, err := replicator.SaveFile(storage.SaveFileParam{FileName: "a.txt", FileData: []byte("content")})
+
+				Expect(err).To(BeNil())
+				Expect(backupStorage.files).To(BeEmpty())
+				Expect(replicaRepo.statuses).To(Equal(map[string]string{
+					"a.txt@backup": "pending",
+					"a.txt@remote": "pending",
+				}))
+
+				go replicator.ProcessQueue()
+
+				Eventually(func() int { return len(remoteStorage.files) }, time.Second).Should(Equal(1))
+				Expect(backupStorage.files["storage/backup/a.txt"]).To(Equal([]byte("content")))
+			})
+
+			It("should leave the replicas pending when the queue is full", func() {
+				_, err := replicator.SaveFile(storage.SaveFileParam{FileName: "a.txt", FileData: []byte("content")})
+				Expect(err).To(BeNil())
+
+				_, err = replicator.SaveFile(storage.SaveFileParam{FileName: "b.txt", FileData: []byte("content")})
+
+				Expect(err).To(BeNil())
+				Expect(localStorage.files).To(HaveKey("storage/file/b.txt"))
+				Expect(replicaRepo.statuses["b.txt@backup"]).To(Equal("pending"))
+			})
+		})
+	})
+
+	Context("SaveStream method", func() {
+		When("replication is sync", func() {
+			It("should copy the primary copy to every replica provider", func() {
+				res, err := replicator.SaveStream(storage.SaveStreamParam{FileName: "a.txt", Reader: strings.NewReader("content")})
+
+				Expect(err).To(BeNil())
+				Expect(res.FileLocation).To(Equal("storage/file"))
+				Expect(localStorage.files["storage/file/a.txt"]).To(Equal([]byte("content")))
+				Expect(backupStorage.files["storage/backup/a.txt"]).To(Equal([]byte("content")))
+				Expect(remoteStorage.files["storage/remote/a.txt"]).To(Equal([]byte("content")))
+			})
+		})
+
+		When("replication is async", func() {
+			It("should read the primary copy from the queue", func() {
+				config.values["REPLICATION_MODE"] = "async"
+
+				_, err := replicator.SaveStream(storage.SaveStreamParam{FileName: "a.txt", Reader: strings.NewReader("content")})
+				Expect(err).To(BeNil())
+				Expect(replicaRepo.statuses["a.txt@backup"]).To(Equal("pending"))
+
+				go replicator.ProcessQueue()
+
+				Eventually(func() int { return len(remoteStorage.files) }, time.Second).Should(Equal(1))
+				Expect(backupStorage.files["storage/backup/a.txt"]).To(Equal([]byte("content")))
+			})
+		})
+	})
+
+	Context("RetrieveFile method", func() {
+		BeforeEach(func() {
+			backupStorage.files["storage/backup/a.txt"] = []byte("backup")
+			remoteStorage.files["storage/remote/a.txt"] = []byte("remote")
+		})
+
+		When("the primary copy is readable", func() {
+			It("should read from the primary provider", func() {
+				localStorage.files["storage/file/a.txt"] = []byte("content")
+
+				res, err := replicator.RetrieveFile("storage/file/a.txt")
+
+				Expect(err).To(BeNil())
+				Expect(res).To(Equal([]byte("content")))
+			})
+		})
+
+		When("the primary copy is lost", func() {
+			It("should read from the first readable replica", func() {
+				backupStorage.isDown = true
+
+				res, err := replicator.RetrieveFile("storage/file/a.txt")
+
+				Expect(err).To(BeNil())
+				Expect(res).To(Equal([]byte("remote")))
+			})
+		})
+
+		When("no replica holds the content", func() {
+			It("should return the primary error", func() {
+				res, err := replicator.RetrieveFile("storage/file/b.txt")
+
+				Expect(res).To(BeNil())
+				Expect(err).To(MatchError("file is not found"))
+			})
+		})
+	})
+
+	Context("DeleteFile method", func() {
+		It("should delete the primary copy and every replica", func() {
+			_, err := replicator.SaveFile(storage.SaveFileParam{FileName: "a.txt", FileData: []byte("content")})
+			Expect(err).To(BeNil())
+			remoteStorage.isDown = true
+
+			err = replicator.DeleteFile("storage/file/a.txt")
+
+			Expect(err).To(BeNil())
+			Expect(localStorage.files).To(BeEmpty())
+			Expect(backupStorage.files).To(BeEmpty())
+			Expect(replicaRepo.statuses).To(BeEmpty())
+		})
+	})
+})
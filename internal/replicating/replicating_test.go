@@ -0,0 +1,111 @@
+package replicating_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/repository"
+	"idaman.id/storage/internal/storage"
+)
+
+func TestReplicating(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Replicating Package")
+}
+
+type FakeConfig struct {
+	values map[string]interface{}
+}
+
+func (c *FakeConfig) GetString(key string) string {
+	value, _ := c.values[key].(string)
+	return value
+}
+
+func (c *FakeConfig) GetInt(key string) int {
+	value, _ := c.values[key].(int)
+	return value
+}
+
+func (c *FakeConfig) GetBool(key string) bool {
+	value, _ := c.values[key].(bool)
+	return value
+}
+
+func (c *FakeConfig) Get(key string) interface{} {
+	return c.values[key]
+}
+
+// FakeReplicaRepository keeps the last status of every replica
+// and pages through the unsynced ones by version id like the mysql repository
+type FakeReplicaRepository struct {
+	repository.ReplicaRepository
+	statuses map[string]string
+	unsynced []repository.ReplicaModel
+	queries  []repository.FindUnsyncedReplicaParam
+}
+
+func (r *FakeReplicaRepository) SaveStatus(p repository.SaveReplicaStatusParam) error {
+	r.statuses[p.FileName+"@"+p.Provider] = p.Status
+	return nil
+}
+
+func (r *FakeReplicaRepository) DeleteByFileName(fileName string) error {
+	for key := range r.statuses {
+		if len(key) > len(fileName) && key[:len(fileName)+1] == fileName+"@" {
+			delete(r.statuses, key)
+		}
+	}
+	return nil
+}
+
+func (r *FakeReplicaRepository) FindUnsynced(p repository.FindUnsyncedReplicaParam) ([]repository.ReplicaModel, error) {
+	r.queries = append(r.queries, p)
+	replicas := []repository.ReplicaModel{}
+	for _, replica := range r.unsynced {
+		status := r.statuses[replica.FileName+"@"+replica.Provider]
+		isAfter := replica.VersionId > p.AfterId || (replica.VersionId == p.AfterId && replica.Provider > p.AfterProvider)
+		if isAfter && status != "synced" && len(replicas) < p.Limit {
+			replicas = append(replicas, replica)
+		}
+	}
+	return replicas, nil
+}
+
+// FakeStorage keeps the files of one location in memory, every call fails while isDown
+type FakeStorage struct {
+	location string
+	files    map[string][]byte
+	isDown   bool
+}
+
+func (s *FakeStorage) SaveFile(p storage.SaveFileParam) (*storage.SaveFileResult, error) {
+	if s.isDown {
+		return nil, errors.New("storage is down")
+	}
+	path := s.location + "/" + p.FileName
+	if _, ok := s.files[path]; ok {
+		return nil, app_error.NewAlreadyExistsError("File")
+	}
+	s.files[path] = p.FileData
+	return &storage.SaveFileResult{FileLocation: s.location, FileName: p.FileName}, nil
+}
+
+func (s *FakeStorage) RetrieveFile(localPath string) (storage.BinaryFile, error) {
+	data, ok := s.files[localPath]
+	if s.isDown || !ok {
+		return nil, errors.New("file is not found")
+	}
+	return data, nil
+}
+
+func (s *FakeStorage) DeleteFile(localPath string) error {
+	if s.isDown {
+		return errors.New("storage is down")
+	}
+	delete(s.files, localPath)
+	return nil
+}
@@ -5,16 +5,31 @@ import (
 )
 
 type FileModel struct {
-	Id           int64
-	UniqueId     string
-	OriginalName string
-	Name         string
-	Extension    string
-	Size         int64
-	Mimetype     string
-	FileLocation string
-	FileName     string
-	CreatedAt    int64
-	UpdatedAt    sql.NullInt64
-	DeletedAt    sql.NullInt64
+	Id                int64
+	UniqueId          string
+	Slug              string
+	OriginalName      string
+	Name              string
+	Extension         string
+	Size              int64
+	Mimetype          string
+	DetectedMimetype  string
+	FileLocation      string
+	FileName          string
+	OwnerId           string
+	TenantId          string
+	ScanStatus        string
+	ExtractedMetadata sql.NullString
+	Checksum          string
+	MetadataStripped  bool
+	Metadata          sql.NullString
+	Visibility        string
+	Revision          int64
+	Version           int64
+	CreatedAt         int64
+	UpdatedAt         sql.NullInt64
+	DeletedAt         sql.NullInt64
+	ExpiresAt         sql.NullInt64
+	Provider          string
+	AccessedAt        sql.NullInt64
 }
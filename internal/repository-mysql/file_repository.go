@@ -2,12 +2,26 @@ package repository_mysql
 
 import (
 	"database/sql"
+	"sort"
+	"strings"
+	"time"
 
 	app_error "idaman.id/storage/internal/error"
 	"idaman.id/storage/internal/file"
 	"idaman.id/storage/internal/repository"
 )
 
+const fileColumns = `
+	id, unique_id, slug, original_name, name,
+	size, extension, mimetype, detected_mimetype, file_location, file_name,
+	owner_id, tenant_id, scan_status, extracted_metadata, checksum, metadata_stripped,
+	metadata, visibility, revision, version, created_at, updated_at, deleted_at, expires_at,
+	provider, accessed_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
 type fileRepository struct {
 	db          *sql.DB
 	fileService file.FileService
@@ -15,26 +29,16 @@ type fileRepository struct {
 
 func (r *fileRepository) FindByIdentifier(identifier string) (*repository.FileModel, error) {
 
+	// a slug may look like another unique id, the unique id match wins
 	uniqueId := r.fileService.RemoveFileExtension(identifier)
-	sqlQuery := `
-		SELECT 
-			id, unique_id, original_name, name, 
-			size, extension, mimetype, file_location, file_name, 
-			created_at, updated_at, deleted_at 
-		FROM file WHERE unique_id = ?`
+	sqlQuery := "SELECT " + fileColumns + " FROM file WHERE (unique_id = ? OR slug = ?) AND deleted_at IS NULL ORDER BY unique_id = ? DESC LIMIT 1"
 	fileStmt, err := r.db.Prepare(sqlQuery)
 	if err != nil {
 		return nil, err
 	}
 	defer fileStmt.Close()
 
-	fileModel := FileModel{}
-	err = fileStmt.QueryRow(uniqueId).Scan(
-		&fileModel.Id, &fileModel.UniqueId, &fileModel.OriginalName, &fileModel.Name,
-		&fileModel.Size, &fileModel.Extension, &fileModel.Mimetype,
-		&fileModel.FileLocation, &fileModel.FileName,
-		&fileModel.CreatedAt, &fileModel.UpdatedAt, &fileModel.DeletedAt,
-	)
+	file, err := scanFile(fileStmt.QueryRow(uniqueId, uniqueId, uniqueId))
 	if err != nil {
 		msg := err.Error()
 		if msg == "sql: no rows in result set" {
@@ -43,16 +47,476 @@ func (r *fileRepository) FindByIdentifier(identifier string) (*repository.FileMo
 		return nil, err
 	}
 
+	tags, err := r.findTags([]string{file.UniqueId})
+	if err != nil {
+		return nil, err
+	}
+	file.Tags = tags[file.UniqueId]
+
+	return file, nil
+}
+
+func (r *fileRepository) FindAll(p repository.FindAllFileParam) (*repository.FindAllFileResult, error) {
+	conditions := []string{"deleted_at IS NULL"}
+	args := []interface{}{}
+
+	if len(p.TenantIds) > 0 {
+		conditions = append(conditions, "tenant_id IN ("+placeholders(len(p.TenantIds))+")")
+		for _, tenantId := range p.TenantIds {
+			args = append(args, tenantId)
+		}
+	}
+
+	if len(p.Tags) > 0 {
+		conditions = append(conditions, `unique_id IN (
+			SELECT file_unique_id FROM file_tag WHERE tag IN (`+placeholders(len(p.Tags))+`)
+			GROUP BY file_unique_id HAVING COUNT(DISTINCT tag) = ?)`)
+		for _, tag := range p.Tags {
+			args = append(args, tag)
+		}
+		args = append(args, len(p.Tags))
+	}
+
+	keys := []string{}
+	for key := range p.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		conditions = append(conditions, "JSON_UNQUOTE(JSON_EXTRACT(metadata, ?)) = ?")
+		args = append(args, `$."`+key+`"`, p.Metadata[key])
+	}
+
+	where := strings.Join(conditions, " AND ")
+	result := &repository.FindAllFileResult{
+		Files: []repository.FileModel{},
+	}
+	err := r.db.QueryRow("SELECT COUNT(*) FROM file WHERE "+where, args...).Scan(&result.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(
+		"SELECT "+fileColumns+" FROM file WHERE "+where+" ORDER BY id DESC LIMIT ? OFFSET ?",
+		append(args, p.Limit, p.Offset)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	uniqueIds := []string{}
+	for rows.Next() {
+		file, err := scanFile(rows)
+		if err != nil {
+			return nil, err
+		}
+		result.Files = append(result.Files, *file)
+		uniqueIds = append(uniqueIds, file.UniqueId)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tags, err := r.findTags(uniqueIds)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result.Files {
+		result.Files[i].Tags = tags[result.Files[i].UniqueId]
+	}
+	return result, nil
+}
+
+func (r *fileRepository) Save(p repository.SaveFileParam) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO file (unique_id, slug, original_name, name, extension, size, mimetype, detected_mimetype, file_location, file_name, owner_id, tenant_id, scan_status, extracted_metadata, checksum, metadata_stripped, metadata, visibility, created_at, expires_at, provider) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		p.UniqueId, p.Slug, p.OriginalName, p.Name,
+		p.Extension, p.Size, p.Mimetype, p.DetectedMimetype, p.FileLocation, p.FileName,
+		p.OwnerId, p.TenantId, p.ScanStatus, nullString(p.ExtractedMetadata),
+		p.Checksum, p.MetadataStripped, nullString(p.Metadata), p.Visibility,
+		p.CreatedAt.Unix(), nullUnixTime(p.ExpiresAt), p.Provider,
+	)
+	if err == nil {
+		err = insertTags(tx, p.UniqueId, p.Tags)
+	}
+	if err == nil {
+		err = insertVersion(tx, repository.VersionModel{
+			FileUniqueId:      p.UniqueId,
+			Version:           1,
+			Size:              p.Size,
+			Extension:         p.Extension,
+			Mimetype:          p.Mimetype,
+			DetectedMimetype:  p.DetectedMimetype,
+			FileLocation:      p.FileLocation,
+			FileName:          p.FileName,
+			ScanStatus:        p.ScanStatus,
+			ExtractedMetadata: p.ExtractedMetadata,
+			Checksum:          p.Checksum,
+			MetadataStripped:  p.MetadataStripped,
+			CreatedAt:         p.CreatedAt,
+		})
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *fileRepository) ReplaceContent(p repository.ReplaceContentParam) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	sqlQuery := `UPDATE file SET
+		version = ?, size = ?, extension = ?, mimetype = ?, detected_mimetype = ?, file_location = ?, file_name = ?,
+		scan_status = ?, extracted_metadata = ?, checksum = ?, metadata_stripped = ?, revision = revision + 1, updated_at = ?
+		WHERE unique_id = ? AND version = ? AND deleted_at IS NULL`
+	args := []interface{}{
+		p.Version, p.Size, p.Extension, p.Mimetype, p.DetectedMimetype, p.FileLocation, p.FileName,
+		p.ScanStatus, nullString(p.ExtractedMetadata), p.Checksum, p.MetadataStripped, p.CreatedAt.Unix(),
+		p.UniqueId, p.Version - 1,
+	}
+	if p.Revision != 0 {
+		sqlQuery += " AND revision = ?"
+		args = append(args, p.Revision)
+	}
+	res, err := tx.Exec(sqlQuery, args...)
+	if err == nil {
+		err = replacedContentError(res)
+	}
+	if err == nil {
+		err = insertVersion(tx, repository.VersionModel{
+			FileUniqueId:      p.UniqueId,
+			Version:           p.Version,
+			Size:              p.Size,
+			Extension:         p.Extension,
+			Mimetype:          p.Mimetype,
+			DetectedMimetype:  p.DetectedMimetype,
+			FileLocation:      p.FileLocation,
+			FileName:          p.FileName,
+			ScanStatus:        p.ScanStatus,
+			ExtractedMetadata: p.ExtractedMetadata,
+			Checksum:          p.Checksum,
+			MetadataStripped:  p.MetadataStripped,
+			CreatedAt:         p.CreatedAt,
+		})
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Delete releases the slug of the file by resetting it to the unique id
+func (r *fileRepository) Delete(p repository.DeleteFileParam) error {
+	res, err := r.db.Exec(
+		"UPDATE file SET deleted_at = ?, slug = unique_id WHERE unique_id = ? AND deleted_at IS NULL",
+		p.DeletedAt.Unix(), p.UniqueId,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return app_error.NewNotfoundError("File")
+	}
+	return nil
+}
+
+func (r *fileRepository) UpdateScanStatus(p repository.UpdateScanStatusParam) error {
+	_, err := r.db.Exec(
+		"UPDATE file SET scan_status = ?, updated_at = ? WHERE unique_id = ? AND file_name = ?",
+		p.ScanStatus, p.UpdatedAt.Unix(), p.UniqueId, p.FileName,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(
+		"UPDATE file_version SET scan_status = ? WHERE file_unique_id = ? AND file_name = ?",
+		p.ScanStatus, p.UniqueId, p.FileName,
+	)
+	return err
+}
+
+func (r *fileRepository) Update(p repository.UpdateFileParam) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	sqlQuery := "UPDATE file SET slug = ?, original_name = ?, name = ?, visibility = ?, metadata = ?, revision = revision + 1, updated_at = ? WHERE unique_id = ? AND deleted_at IS NULL"
+	args := []interface{}{
+		p.Slug, p.OriginalName, p.Name, p.Visibility, nullString(p.Metadata), p.UpdatedAt.Unix(), p.UniqueId,
+	}
+	if p.Revision != 0 {
+		sqlQuery += " AND revision = ?"
+		args = append(args, p.Revision)
+	}
+	res, err := tx.Exec(sqlQuery, args...)
+	if err == nil {
+		err = updatedFileError(res, p.Revision)
+	}
+	if err == nil {
+		_, err = tx.Exec("DELETE FROM file_tag WHERE file_unique_id = ?", p.UniqueId)
+	}
+	if err == nil {
+		err = insertTags(tx, p.UniqueId, p.Tags)
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// FindExpired relies on the `expires_at` index, the tags are not loaded
+func (r *fileRepository) FindExpired(p repository.FindExpiredFileParam) ([]repository.FileModel, error) {
+	rows, err := r.db.Query(
+		"SELECT "+fileColumns+" FROM file WHERE expires_at <= ? AND deleted_at IS NULL ORDER BY expires_at LIMIT ?",
+		p.ExpiredAt.Unix(), p.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	files := []repository.FileModel{}
+	for rows.Next() {
+		file, err := scanFile(rows)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, *file)
+	}
+	return files, rows.Err()
+}
+
+// FindByProvider pages through the files of the providers by id, the tags are loaded
+// since lifecycle rules match on them
+func (r *fileRepository) FindByProvider(p repository.FindByProviderFileParam) ([]repository.FileModel, error) {
+	files := []repository.FileModel{}
+	if len(p.Providers) == 0 {
+		return files, nil
+	}
+
+	args := []interface{}{p.AfterId}
+	for _, provider := range p.Providers {
+		args = append(args, provider)
+	}
+	rows, err := r.db.Query(
+		"SELECT "+fileColumns+" FROM file WHERE id > ? AND provider IN ("+placeholders(len(p.Providers))+") AND deleted_at IS NULL ORDER BY id LIMIT ?",
+		append(args, p.Limit)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	uniqueIds := []string{}
+	for rows.Next() {
+		file, err := scanFile(rows)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, *file)
+		uniqueIds = append(uniqueIds, file.UniqueId)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tags, err := r.findTags(uniqueIds)
+	if err != nil {
+		return nil, err
+	}
+	for i := range files {
+		files[i].Tags = tags[files[i].UniqueId]
+	}
+	return files, nil
+}
+
+func (r *fileRepository) TouchAccess(p repository.TouchAccessParam) error {
+	_, err := r.db.Exec(
+		"UPDATE file SET accessed_at = ? WHERE unique_id = ? AND (accessed_at IS NULL OR accessed_at < ?)",
+		p.AccessedAt.Unix(), p.UniqueId, p.AccessedBefore.Unix(),
+	)
+	return err
+}
+
+// TransitionContent moves the file and its current version record together,
+// the revision is kept since the served content does not change
+func (r *fileRepository) TransitionContent(p repository.TransitionContentParam) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(
+		"UPDATE file SET provider = ?, file_location = ? WHERE unique_id = ? AND file_name = ? AND file_location = ? AND deleted_at IS NULL",
+		p.Provider, p.ToLocation, p.UniqueId, p.FileName, p.FromLocation,
+	)
+	if err == nil {
+		err = replacedContentError(res)
+	}
+	if err == nil {
+		_, err = tx.Exec(
+			"UPDATE file_version SET file_location = ? WHERE file_unique_id = ? AND file_name = ? AND file_location = ?",
+			p.ToLocation, p.UniqueId, p.FileName, p.FromLocation,
+		)
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// IsSlugTaken checks the slug against the slug and unique id of every other file,
+// since both are resolved by FindByIdentifier
+func (r *fileRepository) IsSlugTaken(slug, uniqueId string) (bool, error) {
+	var total int64
+	err := r.db.QueryRow(
+		"SELECT COUNT(*) FROM file WHERE (slug = ? OR unique_id = ?) AND unique_id != ?",
+		slug, slug, uniqueId,
+	).Scan(&total)
+	if err != nil {
+		return false, err
+	}
+	return total > 0, nil
+}
+
+// updatedFileError tells apart a stale revision from a missing file
+// when the update does not affect any row
+func updatedFileError(res sql.Result, revision int64) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+	if revision != 0 {
+		return app_error.NewPreconditionFailedError("File")
+	}
+	return app_error.NewNotfoundError("File")
+}
+
+// replacedContentError reports a content replacement which does not affect any row,
+// the file has been replaced, updated or deleted by another request in the meantime
+func replacedContentError(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return app_error.NewPreconditionFailedError("File")
+	}
+	return nil
+}
+
+// findTags returns the sorted tags of each given file
+func (r *fileRepository) findTags(uniqueIds []string) (map[string][]string, error) {
+	tags := map[string][]string{}
+	if len(uniqueIds) == 0 {
+		return tags, nil
+	}
+
+	args := []interface{}{}
+	for _, uniqueId := range uniqueIds {
+		args = append(args, uniqueId)
+	}
+	rows, err := r.db.Query(
+		"SELECT file_unique_id, tag FROM file_tag WHERE file_unique_id IN ("+placeholders(len(uniqueIds))+") ORDER BY tag",
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var uniqueId, tag string
+		if err = rows.Scan(&uniqueId, &tag); err != nil {
+			return nil, err
+		}
+		tags[uniqueId] = append(tags[uniqueId], tag)
+	}
+	return tags, rows.Err()
+}
+
+func insertTags(tx *sql.Tx, uniqueId string, tags []string) error {
+	for _, tag := range tags {
+		_, err := tx.Exec("INSERT INTO file_tag (file_unique_id, tag) VALUES(?, ?)", uniqueId, tag)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertVersion(tx *sql.Tx, v repository.VersionModel) error {
+	_, err := tx.Exec(
+		"INSERT INTO file_version (file_unique_id, version, size, extension, mimetype, detected_mimetype, file_location, file_name, scan_status, extracted_metadata, checksum, metadata_stripped, created_at) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		v.FileUniqueId, v.Version, v.Size, v.Extension, v.Mimetype, v.DetectedMimetype,
+		v.FileLocation, v.FileName, v.ScanStatus, nullString(v.ExtractedMetadata), v.Checksum, v.MetadataStripped,
+		v.CreatedAt.Unix(),
+	)
+	return err
+}
+
+func scanFile(row rowScanner) (*repository.FileModel, error) {
+	fileModel := FileModel{}
+	err := row.Scan(
+		&fileModel.Id, &fileModel.UniqueId, &fileModel.Slug, &fileModel.OriginalName, &fileModel.Name,
+		&fileModel.Size, &fileModel.Extension, &fileModel.Mimetype, &fileModel.DetectedMimetype,
+		&fileModel.FileLocation, &fileModel.FileName,
+		&fileModel.OwnerId, &fileModel.TenantId, &fileModel.ScanStatus, &fileModel.ExtractedMetadata,
+		&fileModel.Checksum, &fileModel.MetadataStripped, &fileModel.Metadata,
+		&fileModel.Visibility, &fileModel.Revision, &fileModel.Version,
+		&fileModel.CreatedAt, &fileModel.UpdatedAt, &fileModel.DeletedAt, &fileModel.ExpiresAt,
+		&fileModel.Provider, &fileModel.AccessedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	file := repository.FileModel{
-		Id:           fileModel.Id,
-		UniqueId:     fileModel.UniqueId,
-		OriginalName: fileModel.OriginalName,
-		Name:         fileModel.Name,
-		Extension:    fileModel.Extension,
-		Size:         fileModel.Size,
-		Mimetype:     fileModel.Mimetype,
-		FileLocation: fileModel.FileLocation,
-		FileName:     fileModel.FileName,
+		Id:                fileModel.Id,
+		UniqueId:          fileModel.UniqueId,
+		Slug:              fileModel.Slug,
+		OriginalName:      fileModel.OriginalName,
+		Name:              fileModel.Name,
+		Extension:         fileModel.Extension,
+		Size:              fileModel.Size,
+		Mimetype:          fileModel.Mimetype,
+		DetectedMimetype:  fileModel.DetectedMimetype,
+		FileLocation:      fileModel.FileLocation,
+		FileName:          fileModel.FileName,
+		Provider:          fileModel.Provider,
+		OwnerId:           fileModel.OwnerId,
+		TenantId:          fileModel.TenantId,
+		ScanStatus:        fileModel.ScanStatus,
+		ExtractedMetadata: fileModel.ExtractedMetadata.String,
+		Checksum:          fileModel.Checksum,
+		MetadataStripped:  fileModel.MetadataStripped,
+		Metadata:          fileModel.Metadata.String,
+		Tags:              []string{},
+		Visibility:        fileModel.Visibility,
+		Revision:          fileModel.Revision,
+		Version:           fileModel.Version,
 	}
 	file.SetCreatedAtFromUnixTime(fileModel.CreatedAt)
 
@@ -68,17 +532,30 @@ func (r *fileRepository) FindByIdentifier(identifier string) (*repository.FileMo
 		file.SetDeletedAtFromUnixTime(deletedAt.(int64))
 	}
 
+	if fileModel.ExpiresAt.Valid {
+		file.SetExpiresAtFromUnixTime(fileModel.ExpiresAt.Int64)
+	}
+
+	if fileModel.AccessedAt.Valid {
+		file.SetAccessedAtFromUnixTime(fileModel.AccessedAt.Int64)
+	}
+
 	return &file, nil
 }
 
-func (r *fileRepository) Save(p repository.SaveFileParam) error {
-	_, err := r.db.Exec(
-		"INSERT INTO file (unique_id, original_name, name, extension, size, mimetype, file_location, file_name, created_at) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)",
-		p.UniqueId, p.OriginalName, p.Name,
-		p.Extension, p.Size, p.Mimetype, p.FileLocation, p.FileName,
-		p.CreatedAt.Unix(),
-	)
-	return err
+func placeholders(total int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", total), ", ")
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func nullUnixTime(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: t.Unix(), Valid: true}
 }
 
 func NewFileRepository(db *sql.DB, fileService file.FileService) *fileRepository {
@@ -0,0 +1,79 @@
+package repository_mysql
+
+import (
+	"database/sql"
+	"time"
+
+	"idaman.id/storage/internal/repository"
+)
+
+type replicaRepository struct {
+	db *sql.DB
+}
+
+// SaveStatus inserts the replica or overrides the status of the recorded one
+func (r *replicaRepository) SaveStatus(p repository.SaveReplicaStatusParam) error {
+	_, err := r.db.Exec(
+		`INSERT INTO file_replica (file_name, provider, status, updated_at) VALUES(?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE status = VALUES(status), updated_at = VALUES(updated_at)`,
+		p.FileName, p.Provider, p.Status, p.UpdatedAt.Unix(),
+	)
+	return err
+}
+
+func (r *replicaRepository) DeleteByFileName(fileName string) error {
+	_, err := r.db.Exec("DELETE FROM file_replica WHERE file_name = ?", fileName)
+	return err
+}
+
+// FindUnsynced pairs every version with every provider, the versions which are not servable are left out
+// since their content is quarantined or not scanned yet
+func (r *replicaRepository) FindUnsynced(p repository.FindUnsyncedReplicaParam) ([]repository.ReplicaModel, error) {
+	replicas := []repository.ReplicaModel{}
+	if len(p.Providers) == 0 {
+		return replicas, nil
+	}
+
+	providers := "SELECT ? AS provider"
+	args := []interface{}{p.Providers[0]}
+	for _, provider := range p.Providers[1:] {
+		providers += " UNION ALL SELECT ?"
+		args = append(args, provider)
+	}
+	args = append(args, p.AfterId, p.AfterId, p.AfterProvider, p.PendingBefore.Unix(), p.Limit)
+
+	rows, err := r.db.Query(
+		`SELECT v.id, v.file_location, v.file_name, p.provider, COALESCE(r.status, ''), r.updated_at
+		FROM file_version v
+		JOIN file f ON f.unique_id = v.file_unique_id AND f.deleted_at IS NULL
+		JOIN (`+providers+`) p
+		LEFT JOIN file_replica r ON r.file_name = v.file_name AND r.provider = p.provider
+		WHERE (v.id > ? OR (v.id = ? AND p.provider > ?)) AND v.scan_status IN ('', 'unscanned', 'clean')
+		AND (r.status IS NULL OR r.status = 'failed' OR (r.status = 'pending' AND r.updated_at < ?))
+		ORDER BY v.id, p.provider LIMIT ?`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		replica := repository.ReplicaModel{}
+		var updatedAt sql.NullInt64
+		err = rows.Scan(&replica.VersionId, &replica.FileLocation, &replica.FileName, &replica.Provider, &replica.Status, &updatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if updatedAt.Valid {
+			ts := time.Unix(updatedAt.Int64, 0)
+			replica.UpdatedAt = &ts
+		}
+		replicas = append(replicas, replica)
+	}
+	return replicas, rows.Err()
+}
+
+func NewReplicaRepository(db *sql.DB) repository.ReplicaRepository {
+	return &replicaRepository{db}
+}
@@ -0,0 +1,104 @@
+package repository_mysql_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRepositoryMysql(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RepositoryMysql Package")
+}
+
+// FakeStatement is a statement executed through the fake driver
+type FakeStatement struct {
+	Query string
+	Args  []driver.Value
+}
+
+// FakeDriver records the executed statements instead of sending them to mysql,
+// every connection opened with the same name shares the recorded statements
+type FakeDriver struct {
+	mu         sync.Mutex
+	statements map[string][]FakeStatement
+}
+
+func (d *FakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{driver: d, name: name}, nil
+}
+
+func (d *FakeDriver) Statements(name string) []FakeStatement {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.statements[name]
+}
+
+func (d *FakeDriver) record(name string, s FakeStatement) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.statements[name] = append(d.statements[name], s)
+}
+
+type fakeConn struct {
+	driver *FakeDriver
+	name   string
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transaction is not supported")
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.conn.driver.record(s.conn.name, FakeStatement{Query: s.query, Args: args})
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("query is not supported")
+}
+
+var (
+	fakeDriver = &FakeDriver{statements: map[string][]FakeStatement{}}
+	fakeDbs    = 0
+)
+
+func init() {
+	sql.Register("fake-mysql", fakeDriver)
+}
+
+// NewFakeDb opens a database whose statements are recorded under the returned name
+func NewFakeDb() (*sql.DB, string) {
+	fakeDbs++
+	name := "db-" + strconv.Itoa(fakeDbs)
+	db, err := sql.Open("fake-mysql", name)
+	Expect(err).To(BeNil())
+	return db, name
+}
@@ -0,0 +1,57 @@
+package repository_mysql
+
+import (
+	"database/sql"
+	"time"
+
+	"idaman.id/storage/internal/repository"
+)
+
+type transformRepository struct {
+	db *sql.DB
+}
+
+func (r *transformRepository) FindByFile(fileUniqueId string) ([]repository.TransformModel, error) {
+	rows, err := r.db.Query(`
+		SELECT id, file_unique_id, file_location, file_name, created_at 
+		FROM file_transform WHERE file_unique_id = ? ORDER BY id`,
+		fileUniqueId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transforms := []repository.TransformModel{}
+	for rows.Next() {
+		transform := repository.TransformModel{}
+		var createdAt int64
+		err = rows.Scan(
+			&transform.Id, &transform.FileUniqueId, &transform.FileLocation, &transform.FileName, &createdAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		ts := time.Unix(createdAt, 0)
+		transform.CreatedAt = &ts
+		transforms = append(transforms, transform)
+	}
+	return transforms, rows.Err()
+}
+
+func (r *transformRepository) Save(p repository.SaveTransformParam) error {
+	_, err := r.db.Exec(
+		"INSERT INTO file_transform (file_unique_id, file_location, file_name, created_at) VALUES(?, ?, ?, ?)",
+		p.FileUniqueId, p.FileLocation, p.FileName, p.CreatedAt.Unix(),
+	)
+	return err
+}
+
+func (r *transformRepository) DeleteByFile(fileUniqueId string) error {
+	_, err := r.db.Exec("DELETE FROM file_transform WHERE file_unique_id = ?", fileUniqueId)
+	return err
+}
+
+func NewTransformRepository(db *sql.DB) repository.TransformRepository {
+	return &transformRepository{db}
+}
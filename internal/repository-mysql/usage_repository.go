@@ -0,0 +1,79 @@
+package repository_mysql
+
+import (
+	"database/sql"
+	"time"
+
+	"idaman.id/storage/internal/repository"
+)
+
+type usageRepository struct {
+	db *sql.DB
+}
+
+func (r *usageRepository) FindUsage(applicationId string) (*repository.UsageModel, error) {
+	usage := repository.UsageModel{
+		ApplicationId: applicationId,
+	}
+
+	err := r.db.QueryRow(
+		"SELECT total_size, file_count FROM application_usage WHERE application_id = ?",
+		applicationId,
+	).Scan(&usage.TotalSize, &usage.FileCount)
+	if err == sql.ErrNoRows {
+		return &usage, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+func (r *usageRepository) IncrementUsage(p repository.IncrementUsageParam) (bool, error) {
+	now := time.Now().Unix()
+	_, err := r.db.Exec(
+		"INSERT IGNORE INTO application_usage (application_id, total_size, file_count, updated_at) VALUES(?, 0, 0, ?)",
+		p.ApplicationId, now,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := r.db.Exec(`
+		UPDATE application_usage 
+		SET total_size = total_size + ?, file_count = file_count + ?, updated_at = ? 
+		WHERE application_id = ? 
+			AND (? = 0 OR total_size + ? <= ?) 
+			AND (? = 0 OR file_count + ? <= ?)`,
+		p.Size, p.Count, now,
+		p.ApplicationId,
+		p.MaxTotalSize, p.Size, p.MaxTotalSize,
+		p.MaxFileCount, p.Count, p.MaxFileCount,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// DecrementUsage floors the usage at zero, the columns are unsigned
+// so the subtraction is only evaluated when it can not go below zero
+func (r *usageRepository) DecrementUsage(p repository.DecrementUsageParam) error {
+	_, err := r.db.Exec(`
+		UPDATE application_usage 
+		SET total_size = IF(total_size > ?, total_size - ?, 0), file_count = IF(file_count > ?, file_count - ?, 0), updated_at = ? 
+		WHERE application_id = ?`,
+		p.Size, p.Size, p.Count, p.Count, time.Now().Unix(),
+		p.ApplicationId,
+	)
+	return err
+}
+
+func NewUsageRepository(db *sql.DB) repository.UsageRepository {
+	return &usageRepository{db}
+}
@@ -0,0 +1,44 @@
+package repository_mysql_test
+
+import (
+	"database/sql/driver"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/repository"
+	repository_mysql "idaman.id/storage/internal/repository-mysql"
+)
+
+var _ = Describe("Usage Repository", func() {
+	var (
+		usageRepo repository.UsageRepository
+		dbName    string
+	)
+
+	BeforeEach(func() {
+		db, name := NewFakeDb()
+		usageRepo = repository_mysql.NewUsageRepository(db)
+		dbName = name
+	})
+
+	Context("DecrementUsage method", func() {
+		When("released usage is larger than the stored usage", func() {
+			It("should floor the usage at zero without an unsigned subtraction", func() {
+				err := usageRepo.DecrementUsage(repository.DecrementUsageParam{
+					ApplicationId: "billing-app",
+					Size:          2048,
+					Count:         3,
+				})
+
+				statements := fakeDriver.Statements(dbName)
+				Expect(err).To(BeNil())
+				Expect(statements).To(HaveLen(1))
+				Expect(statements[0].Query).To(ContainSubstring("total_size = IF(total_size > ?, total_size - ?, 0)"))
+				Expect(statements[0].Query).To(ContainSubstring("file_count = IF(file_count > ?, file_count - ?, 0)"))
+				Expect(statements[0].Query).ToNot(ContainSubstring("GREATEST"))
+				Expect(statements[0].Args[:4]).To(Equal([]driver.Value{int64(2048), int64(2048), int64(3), int64(3)}))
+				Expect(statements[0].Args[5]).To(Equal("billing-app"))
+			})
+		})
+	})
+})
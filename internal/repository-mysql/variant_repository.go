@@ -0,0 +1,62 @@
+package repository_mysql
+
+import (
+	"database/sql"
+	"time"
+
+	"idaman.id/storage/internal/repository"
+)
+
+type variantRepository struct {
+	db *sql.DB
+}
+
+func (r *variantRepository) FindByFile(fileUniqueId string) ([]repository.VariantModel, error) {
+	rows, err := r.db.Query(`
+		SELECT 
+			id, file_unique_id, name, extension, mimetype, 
+			size, width, height, file_location, file_name, created_at 
+		FROM file_variant WHERE file_unique_id = ? ORDER BY id`,
+		fileUniqueId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := []repository.VariantModel{}
+	for rows.Next() {
+		variant := repository.VariantModel{}
+		var createdAt int64
+		err = rows.Scan(
+			&variant.Id, &variant.FileUniqueId, &variant.Name, &variant.Extension, &variant.Mimetype,
+			&variant.Size, &variant.Width, &variant.Height, &variant.FileLocation, &variant.FileName, &createdAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		ts := time.Unix(createdAt, 0)
+		variant.CreatedAt = &ts
+		variants = append(variants, variant)
+	}
+	return variants, rows.Err()
+}
+
+func (r *variantRepository) Save(p repository.SaveVariantParam) error {
+	_, err := r.db.Exec(
+		"INSERT INTO file_variant (file_unique_id, name, extension, mimetype, size, width, height, file_location, file_name, created_at) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		p.FileUniqueId, p.Name, p.Extension, p.Mimetype,
+		p.Size, p.Width, p.Height, p.FileLocation, p.FileName,
+		p.CreatedAt.Unix(),
+	)
+	return err
+}
+
+func (r *variantRepository) DeleteByFile(fileUniqueId string) error {
+	_, err := r.db.Exec("DELETE FROM file_variant WHERE file_unique_id = ?", fileUniqueId)
+	return err
+}
+
+func NewVariantRepository(db *sql.DB) repository.VariantRepository {
+	return &variantRepository{db}
+}
@@ -0,0 +1,80 @@
+package repository_mysql
+
+import (
+	"database/sql"
+	"time"
+
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/repository"
+)
+
+const versionColumns = `
+	id, file_unique_id, version, size, extension, mimetype, detected_mimetype,
+	file_location, file_name, scan_status, extracted_metadata, checksum, metadata_stripped, created_at`
+
+type versionRepository struct {
+	db *sql.DB
+}
+
+func (r *versionRepository) FindByFile(fileUniqueId string) ([]repository.VersionModel, error) {
+	rows, err := r.db.Query(
+		"SELECT "+versionColumns+" FROM file_version WHERE file_unique_id = ? ORDER BY version",
+		fileUniqueId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []repository.VersionModel{}
+	for rows.Next() {
+		version, err := scanVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, *version)
+	}
+	return versions, rows.Err()
+}
+
+func (r *versionRepository) FindVersion(fileUniqueId string, version int64) (*repository.VersionModel, error) {
+	row := r.db.QueryRow(
+		"SELECT "+versionColumns+" FROM file_version WHERE file_unique_id = ? AND version = ?",
+		fileUniqueId, version,
+	)
+	res, err := scanVersion(row)
+	if err == sql.ErrNoRows {
+		return nil, app_error.NewNotfoundError("Version")
+	}
+	return res, err
+}
+
+func (r *versionRepository) Delete(fileUniqueId string, version int64) error {
+	_, err := r.db.Exec(
+		"DELETE FROM file_version WHERE file_unique_id = ? AND version = ?",
+		fileUniqueId, version,
+	)
+	return err
+}
+
+func scanVersion(row rowScanner) (*repository.VersionModel, error) {
+	version := repository.VersionModel{}
+	var extractedMetadata sql.NullString
+	var createdAt int64
+	err := row.Scan(
+		&version.Id, &version.FileUniqueId, &version.Version, &version.Size, &version.Extension,
+		&version.Mimetype, &version.DetectedMimetype, &version.FileLocation, &version.FileName,
+		&version.ScanStatus, &extractedMetadata, &version.Checksum, &version.MetadataStripped, &createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	version.ExtractedMetadata = extractedMetadata.String
+	ts := time.Unix(createdAt, 0)
+	version.CreatedAt = &ts
+	return &version, nil
+}
+
+func NewVersionRepository(db *sql.DB) repository.VersionRepository {
+	return &versionRepository{db}
+}
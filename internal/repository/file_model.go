@@ -5,18 +5,43 @@ import (
 )
 
 type FileModel struct {
-	Id           int64
-	UniqueId     string
-	OriginalName string
-	Name         string
-	Extension    string
-	Size         int64
-	Mimetype     string
-	FileLocation string
-	FileName     string
-	CreatedAt    *time.Time
-	UpdatedAt    *time.Time
-	DeletedAt    *time.Time
+	Id               int64
+	UniqueId         string
+	Slug             string
+	OriginalName     string
+	Name             string
+	Extension        string
+	Size             int64
+	Mimetype         string
+	DetectedMimetype string
+	FileLocation     string
+	FileName         string
+	// Provider is the storage provider holding the current content
+	Provider   string
+	OwnerId    string
+	TenantId   string
+	ScanStatus string
+	// ExtractedMetadata is JSON encoded extracting.MetadataEntity
+	ExtractedMetadata string
+	Checksum          string
+	MetadataStripped  bool
+	Metadata          string
+	Tags              []string
+	Visibility        string
+	Revision          int64
+	Version           int64
+	CreatedAt         *time.Time
+	UpdatedAt         *time.Time
+	DeletedAt         *time.Time
+	// ExpiresAt is nil when the file never expires
+	ExpiresAt *time.Time
+	// AccessedAt is the last time the content was served, nil when it never was
+	AccessedAt *time.Time
+}
+
+// IsExpired tells whether the file has outlived its expiry, even when it is not reaped yet
+func (m *FileModel) IsExpired(now time.Time) bool {
+	return m.ExpiresAt != nil && !now.Before(*m.ExpiresAt)
 }
 
 func (m *FileModel) SetCreatedAtFromUnixTime(t int64) *FileModel {
@@ -42,3 +67,19 @@ func (m *FileModel) SetDeletedAtFromUnixTime(t int64) *FileModel {
 	}
 	return m
 }
+
+func (m *FileModel) SetExpiresAtFromUnixTime(t int64) *FileModel {
+	if t > 0 {
+		ts := time.Unix(t, 0)
+		m.ExpiresAt = &ts
+	}
+	return m
+}
+
+func (m *FileModel) SetAccessedAtFromUnixTime(t int64) *FileModel {
+	if t > 0 {
+		ts := time.Unix(t, 0)
+		m.AccessedAt = &ts
+	}
+	return m
+}
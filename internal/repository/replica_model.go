@@ -0,0 +1,15 @@
+package repository
+
+import "time"
+
+// ReplicaModel is the copy of a content version held by a secondary provider,
+// VersionId and FileLocation describe the primary content the replica is copied from
+type ReplicaModel struct {
+	VersionId    int64
+	FileLocation string
+	FileName     string
+	Provider     string
+	// Status is empty when the replica has never been recorded
+	Status    string
+	UpdatedAt *time.Time
+}
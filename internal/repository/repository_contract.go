@@ -5,16 +5,226 @@ import "time"
 type FileRepository interface {
 	FindByIdentifier(identifier string) (*FileModel, error)
 	Save(p SaveFileParam) error
+	Delete(p DeleteFileParam) error
+	UpdateScanStatus(p UpdateScanStatusParam) error
+	Update(p UpdateFileParam) error
+	FindAll(p FindAllFileParam) (*FindAllFileResult, error)
+	ReplaceContent(p ReplaceContentParam) error
+	IsSlugTaken(slug, uniqueId string) (bool, error)
+	FindExpired(p FindExpiredFileParam) ([]FileModel, error)
+	FindByProvider(p FindByProviderFileParam) ([]FileModel, error)
+	TouchAccess(p TouchAccessParam) error
+	TransitionContent(p TransitionContentParam) error
+}
+
+// VersionRepository reads the content versions recorded by FileRepository,
+// versions are ordered from the oldest one
+type VersionRepository interface {
+	FindByFile(fileUniqueId string) ([]VersionModel, error)
+	FindVersion(fileUniqueId string, version int64) (*VersionModel, error)
+	Delete(fileUniqueId string, version int64) error
+}
+
+type VariantRepository interface {
+	FindByFile(fileUniqueId string) ([]VariantModel, error)
+	Save(p SaveVariantParam) error
+	DeleteByFile(fileUniqueId string) error
+}
+
+type TransformRepository interface {
+	FindByFile(fileUniqueId string) ([]TransformModel, error)
+	Save(p SaveTransformParam) error
+	DeleteByFile(fileUniqueId string) error
+}
+
+// ReplicaRepository records the replicas of every content version by file name,
+// replicas are recorded independently of the file so they can be written before the file is saved
+type ReplicaRepository interface {
+	SaveStatus(p SaveReplicaStatusParam) error
+	DeleteByFileName(fileName string) error
+	FindUnsynced(p FindUnsyncedReplicaParam) ([]ReplicaModel, error)
+}
+
+type UsageRepository interface {
+	FindUsage(applicationId string) (*UsageModel, error)
+	IncrementUsage(p IncrementUsageParam) (bool, error)
+	DecrementUsage(p DecrementUsageParam) error
 }
 
 type SaveFileParam struct {
-	UniqueId     string
-	OriginalName string
+	UniqueId         string
+	Slug             string
+	OriginalName     string
+	Name             string
+	Extension        string
+	Size             int64
+	Mimetype         string
+	DetectedMimetype string
+	FileLocation     string
+	FileName         string
+	Provider         string
+	OwnerId          string
+	TenantId         string
+	ScanStatus       string
+	// ExtractedMetadata is JSON encoded extracting.MetadataEntity
+	ExtractedMetadata string
+	Checksum          string
+	MetadataStripped  bool
+	// Metadata is JSON encoded user metadata
+	Metadata   string
+	Tags       []string
+	Visibility string
+	CreatedAt  *time.Time
+	// ExpiresAt is nil when the file never expires
+	ExpiresAt *time.Time
+}
+
+type SaveVariantParam struct {
+	FileUniqueId string
 	Name         string
 	Extension    string
-	Size         int64
 	Mimetype     string
+	Size         int64
+	Width        int
+	Height       int
+	FileLocation string
+	FileName     string
+	CreatedAt    *time.Time
+}
+
+type SaveTransformParam struct {
+	FileUniqueId string
 	FileLocation string
 	FileName     string
 	CreatedAt    *time.Time
 }
+
+type DeleteFileParam struct {
+	UniqueId  string
+	DeletedAt *time.Time
+}
+
+// UpdateScanStatusParam only updates the status of the version stored as FileName,
+// so scanning a replaced version never overrides the status of the current one
+type UpdateScanStatusParam struct {
+	UniqueId   string
+	FileName   string
+	ScanStatus string
+	UpdatedAt  *time.Time
+}
+
+// UpdateFileParam replaces the mutable fields of the file and increments its revision,
+// non zero Revision only updates the file when it is still at that revision
+type UpdateFileParam struct {
+	UniqueId     string
+	Slug         string
+	OriginalName string
+	Name         string
+	Visibility   string
+	Metadata     string
+	Tags         []string
+	Revision     int64
+	UpdatedAt    *time.Time
+}
+
+// ReplaceContentParam points the file at a new content version and records the version,
+// the content is only replaced when the file is still at the previous version and,
+// for non zero Revision, at that revision
+type ReplaceContentParam struct {
+	UniqueId          string
+	Version           int64
+	Size              int64
+	Extension         string
+	Mimetype          string
+	DetectedMimetype  string
+	FileLocation      string
+	FileName          string
+	ScanStatus        string
+	ExtractedMetadata string
+	Checksum          string
+	MetadataStripped  bool
+	Revision          int64
+	CreatedAt         *time.Time
+}
+
+// FindAllFileParam lists files having every given tag and metadata value,
+// empty TenantIds lists files of every tenant
+type FindAllFileParam struct {
+	TenantIds []string
+	Tags      []string
+	Metadata  map[string]string
+	Limit     int
+	Offset    int
+}
+
+// FindExpiredFileParam lists up to Limit files which expire at or before ExpiredAt,
+// the files expiring first come first
+type FindExpiredFileParam struct {
+	ExpiredAt *time.Time
+	Limit     int
+}
+
+// FindByProviderFileParam lists up to Limit files held by one of the providers,
+// ordered by id from the first file after AfterId so a caller can page through every file
+type FindByProviderFileParam struct {
+	Providers []string
+	AfterId   int64
+	Limit     int
+}
+
+// TouchAccessParam records an access, the previous access is only overridden
+// when it is older than AccessedBefore, which bounds the writes of a frequently served file
+type TouchAccessParam struct {
+	UniqueId       string
+	AccessedAt     *time.Time
+	AccessedBefore *time.Time
+}
+
+// TransitionContentParam points the current content and its version at the copy held by Provider,
+// the file is only updated while its content is still at FromLocation
+type TransitionContentParam struct {
+	UniqueId     string
+	FileName     string
+	FromLocation string
+	ToLocation   string
+	Provider     string
+}
+
+type SaveReplicaStatusParam struct {
+	FileName  string
+	Provider  string
+	Status    string
+	UpdatedAt *time.Time
+}
+
+// FindUnsyncedReplicaParam lists up to Limit replicas missing on one of the providers for the servable versions
+// of every live file, ordered by version id and provider from the first replica after AfterId and AfterProvider,
+// a replica still pending since PendingBefore is considered lost
+type FindUnsyncedReplicaParam struct {
+	Providers     []string
+	AfterId       int64
+	AfterProvider string
+	PendingBefore *time.Time
+	Limit         int
+}
+
+type FindAllFileResult struct {
+	Files []FileModel
+	Total int64
+}
+
+// IncrementUsageParam increments usage only when it stays within the max values,
+// max value equal to zero is considered unlimited
+type IncrementUsageParam struct {
+	ApplicationId string
+	Size          int64
+	Count         int64
+	MaxTotalSize  int64
+	MaxFileCount  int64
+}
+
+type DecrementUsageParam struct {
+	ApplicationId string
+	Size          int64
+	Count         int64
+}
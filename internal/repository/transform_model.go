@@ -0,0 +1,13 @@
+package repository
+
+import "time"
+
+// TransformModel is a transformed image cached on demand,
+// it is recorded so the cache can be removed together with the file
+type TransformModel struct {
+	Id           int64
+	FileUniqueId string
+	FileLocation string
+	FileName     string
+	CreatedAt    *time.Time
+}
@@ -0,0 +1,7 @@
+package repository
+
+type UsageModel struct {
+	ApplicationId string
+	TotalSize     int64
+	FileCount     int64
+}
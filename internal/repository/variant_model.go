@@ -0,0 +1,17 @@
+package repository
+
+import "time"
+
+type VariantModel struct {
+	Id           int64
+	FileUniqueId string
+	Name         string
+	Extension    string
+	Mimetype     string
+	Size         int64
+	Width        int
+	Height       int
+	FileLocation string
+	FileName     string
+	CreatedAt    *time.Time
+}
@@ -0,0 +1,21 @@
+package repository
+
+import "time"
+
+type VersionModel struct {
+	Id               int64
+	FileUniqueId     string
+	Version          int64
+	Size             int64
+	Extension        string
+	Mimetype         string
+	DetectedMimetype string
+	FileLocation     string
+	FileName         string
+	ScanStatus       string
+	// ExtractedMetadata is JSON encoded extracting.MetadataEntity
+	ExtractedMetadata string
+	Checksum          string
+	MetadataStripped  bool
+	CreatedAt         *time.Time
+}
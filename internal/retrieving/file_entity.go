@@ -2,17 +2,50 @@ package retrieving
 
 import (
 	"time"
+
+	"idaman.id/storage/internal/extracting"
 )
 
 type FileEntity struct {
-	UniqueId     string
-	OriginalName string
-	Name         string
-	Extension    string
-	Size         int64
-	Mimetype     string
-	Url          string
-	CreatedAt    *time.Time
-	UpdatedAt    *time.Time
-	DeletedAt    *time.Time
+	UniqueId          string
+	Slug              string
+	OriginalName      string
+	Name              string
+	Extension         string
+	Size              int64
+	Mimetype          string
+	DetectedMimetype  string
+	Url               string
+	OwnerId           string
+	TenantId          string
+	ScanStatus        string
+	ExtractedMetadata *extracting.MetadataEntity
+	Checksum          string
+	MetadataStripped  bool
+	Metadata          map[string]string
+	Tags              []string
+	Visibility        string
+	Provider          string
+	Revision          int64
+	Version           int64
+	CreatedAt         *time.Time
+	UpdatedAt         *time.Time
+	DeletedAt         *time.Time
+	ExpiresAt         *time.Time
+	Variants          []VariantEntity
+}
+
+type VariantEntity struct {
+	Name      string
+	Extension string
+	Mimetype  string
+	Size      int64
+	Width     int
+	Height    int
+	Url       string
+}
+
+type SignedUrlEntity struct {
+	Url       string
+	ExpiresAt *time.Time
 }
@@ -1,19 +1,92 @@
 package retrieving
 
+import (
+	"idaman.id/storage/internal/auth"
+	"idaman.id/storage/internal/imaging"
+)
+
+const (
+	LIST_DEFAULT_LIMIT = 20
+	LIST_MAX_LIMIT     = 100
+)
+
 type FileGetter interface {
-	GetFile(identifier string) (*FileEntity, error)
+	GetFile(p GetFileParam) (*FileEntity, error)
 }
 
 type FileRetriever interface {
-	RetrieveFile(identifier string) (*RetrieveFileResult, error)
+	RetrieveFile(p RetrieveFileParam) (*RetrieveFileResult, error)
+}
+
+type FileLister interface {
+	ListFiles(p ListFilesParam) (*ListFilesResult, error)
+}
+
+type FileSigner interface {
+	SignFileUrl(p SignFileUrlParam) (*SignedUrlEntity, error)
 }
 
 type RetrieveService interface {
 	FileGetter
 	FileRetriever
+	FileLister
+	FileSigner
+}
+
+type GetFileParam struct {
+	Identifier string
+	Claims     *auth.Claims
+}
+
+// RetrieveFileParam optionally retrieves a generated variant or transforms image file,
+// Variant takes precedence over Transform while empty both retrieve the original file.
+// Non zero Version retrieves a previous content version, which has no variant.
+// Private file is only retrieved with a valid Signature issued by SignFileUrl
+type RetrieveFileParam struct {
+	Identifier string
+	Variant    string
+	Transform  *imaging.TransformOption
+	Version    int64
+	Download   bool
+	Signature  string
+	ExpiresAt  int64
+}
+
+// SignFileUrlParam signs the public url for ExpiresIn seconds,
+// zero ExpiresIn uses URL_SIGNING_DEFAULT_TTL.
+// Version, Variant and Transform select the only rendition served by the url
+type SignFileUrlParam struct {
+	Identifier string
+	Claims     *auth.Claims
+	Download   bool
+	ExpiresIn  int
+	Version    int64
+	Variant    string
+	Transform  *imaging.TransformOption
+}
+
+// ListFilesParam filters files having every given tag and metadata value
+type ListFilesParam struct {
+	Claims   *auth.Claims
+	Tags     []string
+	Metadata map[string]string
+	Limit    int
+	Offset   int
+}
+
+type ListFilesResult struct {
+	Files  []FileEntity
+	Total  int64
+	Limit  int
+	Offset int
 }
 
+// RetrieveFileResult is Redirect without FileData when the file is requested
+// by its unique id while it has a slug, File.Url is then the canonical location.
+// IsAttachment is set when download is requested or the mimetype is not safe to render inline
 type RetrieveFileResult struct {
-	File     *FileEntity
-	FileData []byte
+	File         *FileEntity
+	FileData     []byte
+	Redirect     bool
+	IsAttachment bool
 }
@@ -0,0 +1,13 @@
+package retrieving
+
+// listRule filters by metadata keys which could have been stored by the update endpoint
+type listRule struct {
+	Metadata map[string]string `json:"metadata" validate:"valid_metadata"`
+}
+
+func NewListRule(p ListFilesParam) *listRule {
+	lr := listRule{
+		Metadata: p.Metadata,
+	}
+	return &lr
+}
@@ -2,84 +2,488 @@ package retrieving
 
 import (
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"idaman.id/storage/internal/config"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/extracting"
 	"idaman.id/storage/internal/file"
+	"idaman.id/storage/internal/imaging"
 	"idaman.id/storage/internal/repository"
+	"idaman.id/storage/internal/scanning"
+	"idaman.id/storage/internal/serialization"
+	"idaman.id/storage/internal/signing"
 	"idaman.id/storage/internal/storage"
+	"idaman.id/storage/internal/validation"
 )
 
 type retrieveService struct {
 	configGetter     config.Getter
 	fileRepo         repository.FileRepository
+	variantRepo      repository.VariantRepository
 	fileService      file.FileService
 	storageRetriever storage.Retriever
+	storageSaver     storage.Saver
+	transformer      imaging.Transformer
+	decoder          serialization.Decoder
+	versionRepo      repository.VersionRepository
+	signer           signing.Signer
+	transformRepo    repository.TransformRepository
+	validator        validation.Validator
 }
 
-func (s *retrieveService) GetFile(identifier string) (*FileEntity, error) {
+func (s *retrieveService) GetFile(p GetFileParam) (*FileEntity, error) {
 
-	fileRecord, err := s.fileRepo.FindByIdentifier(identifier)
+	fileRecord, err := s.fileRepo.FindByIdentifier(p.Identifier)
 	if err != nil {
 		return nil, err
 	}
 
+	if !p.Claims.CanAccessTenant(fileRecord.TenantId) {
+		return nil, app_error.NewForbiddenError("File")
+	}
+
+	fileEntity := s.newFileEntity(fileRecord)
+	variants, err := s.variantRepo.FindByFile(fileRecord.UniqueId)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range variants {
+		fileEntity.Variants = append(fileEntity.Variants, VariantEntity{
+			Name:      v.Name,
+			Extension: v.Extension,
+			Mimetype:  v.Mimetype,
+			Size:      v.Size,
+			Width:     v.Width,
+			Height:    v.Height,
+			Url:       fmt.Sprintf("%s?variant=%s", fileEntity.Url, v.Name),
+		})
+	}
+	return fileEntity, nil
+}
+
+// ListFiles lists the files accessible by the claims, newest first,
+// the variants are only loaded by GetFile
+func (s *retrieveService) ListFiles(p ListFilesParam) (*ListFilesResult, error) {
+	err := s.validator.Validate(*NewListRule(p))
+	if err != nil {
+		return nil, err
+	}
+
+	limit := p.Limit
+	if limit <= 0 {
+		limit = LIST_DEFAULT_LIMIT
+	}
+	if limit > LIST_MAX_LIMIT {
+		limit = LIST_MAX_LIMIT
+	}
+	offset := p.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	// files stored without tenant are shared, see auth.Claims.CanAccessTenant
+	tenantIds := []string{}
+	if p.Claims != nil {
+		tenantIds = []string{"", p.Claims.Tenant}
+	}
+
+	records, err := s.fileRepo.FindAll(repository.FindAllFileParam{
+		TenantIds: tenantIds,
+		Tags:      p.Tags,
+		Metadata:  p.Metadata,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ListFilesResult{
+		Files:  []FileEntity{},
+		Total:  records.Total,
+		Limit:  limit,
+		Offset: offset,
+	}
+	for i := range records.Files {
+		res.Files = append(res.Files, *s.newFileEntity(&records.Files[i]))
+	}
+	return res, nil
+}
+
+func (s *retrieveService) newFileEntity(fileRecord *repository.FileModel) *FileEntity {
 	appUrl := s.configGetter.GetString("APP_URL")
-	url := fmt.Sprintf("%s/%s/%s", appUrl, "file", fileRecord.FileName)
+	url := fmt.Sprintf("%s/%s/%s.%s", appUrl, "file", fileRecord.Slug, fileRecord.Extension)
 
 	fileEntity := &FileEntity{
-		UniqueId:     fileRecord.UniqueId,
-		OriginalName: fileRecord.OriginalName,
-		Name:         fileRecord.Name,
-		Extension:    fileRecord.Extension,
-		Size:         fileRecord.Size,
-		Mimetype:     fileRecord.Mimetype,
-		Url:          url,
-		CreatedAt:    fileRecord.CreatedAt,
-		UpdatedAt:    fileRecord.UpdatedAt,
-		DeletedAt:    fileRecord.DeletedAt,
+		UniqueId:         fileRecord.UniqueId,
+		Slug:             fileRecord.Slug,
+		OriginalName:     fileRecord.OriginalName,
+		Name:             fileRecord.Name,
+		Extension:        fileRecord.Extension,
+		Size:             fileRecord.Size,
+		Mimetype:         fileRecord.Mimetype,
+		DetectedMimetype: fileRecord.DetectedMimetype,
+		Url:              url,
+		OwnerId:          fileRecord.OwnerId,
+		TenantId:         fileRecord.TenantId,
+		ScanStatus:       fileRecord.ScanStatus,
+		Checksum:         fileRecord.Checksum,
+		MetadataStripped: fileRecord.MetadataStripped,
+		Metadata:         map[string]string{},
+		Tags:             fileRecord.Tags,
+		Visibility:       fileRecord.Visibility,
+		Provider:         fileRecord.Provider,
+		Revision:         fileRecord.Revision,
+		Version:          fileRecord.Version,
+		CreatedAt:        fileRecord.CreatedAt,
+		UpdatedAt:        fileRecord.UpdatedAt,
+		DeletedAt:        fileRecord.DeletedAt,
+		ExpiresAt:        fileRecord.ExpiresAt,
+		Variants:         []VariantEntity{},
 	}
-	return fileEntity, nil
+	if fileEntity.Tags == nil {
+		fileEntity.Tags = []string{}
+	}
+
+	// metadata is informative, a broken document should not hide the file
+	if fileRecord.ExtractedMetadata != "" {
+		metadata := &extracting.MetadataEntity{}
+		if s.decoder.Decode([]byte(fileRecord.ExtractedMetadata), metadata) == nil {
+			fileEntity.ExtractedMetadata = metadata
+		}
+	}
+	if fileRecord.Metadata != "" {
+		metadata := map[string]string{}
+		if s.decoder.Decode([]byte(fileRecord.Metadata), &metadata) == nil {
+			fileEntity.Metadata = metadata
+		}
+	}
+	return fileEntity
 }
 
-func (s *retrieveService) RetrieveFile(identifier string) (*RetrieveFileResult, error) {
+func (s *retrieveService) RetrieveFile(p RetrieveFileParam) (*RetrieveFileResult, error) {
 
-	fileRecord, err := s.fileRepo.FindByIdentifier(identifier)
+	fileRecord, err := s.fileRepo.FindByIdentifier(p.Identifier)
 	if err != nil {
 		return nil, err
 	}
 
+	if p.Signature != "" {
+		err = s.signer.Verify(signing.VerifyParam{
+			UniqueId:  fileRecord.UniqueId,
+			Download:  p.Download,
+			ExpiresAt: p.ExpiresAt,
+			Version:   p.Version,
+			Variant:   p.Variant,
+			Transform: transformKey(p.Transform),
+			Signature: p.Signature,
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else if fileRecord.Visibility == file.VISIBILITY_PRIVATE {
+		return nil, app_error.NewForbiddenError("File")
+	}
+
+	// expired files are served as gone until the reaper deletes them
+	if fileRecord.IsExpired(time.Now()) {
+		return nil, app_error.NewExpiredError("File")
+	}
+
+	requestedName := s.fileService.RemoveFileExtension(p.Identifier)
+	if requestedName != fileRecord.Slug && requestedName == fileRecord.UniqueId {
+		result := &RetrieveFileResult{
+			File:     s.newFileEntity(fileRecord),
+			Redirect: true,
+		}
+		return result, nil
+	}
+
+	isPreviousVersion := p.Version != 0 && p.Version != fileRecord.Version
+	if isPreviousVersion {
+		if p.Variant != "" {
+			return nil, app_error.NewNotfoundError("Variant")
+		}
+		fileRecord, err = s.withVersion(fileRecord, p.Version)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !scanning.IsServable(fileRecord.ScanStatus) {
+		return nil, app_error.NewUnavailableError("File")
+	}
+
+	var option *imaging.TransformOption
+	if p.Variant == "" && !p.Transform.IsEmpty() {
+		option, err = s.transformer.Normalize(*p.Transform, fileRecord.Mimetype)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fileResult := s.newFileEntity(fileRecord)
+
+	var fileData []byte
+	if p.Variant != "" {
+		var variant *repository.VariantModel
+		variant, err = s.findVariant(fileRecord.UniqueId, p.Variant)
+		if err != nil {
+			return nil, err
+		}
+		localPath := fmt.Sprintf("%s/%s", variant.FileLocation, variant.FileName)
+		fileData, err = s.storageRetriever.RetrieveFile(localPath)
+		fileResult.Extension = variant.Extension
+		fileResult.Mimetype = variant.Mimetype
+		fileResult.Size = variant.Size
+	} else if option == nil {
+		localPath := fmt.Sprintf("%s/%s", fileRecord.FileLocation, fileRecord.FileName)
+		fileData, err = s.storageRetriever.RetrieveFile(localPath)
+	} else {
+		fileData, err = s.retrieveTransformed(fileRecord, *option)
+		fileResult.Extension = option.Extension()
+		fileResult.Mimetype = option.Mimetype()
+		fileResult.Size = int64(len(fileData))
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.touchAccess(fileRecord)
+
+	result := &RetrieveFileResult{
+		FileData:     fileData,
+		File:         fileResult,
+		IsAttachment: p.Download || s.isAttachmentForced(fileResult.Mimetype, fileResult.DetectedMimetype),
+	}
+	return result, nil
+}
+
+// SignFileUrl issues a public url which also serves private file until it expires,
+// the signature is bound to the file unique id so it survives slug changes,
+// and to the requested rendition so the url can not serve another version, variant or transformation
+func (s *retrieveService) SignFileUrl(p SignFileUrlParam) (*SignedUrlEntity, error) {
+	expiresIn := p.ExpiresIn
+	if expiresIn == 0 {
+		expiresIn = s.configGetter.GetInt("URL_SIGNING_DEFAULT_TTL")
+	}
+	tag := ""
+	if expiresIn < 0 {
+		tag = "min"
+	} else if expiresIn > s.configGetter.GetInt("URL_SIGNING_MAX_TTL") {
+		tag = "max"
+	}
+	if tag != "" {
+		return nil, app_error.NewValidationError([]app_error.ValidationItem{
+			{
+				Field:   "expires_in",
+				Message: fmt.Sprintf("Key: 'expires_in' Error:Field validation for 'expires_in' failed on the '%s' tag", tag),
+			},
+		})
+	}
+
+	fileRecord, err := s.fileRepo.FindByIdentifier(p.Identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.Claims.CanAccessTenant(fileRecord.TenantId) {
+		return nil, app_error.NewForbiddenError("File")
+	}
+
+	expiresAt := time.Unix(time.Now().Unix()+int64(expiresIn), 0)
+	signature, err := s.signer.Sign(signing.SignParam{
+		UniqueId:  fileRecord.UniqueId,
+		Download:  p.Download,
+		ExpiresAt: expiresAt,
+		Version:   p.Version,
+		Variant:   p.Variant,
+		Transform: transformKey(p.Transform),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+	query.Set("signature", signature)
+	if p.Download {
+		query.Set("download", "1")
+	}
+	if p.Version != 0 {
+		query.Set("version", strconv.FormatInt(p.Version, 10))
+	}
+	if p.Variant != "" {
+		query.Set("variant", p.Variant)
+	}
+	if !p.Transform.IsEmpty() {
+		setTransformQuery(query, p.Transform)
+	}
+	res := &SignedUrlEntity{
+		Url:       fmt.Sprintf("%s?%s", s.newFileEntity(fileRecord).Url, query.Encode()),
+		ExpiresAt: &expiresAt,
+	}
+	return res, nil
+}
+
+// transformKey is the signed form of the requested transformation, empty when there is none
+func transformKey(o *imaging.TransformOption) string {
+	if o.IsEmpty() {
+		return ""
+	}
+	return o.Key()
+}
+
+// setTransformQuery writes the option as the query parameters read by the file resource
+func setTransformQuery(query url.Values, o *imaging.TransformOption) {
+	if o.Width != 0 {
+		query.Set("width", strconv.Itoa(o.Width))
+	}
+	if o.Height != 0 {
+		query.Set("height", strconv.Itoa(o.Height))
+	}
+	if o.Fit != "" {
+		query.Set("fit", o.Fit)
+	}
+	if o.Crop != "" {
+		query.Set("crop", o.Crop)
+	}
+	if o.Quality != 0 {
+		query.Set("quality", strconv.Itoa(o.Quality))
+	}
+	if o.Format != "" {
+		query.Set("format", o.Format)
+	}
+}
+
+// isAttachmentForced tells whether any of the mimetypes is listed by DOWNLOAD_ATTACHMENT_MIMETYPES,
+// such content (e.g: html or svg) could run script on the app origin when it is rendered inline
+func (s *retrieveService) isAttachmentForced(mimetypes ...string) bool {
+	forced := strings.Split(s.configGetter.GetString("DOWNLOAD_ATTACHMENT_MIMETYPES"), ",")
+	for _, mimetype := range mimetypes {
+		mimetype = file.NormalizeMimeType(mimetype)
+		if mimetype == "" {
+			continue
+		}
+		for _, f := range forced {
+			if file.NormalizeMimeType(f) == mimetype {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// touchAccess records the access for the lifecycle rules at most once every ACCESS_TRACKING_INTERVAL,
+// serving the file does not depend on the access being recorded
+func (s *retrieveService) touchAccess(fileRecord *repository.FileModel) {
+	interval := time.Duration(s.configGetter.GetInt("ACCESS_TRACKING_INTERVAL")) * time.Second
+	if interval <= 0 {
+		return
+	}
+	accessedAt := time.Now()
+	accessedBefore := accessedAt.Add(-interval)
+	if fileRecord.AccessedAt != nil && fileRecord.AccessedAt.After(accessedBefore) {
+		return
+	}
+	s.fileRepo.TouchAccess(repository.TouchAccessParam{
+		UniqueId:       fileRecord.UniqueId,
+		AccessedAt:     &accessedAt,
+		AccessedBefore: &accessedBefore,
+	})
+}
+
+// withVersion returns a copy of the file record pointing at the content of the given version
+func (s *retrieveService) withVersion(fileRecord *repository.FileModel, version int64) (*repository.FileModel, error) {
+	versionRecord, err := s.versionRepo.FindVersion(fileRecord.UniqueId, version)
+	if err != nil {
+		return nil, err
+	}
+
+	res := *fileRecord
+	res.Version = versionRecord.Version
+	res.Size = versionRecord.Size
+	res.Extension = versionRecord.Extension
+	res.Mimetype = versionRecord.Mimetype
+	res.DetectedMimetype = versionRecord.DetectedMimetype
+	res.FileLocation = versionRecord.FileLocation
+	res.FileName = versionRecord.FileName
+	res.ScanStatus = versionRecord.ScanStatus
+	res.Checksum = versionRecord.Checksum
+	res.MetadataStripped = versionRecord.MetadataStripped
+	return &res, nil
+}
+
+func (s *retrieveService) findVariant(fileUniqueId, name string) (*repository.VariantModel, error) {
+	variants, err := s.variantRepo.FindByFile(fileUniqueId)
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range variants {
+		if v.Name == name {
+			return &variants[i], nil
+		}
+	}
+	return nil, app_error.NewNotfoundError("Variant")
+}
+
+// retrieveTransformed serves the transformed image from storage when it was already generated,
+// otherwise the original file is transformed and the result is cached next to the file under a name derived from
+// the stored file name and the option, so every content version has its own cache
+func (s *retrieveService) retrieveTransformed(fileRecord *repository.FileModel, option imaging.TransformOption) ([]byte, error) {
+	baseName := s.fileService.RemoveFileExtension(fileRecord.FileName)
+	variantName := fmt.Sprintf("%s.%s.%s", baseName, option.Key(), option.Extension())
+	variantData, err := s.storageRetriever.RetrieveFile(fmt.Sprintf("%s/%s", fileRecord.FileLocation, variantName))
+	if err == nil {
+		return variantData, nil
+	}
+
 	localPath := fmt.Sprintf("%s/%s", fileRecord.FileLocation, fileRecord.FileName)
 	fileData, err := s.storageRetriever.RetrieveFile(localPath)
 	if err != nil {
 		return nil, err
 	}
 
-	appUrl := s.configGetter.GetString("APP_URL")
-	url := fmt.Sprintf("%s/%s/%s", appUrl, "file", fileRecord.FileName)
-	fileResult := &FileEntity{
-		UniqueId:     fileRecord.UniqueId,
-		OriginalName: fileRecord.OriginalName,
-		Name:         fileRecord.Name,
-		Extension:    fileRecord.Extension,
-		Mimetype:     fileRecord.Mimetype,
-		Size:         fileRecord.Size,
-		Url:          url,
-		CreatedAt:    fileRecord.CreatedAt,
-		UpdatedAt:    fileRecord.UpdatedAt,
-		DeletedAt:    fileRecord.DeletedAt,
+	res, err := s.transformer.Transform(fileData, option)
+	if err != nil {
+		return nil, err
 	}
-	result := &RetrieveFileResult{
-		FileData: fileData,
-		File:     fileResult,
+
+	// failing to cache only costs another transformation on the next request
+	saved, err := s.storageSaver.SaveFile(storage.SaveFileParam{
+		FileName: variantName,
+		FileData: res.Data,
+		Provider: fileRecord.Provider,
+	})
+	if err != nil {
+		return res.Data, nil
 	}
-	return result, nil
+	createdAt := time.Now()
+	s.transformRepo.Save(repository.SaveTransformParam{
+		FileUniqueId: fileRecord.UniqueId,
+		FileLocation: saved.FileLocation,
+		FileName:     saved.FileName,
+		CreatedAt:    &createdAt,
+	})
+	return res.Data, nil
 }
 
-func NewRetrieveService(fr repository.FileRepository, cg config.Getter, fs file.FileService, sr storage.Retriever) RetrieveService {
+func NewRetrieveService(fr repository.FileRepository, vr repository.VariantRepository, cg config.Getter, fs file.FileService, sr storage.Retriever, ss storage.Saver, tr imaging.Transformer, d serialization.Decoder, vsr repository.VersionRepository, sg signing.Signer, tfr repository.TransformRepository, v validation.Validator) RetrieveService {
 	return &retrieveService{
 		configGetter:     cg,
 		fileRepo:         fr,
+		variantRepo:      vr,
 		fileService:      fs,
 		storageRetriever: sr,
+		storageSaver:     ss,
+		transformer:      tr,
+		decoder:          d,
+		versionRepo:      vsr,
+		signer:           sg,
+		transformRepo:    tfr,
+		validator:        v,
 	}
 }
@@ -0,0 +1,18 @@
+package sanitizing
+
+type Sanitizer interface {
+	// StripMetadata removes exif, xmp and gps blocks from jpeg, png and webp images,
+	// other file is returned as is
+	StripMetadata(p StripMetadataParam) (*StripMetadataResult, error)
+}
+
+type StripMetadataParam struct {
+	Mimetype string
+	FileData []byte
+}
+
+type StripMetadataResult struct {
+	FileData []byte
+	// IsStripped is false when the file type is not supported
+	IsStripped bool
+}
@@ -0,0 +1,103 @@
+package sanitizing
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	"idaman.id/storage/internal/config"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/extracting"
+	"idaman.id/storage/internal/file"
+)
+
+type sanitizingService struct {
+	extractor    extracting.Extractor
+	configGetter config.Getter
+}
+
+// StripMetadata drops the metadata blocks without touching the image data when possible,
+// jpeg and png having exif orientation are re-encoded upright since the orientation is dropped too
+func (s *sanitizingService) StripMetadata(p StripMetadataParam) (*StripMetadataResult, error) {
+	mimetype := file.NormalizeMimeType(p.Mimetype)
+	orientation := s.readOrientation(mimetype, p.FileData)
+
+	var stripped []byte
+	var err error
+	switch mimetype {
+	case "image/jpeg":
+		stripped, err = stripJpeg(p.FileData)
+	case "image/png":
+		stripped, err = stripPng(p.FileData)
+	case "image/webp":
+		// webp can not be re-encoded using the standard library, so its orientation is kept instead
+		stripped, err = stripWebp(p.FileData, orientation)
+	default:
+		return &StripMetadataResult{FileData: p.FileData}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if orientation > 1 && mimetype != "image/webp" {
+		upright, err := s.applyOrientation(mimetype, stripped, orientation)
+		if err != nil {
+			return nil, err
+		}
+		stripped = upright
+	}
+
+	res := &StripMetadataResult{
+		FileData:   stripped,
+		IsStripped: true,
+	}
+	return res, nil
+}
+
+func (s *sanitizingService) readOrientation(mimetype string, data []byte) int {
+	metadata := s.extractor.ExtractMetadata(extracting.ExtractMetadataParam{
+		Mimetype: mimetype,
+		FileData: data,
+	})
+	if metadata.Image == nil {
+		return 0
+	}
+	return metadata.Image.Orientation
+}
+
+func (s *sanitizingService) applyOrientation(mimetype string, data []byte, orientation int) ([]byte, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, app_error.NewUnsupportedError("Image")
+	}
+	maxPixels := s.configGetter.GetInt("IMAGE_MAX_SOURCE_PIXELS")
+	if maxPixels > 0 && cfg.Width*cfg.Height > maxPixels {
+		return nil, app_error.NewUnsupportedError("Image size")
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, app_error.NewUnsupportedError("Image")
+	}
+	dst := orient(src, orientation)
+
+	buf := bytes.Buffer{}
+	if mimetype == "image/png" {
+		err = png.Encode(&buf, dst)
+	} else {
+		quality := s.configGetter.GetInt("IMAGE_DEFAULT_QUALITY")
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: quality})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func NewSanitizingService(ex extracting.Extractor, cg config.Getter) Sanitizer {
+	return &sanitizingService{
+		extractor:    ex,
+		configGetter: cg,
+	}
+}
@@ -0,0 +1,164 @@
+package sanitizing_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/extracting"
+	"idaman.id/storage/internal/sanitizing"
+)
+
+var _ = Describe("Sanitizing Service", func() {
+	var (
+		cfg       *FakeConfig
+		sanitizer sanitizing.Sanitizer
+	)
+
+	BeforeEach(func() {
+		cfg = &FakeConfig{values: map[string]interface{}{
+			"IMAGE_DEFAULT_QUALITY":   85,
+			"IMAGE_MAX_SOURCE_PIXELS": 1000000,
+		}}
+		sanitizer = sanitizing.NewSanitizingService(extracting.NewExtractingService(), cfg)
+	})
+
+	Context("StripMetadata method", func() {
+		When("jpeg has no orientation", func() {
+			It("should only drop the metadata segments", func() {
+				original := NewJpeg(32, 16)
+				data := InsertJpegSegment(original, 0xE1, append([]byte("Exif\x00\x00"), NewExif(1)...))
+				data = InsertJpegSegment(data, 0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta/>"))
+
+				res, err := sanitizer.StripMetadata(sanitizing.StripMetadataParam{
+					Mimetype: "image/jpeg",
+					FileData: data,
+				})
+
+				Expect(err).To(BeNil())
+				Expect(res.IsStripped).To(BeTrue())
+				Expect(res.FileData).To(Equal(original))
+			})
+		})
+
+		When("jpeg is rotated", func() {
+			It("should apply the orientation", func() {
+				data := InsertJpegSegment(NewJpeg(32, 16), 0xE1, append([]byte("Exif\x00\x00"), NewExif(6)...))
+
+				res, err := sanitizer.StripMetadata(sanitizing.StripMetadataParam{
+					Mimetype: "image/jpeg",
+					FileData: data,
+				})
+
+				Expect(err).To(BeNil())
+				Expect(bytes.Contains(res.FileData, []byte("Exif"))).To(BeFalse())
+				cfg, _, err := image.DecodeConfig(bytes.NewReader(res.FileData))
+				Expect(err).To(BeNil())
+				Expect(cfg.Width).To(Equal(16))
+				Expect(cfg.Height).To(Equal(32))
+			})
+		})
+
+		When("rotated jpeg has too many pixels", func() {
+			It("should return unsupported error without decoding it", func() {
+				cfg.values["IMAGE_MAX_SOURCE_PIXELS"] = 100
+				data := InsertJpegSegment(NewJpeg(32, 16), 0xE1, append([]byte("Exif\x00\x00"), NewExif(6)...))
+
+				res, err := sanitizer.StripMetadata(sanitizing.StripMetadataParam{
+					Mimetype: "image/jpeg",
+					FileData: data,
+				})
+
+				Expect(res).To(BeNil())
+				Expect(err).To(MatchError(app_error.NewUnsupportedError("Image size")))
+			})
+		})
+
+		When("png has exif and text chunks", func() {
+			It("should drop the chunks and apply the orientation", func() {
+				data := InsertPngChunk(NewPng(32, 16), "eXIf", NewExif(8))
+				data = InsertPngChunk(data, "iTXt", []byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00<x:xmpmeta/>"))
+
+				res, err := sanitizer.StripMetadata(sanitizing.StripMetadataParam{
+					Mimetype: "image/png",
+					FileData: data,
+				})
+
+				Expect(err).To(BeNil())
+				Expect(res.IsStripped).To(BeTrue())
+				Expect(bytes.Contains(res.FileData, []byte("eXIf"))).To(BeFalse())
+				Expect(bytes.Contains(res.FileData, []byte("iTXt"))).To(BeFalse())
+				cfg, _, err := image.DecodeConfig(bytes.NewReader(res.FileData))
+				Expect(err).To(BeNil())
+				Expect(cfg.Width).To(Equal(16))
+				Expect(cfg.Height).To(Equal(32))
+			})
+		})
+
+		When("webp has exif and xmp chunks", func() {
+			It("should drop the chunks and clear the flags", func() {
+				res, err := sanitizer.StripMetadata(sanitizing.StripMetadataParam{
+					Mimetype: "image/webp",
+					FileData: NewWebp(1),
+				})
+
+				Expect(err).To(BeNil())
+				Expect(res.IsStripped).To(BeTrue())
+				Expect(bytes.Contains(res.FileData, []byte("EXIF"))).To(BeFalse())
+				Expect(bytes.Contains(res.FileData, []byte("XMP "))).To(BeFalse())
+				Expect(res.FileData[20]).To(Equal(byte(0)))
+				Expect(int(binary.LittleEndian.Uint32(res.FileData[4:]))).To(Equal(len(res.FileData) - 8))
+			})
+		})
+
+		When("webp is rotated", func() {
+			It("should keep the orientation only", func() {
+				res, err := sanitizer.StripMetadata(sanitizing.StripMetadataParam{
+					Mimetype: "image/webp",
+					FileData: NewWebp(6),
+				})
+
+				Expect(err).To(BeNil())
+				Expect(res.IsStripped).To(BeTrue())
+				Expect(bytes.Contains(res.FileData, []byte("XMP "))).To(BeFalse())
+				Expect(res.FileData[20]).To(Equal(byte(0x08)))
+				Expect(int(binary.LittleEndian.Uint32(res.FileData[4:]))).To(Equal(len(res.FileData) - 8))
+				metadata := extracting.NewExtractingService().ExtractMetadata(extracting.ExtractMetadataParam{
+					Mimetype: "image/webp",
+					FileData: res.FileData,
+				})
+				Expect(metadata.Image.Orientation).To(Equal(6))
+				// the gps ifd pointer is dropped along with the other tags
+				Expect(bytes.Contains(res.FileData, []byte{0x25, 0x88})).To(BeFalse())
+			})
+		})
+
+		When("file is not a supported image", func() {
+			It("should return the file as is", func() {
+				data := []byte("%PDF-1.7")
+				res, err := sanitizer.StripMetadata(sanitizing.StripMetadataParam{
+					Mimetype: "application/pdf",
+					FileData: data,
+				})
+
+				Expect(err).To(BeNil())
+				Expect(res).To(Equal(&sanitizing.StripMetadataResult{FileData: data}))
+			})
+		})
+
+		When("image is malformed", func() {
+			It("should return unsupported error", func() {
+				res, err := sanitizer.StripMetadata(sanitizing.StripMetadataParam{
+					Mimetype: "image/jpeg",
+					FileData: []byte("\xFF\xD8\xFF\xE1\xFF\xFF"),
+				})
+
+				Expect(res).To(BeNil())
+				Expect(err).To(Equal(app_error.NewUnsupportedError("Image")))
+			})
+		})
+	})
+})
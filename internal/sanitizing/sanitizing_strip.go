@@ -0,0 +1,145 @@
+package sanitizing
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	app_error "idaman.id/storage/internal/error"
+)
+
+// jpegDroppedMarkers are APP1 (exif and xmp), APP13 (iptc) and comment segments
+var jpegDroppedMarkers = map[byte]bool{
+	0xE1: true,
+	0xED: true,
+	0xFE: true,
+}
+
+// pngDroppedChunks are the exif, textual (including xmp) and modification time chunks
+var pngDroppedChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"tIME": true,
+}
+
+func stripJpeg(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, app_error.NewUnsupportedError("Image")
+	}
+
+	res := bytes.Buffer{}
+	res.Write(data[:2])
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			return nil, app_error.NewUnsupportedError("Image")
+		}
+		marker := data[i+1]
+		// the remaining data is the compressed image which holds no metadata
+		if marker == 0xDA || marker == 0xD9 {
+			res.Write(data[i:])
+			return res.Bytes(), nil
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return nil, app_error.NewUnsupportedError("Image")
+		}
+		if !jpegDroppedMarkers[marker] {
+			res.Write(data[i : i+2+size])
+		}
+		i += 2 + size
+	}
+	return nil, app_error.NewUnsupportedError("Image")
+}
+
+func stripPng(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")) {
+		return nil, app_error.NewUnsupportedError("Image")
+	}
+
+	res := bytes.Buffer{}
+	res.Write(data[:8])
+	pos := 8
+	for pos+12 <= len(data) {
+		size := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + size
+		if size < 0 || end > len(data) {
+			return nil, app_error.NewUnsupportedError("Image")
+		}
+		kind := string(data[pos+4 : pos+8])
+		if !pngDroppedChunks[kind] {
+			res.Write(data[pos:end])
+		}
+		if kind == "IEND" {
+			return res.Bytes(), nil
+		}
+		pos = end
+	}
+	return nil, app_error.NewUnsupportedError("Image")
+}
+
+// stripWebp replaces the exif chunk with one holding the orientation only when it is not upright
+func stripWebp(data []byte, orientation int) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, app_error.NewUnsupportedError("Image")
+	}
+
+	body := bytes.Buffer{}
+	body.WriteString("WEBP")
+	pos := 12
+	for pos+8 <= len(data) {
+		kind := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		// chunks are padded to even size
+		end := pos + 8 + size + size%2
+		if size < 0 || end > len(data) {
+			return nil, app_error.NewUnsupportedError("Image")
+		}
+
+		switch kind {
+		case "XMP ":
+		case "EXIF":
+			if orientation > 1 {
+				exif := newOrientationExif(orientation)
+				body.WriteString("EXIF")
+				binary.Write(&body, binary.LittleEndian, uint32(len(exif)))
+				body.Write(exif)
+			}
+		case "VP8X":
+			chunk := append([]byte{}, data[pos:end]...)
+			if size > 0 {
+				// clear the xmp presence flag, and the exif one unless the orientation is kept
+				chunk[8] &^= 0x04
+				if orientation <= 1 {
+					chunk[8] &^= 0x08
+				}
+			}
+			body.Write(chunk)
+		default:
+			body.Write(data[pos:end])
+		}
+		pos = end
+	}
+
+	res := bytes.Buffer{}
+	res.WriteString("RIFF")
+	binary.Write(&res, binary.LittleEndian, uint32(body.Len()))
+	res.Write(body.Bytes())
+	return res.Bytes(), nil
+}
+
+// newOrientationExif creates little endian tiff structure holding the orientation tag only
+func newOrientationExif(orientation int) []byte {
+	le := binary.LittleEndian
+	res := bytes.Buffer{}
+	res.WriteString("II")
+	binary.Write(&res, le, uint16(42))
+	binary.Write(&res, le, uint32(8))
+	binary.Write(&res, le, uint16(1))
+	binary.Write(&res, le, []uint16{0x0112, 3})
+	binary.Write(&res, le, uint32(1))
+	binary.Write(&res, le, []uint16{uint16(orientation), 0})
+	binary.Write(&res, le, uint32(0))
+	return res.Bytes()
+}
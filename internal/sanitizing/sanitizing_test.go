@@ -0,0 +1,136 @@
+package sanitizing_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSanitizing(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Sanitizing Package")
+}
+
+type FakeConfig struct {
+	values map[string]interface{}
+}
+
+func (c *FakeConfig) GetString(key string) string {
+	v, _ := c.values[key].(string)
+	return v
+}
+
+func (c *FakeConfig) GetInt(key string) int {
+	v, _ := c.values[key].(int)
+	return v
+}
+
+func (c *FakeConfig) GetBool(key string) bool {
+	v, _ := c.values[key].(bool)
+	return v
+}
+
+func (c *FakeConfig) Get(key string) interface{} {
+	return c.values[key]
+}
+
+func NewImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{255, 0, 0, 255})
+		}
+	}
+	return img
+}
+
+func NewJpeg(width, height int) []byte {
+	buf := bytes.Buffer{}
+	Expect(jpeg.Encode(&buf, NewImage(width, height), nil)).To(Succeed())
+	return buf.Bytes()
+}
+
+func NewPng(width, height int) []byte {
+	buf := bytes.Buffer{}
+	Expect(png.Encode(&buf, NewImage(width, height))).To(Succeed())
+	return buf.Bytes()
+}
+
+// NewExif creates little endian tiff structure holding the orientation and gps ifd pointer
+func NewExif(orientation uint16) []byte {
+	le := binary.LittleEndian
+	b := &bytes.Buffer{}
+	b.WriteString("II")
+	binary.Write(b, le, uint16(42))
+	binary.Write(b, le, uint32(8))
+	binary.Write(b, le, uint16(2))
+	binary.Write(b, le, []uint16{0x0112, 3})
+	binary.Write(b, le, uint32(1))
+	binary.Write(b, le, []uint16{orientation, 0})
+	binary.Write(b, le, []uint16{0x8825, 4})
+	binary.Write(b, le, uint32(1))
+	binary.Write(b, le, uint32(38))
+	binary.Write(b, le, uint32(0))
+	// empty gps ifd
+	binary.Write(b, le, uint16(0))
+	return b.Bytes()
+}
+
+// InsertJpegSegment puts the segment right after the start of image marker
+func InsertJpegSegment(data []byte, marker byte, content []byte) []byte {
+	header := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(header[2:], uint16(len(content)+2))
+	res := append([]byte{}, data[:2]...)
+	res = append(res, header...)
+	res = append(res, content...)
+	return append(res, data[2:]...)
+}
+
+// InsertPngChunk puts the chunk right after the IHDR chunk
+func InsertPngChunk(data []byte, kind string, content []byte) []byte {
+	chunk := make([]byte, 8, 12+len(content))
+	binary.BigEndian.PutUint32(chunk, uint32(len(content)))
+	copy(chunk[4:], kind)
+	chunk = append(chunk, content...)
+	crc := make([]byte, 4)
+	binary.BigEndian.PutUint32(crc, crc32.ChecksumIEEE(chunk[4:]))
+	chunk = append(chunk, crc...)
+
+	// signature (8) and IHDR chunk (25)
+	res := append([]byte{}, data[:33]...)
+	res = append(res, chunk...)
+	return append(res, data[33:]...)
+}
+
+// NewWebp creates extended webp container, only the chunk layout matters for stripping
+func NewWebp(orientation uint16) []byte {
+	body := &bytes.Buffer{}
+	body.WriteString("WEBP")
+	writeRiffChunk(body, "VP8X", []byte{0x0C, 0, 0, 0, 9, 0, 0, 9, 0, 0})
+	writeRiffChunk(body, "VP8L", []byte{0x2F, 1, 2, 3, 4})
+	writeRiffChunk(body, "EXIF", NewExif(orientation))
+	writeRiffChunk(body, "XMP ", []byte("<x:xmpmeta/>"))
+
+	res := &bytes.Buffer{}
+	res.WriteString("RIFF")
+	binary.Write(res, binary.LittleEndian, uint32(body.Len()))
+	res.Write(body.Bytes())
+	return res.Bytes()
+}
+
+func writeRiffChunk(b *bytes.Buffer, kind string, content []byte) {
+	b.WriteString(kind)
+	binary.Write(b, binary.LittleEndian, uint32(len(content)))
+	b.Write(content)
+	if len(content)%2 == 1 {
+		b.WriteByte(0)
+	}
+}
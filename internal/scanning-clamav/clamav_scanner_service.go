@@ -0,0 +1,130 @@
+package scanning_clamav
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"idaman.id/storage/internal/config"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/scanning"
+)
+
+const CHUNK_SIZE = 64 * 1024
+
+// clamavScanner streams file content to clamd using the INSTREAM command,
+// clamd may listen on tcp (tcp://host:port) or unix socket (unix:///path/to/clamd.ctl)
+type clamavScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// Scan reports every connection and protocol failure as unavailable scanner,
+// so the clamd address and socket errors are never exposed to the client
+func (s *clamavScanner) Scan(r io.Reader) (*scanning.ScanResult, error) {
+	res, err := s.scan(r)
+	if err != nil {
+		return nil, app_error.NewUnavailableError("Scanner")
+	}
+	return res, nil
+}
+
+func (s *clamavScanner) scan(r io.Reader) (*scanning.ScanResult, error) {
+	conn, err := net.DialTimeout(s.network, s.address, s.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	err = conn.SetDeadline(time.Now().Add(s.timeout))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = conn.Write([]byte("zINSTREAM\x00"))
+	if err != nil {
+		return nil, err
+	}
+
+	// every chunk is prefixed by its size
+	chunk := make([]byte, 4+CHUNK_SIZE)
+	for {
+		n, readErr := io.ReadFull(r, chunk[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(chunk[:4], uint32(n))
+			_, err = conn.Write(chunk[:4+n])
+			if err != nil {
+				return nil, err
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+	_, err = conn.Write([]byte{0, 0, 0, 0})
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString('\x00')
+	if err != nil && reply == "" {
+		return nil, err
+	}
+	return parseReply(reply)
+}
+
+// parseReply reads clamd reply such as "stream: OK" or "stream: Eicar-Signature FOUND"
+func parseReply(reply string) (*scanning.ScanResult, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	status := reply
+	if i := strings.Index(reply, ": "); i >= 0 {
+		status = reply[i+2:]
+	}
+
+	switch {
+	case status == "OK":
+		return &scanning.ScanResult{}, nil
+	case strings.HasSuffix(status, " FOUND"):
+		res := &scanning.ScanResult{
+			IsInfected: true,
+			Signature:  strings.TrimSuffix(status, " FOUND"),
+		}
+		return res, nil
+	}
+	return nil, errors.New("clamd: " + reply)
+}
+
+func NewClamavScanner(cg config.Getter) (scanning.Scanner, error) {
+	address := cg.GetString("CLAMAV_ADDRESS")
+	network := "tcp"
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		network = "unix"
+		address = strings.TrimPrefix(address, "unix://")
+	case strings.HasPrefix(address, "tcp://"):
+		address = strings.TrimPrefix(address, "tcp://")
+	}
+	if address == "" {
+		return nil, errors.New("clamav address is not configured")
+	}
+
+	timeout := time.Duration(cg.GetInt("CLAMAV_TIMEOUT")) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	s := &clamavScanner{
+		network: network,
+		address: address,
+		timeout: timeout,
+	}
+	return s, nil
+}
@@ -0,0 +1,124 @@
+package scanning_clamav_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/scanning"
+	scanning_clamav "idaman.id/storage/internal/scanning-clamav"
+)
+
+var _ = Describe("Clamav Scanner Service", func() {
+
+	Context("NewClamavScanner function", func() {
+		When("address is not configured", func() {
+			It("should return error", func() {
+				s, err := scanning_clamav.NewClamavScanner(&FakeConfig{values: map[string]interface{}{}})
+
+				Expect(s).To(BeNil())
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+
+	Context("Scan method over tcp", func() {
+		var (
+			clamd   *FakeClamd
+			scanner scanning.Scanner
+		)
+
+		BeforeEach(func() {
+			clamd = NewFakeClamd("tcp", "127.0.0.1:0")
+			cfg := &FakeConfig{values: map[string]interface{}{
+				"CLAMAV_ADDRESS": "tcp://" + clamd.listener.Addr().String(),
+				"CLAMAV_TIMEOUT": 5,
+			}}
+
+			var err error
+			scanner, err = scanning_clamav.NewClamavScanner(cfg)
+			Expect(err).To(BeNil())
+		})
+
+		AfterEach(func() {
+			clamd.Close()
+		})
+
+		When("content is clean", func() {
+			It("should return clean result", func() {
+				res, err := scanner.Scan(strings.NewReader("hello world"))
+
+				Expect(err).To(BeNil())
+				Expect(res).To(Equal(&scanning.ScanResult{}))
+			})
+		})
+
+		When("content is larger than one chunk", func() {
+			It("should stream every chunk", func() {
+				data := append(bytes.Repeat([]byte("a"), scanning_clamav.CHUNK_SIZE*2), []byte("EICAR")...)
+				res, err := scanner.Scan(bytes.NewReader(data))
+
+				Expect(err).To(BeNil())
+				Expect(res.IsInfected).To(BeTrue())
+			})
+		})
+
+		When("content is infected", func() {
+			It("should return infected result", func() {
+				res, err := scanner.Scan(strings.NewReader("X5O!P%@AP[4\\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*"))
+
+				Expect(err).To(BeNil())
+				Expect(res).To(Equal(&scanning.ScanResult{
+					IsInfected: true,
+					Signature:  "Eicar-Signature",
+				}))
+			})
+		})
+
+		When("clamd replies with error", func() {
+			It("should return error", func() {
+				clamd.reply = "INSTREAM size limit exceeded. ERROR"
+				res, err := scanner.Scan(strings.NewReader("hello world"))
+
+				Expect(res).To(BeNil())
+				Expect(err).To(Equal(app_error.NewUnavailableError("Scanner")))
+			})
+		})
+	})
+
+	Context("Scan method over unix socket", func() {
+		When("content is infected", func() {
+			It("should return infected result", func() {
+				socket := filepath.Join(GinkgoT().TempDir(), "clamd.ctl")
+				clamd := NewFakeClamd("unix", socket)
+				defer clamd.Close()
+
+				scanner, err := scanning_clamav.NewClamavScanner(&FakeConfig{values: map[string]interface{}{
+					"CLAMAV_ADDRESS": "unix://" + socket,
+				}})
+				Expect(err).To(BeNil())
+
+				res, err := scanner.Scan(strings.NewReader("EICAR"))
+
+				Expect(err).To(BeNil())
+				Expect(res.IsInfected).To(BeTrue())
+			})
+		})
+	})
+
+	Context("Scan method when clamd is unreachable", func() {
+		It("should return error", func() {
+			scanner, _ := scanning_clamav.NewClamavScanner(&FakeConfig{values: map[string]interface{}{
+				"CLAMAV_ADDRESS": "unix://" + filepath.Join(GinkgoT().TempDir(), "missing.ctl"),
+			}})
+			res, err := scanner.Scan(strings.NewReader("hello world"))
+
+			Expect(res).To(BeNil())
+			Expect(err).To(Equal(app_error.NewUnavailableError("Scanner")))
+			Expect(err.Error()).ToNot(ContainSubstring("missing.ctl"))
+		})
+	})
+})
@@ -0,0 +1,107 @@
+package scanning_clamav_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestScanningClamav(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Scanning Clamav Package")
+}
+
+type FakeConfig struct {
+	values map[string]interface{}
+}
+
+func (c *FakeConfig) GetString(key string) string {
+	v, _ := c.values[key].(string)
+	return v
+}
+
+func (c *FakeConfig) GetInt(key string) int {
+	v, _ := c.values[key].(int)
+	return v
+}
+
+func (c *FakeConfig) GetBool(key string) bool {
+	v, _ := c.values[key].(bool)
+	return v
+}
+
+func (c *FakeConfig) Get(key string) interface{} {
+	return c.values[key]
+}
+
+// FakeClamd answers INSTREAM commands, content containing "EICAR" is reported as infected
+type FakeClamd struct {
+	listener net.Listener
+	reply    string
+}
+
+func NewFakeClamd(network, address string) *FakeClamd {
+	l, err := net.Listen(network, address)
+	Expect(err).To(BeNil())
+
+	d := &FakeClamd{listener: l}
+	go d.serve()
+	return d
+}
+
+func (d *FakeClamd) Close() {
+	d.listener.Close()
+}
+
+func (d *FakeClamd) serve() {
+	for {
+		conn, err := d.listener.Accept()
+		if err != nil {
+			return
+		}
+		go d.handle(conn)
+	}
+}
+
+func (d *FakeClamd) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	cmd, err := r.ReadString('\x00')
+	if err != nil || cmd != "zINSTREAM\x00" {
+		conn.Write([]byte("UNKNOWN COMMAND\x00"))
+		return
+	}
+
+	data := bytes.Buffer{}
+	size := make([]byte, 4)
+	for {
+		_, err = io.ReadFull(r, size)
+		if err != nil {
+			return
+		}
+		n := binary.BigEndian.Uint32(size)
+		if n == 0 {
+			break
+		}
+		_, err = io.CopyN(&data, r, int64(n))
+		if err != nil {
+			return
+		}
+	}
+
+	reply := "stream: OK"
+	if d.reply != "" {
+		reply = d.reply
+	} else if strings.Contains(data.String(), "EICAR") {
+		reply = "stream: Eicar-Signature FOUND"
+	}
+	conn.Write([]byte(reply + "\x00"))
+}
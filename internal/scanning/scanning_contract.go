@@ -0,0 +1,57 @@
+package scanning
+
+import "io"
+
+const (
+	SCAN_STATUS_UNSCANNED = "unscanned"
+	SCAN_STATUS_PENDING   = "pending"
+	SCAN_STATUS_CLEAN     = "clean"
+	SCAN_STATUS_INFECTED  = "infected"
+	SCAN_STATUS_FAILED    = "failed"
+
+	SCAN_MODE_SYNC  = "sync"
+	SCAN_MODE_ASYNC = "async"
+)
+
+type Scanner interface {
+	// Scan reads the content until its end
+	Scan(r io.Reader) (*ScanResult, error)
+}
+
+type ScanService interface {
+	IsAsync() bool
+	ScanFile(p ScanFileParam) (*ScanFileResult, error)
+	// ScanStream scans the content while the caller stores it, nothing is quarantined since the content is not kept,
+	// an infected content is moved by QuarantineStoredFile once it is stored
+	ScanStream(r io.Reader) (*ScanFileResult, error)
+	QuarantineStoredFile(p QuarantineStoredFileParam) error
+	ScanStoredFile(p ScanStoredFileParam)
+}
+
+type ScanResult struct {
+	IsInfected bool
+	Signature  string
+}
+
+type ScanFileParam struct {
+	FileName string
+	FileData []byte
+}
+
+type ScanFileResult struct {
+	ScanStatus string
+	Signature  string
+}
+
+// ScanStoredFileParam reads the content from the storage when FileData is nil, e.g: a streamed content
+type ScanStoredFileParam struct {
+	UniqueId     string
+	FileLocation string
+	FileName     string
+	FileData     []byte
+}
+
+type QuarantineStoredFileParam struct {
+	FileLocation string
+	FileName     string
+}
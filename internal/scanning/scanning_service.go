@@ -0,0 +1,152 @@
+package scanning
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"idaman.id/storage/internal/repository"
+	"idaman.id/storage/internal/storage"
+)
+
+type scanService struct {
+	scanner     Scanner
+	mode        string
+	workers     chan struct{}
+	quarantine  storage.Saver
+	fileStorage storage.Storage
+	fileRepo    repository.FileRepository
+}
+
+func (s *scanService) IsAsync() bool {
+	return s.mode == SCAN_MODE_ASYNC
+}
+
+// ScanFile scans the file before it is stored, infected file is kept in quarantine only
+func (s *scanService) ScanFile(p ScanFileParam) (*ScanFileResult, error) {
+	res, err := s.scanner.Scan(bytes.NewReader(p.FileData))
+	if err != nil {
+		return nil, err
+	}
+
+	if !res.IsInfected {
+		return &ScanFileResult{ScanStatus: SCAN_STATUS_CLEAN}, nil
+	}
+
+	_, err = s.quarantine.SaveFile(storage.SaveFileParam{
+		FileName: p.FileName,
+		FileData: p.FileData,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := &ScanFileResult{
+		ScanStatus: SCAN_STATUS_INFECTED,
+		Signature:  res.Signature,
+	}
+	return result, nil
+}
+
+func (s *scanService) ScanStream(r io.Reader) (*ScanFileResult, error) {
+	res, err := s.scanner.Scan(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if !res.IsInfected {
+		return &ScanFileResult{ScanStatus: SCAN_STATUS_CLEAN}, nil
+	}
+	result := &ScanFileResult{
+		ScanStatus: SCAN_STATUS_INFECTED,
+		Signature:  res.Signature,
+	}
+	return result, nil
+}
+
+func (s *scanService) QuarantineStoredFile(p QuarantineStoredFileParam) error {
+	return s.quarantineStoredFile(p.FileLocation, p.FileName, nil)
+}
+
+// ScanStoredFile scans an already stored file in background,
+// infected file is moved into quarantine and the file record keeps the final scan status,
+// it waits for a free worker when every worker is already scanning
+func (s *scanService) ScanStoredFile(p ScanStoredFileParam) {
+	s.workers <- struct{}{}
+	go func() {
+		defer func() { <-s.workers }()
+		s.scanStoredFile(p)
+	}()
+}
+
+func (s *scanService) scanStoredFile(p ScanStoredFileParam) {
+	status := SCAN_STATUS_FAILED
+	fileData := p.FileData
+	var err error
+	if fileData == nil {
+		fileData, err = s.fileStorage.RetrieveFile(fmt.Sprintf("%s/%s", p.FileLocation, p.FileName))
+	}
+	var res *ScanResult
+	if err == nil {
+		res, err = s.scanner.Scan(bytes.NewReader(fileData))
+	}
+	if err == nil && !res.IsInfected {
+		status = SCAN_STATUS_CLEAN
+	}
+	if err == nil && res.IsInfected {
+		status = SCAN_STATUS_INFECTED
+		// the record is marked as infected anyway, so the public copy is never served
+		s.quarantineStoredFile(p.FileLocation, p.FileName, fileData)
+	}
+
+	updatedAt := time.Now()
+	s.fileRepo.UpdateScanStatus(repository.UpdateScanStatusParam{
+		UniqueId:   p.UniqueId,
+		FileName:   p.FileName,
+		ScanStatus: status,
+		UpdatedAt:  &updatedAt,
+	})
+}
+
+// quarantineStoredFile keeps the content in quarantine before it is deleted from the storage,
+// nil fileData is read from the storage
+func (s *scanService) quarantineStoredFile(fileLocation, fileName string, fileData []byte) error {
+	filePath := fmt.Sprintf("%s/%s", fileLocation, fileName)
+	if fileData == nil {
+		var err error
+		fileData, err = s.fileStorage.RetrieveFile(filePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := s.quarantine.SaveFile(storage.SaveFileParam{
+		FileName: fileName,
+		FileData: fileData,
+	})
+	if err != nil {
+		return err
+	}
+	return s.fileStorage.DeleteFile(filePath)
+}
+
+// IsServable tells whether file with the given scan status can be served publicly,
+// file uploaded while scanning is disabled is considered servable
+func IsServable(scanStatus string) bool {
+	return scanStatus == "" || scanStatus == SCAN_STATUS_UNSCANNED || scanStatus == SCAN_STATUS_CLEAN
+}
+
+// NewScanService scans at most workers stored files at once, at least one
+func NewScanService(sc Scanner, mode string, workers int, q storage.Saver, fs storage.Storage, fr repository.FileRepository) ScanService {
+	if workers < 1 {
+		workers = 1
+	}
+	return &scanService{
+		scanner:     sc,
+		mode:        mode,
+		workers:     make(chan struct{}, workers),
+		quarantine:  q,
+		fileStorage: fs,
+		fileRepo:    fr,
+	}
+}
@@ -0,0 +1,210 @@
+package scanning_test
+
+import (
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/scanning"
+)
+
+var _ = Describe("Scanning Service", func() {
+	var (
+		scanner    *FakeScanner
+		quarantine *FakeStorage
+		fileStore  *FakeStorage
+		fileRepo   *FakeFileRepository
+	)
+
+	BeforeEach(func() {
+		scanner = &FakeScanner{}
+		quarantine = &FakeStorage{}
+		fileStore = &FakeStorage{files: map[string][]byte{"storage/file/file-1.txt": []byte("infected")}}
+		fileRepo = &FakeFileRepository{status: map[string]string{}}
+	})
+
+	Context("IsAsync method", func() {
+		It("should follow the scan mode", func() {
+			syncService := scanning.NewScanService(scanner, scanning.SCAN_MODE_SYNC, 1, quarantine, fileStore, fileRepo)
+			asyncService := scanning.NewScanService(scanner, scanning.SCAN_MODE_ASYNC, 1, quarantine, fileStore, fileRepo)
+
+			Expect(syncService.IsAsync()).To(BeFalse())
+			Expect(asyncService.IsAsync()).To(BeTrue())
+		})
+	})
+
+	Context("ScanFile method", func() {
+		var (
+			s scanning.ScanService
+		)
+
+		BeforeEach(func() {
+			s = scanning.NewScanService(scanner, scanning.SCAN_MODE_SYNC, 1, quarantine, fileStore, fileRepo)
+		})
+
+		When("file is clean", func() {
+			It("should return clean status", func() {
+				res, err := s.ScanFile(scanning.ScanFileParam{FileName: "a.txt", FileData: []byte("clean")})
+
+				Expect(err).To(BeNil())
+				Expect(res.ScanStatus).To(Equal(scanning.SCAN_STATUS_CLEAN))
+				Expect(quarantine.Saved()).To(BeEmpty())
+			})
+		})
+
+		When("file is infected", func() {
+			It("should quarantine the file", func() {
+				res, err := s.ScanFile(scanning.ScanFileParam{FileName: "a.txt", FileData: []byte("infected")})
+
+				Expect(err).To(BeNil())
+				Expect(res).To(Equal(&scanning.ScanFileResult{
+					ScanStatus: scanning.SCAN_STATUS_INFECTED,
+					Signature:  "Eicar-Signature",
+				}))
+				Expect(quarantine.Saved()).To(Equal([]string{"a.txt"}))
+			})
+		})
+
+		When("scanner is failed", func() {
+			It("should return error", func() {
+				scanner.err = errors.New("connection refused")
+				res, err := s.ScanFile(scanning.ScanFileParam{FileName: "a.txt", FileData: []byte("clean")})
+
+				Expect(res).To(BeNil())
+				Expect(err).To(MatchError("connection refused"))
+			})
+		})
+	})
+
+	Context("ScanStream method", func() {
+		var (
+			s scanning.ScanService
+		)
+
+		BeforeEach(func() {
+			s = scanning.NewScanService(scanner, scanning.SCAN_MODE_SYNC, 1, quarantine, fileStore, fileRepo)
+		})
+
+		When("stream is clean", func() {
+			It("should return clean status", func() {
+				res, err := s.ScanStream(strings.NewReader("clean"))
+
+				Expect(err).To(BeNil())
+				Expect(res.ScanStatus).To(Equal(scanning.SCAN_STATUS_CLEAN))
+			})
+		})
+
+		When("stream is infected", func() {
+			It("should return infected status without quarantine", func() {
+				res, err := s.ScanStream(strings.NewReader("infected"))
+
+				Expect(err).To(BeNil())
+				Expect(res).To(Equal(&scanning.ScanFileResult{
+					ScanStatus: scanning.SCAN_STATUS_INFECTED,
+					Signature:  "Eicar-Signature",
+				}))
+				Expect(quarantine.Saved()).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("QuarantineStoredFile method", func() {
+		It("should move the stored content into quarantine", func() {
+			s := scanning.NewScanService(scanner, scanning.SCAN_MODE_SYNC, 1, quarantine, fileStore, fileRepo)
+
+			err := s.QuarantineStoredFile(scanning.QuarantineStoredFileParam{FileLocation: "storage/file", FileName: "file-1.txt"})
+
+			Expect(err).To(BeNil())
+			Expect(quarantine.Saved()).To(Equal([]string{"file-1.txt"}))
+			Expect(fileStore.Deleted()).To(Equal([]string{"storage/file/file-1.txt"}))
+		})
+	})
+
+	Context("ScanStoredFile method", func() {
+		var (
+			s scanning.ScanService
+			p scanning.ScanStoredFileParam
+		)
+
+		BeforeEach(func() {
+			s = scanning.NewScanService(scanner, scanning.SCAN_MODE_ASYNC, 1, quarantine, fileStore, fileRepo)
+			p = scanning.ScanStoredFileParam{
+				UniqueId:     "file-1",
+				FileLocation: "storage/file",
+				FileName:     "file-1.txt",
+				FileData:     []byte("clean"),
+			}
+		})
+
+		When("file is clean", func() {
+			It("should mark file as clean", func() {
+				s.ScanStoredFile(p)
+
+				Eventually(func() string { return fileRepo.ScanStatus("file-1") }).Should(Equal(scanning.SCAN_STATUS_CLEAN))
+				Expect(fileStore.Deleted()).To(BeEmpty())
+			})
+		})
+
+		When("file is infected", func() {
+			It("should move file into quarantine", func() {
+				p.FileData = []byte("infected")
+				s.ScanStoredFile(p)
+
+				Eventually(func() string { return fileRepo.ScanStatus("file-1") }).Should(Equal(scanning.SCAN_STATUS_INFECTED))
+				Expect(quarantine.Saved()).To(Equal([]string{"file-1.txt"}))
+				Expect(fileStore.Deleted()).To(Equal([]string{"storage/file/file-1.txt"}))
+			})
+		})
+
+		When("file data is not given", func() {
+			It("should scan the stored content", func() {
+				p.FileData = nil
+				s.ScanStoredFile(p)
+
+				Eventually(func() string { return fileRepo.ScanStatus("file-1") }).Should(Equal(scanning.SCAN_STATUS_INFECTED))
+				Expect(quarantine.Saved()).To(Equal([]string{"file-1.txt"}))
+			})
+		})
+
+		When("scanner is failed", func() {
+			It("should mark file as failed", func() {
+				scanner.err = errors.New("connection refused")
+				s.ScanStoredFile(p)
+
+				Eventually(func() string { return fileRepo.ScanStatus("file-1") }).Should(Equal(scanning.SCAN_STATUS_FAILED))
+			})
+		})
+
+		When("more files are stored than workers", func() {
+			It("should scan one file at a time per worker", func() {
+				scanner.release = make(chan bool)
+				s.ScanStoredFile(p)
+				p.UniqueId = "file-2"
+				isQueued := make(chan bool)
+				go func() {
+					s.ScanStoredFile(p)
+					close(isQueued)
+				}()
+
+				Consistently(isQueued).ShouldNot(BeClosed())
+				Expect(scanner.Running()).To(Equal(1))
+				close(scanner.release)
+				Eventually(isQueued).Should(BeClosed())
+				Eventually(func() string { return fileRepo.ScanStatus("file-2") }).Should(Equal(scanning.SCAN_STATUS_CLEAN))
+				Expect(scanner.MaxRunning()).To(Equal(1))
+			})
+		})
+	})
+
+	Context("IsServable function", func() {
+		It("should only serve unscanned and clean file", func() {
+			Expect(scanning.IsServable("")).To(BeTrue())
+			Expect(scanning.IsServable(scanning.SCAN_STATUS_UNSCANNED)).To(BeTrue())
+			Expect(scanning.IsServable(scanning.SCAN_STATUS_CLEAN)).To(BeTrue())
+			Expect(scanning.IsServable(scanning.SCAN_STATUS_PENDING)).To(BeFalse())
+			Expect(scanning.IsServable(scanning.SCAN_STATUS_INFECTED)).To(BeFalse())
+			Expect(scanning.IsServable(scanning.SCAN_STATUS_FAILED)).To(BeFalse())
+		})
+	})
+})
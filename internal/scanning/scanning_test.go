@@ -0,0 +1,175 @@
+package scanning_test
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/repository"
+	"idaman.id/storage/internal/scanning"
+	"idaman.id/storage/internal/storage"
+)
+
+func TestScanning(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Scanning Package")
+}
+
+// FakeScanner blocks every scan until release is closed when it is set
+type FakeScanner struct {
+	err     error
+	release chan bool
+
+	mu         sync.Mutex
+	running    int
+	maxRunning int
+}
+
+func (s *FakeScanner) Scan(r io.Reader) (*scanning.ScanResult, error) {
+	s.mu.Lock()
+	s.running++
+	if s.running > s.maxRunning {
+		s.maxRunning = s.running
+	}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.running--
+		s.mu.Unlock()
+	}()
+
+	if s.release != nil {
+		<-s.release
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if string(data) == "infected" {
+		return &scanning.ScanResult{IsInfected: true, Signature: "Eicar-Signature"}, nil
+	}
+	return &scanning.ScanResult{}, nil
+}
+
+func (s *FakeScanner) Running() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running
+}
+
+func (s *FakeScanner) MaxRunning() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.maxRunning
+}
+
+type FakeStorage struct {
+	mu      sync.Mutex
+	files   map[string][]byte
+	saved   []string
+	deleted []string
+}
+
+func (s *FakeStorage) SaveFile(p storage.SaveFileParam) (*storage.SaveFileResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saved = append(s.saved, p.FileName)
+	return &storage.SaveFileResult{FileLocation: "storage/quarantine", FileName: p.FileName}, nil
+}
+
+func (s *FakeStorage) RetrieveFile(localPath string) (storage.BinaryFile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.files[localPath]
+	if !ok {
+		return nil, errors.New("file is not found")
+	}
+	return data, nil
+}
+
+func (s *FakeStorage) DeleteFile(localPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleted = append(s.deleted, localPath)
+	return nil
+}
+
+func (s *FakeStorage) Saved() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.saved
+}
+
+func (s *FakeStorage) Deleted() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.deleted
+}
+
+type FakeFileRepository struct {
+	mu     sync.Mutex
+	status map[string]string
+}
+
+func (r *FakeFileRepository) FindByIdentifier(identifier string) (*repository.FileModel, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *FakeFileRepository) Save(p repository.SaveFileParam) error {
+	return nil
+}
+
+func (r *FakeFileRepository) Delete(p repository.DeleteFileParam) error {
+	return nil
+}
+
+func (r *FakeFileRepository) UpdateScanStatus(p repository.UpdateScanStatusParam) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status[p.UniqueId] = p.ScanStatus
+	return nil
+}
+
+func (r *FakeFileRepository) Update(p repository.UpdateFileParam) error {
+	return nil
+}
+
+func (r *FakeFileRepository) ReplaceContent(p repository.ReplaceContentParam) error {
+	return nil
+}
+
+func (r *FakeFileRepository) FindAll(p repository.FindAllFileParam) (*repository.FindAllFileResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *FakeFileRepository) IsSlugTaken(slug, uniqueId string) (bool, error) {
+	return false, errors.New("not implemented")
+}
+
+func (r *FakeFileRepository) FindExpired(p repository.FindExpiredFileParam) ([]repository.FileModel, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *FakeFileRepository) FindByProvider(p repository.FindByProviderFileParam) ([]repository.FileModel, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *FakeFileRepository) TouchAccess(p repository.TouchAccessParam) error {
+	return nil
+}
+
+func (r *FakeFileRepository) TransitionContent(p repository.TransitionContentParam) error {
+	return nil
+}
+
+func (r *FakeFileRepository) ScanStatus(uniqueId string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status[uniqueId]
+}
@@ -0,0 +1,32 @@
+package signing
+
+import "time"
+
+type Signer interface {
+	// Sign returns the signature of a file url valid until ExpiresAt,
+	// the signature covers the file and every signed parameter
+	Sign(p SignParam) (string, error)
+	// Verify fails with forbidden error when the signature does not match or is expired
+	Verify(p VerifyParam) error
+}
+
+// SignParam signs the served rendition along with the file, Version, Variant and Transform,
+// the key of the transformation option, are left empty when the current content is served as is
+type SignParam struct {
+	UniqueId  string
+	Download  bool
+	ExpiresAt time.Time
+	Version   int64
+	Variant   string
+	Transform string
+}
+
+type VerifyParam struct {
+	UniqueId  string
+	Download  bool
+	ExpiresAt int64
+	Version   int64
+	Variant   string
+	Transform string
+	Signature string
+}
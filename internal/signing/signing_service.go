@@ -0,0 +1,69 @@
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"idaman.id/storage/internal/config"
+	app_error "idaman.id/storage/internal/error"
+)
+
+type signingService struct {
+	configGetter config.Getter
+}
+
+func (s *signingService) Sign(p SignParam) (string, error) {
+	secret := s.configGetter.GetString("URL_SIGNING_SECRET")
+	if secret == "" {
+		return "", app_error.NewUnsupportedError("Signed url")
+	}
+	payload := newPayload(p.UniqueId, p.Download, p.ExpiresAt.Unix(), p.Version, p.Variant, p.Transform)
+	return sign(secret, payload), nil
+}
+
+func (s *signingService) Verify(p VerifyParam) error {
+	secret := s.configGetter.GetString("URL_SIGNING_SECRET")
+	if secret == "" || p.Signature == "" {
+		return app_error.NewForbiddenError("Signature")
+	}
+	if time.Now().Unix() > p.ExpiresAt {
+		return app_error.NewForbiddenError("Signature")
+	}
+
+	expected := sign(secret, newPayload(p.UniqueId, p.Download, p.ExpiresAt, p.Version, p.Variant, p.Transform))
+	if !hmac.Equal([]byte(expected), []byte(p.Signature)) {
+		return app_error.NewForbiddenError("Signature")
+	}
+	return nil
+}
+
+// newPayload joins the signed parameters with newline, the rendition is only appended
+// when it is given so the urls signed before it was covered are still valid
+func newPayload(uniqueId string, download bool, expiresAt, version int64, variant, transform string) string {
+	disposition := "inline"
+	if download {
+		disposition = "attachment"
+	}
+	payload := fmt.Sprintf("%s\n%s\n%d", uniqueId, disposition, expiresAt)
+	if version != 0 || variant != "" || transform != "" {
+		payload += fmt.Sprintf("\n%d\n%s\n%s", version, variant, transform)
+	}
+	return payload
+}
+
+// sign computes HMAC-SHA256 of the payload,
+// encoded as unpadded base64url to be used as query value as is
+func sign(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func NewSigningService(cg config.Getter) Signer {
+	return &signingService{
+		configGetter: cg,
+	}
+}
@@ -0,0 +1,142 @@
+package signing_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/signing"
+)
+
+var _ = Describe("Signing Service", func() {
+	var (
+		signer    signing.Signer
+		expiresAt time.Time
+	)
+
+	BeforeEach(func() {
+		signer = signing.NewSigningService(&FakeConfig{values: map[string]interface{}{
+			"URL_SIGNING_SECRET": "secret",
+		}})
+		expiresAt = time.Now().Add(time.Hour)
+	})
+
+	Context("Sign method", func() {
+		When("secret is not configured", func() {
+			It("should return unsupported error", func() {
+				signer = signing.NewSigningService(&FakeConfig{values: map[string]interface{}{}})
+				res, err := signer.Sign(signing.SignParam{UniqueId: "file-1", ExpiresAt: expiresAt})
+
+				Expect(res).To(BeEmpty())
+				Expect(err).To(Equal(app_error.NewUnsupportedError("Signed url")))
+			})
+		})
+
+		When("disposition is different", func() {
+			It("should return different signature", func() {
+				inline, _ := signer.Sign(signing.SignParam{UniqueId: "file-1", ExpiresAt: expiresAt})
+				attachment, _ := signer.Sign(signing.SignParam{UniqueId: "file-1", Download: true, ExpiresAt: expiresAt})
+
+				Expect(inline).ToNot(BeEmpty())
+				Expect(inline).ToNot(Equal(attachment))
+			})
+		})
+	})
+
+	Context("Verify method", func() {
+		var (
+			param signing.VerifyParam
+		)
+
+		BeforeEach(func() {
+			signature, err := signer.Sign(signing.SignParam{UniqueId: "file-1", Download: true, ExpiresAt: expiresAt})
+			Expect(err).To(BeNil())
+			param = signing.VerifyParam{
+				UniqueId:  "file-1",
+				Download:  true,
+				ExpiresAt: expiresAt.Unix(),
+				Signature: signature,
+			}
+		})
+
+		When("signature matches", func() {
+			It("should return nil", func() {
+				err := signer.Verify(param)
+
+				Expect(err).To(BeNil())
+			})
+		})
+
+		When("signed parameter is changed", func() {
+			It("should return forbidden error", func() {
+				param.Download = false
+				Expect(signer.Verify(param)).To(Equal(app_error.NewForbiddenError("Signature")))
+
+				param.Download = true
+				param.UniqueId = "file-2"
+				Expect(signer.Verify(param)).To(Equal(app_error.NewForbiddenError("Signature")))
+
+				param.UniqueId = "file-1"
+				param.ExpiresAt = param.ExpiresAt + 60
+				Expect(signer.Verify(param)).To(Equal(app_error.NewForbiddenError("Signature")))
+			})
+		})
+
+		When("rendition is signed", func() {
+			BeforeEach(func() {
+				param.Version = 1
+				param.Variant = "thumb"
+				param.Signature, _ = signer.Sign(signing.SignParam{
+					UniqueId:  "file-1",
+					Download:  true,
+					ExpiresAt: expiresAt,
+					Version:   1,
+					Variant:   "thumb",
+				})
+			})
+
+			It("should only serve the signed rendition", func() {
+				Expect(signer.Verify(param)).To(BeNil())
+
+				param.Variant = ""
+				Expect(signer.Verify(param)).To(Equal(app_error.NewForbiddenError("Signature")))
+
+				param.Variant = "thumb"
+				param.Version = 2
+				Expect(signer.Verify(param)).To(Equal(app_error.NewForbiddenError("Signature")))
+
+				param.Version = 1
+				param.Transform = "9f86d081884c7d65"
+				Expect(signer.Verify(param)).To(Equal(app_error.NewForbiddenError("Signature")))
+			})
+		})
+
+		When("rendition is requested on a url signed without it", func() {
+			It("should return forbidden error", func() {
+				param.Transform = "9f86d081884c7d65"
+				Expect(signer.Verify(param)).To(Equal(app_error.NewForbiddenError("Signature")))
+			})
+		})
+
+		When("signature is expired", func() {
+			It("should return forbidden error", func() {
+				expired := time.Now().Add(-time.Minute)
+				param.ExpiresAt = expired.Unix()
+				param.Signature, _ = signer.Sign(signing.SignParam{UniqueId: "file-1", Download: true, ExpiresAt: expired})
+				err := signer.Verify(param)
+
+				Expect(err).To(Equal(app_error.NewForbiddenError("Signature")))
+			})
+		})
+
+		When("signature is missing", func() {
+			It("should return forbidden error", func() {
+				param.Signature = ""
+				err := signer.Verify(param)
+
+				Expect(err).To(Equal(app_error.NewForbiddenError("Signature")))
+			})
+		})
+	})
+})
@@ -3,6 +3,7 @@ package storage_local
 import (
 	"bufio"
 	"errors"
+	"io"
 	"io/fs"
 	"io/ioutil"
 	"os"
@@ -53,6 +54,37 @@ func (s *storageLocal) SaveFile(param storage.SaveFileParam) (*storage.SaveFileR
 	return &res, nil
 }
 
+// SaveStream creates the file exclusively and removes the partial file when the content can not be read
+func (s *storageLocal) SaveStream(param storage.SaveStreamParam) (*storage.SaveFileResult, error) {
+	fl := s.storageDir
+	fn := param.FileName
+	path := fl + "/" + fn
+
+	osFile, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return nil, app_error.NewAlreadyExistsError("File")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = io.Copy(osFile, param.Reader)
+	closeErr := osFile.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	res := storage.SaveFileResult{
+		FileLocation: fl,
+		FileName:     fn,
+	}
+	return &res, nil
+}
+
 func (s *storageLocal) DeleteFile(fileLocation string) error {
 	err := os.Remove(fileLocation)
 
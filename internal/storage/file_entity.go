@@ -10,3 +10,10 @@ type FileEntity struct {
 	LocalPath string
 	CreatedAt time.Time
 }
+
+type ProviderEntity struct {
+	Name string
+	// Location is the FileLocation of every file saved by the provider
+	Location string
+	Storage  Storage
+}
@@ -1,5 +1,7 @@
 package storage
 
+import "io"
+
 type BinaryFile = []byte
 
 type Retriever interface {
@@ -14,9 +16,12 @@ type Saver interface {
 	SaveFile(param SaveFileParam) (result *SaveFileResult, err error)
 }
 
+// SaveFileParam saves to the storage of Provider when it goes through a Registry,
+// empty Provider saves to the default provider
 type SaveFileParam struct {
 	FileName string
 	FileData BinaryFile
+	Provider string
 }
 
 type SaveFileResult struct {
@@ -24,8 +29,35 @@ type SaveFileResult struct {
 	FileName     string
 }
 
+// StreamSaver saves the content while it is read, so it is never held in memory
+type StreamSaver interface {
+	SaveStream(param SaveStreamParam) (result *SaveFileResult, err error)
+}
+
+// SaveStreamParam is SaveFileParam whose content is read from Reader,
+// nothing is left under FileName when Reader fails
+type SaveStreamParam struct {
+	FileName string
+	Reader   io.Reader
+	Provider string
+}
+
 type Storage interface {
 	Saver
 	Retriever
 	Deleter
 }
+
+// StreamStorage is the storage of the uploaded contents, which may be streamed
+type StreamStorage interface {
+	Storage
+	StreamSaver
+}
+
+// Registry is the storage of every configured provider,
+// files are retrieved and deleted through the provider whose location holds them
+type Registry interface {
+	StreamStorage
+	// Provider returns the named provider, `UnsupportedError` when it is not configured
+	Provider(name string) (*ProviderEntity, error)
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"io"
+	"strings"
+
+	app_error "idaman.id/storage/internal/error"
+)
+
+type providerRegistry struct {
+	defaultProvider string
+	providers       map[string]*ProviderEntity
+}
+
+func (r *providerRegistry) Provider(name string) (*ProviderEntity, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, app_error.NewUnsupportedError("Provider")
+	}
+	return provider, nil
+}
+
+func (r *providerRegistry) SaveFile(p SaveFileParam) (*SaveFileResult, error) {
+	name := p.Provider
+	if name == "" {
+		name = r.defaultProvider
+	}
+	provider, err := r.Provider(name)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Storage.SaveFile(p)
+}
+
+// SaveStream streams to the provider storage when it is a StreamSaver,
+// otherwise the content is read in memory and saved as a file
+func (r *providerRegistry) SaveStream(p SaveStreamParam) (*SaveFileResult, error) {
+	name := p.Provider
+	if name == "" {
+		name = r.defaultProvider
+	}
+	provider, err := r.Provider(name)
+	if err != nil {
+		return nil, err
+	}
+	if streamSaver, ok := provider.Storage.(StreamSaver); ok {
+		return streamSaver.SaveStream(p)
+	}
+
+	fileData, err := io.ReadAll(p.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Storage.SaveFile(SaveFileParam{
+		FileName: p.FileName,
+		FileData: fileData,
+	})
+}
+
+func (r *providerRegistry) RetrieveFile(localPath string) (BinaryFile, error) {
+	return r.locate(localPath).RetrieveFile(localPath)
+}
+
+func (r *providerRegistry) DeleteFile(localPath string) error {
+	return r.locate(localPath).DeleteFile(localPath)
+}
+
+// locate returns the storage of the provider with the longest location holding the path,
+// paths outside of every location belong to the default provider
+func (r *providerRegistry) locate(localPath string) Storage {
+	var located *ProviderEntity
+	for _, provider := range r.providers {
+		if !strings.HasPrefix(localPath, provider.Location+"/") {
+			continue
+		}
+		if located == nil || len(provider.Location) > len(located.Location) {
+			located = provider
+		}
+	}
+	if located == nil {
+		located = r.providers[r.defaultProvider]
+	}
+	return located.Storage
+}
+
+// NewProviderRegistry requires the default provider to be one of the providers
+func NewProviderRegistry(defaultProvider string, providers []ProviderEntity) (Registry, error) {
+	r := &providerRegistry{
+		defaultProvider: defaultProvider,
+		providers:       map[string]*ProviderEntity{},
+	}
+	for i := range providers {
+		r.providers[providers[i].Name] = &providers[i]
+	}
+	if _, ok := r.providers[defaultProvider]; !ok {
+		return nil, app_error.NewUnsupportedError("Provider")
+	}
+	return r, nil
+}
@@ -0,0 +1,132 @@
+package storage_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/storage"
+)
+
+var _ = Describe("Storage Service", func() {
+	var (
+		localStorage   *FakeStorage
+		archiveStorage *FakeStorage
+		registry       storage.Registry
+	)
+
+	BeforeEach(func() {
+		localStorage = &FakeStorage{location: "storage/file"}
+		archiveStorage = &FakeStorage{location: "storage/file-archive"}
+		registry, _ = storage.NewProviderRegistry("local", []storage.ProviderEntity{
+			{Name: "local", Location: "storage/file", Storage: localStorage},
+			{Name: "archive", Location: "storage/file-archive", Storage: archiveStorage},
+		})
+	})
+
+	Context("NewProviderRegistry function", func() {
+		When("default provider is not configured", func() {
+			It("should return unsupported error", func() {
+				res, err := storage.NewProviderRegistry("s3", []storage.ProviderEntity{
+					{Name: "local", Location: "storage/file", Storage: localStorage},
+				})
+
+				Expect(res).To(BeNil())
+				Expect(err).To(Equal(app_error.NewUnsupportedError("Provider")))
+			})
+		})
+	})
+
+	Context("Provider method", func() {
+		When("provider is not configured", func() {
+			It("should return unsupported error", func() {
+				res, err := registry.Provider("s3")
+
+				Expect(res).To(BeNil())
+				Expect(err).To(Equal(app_error.NewUnsupportedError("Provider")))
+			})
+		})
+
+		When("provider is configured", func() {
+			It("should return the provider", func() {
+				res, err := registry.Provider("archive")
+
+				Expect(err).To(BeNil())
+				Expect(res.Location).To(Equal("storage/file-archive"))
+			})
+		})
+	})
+
+	Context("SaveFile method", func() {
+		When("provider is empty", func() {
+			It("should save to the default provider", func() {
+				res, err := registry.SaveFile(storage.SaveFileParam{FileName: "file-1.png"})
+
+				Expect(err).To(BeNil())
+				Expect(res.FileLocation).To(Equal("storage/file"))
+				Expect(localStorage.saved).To(Equal([]string{"file-1.png"}))
+			})
+		})
+
+		When("provider is given", func() {
+			It("should save to the provider", func() {
+				res, err := registry.SaveFile(storage.SaveFileParam{FileName: "file-1.png", Provider: "archive"})
+
+				Expect(err).To(BeNil())
+				Expect(res.FileLocation).To(Equal("storage/file-archive"))
+				Expect(localStorage.saved).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("SaveStream method", func() {
+		When("provider storage can not stream", func() {
+			It("should save the content read in memory", func() {
+				res, err := registry.SaveStream(storage.SaveStreamParam{FileName: "file-1.txt", Reader: strings.NewReader("hello")})
+
+				Expect(err).To(BeNil())
+				Expect(res.FileLocation).To(Equal("storage/file"))
+				Expect(localStorage.saved).To(Equal([]string{"file-1.txt"}))
+				Expect(localStorage.contents).To(Equal([]string{"hello"}))
+			})
+		})
+
+		When("provider storage can stream", func() {
+			It("should stream to the provider", func() {
+				streamStorage := &FakeStreamStorage{FakeStorage: FakeStorage{location: "storage/file-stream"}}
+				registry, _ = storage.NewProviderRegistry("stream", []storage.ProviderEntity{
+					{Name: "stream", Location: "storage/file-stream", Storage: streamStorage},
+				})
+
+				res, err := registry.SaveStream(storage.SaveStreamParam{FileName: "file-1.txt", Reader: strings.NewReader("hello")})
+
+				Expect(err).To(BeNil())
+				Expect(res.FileLocation).To(Equal("storage/file-stream"))
+				Expect(streamStorage.saved).To(BeEmpty())
+				Expect(streamStorage.streamed).To(Equal([]string{"hello"}))
+			})
+		})
+	})
+
+	Context("RetrieveFile and DeleteFile method", func() {
+		When("path is held by a provider sharing a location prefix", func() {
+			It("should use the provider holding the path", func() {
+				registry.RetrieveFile("storage/file-archive/file-1.png")
+				registry.DeleteFile("storage/file-archive/file-1.png")
+
+				Expect(archiveStorage.retrieved).To(Equal([]string{"storage/file-archive/file-1.png"}))
+				Expect(archiveStorage.deleted).To(Equal([]string{"storage/file-archive/file-1.png"}))
+				Expect(localStorage.retrieved).To(BeEmpty())
+			})
+		})
+
+		When("path is outside of every location", func() {
+			It("should use the default provider", func() {
+				registry.RetrieveFile("custom-dir/file-1.png")
+
+				Expect(localStorage.retrieved).To(Equal([]string{"custom-dir/file-1.png"}))
+			})
+		})
+	})
+})
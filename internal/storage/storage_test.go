@@ -0,0 +1,54 @@
+package storage_test
+
+import (
+	"io"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/storage"
+)
+
+func TestStorage(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Storage Package")
+}
+
+type FakeStorage struct {
+	location  string
+	saved     []string
+	contents  []string
+	retrieved []string
+	deleted   []string
+}
+
+func (s *FakeStorage) SaveFile(p storage.SaveFileParam) (*storage.SaveFileResult, error) {
+	s.saved = append(s.saved, p.FileName)
+	s.contents = append(s.contents, string(p.FileData))
+	return &storage.SaveFileResult{FileLocation: s.location, FileName: p.FileName}, nil
+}
+
+func (s *FakeStorage) RetrieveFile(localPath string) (storage.BinaryFile, error) {
+	s.retrieved = append(s.retrieved, localPath)
+	return []byte{}, nil
+}
+
+func (s *FakeStorage) DeleteFile(localPath string) error {
+	s.deleted = append(s.deleted, localPath)
+	return nil
+}
+
+// FakeStreamStorage saves the streamed contents without going through SaveFile
+type FakeStreamStorage struct {
+	FakeStorage
+	streamed []string
+}
+
+func (s *FakeStreamStorage) SaveStream(p storage.SaveStreamParam) (*storage.SaveFileResult, error) {
+	fileData, err := io.ReadAll(p.Reader)
+	if err != nil {
+		return nil, err
+	}
+	s.streamed = append(s.streamed, string(fileData))
+	return &storage.SaveFileResult{FileLocation: s.location, FileName: p.FileName}, nil
+}
@@ -0,0 +1,21 @@
+package tiering
+
+import "errors"
+
+var (
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+)
+
+type Tierer interface {
+	// Transition moves the files due for an application lifecycle transition rule to the target provider,
+	// every run continues after the last file checked by the previous run and wraps around at the end
+	Transition() (*TransitionResult, error)
+}
+
+type TransitionResult struct {
+	TransitionedFiles int64
+	TransitionedSize  int64
+	FailedFiles       int64
+	// OrphanedFiles are moved while their source copy could not be deleted
+	OrphanedFiles int64
+}
@@ -0,0 +1,191 @@
+package tiering
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"idaman.id/storage/internal/application"
+	"idaman.id/storage/internal/config"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/metrics"
+	"idaman.id/storage/internal/repository"
+	"idaman.id/storage/internal/scanning"
+	"idaman.id/storage/internal/storage"
+)
+
+type tierMetrics struct {
+	runs              metrics.Counter
+	transitionedFiles metrics.Counter
+	transitionedSize  metrics.Counter
+	failedFiles       metrics.Counter
+	orphanedFiles     metrics.Counter
+	lastRun           metrics.Gauge
+	lastDuration      metrics.Gauge
+}
+
+type tierService struct {
+	configGetter config.Getter
+	fileRepo     repository.FileRepository
+	appGetter    application.ApplicationGetter
+	registry     storage.Registry
+	metrics      tierMetrics
+	now          func() time.Time
+	// cursor is the id of the last file checked
+	cursor int64
+}
+
+func (s *tierService) Transition() (*TransitionResult, error) {
+	startedAt := s.now()
+	res := &TransitionResult{}
+	err := s.transition(startedAt, res)
+
+	s.metrics.runs.Add(1)
+	s.metrics.transitionedFiles.Add(float64(res.TransitionedFiles))
+	s.metrics.transitionedSize.Add(float64(res.TransitionedSize))
+	s.metrics.failedFiles.Add(float64(res.FailedFiles))
+	s.metrics.orphanedFiles.Add(float64(res.OrphanedFiles))
+	s.metrics.lastRun.Set(float64(startedAt.Unix()))
+	s.metrics.lastDuration.Set(s.now().Sub(startedAt).Seconds())
+	return res, err
+}
+
+// transition checks at most TIERING_MAX_BATCHES batches of the files held by STORAGE_PROVIDERS,
+// the cursor goes back to the first file once the last one is checked
+func (s *tierService) transition(now time.Time, res *TransitionResult) error {
+	providers := []string{}
+	for _, provider := range strings.Split(s.configGetter.GetString("STORAGE_PROVIDERS"), ",") {
+		if provider = strings.TrimSpace(provider); provider != "" {
+			providers = append(providers, provider)
+		}
+	}
+
+	batchSize := s.configGetter.GetInt("TIERING_BATCH_SIZE")
+	maxBatches := s.configGetter.GetInt("TIERING_MAX_BATCHES")
+	for batch := 0; batch < maxBatches; batch++ {
+		files, err := s.fileRepo.FindByProvider(repository.FindByProviderFileParam{
+			Providers: providers,
+			AfterId:   s.cursor,
+			Limit:     batchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, f := range files {
+			s.cursor = f.Id
+			isMoved, isOrphaned, err := s.transitionFile(f, now)
+			if err != nil {
+				res.FailedFiles++
+				continue
+			}
+			if isMoved {
+				res.TransitionedFiles++
+				res.TransitionedSize += f.Size
+			}
+			if isOrphaned {
+				res.OrphanedFiles++
+			}
+		}
+
+		if len(files) < batchSize {
+			s.cursor = 0
+			break
+		}
+	}
+	return nil
+}
+
+// transitionFile copies the content to the target provider and verifies the copy before pointing the record at it,
+// the source is only deleted once the record is moved so a failure at any step keeps the file served
+func (s *tierService) transitionFile(f repository.FileModel, now time.Time) (isMoved, isOrphaned bool, err error) {
+	app := s.appGetter.GetApplication(f.TenantId)
+	rule := app.FindTransitionRule(f.Provider, f.Mimetype, f.Size, f.Tags)
+	if rule == nil || !rule.IsTransitionDue(f.CreatedAt, f.AccessedAt, now) {
+		return false, false, nil
+	}
+
+	// files being scanned may still be moved to quarantine
+	if !scanning.IsServable(f.ScanStatus) {
+		return false, false, nil
+	}
+
+	target, err := s.registry.Provider(rule.TransitionTo)
+	if err != nil {
+		return false, false, err
+	}
+
+	sourcePath := fmt.Sprintf("%s/%s", f.FileLocation, f.FileName)
+	fileData, err := s.registry.RetrieveFile(sourcePath)
+	if err != nil {
+		return false, false, err
+	}
+	checksum := sha256.Sum256(fileData)
+	if f.Checksum != "" && f.Checksum != hex.EncodeToString(checksum[:]) {
+		return false, false, ErrChecksumMismatch
+	}
+
+	// a copy left by an interrupted run is verified like a new one
+	location := target.Location
+	saved, err := target.Storage.SaveFile(storage.SaveFileParam{
+		FileName: f.FileName,
+		FileData: fileData,
+	})
+	if _, isExists := err.(*app_error.AlreadyExistsError); err != nil && !isExists {
+		return false, false, err
+	}
+	if saved != nil {
+		location = saved.FileLocation
+	}
+	targetPath := fmt.Sprintf("%s/%s", location, f.FileName)
+	if targetPath == sourcePath {
+		return false, false, nil
+	}
+
+	copyData, err := target.Storage.RetrieveFile(targetPath)
+	if err == nil && sha256.Sum256(copyData) != checksum {
+		err = ErrChecksumMismatch
+	}
+	if err == nil {
+		err = s.fileRepo.TransitionContent(repository.TransitionContentParam{
+			UniqueId:     f.UniqueId,
+			FileName:     f.FileName,
+			FromLocation: f.FileLocation,
+			ToLocation:   location,
+			Provider:     target.Name,
+		})
+	}
+	if _, isReplaced := err.(*app_error.PreconditionFailedError); isReplaced {
+		// the content has been replaced or deleted since the batch was loaded
+		target.Storage.DeleteFile(targetPath)
+		return false, false, nil
+	}
+	if err != nil {
+		target.Storage.DeleteFile(targetPath)
+		return false, false, err
+	}
+
+	err = s.registry.DeleteFile(sourcePath)
+	return true, err != nil, nil
+}
+
+func NewTierService(cg config.Getter, fr repository.FileRepository, ag application.ApplicationGetter, r storage.Registry, mr metrics.Registry) Tierer {
+	return &tierService{
+		configGetter: cg,
+		fileRepo:     fr,
+		appGetter:    ag,
+		registry:     r,
+		metrics: tierMetrics{
+			runs:              mr.Counter("storage_tiering_runs_total", "Number of lifecycle transition runs"),
+			transitionedFiles: mr.Counter("storage_tiering_transitioned_files_total", "Number of files moved to another provider"),
+			transitionedSize:  mr.Counter("storage_tiering_transitioned_bytes_total", "Size of the files moved to another provider"),
+			failedFiles:       mr.Counter("storage_tiering_failed_files_total", "Number of files which could not be moved"),
+			orphanedFiles:     mr.Counter("storage_tiering_orphaned_files_total", "Number of moved files whose source copy could not be deleted"),
+			lastRun:           mr.Gauge("storage_tiering_last_run_timestamp_seconds", "Start time of the last transition run"),
+			lastDuration:      mr.Gauge("storage_tiering_last_run_duration_seconds", "Duration of the last transition run"),
+		},
+		now: time.Now,
+	}
+}
@@ -0,0 +1,186 @@
+package tiering_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/application"
+	"idaman.id/storage/internal/metrics"
+	"idaman.id/storage/internal/repository"
+	"idaman.id/storage/internal/storage"
+	"idaman.id/storage/internal/tiering"
+)
+
+var _ = Describe("Tier Service", func() {
+	const (
+		// sha256 of "content"
+		checksum = "ed7002b439e9ac845f22357d822bac1444730fbdb6016d3ec9432297b9ec9f73"
+	)
+
+	var (
+		tierer         tiering.Tierer
+		fileRepo       *FakeFileRepository
+		localStorage   *FakeStorage
+		archiveStorage *FakeStorage
+		config         *FakeConfig
+		createdAt      time.Time
+		accessedAt     time.Time
+	)
+
+	newFile := func(id int64, uniqueId string) repository.FileModel {
+		return repository.FileModel{
+			Id:           id,
+			UniqueId:     uniqueId,
+			Size:         7,
+			Mimetype:     "video/mp4",
+			FileLocation: "storage/file",
+			FileName:     uniqueId + ".mp4",
+			Provider:     "local",
+			ScanStatus:   "clean",
+			Checksum:     checksum,
+			CreatedAt:    &createdAt,
+		}
+	}
+
+	BeforeEach(func() {
+		createdAt = time.Now().Add(-48 * time.Hour)
+		accessedAt = time.Now()
+		fileRepo = &FakeFileRepository{
+			files: []repository.FileModel{
+				newFile(1, "a"),
+				newFile(2, "b"),
+				newFile(3, "c"),
+			},
+			replaced: map[string]bool{},
+		}
+		localStorage = &FakeStorage{location: "storage/file", files: map[string][]byte{
+			"storage/file/a.mp4": []byte("content"),
+			"storage/file/b.mp4": []byte("content"),
+			"storage/file/c.mp4": []byte("content"),
+		}}
+		archiveStorage = &FakeStorage{location: "storage/archive", files: map[string][]byte{}}
+		registry, _ := storage.NewProviderRegistry("local", []storage.ProviderEntity{
+			{Name: "local", Location: "storage/file", Storage: localStorage},
+			{Name: "archive", Location: "storage/archive", Storage: archiveStorage},
+		})
+		appGetter := &FakeApplicationGetter{app: &application.ApplicationEntity{
+			Lifecycle: []application.LifecycleRuleEntity{
+				{Provider: "local", Mimetypes: []string{"video/*"}, TransitionTo: "archive", TransitionAfter: 86400},
+			},
+		}}
+		config = &FakeConfig{values: map[string]interface{}{
+			"STORAGE_PROVIDERS":   "local, archive",
+			"TIERING_BATCH_SIZE":  2,
+			"TIERING_MAX_BATCHES": 10,
+		}}
+		tierer = tiering.NewTierService(config, fileRepo, appGetter, registry, metrics.NewRegistry())
+	})
+
+	Context("Transition method", func() {
+		When("files are due for transition", func() {
+			It("should move them to the target provider", func() {
+				res, err := tierer.Transition()
+
+				Expect(err).To(BeNil())
+				Expect(res.TransitionedFiles).To(Equal(int64(3)))
+				Expect(res.TransitionedSize).To(Equal(int64(21)))
+				Expect(fileRepo.queries[0].Providers).To(Equal([]string{"local", "archive"}))
+				Expect(fileRepo.transitions[0]).To(Equal(repository.TransitionContentParam{
+					UniqueId:     "a",
+					FileName:     "a.mp4",
+					FromLocation: "storage/file",
+					ToLocation:   "storage/archive",
+					Provider:     "archive",
+				}))
+				Expect(archiveStorage.files).To(HaveKey("storage/archive/a.mp4"))
+				Expect(localStorage.files).To(BeEmpty())
+			})
+		})
+
+		When("file is not old enough", func() {
+			It("should keep the file", func() {
+				createdAt = time.Now()
+				res, err := tierer.Transition()
+
+				Expect(err).To(BeNil())
+				Expect(res.TransitionedFiles).To(Equal(int64(0)))
+				Expect(localStorage.files).To(HaveLen(3))
+			})
+		})
+
+		When("file was accessed recently", func() {
+			It("should keep the file", func() {
+				fileRepo.files = []repository.FileModel{newFile(1, "a")}
+				fileRepo.files[0].AccessedAt = &accessedAt
+				appGetter := &FakeApplicationGetter{app: &application.ApplicationEntity{
+					Lifecycle: []application.LifecycleRuleEntity{
+						{TransitionTo: "archive", TransitionAfterAccess: 3600},
+					},
+				}}
+				registry, _ := storage.NewProviderRegistry("local", []storage.ProviderEntity{
+					{Name: "local", Location: "storage/file", Storage: localStorage},
+					{Name: "archive", Location: "storage/archive", Storage: archiveStorage},
+				})
+				tierer = tiering.NewTierService(config, fileRepo, appGetter, registry, metrics.NewRegistry())
+				res, err := tierer.Transition()
+
+				Expect(err).To(BeNil())
+				Expect(res.TransitionedFiles).To(Equal(int64(0)))
+				Expect(archiveStorage.files).To(BeEmpty())
+			})
+		})
+
+		When("source content does not match its checksum", func() {
+			It("should count the file as failed and keep it", func() {
+				localStorage.files["storage/file/b.mp4"] = []byte("corrupted")
+				res, err := tierer.Transition()
+
+				Expect(err).To(BeNil())
+				Expect(res.TransitionedFiles).To(Equal(int64(2)))
+				Expect(res.FailedFiles).To(Equal(int64(1)))
+				Expect(localStorage.files).To(HaveKey("storage/file/b.mp4"))
+				Expect(archiveStorage.files).NotTo(HaveKey("storage/archive/b.mp4"))
+			})
+		})
+
+		When("file content is replaced during the transition", func() {
+			It("should remove the copy and keep the source", func() {
+				fileRepo.replaced["a"] = true
+				res, err := tierer.Transition()
+
+				Expect(err).To(BeNil())
+				Expect(res.TransitionedFiles).To(Equal(int64(2)))
+				Expect(res.FailedFiles).To(Equal(int64(0)))
+				Expect(localStorage.files).To(HaveKey("storage/file/a.mp4"))
+				Expect(archiveStorage.files).NotTo(HaveKey("storage/archive/a.mp4"))
+			})
+		})
+
+		When("a verified copy is left by an interrupted run", func() {
+			It("should reuse the copy", func() {
+				archiveStorage.files["storage/archive/a.mp4"] = []byte("content")
+				res, err := tierer.Transition()
+
+				Expect(err).To(BeNil())
+				Expect(res.TransitionedFiles).To(Equal(int64(3)))
+				Expect(localStorage.files).To(BeEmpty())
+			})
+		})
+
+		When("max batches is reached", func() {
+			It("should continue after the last checked file on the next run", func() {
+				config.values["TIERING_MAX_BATCHES"] = 1
+				tierer.Transition()
+				res, err := tierer.Transition()
+
+				Expect(err).To(BeNil())
+				Expect(res.TransitionedFiles).To(Equal(int64(1)))
+				Expect(fileRepo.queries[1].AfterId).To(Equal(int64(2)))
+
+				tierer.Transition()
+				Expect(fileRepo.queries[2].AfterId).To(Equal(int64(0)))
+			})
+		})
+	})
+})
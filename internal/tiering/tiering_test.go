@@ -0,0 +1,105 @@
+package tiering_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/application"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/repository"
+	"idaman.id/storage/internal/storage"
+)
+
+func TestTiering(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Tiering Package")
+}
+
+type FakeConfig struct {
+	values map[string]interface{}
+}
+
+func (c *FakeConfig) GetString(key string) string {
+	value, _ := c.values[key].(string)
+	return value
+}
+
+func (c *FakeConfig) GetInt(key string) int {
+	value, _ := c.values[key].(int)
+	return value
+}
+
+func (c *FakeConfig) GetBool(key string) bool {
+	value, _ := c.values[key].(bool)
+	return value
+}
+
+func (c *FakeConfig) Get(key string) interface{} {
+	return c.values[key]
+}
+
+// FakeFileRepository pages through the files by id like the mysql repository
+type FakeFileRepository struct {
+	repository.FileRepository
+	files       []repository.FileModel
+	queries     []repository.FindByProviderFileParam
+	transitions []repository.TransitionContentParam
+	replaced    map[string]bool
+}
+
+func (r *FakeFileRepository) FindByProvider(p repository.FindByProviderFileParam) ([]repository.FileModel, error) {
+	r.queries = append(r.queries, p)
+	files := []repository.FileModel{}
+	for _, f := range r.files {
+		if f.Id > p.AfterId && len(files) < p.Limit {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+func (r *FakeFileRepository) TransitionContent(p repository.TransitionContentParam) error {
+	if r.replaced[p.UniqueId] {
+		return app_error.NewPreconditionFailedError("File")
+	}
+	r.transitions = append(r.transitions, p)
+	return nil
+}
+
+type FakeApplicationGetter struct {
+	app *application.ApplicationEntity
+}
+
+func (g *FakeApplicationGetter) GetApplication(id string) *application.ApplicationEntity {
+	return g.app
+}
+
+// FakeStorage keeps the files of one location in memory
+type FakeStorage struct {
+	location string
+	files    map[string][]byte
+}
+
+func (s *FakeStorage) SaveFile(p storage.SaveFileParam) (*storage.SaveFileResult, error) {
+	path := s.location + "/" + p.FileName
+	if _, ok := s.files[path]; ok {
+		return nil, app_error.NewAlreadyExistsError("File")
+	}
+	s.files[path] = p.FileData
+	return &storage.SaveFileResult{FileLocation: s.location, FileName: p.FileName}, nil
+}
+
+func (s *FakeStorage) RetrieveFile(localPath string) (storage.BinaryFile, error) {
+	data, ok := s.files[localPath]
+	if !ok {
+		return nil, errors.New("file is not found")
+	}
+	return data, nil
+}
+
+func (s *FakeStorage) DeleteFile(localPath string) error {
+	delete(s.files, localPath)
+	return nil
+}
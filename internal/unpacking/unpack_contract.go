@@ -0,0 +1,23 @@
+package unpacking
+
+const (
+	SKIP_REASON_UNSAFE_PATH = "unsafe_path"
+	SKIP_REASON_SYMLINK     = "symlink"
+	SKIP_REASON_UNSUPPORTED = "unsupported"
+)
+
+type Unpacker interface {
+	// Unpack reads every regular file of a zip, tar or tar.gz archive into memory,
+	// nothing is written to disk so the entry names are only used as file names
+	Unpack(p UnpackParam) (*UnpackResult, error)
+}
+
+// UnpackParam holds the archive content, the archive format is sniffed from Data
+type UnpackParam struct {
+	Data []byte
+}
+
+type UnpackResult struct {
+	Entries []EntryEntity
+	Skipped []SkippedEntity
+}
@@ -0,0 +1,12 @@
+package unpacking
+
+// EntryEntity is a regular file of the archive, Name is the cleaned path inside the archive
+type EntryEntity struct {
+	Name string
+	Data []byte
+}
+
+type SkippedEntity struct {
+	Name   string
+	Reason string
+}
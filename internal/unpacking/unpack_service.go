@@ -0,0 +1,206 @@
+package unpacking
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"idaman.id/storage/internal/config"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/file"
+)
+
+type unpackService struct {
+	configGetter config.Getter
+}
+
+// limiter tracks the uncompressed bytes against the configured total size and compression ratio,
+// the declared entry sizes are never trusted since a crafted archive can lie about them
+type limiter struct {
+	maxEntries int
+	maxSize    int64
+	maxRatio   int64
+	entries    int
+	size       int64
+}
+
+func (s *unpackService) Unpack(p UnpackParam) (*UnpackResult, error) {
+	l := &limiter{
+		maxEntries: s.configGetter.GetInt("UNPACK_MAX_ENTRIES"),
+		maxSize:    int64(s.configGetter.GetInt("UNPACK_MAX_SIZE")),
+		maxRatio:   int64(s.configGetter.GetInt("UNPACK_MAX_RATIO")),
+	}
+	if l.maxRatio > 0 {
+		ratioSize := l.maxRatio * int64(len(p.Data))
+		if l.maxSize <= 0 || ratioSize < l.maxSize {
+			l.maxSize = ratioSize
+		}
+	}
+
+	switch file.DetectMimeType(p.Data) {
+	case file.MIME_ZIP:
+		return s.unpackZip(p.Data, l)
+	case "application/x-gzip":
+		gr, err := gzip.NewReader(bytes.NewReader(p.Data))
+		if err != nil {
+			return nil, newInvalidArchiveError("archive")
+		}
+		defer gr.Close()
+		return s.unpackTar(gr, l)
+	case "application/x-tar":
+		return s.unpackTar(bytes.NewReader(p.Data), l)
+	}
+	return nil, newInvalidArchiveError("archive")
+}
+
+func (s *unpackService) unpackZip(data []byte, l *limiter) (*UnpackResult, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, newInvalidArchiveError("archive")
+	}
+
+	res := &UnpackResult{
+		Entries: []EntryEntity{},
+		Skipped: []SkippedEntity{},
+	}
+	for _, f := range zr.File {
+		mode := f.Mode()
+		if mode.IsDir() {
+			continue
+		}
+		name, isSafe := cleanEntryName(f.Name)
+		if !isSafe {
+			res.skip(f.Name, SKIP_REASON_UNSAFE_PATH)
+			continue
+		}
+		if !mode.IsRegular() {
+			res.skip(name, skipReason(mode&fs.ModeSymlink != 0))
+			continue
+		}
+		if l.maxRatio > 0 && f.UncompressedSize64 > uint64(l.maxRatio)*(f.CompressedSize64+1) {
+			return nil, newInvalidArchiveError("max_ratio")
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, newInvalidArchiveError("archive")
+		}
+		fileData, err := l.read(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		res.Entries = append(res.Entries, EntryEntity{Name: name, Data: fileData})
+	}
+	return res, nil
+}
+
+func (s *unpackService) unpackTar(r io.Reader, l *limiter) (*UnpackResult, error) {
+	tr := tar.NewReader(r)
+	res := &UnpackResult{
+		Entries: []EntryEntity{},
+		Skipped: []SkippedEntity{},
+	}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, newInvalidArchiveError("archive")
+		}
+		if header.Typeflag == tar.TypeDir {
+			continue
+		}
+		name, isSafe := cleanEntryName(header.Name)
+		if !isSafe {
+			res.skip(header.Name, SKIP_REASON_UNSAFE_PATH)
+			continue
+		}
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeRegA {
+			res.skip(name, skipReason(header.Typeflag == tar.TypeSymlink || header.Typeflag == tar.TypeLink))
+			continue
+		}
+
+		fileData, err := l.read(tr)
+		if err != nil {
+			return nil, err
+		}
+		res.Entries = append(res.Entries, EntryEntity{Name: name, Data: fileData})
+	}
+	return res, nil
+}
+
+// read stops as soon as the entry count or the total size goes over the limit
+func (l *limiter) read(r io.Reader) ([]byte, error) {
+	l.entries++
+	if l.maxEntries > 0 && l.entries > l.maxEntries {
+		return nil, newInvalidArchiveError("max_entries")
+	}
+	if l.maxSize <= 0 {
+		return io.ReadAll(r)
+	}
+
+	fileData, err := io.ReadAll(io.LimitReader(r, l.maxSize-l.size+1))
+	if err != nil {
+		return nil, newInvalidArchiveError("archive")
+	}
+	l.size += int64(len(fileData))
+	if l.size > l.maxSize {
+		return nil, newInvalidArchiveError("max_size")
+	}
+	return fileData, nil
+}
+
+func (r *UnpackResult) skip(name, reason string) {
+	r.Skipped = append(r.Skipped, SkippedEntity{
+		Name:   name,
+		Reason: reason,
+	})
+}
+
+func skipReason(isLink bool) string {
+	if isLink {
+		return SKIP_REASON_SYMLINK
+	}
+	return SKIP_REASON_UNSUPPORTED
+}
+
+// cleanEntryName rejects absolute names and names escaping the archive root (zip slip)
+func cleanEntryName(name string) (string, bool) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if name == "" || strings.ContainsRune(name, 0) || path.IsAbs(name) || strings.Contains(strings.SplitN(name, "/", 2)[0], ":") {
+		return "", false
+	}
+	for _, segment := range strings.Split(name, "/") {
+		if segment == ".." {
+			return "", false
+		}
+	}
+	cleaned := path.Clean(name)
+	if cleaned == "." {
+		return "", false
+	}
+	return cleaned, true
+}
+
+func newInvalidArchiveError(tag string) error {
+	return app_error.NewValidationError([]app_error.ValidationItem{
+		{
+			Field:   "file",
+			Message: fmt.Sprintf("Key: 'file' Error:Field validation for 'file' failed on the '%s' tag", tag),
+		},
+	})
+}
+
+func NewUnpackService(cg config.Getter) Unpacker {
+	return &unpackService{
+		configGetter: cg,
+	}
+}
@@ -0,0 +1,132 @@
+package unpacking_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/unpacking"
+)
+
+var _ = Describe("Unpack Service", func() {
+	var (
+		service unpacking.Unpacker
+		config  *FakeConfig
+	)
+
+	BeforeEach(func() {
+		config = &FakeConfig{values: map[string]interface{}{
+			"UNPACK_MAX_ENTRIES": 10,
+			"UNPACK_MAX_SIZE":    1000,
+			"UNPACK_MAX_RATIO":   100,
+		}}
+		service = unpacking.NewUnpackService(config)
+	})
+
+	When("content is not an archive", func() {
+		It("should return validation error", func() {
+			res, err := service.Unpack(unpacking.UnpackParam{Data: []byte("plain text")})
+
+			Expect(res).To(BeNil())
+			Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+		})
+	})
+
+	When("zip archive is valid", func() {
+		It("should return the regular files", func() {
+			data := newZip(
+				archiveEntry{name: "docs/"},
+				archiveEntry{name: "docs/report.txt", data: "report"},
+				archiveEntry{name: "./readme.txt", data: "readme"},
+			)
+			res, err := service.Unpack(unpacking.UnpackParam{Data: data})
+
+			Expect(err).To(BeNil())
+			Expect(res.Entries).To(Equal([]unpacking.EntryEntity{
+				{Name: "docs/report.txt", Data: []byte("report")},
+				{Name: "readme.txt", Data: []byte("readme")},
+			}))
+			Expect(res.Skipped).To(BeEmpty())
+		})
+	})
+
+	When("zip archive has unsafe entries", func() {
+		It("should skip them", func() {
+			data := newZip(
+				archiveEntry{name: "../../etc/passwd", data: "root"},
+				archiveEntry{name: "/etc/hosts", data: "localhost"},
+				archiveEntry{name: "docs\\..\\..\\evil.sh", data: "#!"},
+				archiveEntry{name: "link", data: "/etc/passwd", isSymlink: true},
+				archiveEntry{name: "ok.txt", data: "ok"},
+			)
+			res, err := service.Unpack(unpacking.UnpackParam{Data: data})
+
+			Expect(err).To(BeNil())
+			Expect(res.Entries).To(HaveLen(1))
+			Expect(res.Skipped).To(Equal([]unpacking.SkippedEntity{
+				{Name: "../../etc/passwd", Reason: unpacking.SKIP_REASON_UNSAFE_PATH},
+				{Name: "/etc/hosts", Reason: unpacking.SKIP_REASON_UNSAFE_PATH},
+				{Name: "docs\\..\\..\\evil.sh", Reason: unpacking.SKIP_REASON_UNSAFE_PATH},
+				{Name: "link", Reason: unpacking.SKIP_REASON_SYMLINK},
+			}))
+		})
+	})
+
+	When("zip archive is highly compressed", func() {
+		It("should return validation error", func() {
+			config.values["UNPACK_MAX_SIZE"] = 1000000
+			data := newZip(archiveEntry{name: "bomb.txt", data: strings.Repeat("0", 500000)})
+			res, err := service.Unpack(unpacking.UnpackParam{Data: data})
+
+			Expect(res).To(BeNil())
+			Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+			Expect(err.(*app_error.ValidationError).Items[0].Message).To(ContainSubstring("max_ratio"))
+		})
+	})
+
+	When("archive exceeds total size", func() {
+		It("should return validation error", func() {
+			config.values["UNPACK_MAX_RATIO"] = 0
+			data := newTarGz(
+				archiveEntry{name: "a.txt", data: strings.Repeat("a", 600)},
+				archiveEntry{name: "b.txt", data: strings.Repeat("b", 600)},
+			)
+			res, err := service.Unpack(unpacking.UnpackParam{Data: data})
+
+			Expect(res).To(BeNil())
+			Expect(err.(*app_error.ValidationError).Items[0].Message).To(ContainSubstring("max_size"))
+		})
+	})
+
+	When("archive has too many entries", func() {
+		It("should return validation error", func() {
+			config.values["UNPACK_MAX_ENTRIES"] = 1
+			data := newZip(archiveEntry{name: "a.txt", data: "a"}, archiveEntry{name: "b.txt", data: "b"})
+			res, err := service.Unpack(unpacking.UnpackParam{Data: data})
+
+			Expect(res).To(BeNil())
+			Expect(err.(*app_error.ValidationError).Items[0].Message).To(ContainSubstring("max_entries"))
+		})
+	})
+
+	When("tar.gz archive is valid", func() {
+		It("should return the regular files and skip links", func() {
+			data := newTarGz(
+				archiveEntry{name: "photos/a.txt", data: "a"},
+				archiveEntry{name: "photos/link", data: "../../secret", isSymlink: true},
+				archiveEntry{name: "../b.txt", data: "b"},
+			)
+			res, err := service.Unpack(unpacking.UnpackParam{Data: data})
+
+			Expect(err).To(BeNil())
+			Expect(res.Entries).To(Equal([]unpacking.EntryEntity{
+				{Name: "photos/a.txt", Data: []byte("a")},
+			}))
+			Expect(res.Skipped).To(Equal([]unpacking.SkippedEntity{
+				{Name: "photos/link", Reason: unpacking.SKIP_REASON_SYMLINK},
+				{Name: "../b.txt", Reason: unpacking.SKIP_REASON_UNSAFE_PATH},
+			}))
+		})
+	})
+})
@@ -0,0 +1,82 @@
+package unpacking_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/fs"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestUnpacking(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Unpacking Package")
+}
+
+type FakeConfig struct {
+	values map[string]interface{}
+}
+
+func (c *FakeConfig) GetString(key string) string {
+	v, _ := c.values[key].(string)
+	return v
+}
+
+func (c *FakeConfig) GetInt(key string) int {
+	v, _ := c.values[key].(int)
+	return v
+}
+
+func (c *FakeConfig) GetBool(key string) bool {
+	v, _ := c.values[key].(bool)
+	return v
+}
+
+func (c *FakeConfig) Get(key string) interface{} {
+	return c.values[key]
+}
+
+type archiveEntry struct {
+	name      string
+	data      string
+	isSymlink bool
+}
+
+func newZip(entries ...archiveEntry) []byte {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for _, e := range entries {
+		header := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		if e.isSymlink {
+			header.SetMode(fs.ModeSymlink | 0777)
+		}
+		w, err := zw.CreateHeader(header)
+		Expect(err).To(BeNil())
+		w.Write([]byte(e.data))
+	}
+	Expect(zw.Close()).To(BeNil())
+	return buf.Bytes()
+}
+
+func newTarGz(entries ...archiveEntry) []byte {
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.data)), Typeflag: tar.TypeReg}
+		if e.isSymlink {
+			header = &tar.Header{Name: e.name, Linkname: e.data, Typeflag: tar.TypeSymlink}
+		}
+		Expect(tw.WriteHeader(header)).To(BeNil())
+		if !e.isSymlink {
+			tw.Write([]byte(e.data))
+		}
+	}
+	Expect(tw.Close()).To(BeNil())
+	Expect(gw.Close()).To(BeNil())
+	return buf.Bytes()
+}
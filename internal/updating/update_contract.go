@@ -0,0 +1,22 @@
+package updating
+
+import "idaman.id/storage/internal/auth"
+
+type UpdateService interface {
+	UpdateFile(p UpdateFileParam) error
+}
+
+// UpdateFileParam replaces the whole user metadata and tags,
+// nil value keeps the current one while empty value clears it.
+// Empty Name, Slug and Visibility keep the current value, renaming keeps the slug, and
+// non zero Revision only updates the file when it is still at that revision
+type UpdateFileParam struct {
+	Identifier string
+	Claims     *auth.Claims
+	Name       string
+	Slug       string
+	Visibility string
+	Metadata   map[string]string
+	Tags       []string
+	Revision   int64
+}
@@ -0,0 +1,20 @@
+package updating
+
+type updateRule struct {
+	Name       string            `json:"name" validate:"omitempty,max=255"`
+	Slug       string            `json:"slug" validate:"omitempty,valid_slug"`
+	Visibility string            `json:"visibility" validate:"omitempty,oneof=public private"`
+	Metadata   map[string]string `json:"metadata" validate:"valid_metadata"`
+	Tags       []string          `json:"tags" validate:"valid_tags"`
+}
+
+func NewUpdateRule(p UpdateFileParam) *updateRule {
+	ur := updateRule{
+		Name:       p.Name,
+		Slug:       p.Slug,
+		Visibility: p.Visibility,
+		Metadata:   p.Metadata,
+		Tags:       p.Tags,
+	}
+	return &ur
+}
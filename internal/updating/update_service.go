@@ -0,0 +1,121 @@
+package updating
+
+import (
+	"fmt"
+	"time"
+
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/file"
+	"idaman.id/storage/internal/repository"
+	"idaman.id/storage/internal/serialization"
+	"idaman.id/storage/internal/validation"
+)
+
+type updateService struct {
+	validator   validation.Validator
+	fileRepo    repository.FileRepository
+	encoder     serialization.Encoder
+	fileService file.FileParser
+}
+
+func (s *updateService) UpdateFile(p UpdateFileParam) error {
+	err := s.validator.Validate(*NewUpdateRule(p))
+	if err != nil {
+		return err
+	}
+
+	fileRecord, err := s.fileRepo.FindByIdentifier(p.Identifier)
+	if err != nil {
+		return err
+	}
+
+	if !p.Claims.CanAccessTenant(fileRecord.TenantId) {
+		return app_error.NewForbiddenError("File")
+	}
+
+	if p.Revision != 0 && p.Revision != fileRecord.Revision {
+		return app_error.NewPreconditionFailedError("File")
+	}
+
+	originalName := fileRecord.OriginalName
+	name := fileRecord.Name
+	if p.Name != "" {
+		originalName, name, err = s.parseName(p.Name, fileRecord.Extension)
+		if err != nil {
+			return err
+		}
+	}
+	slug := fileRecord.Slug
+	if p.Slug != "" && p.Slug != fileRecord.Slug {
+		isTaken, err := s.fileRepo.IsSlugTaken(p.Slug, fileRecord.UniqueId)
+		if err != nil {
+			return err
+		}
+		if isTaken {
+			return app_error.NewAlreadyExistsError("Slug")
+		}
+		slug = p.Slug
+	}
+	visibility := fileRecord.Visibility
+	if p.Visibility != "" {
+		visibility = p.Visibility
+	}
+
+	metadata := fileRecord.Metadata
+	if p.Metadata != nil {
+		metadata = ""
+		if len(p.Metadata) > 0 {
+			encoded, err := s.encoder.Encode(p.Metadata)
+			if err != nil {
+				return err
+			}
+			metadata = string(encoded)
+		}
+	}
+	tags := fileRecord.Tags
+	if p.Tags != nil {
+		tags = p.Tags
+	}
+
+	updatedAt := time.Now()
+	return s.fileRepo.Update(repository.UpdateFileParam{
+		UniqueId:     fileRecord.UniqueId,
+		Slug:         slug,
+		OriginalName: originalName,
+		Name:         name,
+		Visibility:   visibility,
+		Metadata:     metadata,
+		Tags:         tags,
+		Revision:     p.Revision,
+		UpdatedAt:    &updatedAt,
+	})
+}
+
+// parseName slugs the new name the same way as uploaded file name,
+// the stored extension is kept since the file content does not change
+func (s *updateService) parseName(value, extension string) (string, string, error) {
+	in := &file.FileInput{Name: value}
+	if extension != "" && s.fileService.ParseExtension(in) != extension {
+		in.Name = fmt.Sprintf("%s.%s", value, extension)
+	}
+
+	name := s.fileService.ParseName(in)
+	if name == "" {
+		return "", "", app_error.NewValidationError([]app_error.ValidationItem{
+			{
+				Field:   "name",
+				Message: "Key: 'name' Error:Field validation for 'name' failed on the 'slug' tag",
+			},
+		})
+	}
+	return s.fileService.ParseOriginalName(in), name, nil
+}
+
+func NewUpdateService(v validation.Validator, fr repository.FileRepository, en serialization.Encoder, fs file.FileParser) UpdateService {
+	return &updateService{
+		validator:   v,
+		fileRepo:    fr,
+		encoder:     en,
+		fileService: fs,
+	}
+}
@@ -1,18 +1,36 @@
 package uploading
 
 import (
-	"time"
+	"idaman.id/storage/internal/extracting"
+	"idaman.id/storage/internal/retrieving"
 )
 
-type FileEntity struct {
-	UniqueId     string
-	OriginalName string
-	Name         string
-	Extension    string
-	Size         int64
-	Mimetype     string
-	Url          string
-	CreatedAt    *time.Time
-	UpdatedAt    *time.Time
-	DeletedAt    *time.Time
+// FileEntity is the uploaded file as it is served by retrieving, so both are presented alike
+type FileEntity = retrieving.FileEntity
+
+type VariantEntity = retrieving.VariantEntity
+
+type UploadArchiveResult struct {
+	Entries []ArchiveEntryEntity
+}
+
+// ArchiveEntryEntity is the upload result of a single archive entry, File is only set when
+// the entry is uploaded, Error tells why it failed and Reason why it was skipped
+type ArchiveEntryEntity struct {
+	Name   string
+	Status string
+	File   *FileEntity
+	Error  error
+	Reason string
+}
+
+// contentEntity is the uploaded content once scanned, sanitized and inspected
+type contentEntity struct {
+	FileData          []byte
+	Size              int64
+	Checksum          string
+	ScanStatus        string
+	Metadata          *extracting.MetadataEntity
+	ExtractedMetadata string
+	MetadataStripped  bool
 }
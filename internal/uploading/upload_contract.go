@@ -1,20 +1,80 @@
 package uploading
 
 import (
-	"mime/multipart"
+	"time"
 
+	"idaman.id/storage/internal/auth"
 	"idaman.id/storage/internal/file"
 )
 
+const (
+	ENTRY_STATUS_UPLOADED = "uploaded"
+	ENTRY_STATUS_FAILED   = "failed"
+	ENTRY_STATUS_SKIPPED  = "skipped"
+)
+
 type UploadService interface {
 	UploadFile(p UploadFileParam) (*FileEntity, error)
+	ReplaceFile(p ReplaceFileParam) (*FileEntity, error)
+	UploadArchive(p UploadArchiveParam) (*UploadArchiveResult, error)
+	UploadRemoteFile(p UploadRemoteFileParam) (*FileEntity, error)
 }
 
+// UploadFileParam optionally requests the Slug of the public url,
+// empty Slug is derived from the file name,
+// the file expires at ExpiresAt or Ttl `second` after upload, otherwise by the application lifecycle rules
 type UploadFileParam struct {
-	File *file.FileEntity
+	File       *file.FileEntity
+	Claims     *auth.Claims
+	Provider   string
+	TotalFiles int
+	Metadata   map[string]string
+	Tags       []string
+	Slug       string
+	ExpiresAt  *time.Time
+	Ttl        int64
+}
+
+// ReplaceFileParam stores File as the new content version of the file,
+// non zero Revision only replaces the content when the file is still at that revision
+type ReplaceFileParam struct {
+	Identifier string
+	File       *file.FileEntity
+	Claims     *auth.Claims
+	Revision   int64
+}
+
+// UploadArchiveParam unpacks File and uploads every entry like UploadFile
+// with the same Provider, Metadata and Tags, the slug of every entry is derived from its name
+type UploadArchiveParam struct {
+	File      *file.FileEntity
+	Claims    *auth.Claims
+	Provider  string
+	Metadata  map[string]string
+	Tags      []string
+	ExpiresAt *time.Time
+	Ttl       int64
+}
+
+// UploadRemoteFileParam downloads Url and uploads it like UploadFile
+type UploadRemoteFileParam struct {
+	Url       string
+	Claims    *auth.Claims
+	Provider  string
+	Metadata  map[string]string
+	Tags      []string
+	Slug      string
+	ExpiresAt *time.Time
+	Ttl       int64
 }
 
 type UploadRuleParam struct {
-	FileHeader *multipart.FileHeader
+	File       *file.FileEntity
 	Provider   string
+	TotalFiles int
+	Metadata   map[string]string
+	Tags       []string
+	Slug       string
+	ExpiresAt  *time.Time
+	Ttl        int64
 }
@@ -1,6 +1,17 @@
 package uploading
 
 import (
+	"bytes"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "golang.org/x/image/webp"
+	"idaman.id/storage/internal/application"
 	"idaman.id/storage/internal/file"
 )
 
@@ -9,14 +20,146 @@ type fileRule struct {
 	Extension string `json:"ext" validate:"required"`
 	Mimetype  string `json:"mimetype" validate:"required"`
 	Size      int64  `json:"size" validate:"required,valid_file_size"`
+	Provider  string `json:"provider" validate:"required,valid_provider"`
+
+	DetectedMimetype string `json:"detected_mimetype" validate:"valid_content_type"`
+
+	Metadata map[string]string `json:"metadata" validate:"valid_metadata"`
+	Tags     []string          `json:"tags" validate:"valid_tags"`
+	Slug     string            `json:"slug" validate:"omitempty,valid_slug"`
+
+	Ttl int64 `json:"ttl" validate:"omitempty,min=1,excluded_with=ExpiresIn"`
+	// ExpiresIn is the `second` left until the requested expiry, negative when it is already past
+	ExpiresIn int64 `json:"expires_at" validate:"omitempty,min=1"`
 }
 
-func NewUploadRule(f *file.FileEntity) *fileRule {
+func NewUploadRule(p UploadRuleParam) *fileRule {
 	fr := fileRule{
-		Name:      f.Name,
-		Extension: f.Extension,
-		Mimetype:  f.Mimetype,
-		Size:      f.Size,
+		Name:      p.File.Name,
+		Extension: p.File.Extension,
+		Mimetype:  p.File.Mimetype,
+		Size:      p.File.Size,
+		Provider:  p.Provider,
+
+		DetectedMimetype: p.File.DetectedMimetype,
+
+		Metadata: p.Metadata,
+		Tags:     p.Tags,
+		Slug:     p.Slug,
+
+		Ttl: p.Ttl,
+	}
+	if p.ExpiresAt != nil {
+		fr.ExpiresIn = int64(time.Until(*p.ExpiresAt).Seconds())
+		if fr.ExpiresIn <= 0 {
+			fr.ExpiresIn = -1
+		}
 	}
 	return &fr
 }
+
+type remoteRule struct {
+	Url string `json:"url" validate:"required,url,max=2048"`
+}
+
+func NewRemoteRule(p UploadRemoteFileParam) *remoteRule {
+	rr := remoteRule{
+		Url: p.Url,
+	}
+	return &rr
+}
+
+type policyRule struct {
+	Data  map[string]interface{}
+	Rules map[string]string
+}
+
+// NewPolicyRule translates an application upload policy into validator rules,
+// image dimension rules only apply to images, an image whose dimension can not be read
+// fails on the required `dimension` rule instead of bypassing them
+func NewPolicyRule(policy *application.PolicyEntity, p UploadRuleParam) *policyRule {
+	mimetype := policyMimetype(p.File.Mimetype, p.File.DetectedMimetype)
+	pr := policyRule{
+		Data: map[string]interface{}{
+			"ext":      p.File.Extension,
+			"mimetype": mimetype,
+			"size":     p.File.Size,
+			"files":    p.TotalFiles,
+		},
+		Rules: map[string]string{},
+	}
+
+	if len(policy.AllowedExtensions) > 0 {
+		pr.Rules["ext"] = "oneof=" + strings.ToLower(strings.Join(policy.AllowedExtensions, " "))
+	}
+	if len(policy.AllowedMimetypes) > 0 {
+		pr.Rules["mimetype"] = "oneof=" + strings.ToLower(strings.Join(policy.AllowedMimetypes, " "))
+	}
+	addRangeRule(pr.Rules, "size", policy.MinSize, policy.MaxSize)
+	if policy.MaxFiles > 0 {
+		pr.Rules["files"] = "max=" + strconv.Itoa(policy.MaxFiles)
+	}
+
+	hasDimensionRule := policy.MinWidth > 0 || policy.MaxWidth > 0 || policy.MinHeight > 0 || policy.MaxHeight > 0
+	if !hasDimensionRule {
+		return &pr
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(p.File.Data))
+	if err != nil {
+		if strings.HasPrefix(mimetype, "image/") {
+			pr.Data["dimension"] = ""
+			pr.Rules["dimension"] = "required"
+		}
+		return &pr
+	}
+	pr.Data["width"] = cfg.Width
+	pr.Data["height"] = cfg.Height
+	addRangeRule(pr.Rules, "width", int64(policy.MinWidth), int64(policy.MaxWidth))
+	addRangeRule(pr.Rules, "height", int64(policy.MinHeight), int64(policy.MaxHeight))
+	return &pr
+}
+
+// policyMimetype is the sniffed mimetype, the declared one only refines it when the content
+// is matched by family, e.g: csv declared for plain text or docx declared for zip
+func policyMimetype(declared, detected string) string {
+	declared = file.NormalizeMimeType(declared)
+	detected = file.NormalizeMimeType(detected)
+	if detected == "" {
+		detected = file.MIME_UNKNOWN
+	}
+	if detected == file.MIME_UNKNOWN || declared == "" || declared == file.MIME_UNKNOWN {
+		return detected
+	}
+	if file.IsMimeTypeCompatible(declared, detected) {
+		return declared
+	}
+	return detected
+}
+
+// NewArchivePolicyRule only checks the amount of file of an archive, it is checked once
+// against every entry of the archive before any of them is uploaded
+func NewArchivePolicyRule(policy *application.PolicyEntity, totalFiles int) *policyRule {
+	pr := policyRule{
+		Data: map[string]interface{}{
+			"files": totalFiles,
+		},
+		Rules: map[string]string{},
+	}
+	if policy.MaxFiles > 0 {
+		pr.Rules["files"] = "max=" + strconv.Itoa(policy.MaxFiles)
+	}
+	return &pr
+}
+
+func addRangeRule(rules map[string]string, field string, min, max int64) {
+	tags := []string{}
+	if min > 0 {
+		tags = append(tags, "min="+strconv.FormatInt(min, 10))
+	}
+	if max > 0 {
+		tags = append(tags, "max="+strconv.FormatInt(max, 10))
+	}
+	if len(tags) > 0 {
+		rules[field] = strings.Join(tags, ",")
+	}
+}
@@ -0,0 +1,293 @@
+package uploading_test
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/application"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/file"
+	"idaman.id/storage/internal/uploading"
+	"idaman.id/storage/internal/validation"
+	validation_go "idaman.id/storage/internal/validation-go"
+)
+
+var _ = Describe("Upload Rule", func() {
+	var (
+		validator validation.Validator
+		policy    *application.PolicyEntity
+		param     uploading.UploadRuleParam
+	)
+
+	BeforeEach(func() {
+		var err error
+		validator, err = validation_go.NewGoValidator(&FakeConfig{values: map[string]interface{}{
+			"MIN_FILE_SIZE":     1,
+			"MAX_FILE_SIZE":     1000,
+			"STORAGE_PROVIDERS": "local,backup",
+			"METADATA_MAX_KEYS": 2,
+			"METADATA_MAX_SIZE": 32,
+			"TAGS_MAX_COUNT":    2,
+		}})
+		Expect(err).To(BeNil())
+
+		pngData := &bytes.Buffer{}
+		png.Encode(pngData, image.NewGray(image.Rect(0, 0, 40, 20)))
+
+		policy = &application.PolicyEntity{
+			AllowedExtensions: []string{"png", "JPG"},
+			AllowedMimetypes:  []string{"image/png", "image/jpeg"},
+			MinSize:           10,
+			MaxSize:           500,
+			MaxWidth:          50,
+			MaxHeight:         10,
+			MaxFiles:          1,
+		}
+		param = uploading.UploadRuleParam{
+			File: &file.FileEntity{
+				Name:      "image",
+				Extension: "png",
+				Mimetype:  "image/png",
+				Size:      int64(pngData.Len()),
+				Data:      pngData.Bytes(),
+
+				DetectedMimetype: "image/png",
+			},
+			Provider:   "local",
+			TotalFiles: 1,
+		}
+	})
+
+	Context("NewUploadRule function", func() {
+		When("provider is not configured", func() {
+			It("should return validation error", func() {
+				param.Provider = "s3"
+				err := validator.Validate(*uploading.NewUploadRule(param))
+
+				Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+				Expect(err.(*app_error.ValidationError).Items[0].Field).To(Equal("provider"))
+			})
+		})
+
+		When("metadata and tags are invalid", func() {
+			It("should return validation error", func() {
+				param.Metadata = map[string]string{"order id": "1"}
+				param.Tags = []string{"invoice", "Paid"}
+				err := validator.Validate(*uploading.NewUploadRule(param))
+
+				Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+				items := err.(*app_error.ValidationError).Items
+				Expect(items).To(HaveLen(2))
+				Expect(items[0].Field).To(Equal("metadata"))
+				Expect(items[1].Field).To(Equal("tags"))
+			})
+		})
+
+		When("metadata and tags exceed the limit", func() {
+			It("should return validation error", func() {
+				param.Metadata = map[string]string{"order_id": "1234567890123456789012345678"}
+				param.Tags = []string{"invoice", "paid", "2021"}
+				err := validator.Validate(*uploading.NewUploadRule(param))
+
+				Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+				Expect(err.(*app_error.ValidationError).Items).To(HaveLen(2))
+			})
+		})
+
+		When("slug is not slugged", func() {
+			It("should return validation error", func() {
+				param.Slug = "Annual Report"
+				err := validator.Validate(*uploading.NewUploadRule(param))
+
+				Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+				Expect(err.(*app_error.ValidationError).Items[0].Field).To(Equal("slug"))
+			})
+		})
+
+		When("expiry is in the past", func() {
+			It("should return validation error", func() {
+				expiresAt := time.Now().Add(-time.Hour)
+				param.ExpiresAt = &expiresAt
+				err := validator.Validate(*uploading.NewUploadRule(param))
+
+				Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+				Expect(err.(*app_error.ValidationError).Items[0].Field).To(Equal("expires_at"))
+			})
+		})
+
+		When("both ttl and expiry are set", func() {
+			It("should return validation error", func() {
+				expiresAt := time.Now().Add(time.Hour)
+				param.ExpiresAt = &expiresAt
+				param.Ttl = 3600
+				err := validator.Validate(*uploading.NewUploadRule(param))
+
+				Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+				Expect(err.(*app_error.ValidationError).Items[0].Field).To(Equal("ttl"))
+			})
+		})
+
+		When("file and provider are valid", func() {
+			It("should pass validation", func() {
+				param.Metadata = map[string]string{"order_id": "1234", "category": "invoice"}
+				param.Tags = []string{"invoice", "billing:2021"}
+				param.Slug = "annual-report-2021"
+				param.Ttl = 86400
+				err := validator.Validate(*uploading.NewUploadRule(param))
+
+				Expect(err).To(BeNil())
+			})
+		})
+	})
+
+	Context("NewRemoteRule function", func() {
+		When("url is not valid", func() {
+			It("should return validation error", func() {
+				err := validator.Validate(*uploading.NewRemoteRule(uploading.UploadRemoteFileParam{Url: "not a url"}))
+
+				Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+				Expect(err.(*app_error.ValidationError).Items[0].Field).To(Equal("url"))
+			})
+		})
+
+		When("url is valid", func() {
+			It("should pass validation", func() {
+				err := validator.Validate(*uploading.NewRemoteRule(uploading.UploadRemoteFileParam{Url: "https://example.com/report.pdf"}))
+
+				Expect(err).To(BeNil())
+			})
+		})
+	})
+
+	Context("NewPolicyRule function", func() {
+		When("file violates several policy rules", func() {
+			It("should return validation item of each rule", func() {
+				param.File.Extension = "gif"
+				param.TotalFiles = 2
+				pr := uploading.NewPolicyRule(policy, param)
+				err := validator.ValidateRules(pr.Data, pr.Rules)
+
+				Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+				items := err.(*app_error.ValidationError).Items
+				fields := []string{}
+				for _, item := range items {
+					fields = append(fields, item.Field)
+				}
+				Expect(fields).To(Equal([]string{"ext", "files", "height"}))
+				Expect(items[0].Message).To(Equal("Key: 'ext' Error:Field validation for 'ext' failed on the 'oneof' tag"))
+			})
+		})
+
+		When("file satisfies the policy", func() {
+			It("should pass validation", func() {
+				policy.MaxHeight = 20
+				pr := uploading.NewPolicyRule(policy, param)
+				err := validator.ValidateRules(pr.Data, pr.Rules)
+
+				Expect(err).To(BeNil())
+				Expect(pr.Data["width"]).To(Equal(40))
+				Expect(pr.Rules["ext"]).To(Equal("oneof=png jpg"))
+				Expect(pr.Rules["size"]).To(Equal("min=10,max=500"))
+			})
+		})
+
+		When("declared mimetype is allowed but the content is not", func() {
+			It("should return validation error", func() {
+				policy.MaxHeight = 20
+				param.File.DetectedMimetype = "image/gif"
+				pr := uploading.NewPolicyRule(policy, param)
+				err := validator.ValidateRules(pr.Data, pr.Rules)
+
+				Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+				Expect(err.(*app_error.ValidationError).Items[0].Field).To(Equal("mimetype"))
+			})
+		})
+
+		When("declared mimetype is an alias", func() {
+			It("should check the normalized mimetype", func() {
+				param.File.Mimetype = "IMAGE/JPG"
+				param.File.DetectedMimetype = "image/jpeg"
+				pr := uploading.NewPolicyRule(policy, param)
+
+				Expect(pr.Data["mimetype"]).To(Equal("image/jpeg"))
+			})
+		})
+
+		When("content is matched by family", func() {
+			It("should check the declared mimetype", func() {
+				param.File.Mimetype = "text/csv; charset=utf-8"
+				param.File.DetectedMimetype = file.MIME_TEXT
+				pr := uploading.NewPolicyRule(policy, param)
+
+				Expect(pr.Data["mimetype"]).To(Equal("text/csv"))
+			})
+		})
+
+		When("file is not an image", func() {
+			It("should skip dimension rules", func() {
+				param.File.Data = []byte("plain text")
+				param.File.DetectedMimetype = file.MIME_TEXT
+				pr := uploading.NewPolicyRule(policy, param)
+
+				Expect(pr.Rules).ToNot(HaveKey("width"))
+				Expect(pr.Rules).ToNot(HaveKey("height"))
+				Expect(pr.Rules).ToNot(HaveKey("dimension"))
+			})
+		})
+
+		When("file is a webp image", func() {
+			It("should check the dimension rules", func() {
+				param.File.Extension = "webp"
+				param.File.Mimetype = "image/webp"
+				param.File.DetectedMimetype = "image/webp"
+				param.File.Data = NewWebp(80, 8)
+				pr := uploading.NewPolicyRule(policy, param)
+				err := validator.ValidateRules(pr.Data, pr.Rules)
+
+				Expect(pr.Data["width"]).To(Equal(80))
+				Expect(pr.Data["height"]).To(Equal(8))
+				Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+				items := err.(*app_error.ValidationError).Items
+				Expect(items[len(items)-1].Field).To(Equal("width"))
+			})
+		})
+
+		When("image dimension can not be read", func() {
+			It("should return validation error", func() {
+				policy.MaxHeight = 20
+				param.File.Data = []byte("\x89PNG\r\n\x1a\n")
+				pr := uploading.NewPolicyRule(policy, param)
+				err := validator.ValidateRules(pr.Data, pr.Rules)
+
+				Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+				Expect(err.(*app_error.ValidationError).Items[0].Message).To(Equal("Key: 'dimension' Error:Field validation for 'dimension' failed on the 'required' tag"))
+			})
+		})
+	})
+
+	Context("NewArchivePolicyRule function", func() {
+		When("archive holds more files than the policy allows", func() {
+			It("should return validation error", func() {
+				pr := uploading.NewArchivePolicyRule(policy, 2)
+				err := validator.ValidateRules(pr.Data, pr.Rules)
+
+				Expect(err).To(BeAssignableToTypeOf(&app_error.ValidationError{}))
+				Expect(err.(*app_error.ValidationError).Items[0].Field).To(Equal("files"))
+			})
+		})
+
+		When("policy does not limit the amount of file", func() {
+			It("should pass validation", func() {
+				policy.MaxFiles = 0
+				pr := uploading.NewArchivePolicyRule(policy, 20)
+				err := validator.ValidateRules(pr.Data, pr.Rules)
+
+				Expect(err).To(BeNil())
+			})
+		})
+	})
+})
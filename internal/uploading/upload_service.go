@@ -1,82 +1,771 @@
 package uploading
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"path"
+	"strings"
 	"time"
 
+	"idaman.id/storage/internal/application"
 	"idaman.id/storage/internal/config"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/extracting"
+	"idaman.id/storage/internal/fetching"
+	"idaman.id/storage/internal/file"
+	"idaman.id/storage/internal/quota"
 	"idaman.id/storage/internal/repository"
+	"idaman.id/storage/internal/sanitizing"
+	"idaman.id/storage/internal/scanning"
+	"idaman.id/storage/internal/serialization"
 	"idaman.id/storage/internal/storage"
 	"idaman.id/storage/internal/text"
+	"idaman.id/storage/internal/unpacking"
 	"idaman.id/storage/internal/validation"
+	"idaman.id/storage/internal/variant"
+	"idaman.id/storage/internal/versioning"
 )
 
 type uploadService struct {
 	validator       validation.Validator
 	configGetter    config.Getter
-	storageSaver    storage.Saver
+	fileStorage     storage.StreamStorage
 	stringGenerator text.Generator
 	fileRepo        repository.FileRepository
+	quotaReserver   quota.QuotaReserver
+	appGetter       application.ApplicationGetter
+	scanService     scanning.ScanService
+	variantService  variant.VariantService
+	extractor       extracting.Extractor
+	serializer      serialization.Serializer
+	sanitizer       sanitizing.Sanitizer
+	versionPruner   versioning.VersionPruner
+	fileParser      file.FileParser
+	unpacker        unpacking.Unpacker
+	fetcher         fetching.Fetcher
 }
 
 func (s *uploadService) UploadFile(p UploadFileParam) (*FileEntity, error) {
-	ur := NewUploadRule(p.File)
+	ownerId, tenantId := "", ""
+	if p.Claims != nil {
+		ownerId = p.Claims.Subject
+		tenantId = p.Claims.Tenant
+	}
+
+	provider := p.Provider
+	if provider == "" {
+		provider = s.configGetter.GetString("DEFAULT_PROVIDER")
+	}
+	totalFiles := p.TotalFiles
+	if totalFiles < 1 {
+		totalFiles = 1
+	}
+	ruleParam := UploadRuleParam{
+		File:       p.File,
+		Provider:   provider,
+		TotalFiles: totalFiles,
+		Metadata:   p.Metadata,
+		Tags:       p.Tags,
+		Slug:       p.Slug,
+		ExpiresAt:  p.ExpiresAt,
+		Ttl:        p.Ttl,
+	}
+
+	ur := NewUploadRule(ruleParam)
 	err := s.validator.Validate(*ur)
+	if err != nil {
+		return nil, err
+	}
 
+	err = readInspectedStream(p.File)
 	if err != nil {
 		return nil, err
 	}
 
+	app := s.appGetter.GetApplication(tenantId)
+	policy := app.FindPolicy(provider)
+	if policy != nil {
+		pr := NewPolicyRule(policy, ruleParam)
+		err = s.validator.ValidateRules(pr.Data, pr.Rules)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	uniqueId := s.stringGenerator.GenerateUuid()
-	createdAt := time.Now()
-	fileName := uniqueId + "." + p.File.Extension
-	res, err := s.storageSaver.SaveFile(storage.SaveFileParam{
-		FileName: fileName,
-		FileData: p.File.Data,
-	})
+	slug, err := s.resolveSlug(p.Slug, p.File.Name, uniqueId)
 	if err != nil {
 		return nil, err
 	}
+	fileName := versioning.VersionFileName(uniqueId, 1, p.File.Extension)
+
+	userMetadata := ""
+	if len(p.Metadata) > 0 {
+		encoded, err := s.serializer.Encode(p.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		userMetadata = string(encoded)
+	}
+
+	content, res, err := s.storeContent(app, tenantId, provider, fileName, p.File, false)
+	if err != nil {
+		return nil, err
+	}
+	createdAt := time.Now()
+	expiresAt := resolveExpiry(app, p, provider, content.Size, createdAt)
+	scanStatus := content.ScanStatus
+	fileData := content.FileData
+	fileSize := content.Size
 
 	appUrl := s.configGetter.GetString("APP_URL")
-	publicUrl := fmt.Sprintf("%s/%s/%s", appUrl, "file", res.FileName)
+	publicUrl := fmt.Sprintf("%s/%s/%s.%s", appUrl, "file", slug, p.File.Extension)
 
 	err = s.fileRepo.Save(repository.SaveFileParam{
-		UniqueId:     uniqueId,
-		OriginalName: p.File.OriginalName,
-		Name:         p.File.Name,
-		Size:         p.File.Size,
-		CreatedAt:    &createdAt,
-		Extension:    p.File.Extension,
-		Mimetype:     p.File.Mimetype,
-		FileLocation: res.FileLocation,
-		FileName:     res.FileName,
+		UniqueId:          uniqueId,
+		Slug:              slug,
+		OriginalName:      p.File.OriginalName,
+		Name:              p.File.Name,
+		Size:              fileSize,
+		CreatedAt:         &createdAt,
+		Extension:         p.File.Extension,
+		Mimetype:          p.File.Mimetype,
+		DetectedMimetype:  p.File.DetectedMimetype,
+		FileLocation:      res.FileLocation,
+		FileName:          res.FileName,
+		Provider:          provider,
+		OwnerId:           ownerId,
+		TenantId:          tenantId,
+		ScanStatus:        scanStatus,
+		ExtractedMetadata: content.ExtractedMetadata,
+		Checksum:          content.Checksum,
+		MetadataStripped:  content.MetadataStripped,
+		Metadata:          userMetadata,
+		Tags:              p.Tags,
+		Visibility:        file.VISIBILITY_PUBLIC,
+		ExpiresAt:         expiresAt,
 	})
 	if err != nil {
+		s.releaseQuota(tenantId, fileSize, false)
 		return nil, err
 	}
 
+	if scanStatus == scanning.SCAN_STATUS_PENDING {
+		s.scanService.ScanStoredFile(scanning.ScanStoredFileParam{
+			UniqueId:     uniqueId,
+			FileLocation: res.FileLocation,
+			FileName:     res.FileName,
+			FileData:     fileData,
+		})
+	}
+
+	variants := s.generateVariants(variant.GenerateVariantsParam{
+		UniqueId:      uniqueId,
+		ApplicationId: tenantId,
+		Mimetype:      p.File.Mimetype,
+		FileData:      fileData,
+	}, publicUrl)
+
 	file := FileEntity{
-		UniqueId:     uniqueId,
-		Name:         p.File.Name,
-		OriginalName: p.File.OriginalName,
-		Size:         p.File.Size,
-		Extension:    p.File.Extension,
-		Mimetype:     p.File.Mimetype,
-		Url:          publicUrl,
-		CreatedAt:    &createdAt,
-		UpdatedAt:    nil,
-		DeletedAt:    nil,
+		UniqueId:          uniqueId,
+		Slug:              slug,
+		Name:              p.File.Name,
+		OriginalName:      p.File.OriginalName,
+		Size:              fileSize,
+		Extension:         p.File.Extension,
+		Mimetype:          p.File.Mimetype,
+		DetectedMimetype:  p.File.DetectedMimetype,
+		Url:               publicUrl,
+		OwnerId:           ownerId,
+		TenantId:          tenantId,
+		ScanStatus:        scanStatus,
+		ExtractedMetadata: content.Metadata,
+		Checksum:          content.Checksum,
+		MetadataStripped:  content.MetadataStripped,
+		Metadata:          p.Metadata,
+		Tags:              p.Tags,
+		Visibility:        file.VISIBILITY_PUBLIC,
+		Provider:          provider,
+		Revision:          1,
+		Version:           1,
+		CreatedAt:         &createdAt,
+		UpdatedAt:         nil,
+		DeletedAt:         nil,
+		ExpiresAt:         expiresAt,
+		Variants:          variants,
 	}
 	return &file, nil
 }
 
-func NewUploadService(v validation.Validator, cg config.Getter, ss storage.Saver, sg text.Generator, fr repository.FileRepository) UploadService {
+// UploadArchive stops on archive wide problems only, e.g: zip bomb or too many entries,
+// an entry which fails the upload validation does not prevent the other entries from being uploaded
+func (s *uploadService) UploadArchive(p UploadArchiveParam) (*UploadArchiveResult, error) {
+	unpacked, err := s.unpacker.Unpack(unpacking.UnpackParam{
+		Data: p.File.Data,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tenantId := ""
+	if p.Claims != nil {
+		tenantId = p.Claims.Tenant
+	}
+	provider := p.Provider
+	if provider == "" {
+		provider = s.configGetter.GetString("DEFAULT_PROVIDER")
+	}
+	policy := s.appGetter.GetApplication(tenantId).FindPolicy(provider)
+	if policy != nil {
+		pr := NewArchivePolicyRule(policy, len(unpacked.Entries))
+		err = s.validator.ValidateRules(pr.Data, pr.Rules)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res := &UploadArchiveResult{
+		Entries: []ArchiveEntryEntity{},
+	}
+	for _, entry := range unpacked.Entries {
+		fileEntity, err := file.NewFileFromInput(file.NewDataInput(path.Base(entry.Name), "", entry.Data), s.fileParser)
+		if err != nil {
+			return nil, err
+		}
+		uploaded, err := s.UploadFile(UploadFileParam{
+			File:       fileEntity,
+			Claims:     p.Claims,
+			Provider:   p.Provider,
+			TotalFiles: 1,
+			Metadata:   p.Metadata,
+			Tags:       p.Tags,
+			ExpiresAt:  p.ExpiresAt,
+			Ttl:        p.Ttl,
+		})
+		if err != nil {
+			res.Entries = append(res.Entries, ArchiveEntryEntity{
+				Name:   entry.Name,
+				Status: ENTRY_STATUS_FAILED,
+				Error:  err,
+			})
+			continue
+		}
+		res.Entries = append(res.Entries, ArchiveEntryEntity{
+			Name:   entry.Name,
+			Status: ENTRY_STATUS_UPLOADED,
+			File:   uploaded,
+		})
+	}
+	for _, skipped := range unpacked.Skipped {
+		res.Entries = append(res.Entries, ArchiveEntryEntity{
+			Name:   skipped.Name,
+			Status: ENTRY_STATUS_SKIPPED,
+			Reason: skipped.Reason,
+		})
+	}
+	return res, nil
+}
+
+// UploadRemoteFile uploads the downloaded content through the same pipeline as UploadFile
+func (s *uploadService) UploadRemoteFile(p UploadRemoteFileParam) (*FileEntity, error) {
+	err := s.validator.Validate(*NewRemoteRule(p))
+	if err != nil {
+		return nil, err
+	}
+
+	fetched, err := s.fetcher.Fetch(fetching.FetchParam{
+		Url: p.Url,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fileEntity, err := file.NewFileFromInput(fetching.NewFileInput(fetched), s.fileParser)
+	if err != nil {
+		return nil, err
+	}
+	return s.UploadFile(UploadFileParam{
+		File:      fileEntity,
+		Claims:    p.Claims,
+		Provider:  p.Provider,
+		Metadata:  p.Metadata,
+		Tags:      p.Tags,
+		Slug:      p.Slug,
+		ExpiresAt: p.ExpiresAt,
+		Ttl:       p.Ttl,
+	})
+}
+
+// ReplaceFile stores a new content version while the unique id, name, metadata and tags are kept,
+// the previous versions stay in storage until they are pruned by the application max versions
+func (s *uploadService) ReplaceFile(p ReplaceFileParam) (*FileEntity, error) {
+	fileRecord, err := s.fileRepo.FindByIdentifier(p.Identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.Claims.CanAccessTenant(fileRecord.TenantId) {
+		return nil, app_error.NewForbiddenError("File")
+	}
+
+	if p.Revision != 0 && p.Revision != fileRecord.Revision {
+		return nil, app_error.NewPreconditionFailedError("File")
+	}
+
+	// the content is saved to the provider of the file, so its rules apply
+	provider := fileRecord.Provider
+	if provider == "" {
+		provider = s.configGetter.GetString("DEFAULT_PROVIDER")
+	}
+	ruleParam := UploadRuleParam{
+		File:       p.File,
+		Provider:   provider,
+		TotalFiles: 1,
+	}
+	err = s.validator.Validate(*NewUploadRule(ruleParam))
+	if err != nil {
+		return nil, err
+	}
+
+	err = readInspectedStream(p.File)
+	if err != nil {
+		return nil, err
+	}
+
+	app := s.appGetter.GetApplication(fileRecord.TenantId)
+	policy := app.FindPolicy(provider)
+	if policy != nil {
+		pr := NewPolicyRule(policy, ruleParam)
+		err = s.validator.ValidateRules(pr.Data, pr.Rules)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	version := fileRecord.Version + 1
+	fileName := versioning.VersionFileName(fileRecord.UniqueId, version, p.File.Extension)
+	// the new version stays on the provider of the file until a lifecycle rule moves it
+	content, res, err := s.storeContent(app, fileRecord.TenantId, provider, fileName, p.File, true)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedAt := time.Now()
+	err = s.fileRepo.ReplaceContent(repository.ReplaceContentParam{
+		UniqueId:          fileRecord.UniqueId,
+		Version:           version,
+		Size:              content.Size,
+		Extension:         p.File.Extension,
+		Mimetype:          p.File.Mimetype,
+		DetectedMimetype:  p.File.DetectedMimetype,
+		FileLocation:      res.FileLocation,
+		FileName:          res.FileName,
+		ScanStatus:        content.ScanStatus,
+		ExtractedMetadata: content.ExtractedMetadata,
+		Checksum:          content.Checksum,
+		MetadataStripped:  content.MetadataStripped,
+		Revision:          p.Revision,
+		CreatedAt:         &updatedAt,
+	})
+	if err != nil {
+		s.releaseQuota(fileRecord.TenantId, content.Size, true)
+		return nil, err
+	}
+
+	if content.ScanStatus == scanning.SCAN_STATUS_PENDING {
+		s.scanService.ScanStoredFile(scanning.ScanStoredFileParam{
+			UniqueId:     fileRecord.UniqueId,
+			FileLocation: res.FileLocation,
+			FileName:     res.FileName,
+			FileData:     content.FileData,
+		})
+	}
+
+	appUrl := s.configGetter.GetString("APP_URL")
+	publicUrl := fmt.Sprintf("%s/%s/%s.%s", appUrl, "file", fileRecord.Slug, p.File.Extension)
+
+	// the previous version variants no longer match the content
+	variants := []VariantEntity{}
+	err = s.variantService.RemoveVariants(variant.RemoveVariantsParam{
+		UniqueId: fileRecord.UniqueId,
+	})
+	if err == nil {
+		variants = s.generateVariants(variant.GenerateVariantsParam{
+			UniqueId:      fileRecord.UniqueId,
+			BaseName:      versioning.VersionBaseName(fileRecord.UniqueId, version),
+			ApplicationId: fileRecord.TenantId,
+			Mimetype:      p.File.Mimetype,
+			FileData:      content.FileData,
+		}, publicUrl)
+	}
+
+	// the content is already replaced, the versions left over are pruned by the next replace
+	s.versionPruner.PruneVersions(versioning.PruneVersionsParam{
+		UniqueId:       fileRecord.UniqueId,
+		ApplicationId:  fileRecord.TenantId,
+		CurrentVersion: version,
+	})
+
+	metadata := map[string]string{}
+	if fileRecord.Metadata != "" {
+		s.serializer.Decode([]byte(fileRecord.Metadata), &metadata)
+	}
+	fileEntity := FileEntity{
+		UniqueId:          fileRecord.UniqueId,
+		Slug:              fileRecord.Slug,
+		Name:              fileRecord.Name,
+		OriginalName:      fileRecord.OriginalName,
+		Size:              content.Size,
+		Extension:         p.File.Extension,
+		Mimetype:          p.File.Mimetype,
+		DetectedMimetype:  p.File.DetectedMimetype,
+		Url:               publicUrl,
+		OwnerId:           fileRecord.OwnerId,
+		TenantId:          fileRecord.TenantId,
+		ScanStatus:        content.ScanStatus,
+		ExtractedMetadata: content.Metadata,
+		Checksum:          content.Checksum,
+		MetadataStripped:  content.MetadataStripped,
+		Metadata:          metadata,
+		Tags:              fileRecord.Tags,
+		Visibility:        fileRecord.Visibility,
+		Provider:          fileRecord.Provider,
+		Revision:          fileRecord.Revision + 1,
+		Version:           version,
+		CreatedAt:         fileRecord.CreatedAt,
+		UpdatedAt:         &updatedAt,
+		DeletedAt:         nil,
+		ExpiresAt:         fileRecord.ExpiresAt,
+		Variants:          variants,
+	}
+	return &fileEntity, nil
+}
+
+// resolveExpiry prefers the expiry requested by the upload over the application lifecycle rules
+// which are matched against the stored size, nil never expires
+func resolveExpiry(app *application.ApplicationEntity, p UploadFileParam, provider string, size int64, createdAt time.Time) *time.Time {
+	if p.ExpiresAt != nil {
+		expiresAt := p.ExpiresAt.Truncate(time.Second)
+		return &expiresAt
+	}
+
+	ttl := p.Ttl
+	if ttl <= 0 {
+		rule := app.FindExpiryRule(provider, p.File.Mimetype, size, p.Tags)
+		if rule == nil {
+			return nil
+		}
+		ttl = rule.ExpireAfter
+	}
+	expiresAt := createdAt.Add(time.Duration(ttl) * time.Second).Truncate(time.Second)
+	return &expiresAt
+}
+
+// resolveSlug keeps the requested slug when it is still available, otherwise the slug is derived
+// from the slugged file name, suffixed by the start of the unique id when it is already taken
+// and falling back to the unique id which never collides
+func (s *uploadService) resolveSlug(requested, name, uniqueId string) (string, error) {
+	if requested != "" {
+		isTaken, err := s.fileRepo.IsSlugTaken(requested, uniqueId)
+		if err != nil {
+			return "", err
+		}
+		if isTaken {
+			return "", app_error.NewAlreadyExistsError("Slug")
+		}
+		return requested, nil
+	}
+
+	base := file.TruncateSlug(name)
+	if base == "" {
+		return uniqueId, nil
+	}
+	suffix := strings.SplitN(uniqueId, "-", 2)[0]
+	shortBase := base
+	if maxLength := file.SLUG_MAX_LENGTH - len(suffix) - 1; len(shortBase) > maxLength {
+		shortBase = file.TruncateSlug(shortBase[:maxLength])
+	}
+	for _, slug := range []string{base, shortBase + "-" + suffix} {
+		isTaken, err := s.fileRepo.IsSlugTaken(slug, uniqueId)
+		if err != nil {
+			return "", err
+		}
+		if !isTaken {
+			return slug, nil
+		}
+	}
+	return uniqueId, nil
+}
+
+// readInspectedStream reads a streamed image into memory as its dimension, metadata and variants
+// are taken from the content, the other streamed contents are stored while they are read
+func readInspectedStream(f *file.FileEntity) error {
+	if f.Stream == nil {
+		return nil
+	}
+	isImage := strings.HasPrefix(f.DetectedMimetype, "image/") ||
+		strings.HasPrefix(file.NormalizeMimeType(f.Mimetype), "image/")
+	if !isImage {
+		return nil
+	}
+	return f.ReadStream()
+}
+
+// storeContent saves the processed content under the reserved quota, which is released when it fails
+func (s *uploadService) storeContent(app *application.ApplicationEntity, tenantId, provider, fileName string, f *file.FileEntity, isVersion bool) (*contentEntity, *storage.SaveFileResult, error) {
+	if f.Stream != nil {
+		return s.saveStream(tenantId, provider, fileName, f, isVersion)
+	}
+
+	content, err := s.processContent(app, fileName, f)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = s.quotaReserver.ReserveQuota(quota.ReserveQuotaParam{
+		ApplicationId: tenantId,
+		Size:          content.Size,
+		IsVersion:     isVersion,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res, err := s.fileStorage.SaveFile(storage.SaveFileParam{
+		FileName: fileName,
+		FileData: content.FileData,
+		Provider: provider,
+	})
+	if err != nil {
+		s.releaseQuota(tenantId, content.Size, isVersion)
+		return nil, nil, err
+	}
+	return content, res, nil
+}
+
+// errScanStopped fails the stream when the scanner stops reading it before the end
+var errScanStopped = errors.New("scan stopped before the end of the stream")
+
+// saveStream saves the declared size of the streamed content while it is hashed and, in sync mode, scanned,
+// the stored copy is removed when the content is invalid and quarantined when it is infected,
+// the metadata of a streamed content is neither stripped nor extracted
+func (s *uploadService) saveStream(tenantId, provider, fileName string, f *file.FileEntity, isVersion bool) (*contentEntity, *storage.SaveFileResult, error) {
+	err := s.quotaReserver.ReserveQuota(quota.ReserveQuotaParam{
+		ApplicationId: tenantId,
+		Size:          f.Size,
+		IsVersion:     isVersion,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// one byte over the declared size is read to tell a longer content apart
+	hash := sha256.New()
+	counter := &sizeCounter{}
+	reader := io.TeeReader(io.LimitReader(f.Stream, f.Size+1), io.MultiWriter(hash, counter))
+
+	scanStatus := scanning.SCAN_STATUS_UNSCANNED
+	if s.scanService != nil && s.scanService.IsAsync() {
+		scanStatus = scanning.SCAN_STATUS_PENDING
+	}
+	var scanResult *scanning.ScanFileResult
+	var scanErr error
+	var scanWriter *io.PipeWriter
+	scanned := make(chan struct{})
+	if s.scanService != nil && !s.scanService.IsAsync() {
+		scanReader, pw := io.Pipe()
+		scanWriter = pw
+		reader = io.TeeReader(reader, scanWriter)
+		go func() {
+			defer close(scanned)
+			scanResult, scanErr = s.scanService.ScanStream(scanReader)
+			if scanErr != nil {
+				scanReader.CloseWithError(scanErr)
+				return
+			}
+			scanReader.CloseWithError(errScanStopped)
+		}()
+	}
+
+	res, err := s.fileStorage.SaveStream(storage.SaveStreamParam{
+		FileName: fileName,
+		Reader:   reader,
+		Provider: provider,
+	})
+	if scanWriter != nil {
+		scanWriter.CloseWithError(err)
+		<-scanned
+		if scanErr != nil && (err == nil || errors.Is(err, scanErr)) {
+			err = scanErr
+		}
+		if err == nil {
+			scanStatus = scanResult.ScanStatus
+		}
+	}
+	if err == nil && counter.size != f.Size {
+		err = app_error.NewValidationError([]app_error.ValidationItem{
+			{
+				Field:   "size",
+				Message: "Key: 'size' Error:Field validation for 'size' failed on the 'len' tag",
+			},
+		})
+	}
+	if err != nil {
+		if res != nil {
+			s.fileStorage.DeleteFile(fmt.Sprintf("%s/%s", res.FileLocation, res.FileName))
+		}
+		s.releaseQuota(tenantId, f.Size, isVersion)
+		return nil, nil, err
+	}
+
+	if scanStatus == scanning.SCAN_STATUS_INFECTED {
+		s.scanService.QuarantineStoredFile(scanning.QuarantineStoredFileParam{
+			FileLocation: res.FileLocation,
+			FileName:     res.FileName,
+		})
+		s.releaseQuota(tenantId, f.Size, isVersion)
+		return nil, nil, app_error.NewInfectedError("File")
+	}
+
+	content := &contentEntity{
+		Size:       counter.size,
+		Checksum:   hex.EncodeToString(hash.Sum(nil)),
+		ScanStatus: scanStatus,
+	}
+	return content, res, nil
+}
+
+// sizeCounter counts the bytes written to it
+type sizeCounter struct {
+	size int64
+}
+
+func (c *sizeCounter) Write(p []byte) (int, error) {
+	c.size += int64(len(p))
+	return len(p), nil
+}
+
+// processContent scans, sanitizes and inspects the uploaded content before it is stored
+func (s *uploadService) processContent(app *application.ApplicationEntity, fileName string, f *file.FileEntity) (*contentEntity, error) {
+	scanStatus := scanning.SCAN_STATUS_UNSCANNED
+	if s.scanService != nil && s.scanService.IsAsync() {
+		scanStatus = scanning.SCAN_STATUS_PENDING
+	}
+	if s.scanService != nil && !s.scanService.IsAsync() {
+		scanResult, err := s.scanService.ScanFile(scanning.ScanFileParam{
+			FileName: fileName,
+			FileData: f.Data,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if scanResult.ScanStatus == scanning.SCAN_STATUS_INFECTED {
+			return nil, app_error.NewInfectedError("File")
+		}
+		scanStatus = scanResult.ScanStatus
+	}
+
+	fileData := f.Data
+	metadataStripped := false
+	if app.StripMetadata {
+		stripResult, err := s.sanitizer.StripMetadata(sanitizing.StripMetadataParam{
+			Mimetype: f.DetectedMimetype,
+			FileData: fileData,
+		})
+		if err != nil {
+			return nil, err
+		}
+		fileData = stripResult.FileData
+		metadataStripped = stripResult.IsStripped
+	}
+	checksum := sha256.Sum256(fileData)
+
+	metadata := s.extractor.ExtractMetadata(extracting.ExtractMetadataParam{
+		Mimetype: f.DetectedMimetype,
+		FileData: fileData,
+	})
+	extractedMetadata := ""
+	if !metadata.IsEmpty() {
+		encoded, err := s.serializer.Encode(metadata)
+		if err != nil {
+			return nil, err
+		}
+		extractedMetadata = string(encoded)
+	}
+
+	content := &contentEntity{
+		FileData:          fileData,
+		Size:              int64(len(fileData)),
+		Checksum:          hex.EncodeToString(checksum[:]),
+		ScanStatus:        scanStatus,
+		Metadata:          metadata,
+		ExtractedMetadata: extractedMetadata,
+		MetadataStripped:  metadataStripped,
+	}
+	return content, nil
+}
+
+// generateVariants creates the application presets of the stored content,
+// the content is already persisted so failing presets are simply missing from the variant list
+func (s *uploadService) generateVariants(p variant.GenerateVariantsParam, publicUrl string) []VariantEntity {
+	generated, _ := s.variantService.GenerateVariants(p)
+	variants := []VariantEntity{}
+	for _, v := range generated {
+		variants = append(variants, VariantEntity{
+			Name:      v.Name,
+			Extension: v.Extension,
+			Mimetype:  v.Mimetype,
+			Size:      v.Size,
+			Width:     v.Width,
+			Height:    v.Height,
+			Url:       fmt.Sprintf("%s?variant=%s", publicUrl, v.Name),
+		})
+	}
+	return variants
+}
+
+// releaseQuota gives back the reserved quota of a failed upload,
+// the original upload error is more relevant to the caller than the release one
+func (s *uploadService) releaseQuota(applicationId string, size int64, isVersion bool) {
+	s.quotaReserver.ReleaseQuota(quota.ReleaseQuotaParam{
+		ApplicationId: applicationId,
+		Size:          size,
+		IsVersion:     isVersion,
+	})
+}
+
+// UploadServiceDeps holds the collaborators processing the content besides storing it,
+// nil ScanService uploads without scanning
+type UploadServiceDeps struct {
+	ScanService    scanning.ScanService
+	VariantService variant.VariantService
+	Extractor      extracting.Extractor
+	Sanitizer      sanitizing.Sanitizer
+	VersionPruner  versioning.VersionPruner
+	Unpacker       unpacking.Unpacker
+	Fetcher        fetching.Fetcher
+}
+
+func NewUploadService(v validation.Validator, cg config.Getter, fs storage.StreamStorage, sg text.Generator, fr repository.FileRepository, qr quota.QuotaReserver, ag application.ApplicationGetter, sz serialization.Serializer, fp file.FileParser, d UploadServiceDeps) UploadService {
 	return &uploadService{
 		validator:       v,
 		configGetter:    cg,
-		storageSaver:    ss,
+		fileStorage:     fs,
 		stringGenerator: sg,
 		fileRepo:        fr,
+		quotaReserver:   qr,
+		appGetter:       ag,
+		serializer:      sz,
+		fileParser:      fp,
+		scanService:     d.ScanService,
+		variantService:  d.VariantService,
+		extractor:       d.Extractor,
+		sanitizer:       d.Sanitizer,
+		versionPruner:   d.VersionPruner,
+		unpacker:        d.Unpacker,
+		fetcher:         d.Fetcher,
 	}
 }
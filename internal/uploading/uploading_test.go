@@ -0,0 +1,52 @@
+package uploading_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestUploading(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Uploading Package")
+}
+
+type FakeConfig struct {
+	values map[string]interface{}
+}
+
+func (c *FakeConfig) GetString(key string) string {
+	v, _ := c.values[key].(string)
+	return v
+}
+
+func (c *FakeConfig) GetInt(key string) int {
+	v, _ := c.values[key].(int)
+	return v
+}
+
+func (c *FakeConfig) GetBool(key string) bool {
+	v, _ := c.values[key].(bool)
+	return v
+}
+
+func (c *FakeConfig) Get(key string) interface{} {
+	return c.values[key]
+}
+
+// NewWebp creates lossless webp holding only the header, which is enough to read its dimension
+func NewWebp(width, height int) []byte {
+	header := make([]byte, 5)
+	header[0] = 0x2f
+	binary.LittleEndian.PutUint32(header[1:], uint32(width-1)|uint32(height-1)<<14)
+	chunk := append([]byte("VP8L"), 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(chunk[4:], uint32(len(header)))
+	chunk = append(chunk, header...)
+
+	data := append([]byte("RIFF"), 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(data[4:], uint32(4+len(chunk)))
+	data = append(data, "WEBP"...)
+	return append(data, chunk...)
+}
@@ -2,12 +2,17 @@ package validation_go
 
 import (
 	"reflect"
+	"regexp"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"idaman.id/storage/internal/config"
+	"idaman.id/storage/internal/file"
 )
 
+var metadataKeyRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_.-]{0,63}$`)
+var tagRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_.:-]{0,63}$`)
+
 type CustomValidator = func(fl validator.FieldLevel) bool
 type CustomTagName = func(field reflect.StructField) string
 
@@ -34,14 +39,82 @@ func NewValidFileSizeRule(configGetter config.Getter) CustomValidator {
 	}
 }
 
-// func NewValidProviderRule() CustomValidator {
-// 	return func(fl validator.FieldLevel) bool {
-// 		value := fl.Field().Interface().(string)
+func NewValidProviderRule(configGetter config.Getter) CustomValidator {
+	return func(fl validator.FieldLevel) bool {
+		value := fl.Field().Interface().(string)
 
-// 		isProviderValid := value == "local"
-// 		return isProviderValid
-// 	}
-// }
+		providers := strings.Split(configGetter.GetString("STORAGE_PROVIDERS"), ",")
+		for _, provider := range providers {
+			if strings.TrimSpace(provider) == value {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// NewValidContentTypeRule compares the sniffed mimetype against the declared
+// "Extension" and "Mimetype" fields of the same struct
+func NewValidContentTypeRule() CustomValidator {
+	return func(fl validator.FieldLevel) bool {
+		detected := fl.Field().String()
+		parent := fl.Parent()
+
+		ext := parent.FieldByName("Extension")
+		if ext.IsValid() && !file.IsExtensionCompatible(ext.String(), detected) {
+			return false
+		}
+		mimetype := parent.FieldByName("Mimetype")
+		if mimetype.IsValid() && !file.IsMimeTypeCompatible(mimetype.String(), detected) {
+			return false
+		}
+		return true
+	}
+}
+
+// NewValidMetadataRule limits the amount of key, the key format
+// and the total size of keys and values of user metadata
+func NewValidMetadataRule(configGetter config.Getter) CustomValidator {
+	return func(fl validator.FieldLevel) bool {
+		metadata, _ := fl.Field().Interface().(map[string]string)
+		if len(metadata) > configGetter.GetInt("METADATA_MAX_KEYS") {
+			return false
+		}
+
+		size := 0
+		for key, value := range metadata {
+			if !metadataKeyRegexp.MatchString(key) {
+				return false
+			}
+			size += len(key) + len(value)
+		}
+		return size <= configGetter.GetInt("METADATA_MAX_SIZE")
+	}
+}
+
+// NewValidTagsRule limits the amount of tag and expects lowercase tags
+func NewValidTagsRule(configGetter config.Getter) CustomValidator {
+	return func(fl validator.FieldLevel) bool {
+		tags, _ := fl.Field().Interface().([]string)
+		if len(tags) > configGetter.GetInt("TAGS_MAX_COUNT") {
+			return false
+		}
+
+		for _, tag := range tags {
+			if !tagRegexp.MatchString(tag) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// NewValidSlugRule expects a slug which is already in its slugged form
+func NewValidSlugRule() CustomValidator {
+	return func(fl validator.FieldLevel) bool {
+		return file.IsValidSlug(fl.Field().String())
+	}
+}
 
 // func NewValidFileAmountRule(configGetter config.Getter) CustomValidator {
 // 	return func(fl validator.FieldLevel) bool {
@@ -1,7 +1,9 @@
 package validation_go
 
 import (
+	"fmt"
 	"reflect"
+	"sort"
 
 	"github.com/go-playground/locales/en"
 	ut "github.com/go-playground/universal-translator"
@@ -42,6 +44,40 @@ func (s *goValidationService) Validate(i interface{}) error {
 	return vErr
 }
 
+// ValidateRules validates each data field against its validator tag rule,
+// every failing field is reported as one validation item
+func (s *goValidationService) ValidateRules(data map[string]interface{}, rules map[string]string) error {
+	fields := []string{}
+	for field := range rules {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var errItems []app_error.ValidationItem
+	for _, field := range fields {
+		err := s.v.Var(data[field], rules[field])
+		if err == nil {
+			continue
+		}
+
+		vErrors, isValidationErrors := err.(validator.ValidationErrors)
+		if !isValidationErrors {
+			return err
+		}
+		for _, vErr := range vErrors {
+			errItems = append(errItems, app_error.ValidationItem{
+				Field:   field,
+				Message: fmt.Sprintf("Key: '%s' Error:Field validation for '%s' failed on the '%s' tag", field, field, vErr.Tag()),
+			})
+		}
+	}
+
+	if len(errItems) == 0 {
+		return nil
+	}
+	return app_error.NewValidationError(errItems)
+}
+
 func NewGoValidator(cg config.Getter) (*goValidationService, error) {
 	en := en.New()
 	uni := ut.New(en, en)
@@ -63,6 +99,26 @@ func NewGoValidator(cg config.Getter) (*goValidationService, error) {
 			name: "valid_file_size",
 			fn:   NewValidFileSizeRule(cg),
 		},
+		{
+			name: "valid_provider",
+			fn:   NewValidProviderRule(cg),
+		},
+		{
+			name: "valid_content_type",
+			fn:   NewValidContentTypeRule(),
+		},
+		{
+			name: "valid_metadata",
+			fn:   NewValidMetadataRule(cg),
+		},
+		{
+			name: "valid_tags",
+			fn:   NewValidTagsRule(cg),
+		},
+		{
+			name: "valid_slug",
+			fn:   NewValidSlugRule(),
+		},
 	}
 
 	for _, cv := range cValidations {
@@ -2,4 +2,5 @@ package validation
 
 type Validator interface {
 	Validate(i interface{}) error
+	ValidateRules(data map[string]interface{}, rules map[string]string) error
 }
@@ -0,0 +1,28 @@
+package variant
+
+type VariantGenerator interface {
+	GenerateVariants(p GenerateVariantsParam) ([]VariantEntity, error)
+}
+
+type VariantRemover interface {
+	RemoveVariants(p RemoveVariantsParam) error
+}
+
+type VariantService interface {
+	VariantGenerator
+	VariantRemover
+}
+
+// GenerateVariantsParam names the generated objects after BaseName,
+// so every content version of the file has its own objects, empty BaseName uses UniqueId
+type GenerateVariantsParam struct {
+	UniqueId      string
+	BaseName      string
+	ApplicationId string
+	Mimetype      string
+	FileData      []byte
+}
+
+type RemoveVariantsParam struct {
+	UniqueId string
+}
@@ -0,0 +1,15 @@
+package variant
+
+import "time"
+
+type VariantEntity struct {
+	Name         string
+	Extension    string
+	Mimetype     string
+	Size         int64
+	Width        int
+	Height       int
+	FileLocation string
+	FileName     string
+	CreatedAt    *time.Time
+}
@@ -0,0 +1,121 @@
+package variant
+
+import (
+	"fmt"
+	"time"
+
+	"idaman.id/storage/internal/application"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/imaging"
+	"idaman.id/storage/internal/repository"
+	"idaman.id/storage/internal/storage"
+)
+
+type variantService struct {
+	appGetter      application.ApplicationGetter
+	transformer    imaging.Transformer
+	storageSaver   storage.Saver
+	storageDeleter storage.Deleter
+	variantRepo    repository.VariantRepository
+}
+
+// GenerateVariants creates every preset of the application from the uploaded image,
+// preset which can not be applied to the file is skipped so non image upload has no variant
+func (s *variantService) GenerateVariants(p GenerateVariantsParam) ([]VariantEntity, error) {
+	app := s.appGetter.GetApplication(p.ApplicationId)
+	baseName := p.BaseName
+	if baseName == "" {
+		baseName = p.UniqueId
+	}
+	variants := []VariantEntity{}
+	savedFiles := map[string]*storage.SaveFileResult{}
+
+	for _, preset := range app.Presets {
+		option, err := s.transformer.Normalize(imaging.TransformOption{
+			Width:   preset.Width,
+			Height:  preset.Height,
+			Fit:     preset.Fit,
+			Crop:    preset.Crop,
+			Quality: preset.Quality,
+			Format:  preset.Format,
+		}, p.Mimetype)
+		if err != nil {
+			continue
+		}
+
+		res, err := s.transformer.Transform(p.FileData, *option)
+		if err != nil {
+			continue
+		}
+
+		// preset shares the on-demand transformation cache name, identical presets share the same object
+		fileName := fmt.Sprintf("%s.%s.%s", baseName, option.Key(), option.Extension())
+		saved, isSaved := savedFiles[fileName]
+		if !isSaved {
+			saved, err = s.storageSaver.SaveFile(storage.SaveFileParam{
+				FileName: fileName,
+				FileData: res.Data,
+			})
+			if err != nil {
+				return variants, err
+			}
+			savedFiles[fileName] = saved
+		}
+
+		createdAt := time.Now()
+		variant := VariantEntity{
+			Name:         preset.Name,
+			Extension:    res.Extension,
+			Mimetype:     res.Mimetype,
+			Size:         int64(len(res.Data)),
+			Width:        res.Width,
+			Height:       res.Height,
+			FileLocation: saved.FileLocation,
+			FileName:     saved.FileName,
+			CreatedAt:    &createdAt,
+		}
+		err = s.variantRepo.Save(repository.SaveVariantParam{
+			FileUniqueId: p.UniqueId,
+			Name:         variant.Name,
+			Extension:    variant.Extension,
+			Mimetype:     variant.Mimetype,
+			Size:         variant.Size,
+			Width:        variant.Width,
+			Height:       variant.Height,
+			FileLocation: variant.FileLocation,
+			FileName:     variant.FileName,
+			CreatedAt:    variant.CreatedAt,
+		})
+		if err != nil {
+			return variants, err
+		}
+		variants = append(variants, variant)
+	}
+	return variants, nil
+}
+
+func (s *variantService) RemoveVariants(p RemoveVariantsParam) error {
+	variants, err := s.variantRepo.FindByFile(p.UniqueId)
+	if err != nil {
+		return err
+	}
+
+	for _, variant := range variants {
+		localPath := fmt.Sprintf("%s/%s", variant.FileLocation, variant.FileName)
+		err = s.storageDeleter.DeleteFile(localPath)
+		if _, isNotFound := err.(*app_error.NotfoundError); err != nil && !isNotFound {
+			return err
+		}
+	}
+	return s.variantRepo.DeleteByFile(p.UniqueId)
+}
+
+func NewVariantService(ag application.ApplicationGetter, tr imaging.Transformer, ss storage.Saver, sd storage.Deleter, vr repository.VariantRepository) VariantService {
+	return &variantService{
+		appGetter:      ag,
+		transformer:    tr,
+		storageSaver:   ss,
+		storageDeleter: sd,
+		variantRepo:    vr,
+	}
+}
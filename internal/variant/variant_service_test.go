@@ -0,0 +1,115 @@
+package variant_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/application"
+	"idaman.id/storage/internal/repository"
+	"idaman.id/storage/internal/variant"
+)
+
+var _ = Describe("Variant Service", func() {
+	var (
+		appGetter   *FakeApplicationGetter
+		fileStorage *FakeStorage
+		variantRepo *FakeVariantRepository
+		s           variant.VariantService
+		p           variant.GenerateVariantsParam
+	)
+
+	BeforeEach(func() {
+		appGetter = &FakeApplicationGetter{presets: []application.PresetEntity{
+			{Name: "thumb", Width: 150, Height: 150, Fit: "cover"},
+			{Name: "medium", Width: 800},
+		}}
+		fileStorage = &FakeStorage{}
+		variantRepo = &FakeVariantRepository{}
+		s = variant.NewVariantService(appGetter, &FakeTransformer{}, fileStorage, fileStorage, variantRepo)
+		p = variant.GenerateVariantsParam{
+			UniqueId:      "file-1",
+			ApplicationId: "app-1",
+			Mimetype:      "image/png",
+			FileData:      []byte("png"),
+		}
+	})
+
+	Context("GenerateVariants method", func() {
+		When("file is an image", func() {
+			It("should generate every preset", func() {
+				res, err := s.GenerateVariants(p)
+
+				Expect(err).To(BeNil())
+				Expect(res).To(HaveLen(2))
+				Expect(res[0].Name).To(Equal("thumb"))
+				Expect(res[0].Width).To(Equal(150))
+				Expect(res[0].Mimetype).To(Equal("image/png"))
+				Expect(res[0].FileName).To(MatchRegexp(`^file-1\.[0-9a-f]{16}\.png$`))
+				Expect(res[1].Name).To(Equal("medium"))
+				Expect(fileStorage.saved).To(HaveLen(2))
+				Expect(variantRepo.variants).To(HaveLen(2))
+			})
+		})
+
+		When("base name is given", func() {
+			It("should name the objects after the content version", func() {
+				p.BaseName = "file-1.v2"
+				res, err := s.GenerateVariants(p)
+
+				Expect(err).To(BeNil())
+				Expect(res[0].FileName).To(MatchRegexp(`^file-1\.v2\.[0-9a-f]{16}\.png$`))
+				Expect(variantRepo.variants[0].FileUniqueId).To(Equal("file-1"))
+			})
+		})
+
+		When("file is not an image", func() {
+			It("should not generate any variant", func() {
+				p.Mimetype = "application/pdf"
+				res, err := s.GenerateVariants(p)
+
+				Expect(err).To(BeNil())
+				Expect(res).To(BeEmpty())
+				Expect(fileStorage.saved).To(BeEmpty())
+			})
+		})
+
+		When("presets are identical", func() {
+			It("should share the stored object", func() {
+				appGetter.presets = append(appGetter.presets, application.PresetEntity{Name: "small", Width: 150, Height: 150, Fit: "cover"})
+				res, err := s.GenerateVariants(p)
+
+				Expect(err).To(BeNil())
+				Expect(res).To(HaveLen(3))
+				Expect(res[2].FileName).To(Equal(res[0].FileName))
+				Expect(fileStorage.saved).To(HaveLen(2))
+			})
+		})
+
+		When("variant record can not be saved", func() {
+			It("should return error", func() {
+				variantRepo.failSave = true
+				res, err := s.GenerateVariants(p)
+
+				Expect(res).To(BeEmpty())
+				Expect(err).To(MatchError("db error"))
+			})
+		})
+	})
+
+	Context("RemoveVariants method", func() {
+		When("file has variants", func() {
+			It("should remove the objects and the records", func() {
+				variantRepo.variants = []repository.VariantModel{
+					{FileUniqueId: "file-1", Name: "thumb", FileLocation: "storage/file", FileName: "thumb.png"},
+					{FileUniqueId: "file-1", Name: "medium", FileLocation: "storage/file", FileName: "missing.png"},
+					{FileUniqueId: "file-2", Name: "thumb", FileLocation: "storage/file", FileName: "other.png"},
+				}
+				err := s.RemoveVariants(variant.RemoveVariantsParam{UniqueId: "file-1"})
+
+				Expect(err).To(BeNil())
+				Expect(fileStorage.deleted).To(Equal([]string{"storage/file/thumb.png"}))
+				Expect(variantRepo.variants).To(HaveLen(1))
+				Expect(variantRepo.variants[0].FileUniqueId).To(Equal("file-2"))
+			})
+		})
+	})
+})
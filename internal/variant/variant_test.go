@@ -0,0 +1,112 @@
+package variant_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/application"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/imaging"
+	"idaman.id/storage/internal/repository"
+	"idaman.id/storage/internal/storage"
+)
+
+func TestVariant(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Variant Package")
+}
+
+type FakeApplicationGetter struct {
+	presets []application.PresetEntity
+}
+
+func (stub *FakeApplicationGetter) GetApplication(id string) *application.ApplicationEntity {
+	return &application.ApplicationEntity{
+		Id:      id,
+		Presets: stub.presets,
+	}
+}
+
+// FakeTransformer only supports png source, the output data is the option key
+type FakeTransformer struct {
+}
+
+func (stub *FakeTransformer) Normalize(o imaging.TransformOption, mimetype string) (*imaging.TransformOption, error) {
+	if mimetype != "image/png" {
+		return nil, app_error.NewUnsupportedError("Image")
+	}
+	if o.Format == "" {
+		o.Format = imaging.FORMAT_PNG
+	}
+	return &o, nil
+}
+
+func (stub *FakeTransformer) Transform(data []byte, o imaging.TransformOption) (*imaging.TransformResult, error) {
+	res := &imaging.TransformResult{
+		Data:      []byte(o.Key()),
+		Mimetype:  o.Mimetype(),
+		Extension: o.Extension(),
+		Width:     o.Width,
+		Height:    o.Height,
+	}
+	return res, nil
+}
+
+type FakeStorage struct {
+	saved   []string
+	deleted []string
+}
+
+func (s *FakeStorage) SaveFile(p storage.SaveFileParam) (*storage.SaveFileResult, error) {
+	s.saved = append(s.saved, p.FileName)
+	return &storage.SaveFileResult{FileLocation: "storage/file", FileName: p.FileName}, nil
+}
+
+func (s *FakeStorage) DeleteFile(localPath string) error {
+	if localPath == "storage/file/missing.png" {
+		return app_error.NewNotfoundError("File")
+	}
+	s.deleted = append(s.deleted, localPath)
+	return nil
+}
+
+type FakeVariantRepository struct {
+	variants []repository.VariantModel
+	failSave bool
+}
+
+func (r *FakeVariantRepository) FindByFile(fileUniqueId string) ([]repository.VariantModel, error) {
+	res := []repository.VariantModel{}
+	for _, v := range r.variants {
+		if v.FileUniqueId == fileUniqueId {
+			res = append(res, v)
+		}
+	}
+	return res, nil
+}
+
+func (r *FakeVariantRepository) Save(p repository.SaveVariantParam) error {
+	if r.failSave {
+		return errors.New("db error")
+	}
+	r.variants = append(r.variants, repository.VariantModel{
+		FileUniqueId: p.FileUniqueId,
+		Name:         p.Name,
+		FileLocation: p.FileLocation,
+		FileName:     p.FileName,
+	})
+	return nil
+}
+
+func (r *FakeVariantRepository) DeleteByFile(fileUniqueId string) error {
+	res := []repository.VariantModel{}
+	for _, v := range r.variants {
+		if v.FileUniqueId != fileUniqueId {
+			res = append(res, v)
+		}
+	}
+	r.variants = res
+	return nil
+}
@@ -0,0 +1,43 @@
+package versioning
+
+import "idaman.id/storage/internal/auth"
+
+type VersionLister interface {
+	ListVersions(p ListVersionsParam) ([]VersionEntity, error)
+}
+
+type VersionRestorer interface {
+	RestoreVersion(p RestoreVersionParam) error
+}
+
+type VersionPruner interface {
+	PruneVersions(p PruneVersionsParam) error
+}
+
+type VersionService interface {
+	VersionLister
+	VersionRestorer
+	VersionPruner
+}
+
+type ListVersionsParam struct {
+	Identifier string
+	Claims     *auth.Claims
+}
+
+// RestoreVersionParam copies the content of Version into a new current version,
+// non zero Revision only restores the content when the file is still at that revision
+type RestoreVersionParam struct {
+	Identifier string
+	Claims     *auth.Claims
+	Version    int64
+	Revision   int64
+}
+
+// PruneVersionsParam removes the oldest versions above the application max versions,
+// the current version is always kept
+type PruneVersionsParam struct {
+	UniqueId       string
+	ApplicationId  string
+	CurrentVersion int64
+}
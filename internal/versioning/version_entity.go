@@ -0,0 +1,15 @@
+package versioning
+
+import "time"
+
+type VersionEntity struct {
+	Version    int64
+	Size       int64
+	Extension  string
+	Mimetype   string
+	ScanStatus string
+	Checksum   string
+	Url        string
+	IsCurrent  bool
+	CreatedAt  *time.Time
+}
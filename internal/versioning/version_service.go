@@ -0,0 +1,219 @@
+package versioning
+
+import (
+	"fmt"
+	"time"
+
+	"idaman.id/storage/internal/application"
+	"idaman.id/storage/internal/config"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/quota"
+	"idaman.id/storage/internal/repository"
+	"idaman.id/storage/internal/scanning"
+	"idaman.id/storage/internal/storage"
+	"idaman.id/storage/internal/variant"
+)
+
+type versionService struct {
+	configGetter   config.Getter
+	fileRepo       repository.FileRepository
+	versionRepo    repository.VersionRepository
+	fileStorage    storage.Storage
+	quotaReserver  quota.QuotaReserver
+	appGetter      application.ApplicationGetter
+	variantService variant.VariantService
+}
+
+func (s *versionService) ListVersions(p ListVersionsParam) ([]VersionEntity, error) {
+	fileRecord, err := s.fileRepo.FindByIdentifier(p.Identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.Claims.CanAccessTenant(fileRecord.TenantId) {
+		return nil, app_error.NewForbiddenError("File")
+	}
+
+	versionRecords, err := s.versionRepo.FindByFile(fileRecord.UniqueId)
+	if err != nil {
+		return nil, err
+	}
+
+	appUrl := s.configGetter.GetString("APP_URL")
+	url := fmt.Sprintf("%s/%s/%s.%s", appUrl, "file", fileRecord.Slug, fileRecord.Extension)
+	versions := []VersionEntity{}
+	for _, v := range versionRecords {
+		versions = append(versions, VersionEntity{
+			Version:    v.Version,
+			Size:       v.Size,
+			Extension:  v.Extension,
+			Mimetype:   v.Mimetype,
+			ScanStatus: v.ScanStatus,
+			Checksum:   v.Checksum,
+			Url:        fmt.Sprintf("%s?version=%d", url, v.Version),
+			IsCurrent:  v.Version == fileRecord.Version,
+			CreatedAt:  v.CreatedAt,
+		})
+	}
+	return versions, nil
+}
+
+// RestoreVersion stores a copy of a previous version as the new current version,
+// so the history keeps growing and the restored version is pruned like any other
+func (s *versionService) RestoreVersion(p RestoreVersionParam) error {
+	fileRecord, err := s.fileRepo.FindByIdentifier(p.Identifier)
+	if err != nil {
+		return err
+	}
+
+	if !p.Claims.CanAccessTenant(fileRecord.TenantId) {
+		return app_error.NewForbiddenError("File")
+	}
+
+	if p.Revision != 0 && p.Revision != fileRecord.Revision {
+		return app_error.NewPreconditionFailedError("File")
+	}
+
+	source, err := s.versionRepo.FindVersion(fileRecord.UniqueId, p.Version)
+	if err != nil {
+		return err
+	}
+
+	if !scanning.IsServable(source.ScanStatus) {
+		return app_error.NewUnavailableError("Version")
+	}
+
+	fileData, err := s.fileStorage.RetrieveFile(fmt.Sprintf("%s/%s", source.FileLocation, source.FileName))
+	if err != nil {
+		return err
+	}
+
+	err = s.quotaReserver.ReserveQuota(quota.ReserveQuotaParam{
+		ApplicationId: fileRecord.TenantId,
+		Size:          source.Size,
+		IsVersion:     true,
+	})
+	if err != nil {
+		return err
+	}
+
+	version := fileRecord.Version + 1
+	res, err := s.fileStorage.SaveFile(storage.SaveFileParam{
+		FileName: VersionFileName(fileRecord.UniqueId, version, source.Extension),
+		FileData: fileData,
+		Provider: fileRecord.Provider,
+	})
+	if err == nil {
+		createdAt := time.Now()
+		err = s.fileRepo.ReplaceContent(repository.ReplaceContentParam{
+			UniqueId:          fileRecord.UniqueId,
+			Version:           version,
+			Size:              source.Size,
+			Extension:         source.Extension,
+			Mimetype:          source.Mimetype,
+			DetectedMimetype:  source.DetectedMimetype,
+			FileLocation:      res.FileLocation,
+			FileName:          res.FileName,
+			ScanStatus:        source.ScanStatus,
+			ExtractedMetadata: source.ExtractedMetadata,
+			Checksum:          source.Checksum,
+			MetadataStripped:  source.MetadataStripped,
+			Revision:          p.Revision,
+			CreatedAt:         &createdAt,
+		})
+	}
+	if err != nil {
+		s.quotaReserver.ReleaseQuota(quota.ReleaseQuotaParam{
+			ApplicationId: fileRecord.TenantId,
+			Size:          source.Size,
+			IsVersion:     true,
+		})
+		return err
+	}
+
+	// the content is already restored, failing presets are simply missing from the variant list
+	err = s.variantService.RemoveVariants(variant.RemoveVariantsParam{
+		UniqueId: fileRecord.UniqueId,
+	})
+	if err == nil {
+		s.variantService.GenerateVariants(variant.GenerateVariantsParam{
+			UniqueId:      fileRecord.UniqueId,
+			BaseName:      VersionBaseName(fileRecord.UniqueId, version),
+			ApplicationId: fileRecord.TenantId,
+			Mimetype:      source.Mimetype,
+			FileData:      fileData,
+		})
+	}
+
+	return s.PruneVersions(PruneVersionsParam{
+		UniqueId:       fileRecord.UniqueId,
+		ApplicationId:  fileRecord.TenantId,
+		CurrentVersion: version,
+	})
+}
+
+func (s *versionService) PruneVersions(p PruneVersionsParam) error {
+	app := s.appGetter.GetApplication(p.ApplicationId)
+	if app.MaxVersions <= 0 {
+		return nil
+	}
+
+	versions, err := s.versionRepo.FindByFile(p.UniqueId)
+	if err != nil {
+		return err
+	}
+
+	excess := len(versions) - app.MaxVersions
+	for _, v := range versions {
+		if excess <= 0 {
+			break
+		}
+		if v.Version == p.CurrentVersion {
+			continue
+		}
+
+		err = s.versionRepo.Delete(p.UniqueId, v.Version)
+		if err != nil {
+			return err
+		}
+		err = s.quotaReserver.ReleaseQuota(quota.ReleaseQuotaParam{
+			ApplicationId: p.ApplicationId,
+			Size:          v.Size,
+			IsVersion:     true,
+		})
+		if err != nil {
+			return err
+		}
+		err = s.fileStorage.DeleteFile(fmt.Sprintf("%s/%s", v.FileLocation, v.FileName))
+		if _, isNotFound := err.(*app_error.NotfoundError); err != nil && !isNotFound {
+			return err
+		}
+		excess--
+	}
+	return nil
+}
+
+// VersionBaseName names the stored objects of a content version without extension,
+// the first version keeps the name given on upload
+func VersionBaseName(uniqueId string, version int64) string {
+	if version <= 1 {
+		return uniqueId
+	}
+	return fmt.Sprintf("%s.v%d", uniqueId, version)
+}
+
+func VersionFileName(uniqueId string, version int64, extension string) string {
+	return fmt.Sprintf("%s.%s", VersionBaseName(uniqueId, version), extension)
+}
+
+func NewVersionService(cg config.Getter, fr repository.FileRepository, vr repository.VersionRepository, fs storage.Storage, qr quota.QuotaReserver, ag application.ApplicationGetter, vs variant.VariantService) VersionService {
+	return &versionService{
+		configGetter:   cg,
+		fileRepo:       fr,
+		versionRepo:    vr,
+		fileStorage:    fs,
+		quotaReserver:  qr,
+		appGetter:      ag,
+		variantService: vs,
+	}
+}
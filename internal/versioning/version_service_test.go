@@ -0,0 +1,100 @@
+package versioning_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/quota"
+	"idaman.id/storage/internal/repository"
+	"idaman.id/storage/internal/versioning"
+)
+
+var _ = Describe("Version Service", func() {
+	var (
+		appGetter     *FakeApplicationGetter
+		fileStorage   *FakeStorage
+		versionRepo   *FakeVersionRepository
+		quotaReserver *FakeQuotaReserver
+		s             versioning.VersionService
+		p             versioning.PruneVersionsParam
+	)
+
+	BeforeEach(func() {
+		appGetter = &FakeApplicationGetter{maxVersions: 2}
+		fileStorage = &FakeStorage{}
+		versionRepo = &FakeVersionRepository{
+			versions: []repository.VersionModel{
+				{FileUniqueId: "file-1", Version: 1, Size: 10, FileLocation: "storage/file", FileName: "file-1.png"},
+				{FileUniqueId: "file-1", Version: 2, Size: 20, FileLocation: "storage/file", FileName: "missing.png"},
+				{FileUniqueId: "file-1", Version: 3, Size: 30, FileLocation: "storage/file", FileName: "file-1.v3.png"},
+				{FileUniqueId: "file-2", Version: 1, Size: 40, FileLocation: "storage/file", FileName: "file-2.png"},
+			},
+		}
+		quotaReserver = &FakeQuotaReserver{}
+		s = versioning.NewVersionService(nil, nil, versionRepo, fileStorage, quotaReserver, appGetter, nil)
+		p = versioning.PruneVersionsParam{
+			UniqueId:       "file-1",
+			ApplicationId:  "app-1",
+			CurrentVersion: 3,
+		}
+	})
+
+	Context("PruneVersions method", func() {
+		When("file has more versions than the application keeps", func() {
+			It("should remove the oldest versions", func() {
+				err := s.PruneVersions(p)
+
+				Expect(err).To(BeNil())
+				Expect(versionRepo.versions).To(HaveLen(3))
+				Expect(versionRepo.versions[0].Version).To(Equal(int64(2)))
+				Expect(fileStorage.deleted).To(Equal([]string{"storage/file/file-1.png"}))
+				Expect(quotaReserver.released).To(Equal([]quota.ReleaseQuotaParam{
+					{ApplicationId: "app-1", Size: 10, IsVersion: true},
+				}))
+			})
+		})
+
+		When("stored object of the version is already missing", func() {
+			It("should still remove the version", func() {
+				appGetter.maxVersions = 1
+				err := s.PruneVersions(p)
+
+				Expect(err).To(BeNil())
+				Expect(versionRepo.versions).To(HaveLen(2))
+				Expect(versionRepo.versions[0].Version).To(Equal(int64(3)))
+			})
+		})
+
+		When("current version is the oldest one", func() {
+			It("should keep the current version", func() {
+				appGetter.maxVersions = 1
+				p.CurrentVersion = 1
+				err := s.PruneVersions(p)
+
+				Expect(err).To(BeNil())
+				Expect(versionRepo.versions[0].Version).To(Equal(int64(1)))
+				Expect(versionRepo.versions[1].FileUniqueId).To(Equal("file-2"))
+			})
+		})
+
+		When("application keeps every version", func() {
+			It("should not remove any version", func() {
+				appGetter.maxVersions = 0
+				err := s.PruneVersions(p)
+
+				Expect(err).To(BeNil())
+				Expect(versionRepo.versions).To(HaveLen(4))
+				Expect(fileStorage.deleted).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("VersionFileName function", func() {
+		It("should keep the uploaded name for the first version", func() {
+			Expect(versioning.VersionFileName("file-1", 1, "png")).To(Equal("file-1.png"))
+		})
+
+		It("should suffix the later versions", func() {
+			Expect(versioning.VersionFileName("file-1", 4, "png")).To(Equal("file-1.v4.png"))
+		})
+	})
+})
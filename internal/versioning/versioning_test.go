@@ -0,0 +1,96 @@
+package versioning_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"idaman.id/storage/internal/application"
+	app_error "idaman.id/storage/internal/error"
+	"idaman.id/storage/internal/quota"
+	"idaman.id/storage/internal/repository"
+	"idaman.id/storage/internal/storage"
+)
+
+func TestVersioning(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Versioning Package")
+}
+
+type FakeApplicationGetter struct {
+	maxVersions int
+}
+
+func (stub *FakeApplicationGetter) GetApplication(id string) *application.ApplicationEntity {
+	return &application.ApplicationEntity{
+		Id:          id,
+		MaxVersions: stub.maxVersions,
+	}
+}
+
+type FakeStorage struct {
+	deleted []string
+}
+
+func (s *FakeStorage) SaveFile(p storage.SaveFileParam) (*storage.SaveFileResult, error) {
+	return &storage.SaveFileResult{FileLocation: "storage/file", FileName: p.FileName}, nil
+}
+
+func (s *FakeStorage) RetrieveFile(localPath string) (storage.BinaryFile, error) {
+	return []byte{}, nil
+}
+
+func (s *FakeStorage) DeleteFile(localPath string) error {
+	if localPath == "storage/file/missing.png" {
+		return app_error.NewNotfoundError("File")
+	}
+	s.deleted = append(s.deleted, localPath)
+	return nil
+}
+
+type FakeVersionRepository struct {
+	versions []repository.VersionModel
+}
+
+func (r *FakeVersionRepository) FindByFile(fileUniqueId string) ([]repository.VersionModel, error) {
+	versions := []repository.VersionModel{}
+	for _, v := range r.versions {
+		if v.FileUniqueId == fileUniqueId {
+			versions = append(versions, v)
+		}
+	}
+	return versions, nil
+}
+
+func (r *FakeVersionRepository) FindVersion(fileUniqueId string, version int64) (*repository.VersionModel, error) {
+	for i, v := range r.versions {
+		if v.FileUniqueId == fileUniqueId && v.Version == version {
+			return &r.versions[i], nil
+		}
+	}
+	return nil, app_error.NewNotfoundError("Version")
+}
+
+func (r *FakeVersionRepository) Delete(fileUniqueId string, version int64) error {
+	versions := []repository.VersionModel{}
+	for _, v := range r.versions {
+		if v.FileUniqueId != fileUniqueId || v.Version != version {
+			versions = append(versions, v)
+		}
+	}
+	r.versions = versions
+	return nil
+}
+
+type FakeQuotaReserver struct {
+	released []quota.ReleaseQuotaParam
+}
+
+func (q *FakeQuotaReserver) ReserveQuota(p quota.ReserveQuotaParam) error {
+	return nil
+}
+
+func (q *FakeQuotaReserver) ReleaseQuota(p quota.ReleaseQuotaParam) error {
+	q.released = append(q.released, p)
+	return nil
+}